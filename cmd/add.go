@@ -1,12 +1,23 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yarlson/lnk/internal/lnk"
+	error2 "github.com/yarlson/lnk/internal/lnkerror"
 )
 
 func newAddCmd() *cobra.Command {
@@ -20,68 +31,299 @@ Examples:
   lnk add --recursive ~/.config/nvim  # Add directory contents individually  
   lnk add --dry-run ~/.gitconfig      # Preview what would be added
   lnk add --host work ~/.ssh/config   # Add host-specific configuration
+  lnk add --copy ~/.netrc             # Copy instead of symlinking
+  lnk add '~/.config/*.conf'          # Expand the pattern natively
+  lnk add --init ~/.bashrc            # Create the repository first if needed
+  lnk add --date mtime ~/.vimrc       # Date the commit by the file's mtime
+  lnk add --secret ~/.aws/credentials # Encrypt in the repo with git-crypt
+  lnk add --into shell ~/.bashrc      # Store as shell/.bashrc in the repo
+  lnk add -r --skip-errors ~/.config  # Add what can be added, report the rest
+  lnk add --absolute-symlink ~/.vimrc # Link by absolute path, not relative
+  fd -H . ~/.config/fish | lnk add -  # Add the paths piped in
+  lnk add --xattrs ~/.local/bin/tool  # Keep its user.* extended attributes
 
 The --recursive flag processes directory contents individually instead of treating 
 the directory as a single unit. This is useful for configuration directories where
 you want each file managed separately.
 
+The --stdin flag (or a lone - argument) reads the paths to add from standard
+input, one per line, for piping from find or fd; --stdin0 reads them
+NUL-separated instead, as find -print0 and fd -0 write them, for file names
+that contain newlines. Blank lines are ignored, and the paths are added as one
+transaction like any other list. Standard input without a single path is an
+error.
+
 The --dry-run flag shows you exactly what files would be added without making any
-changes to your system - perfect for verification before bulk operations.`,
-		Args:          cobra.MinimumNArgs(1),
+changes to your system - perfect for verification before bulk operations.
+
+Glob patterns (*, ?, [...]) that reach lnk unexpanded are expanded natively,
+relative to the current directory or else your home directory; a pattern that
+matches nothing is an error. ** is not supported - use --recursive instead.
+
+The --copy flag is for filesystems or tools that cannot use symlinks: each file is
+copied into the repository and the original is left in place. The two copies are
+not linked, so edits to the original need an explicit sync step - lnk push or
+lnk sync copies them into the repository before committing. Copy mode handles
+regular files only; use --recursive for directories.
+
+If there is no lnk repository yet, add offers to create one when run from a
+terminal. Pass --init to create it without asking; otherwise add fails.
+
+The --secret flag leaves encryption to git-crypt: each file gets a
+"filter=git-crypt diff=git-crypt" line in the repository's .gitattributes,
+committed together with it, so git-crypt encrypts it transparently. The
+repository must already be set up with 'git-crypt init'; otherwise add fails
+rather than commit the file in plain text.
+
+Files larger than add.max_size in config.toml (10MB by default) and files git
+would treat as binary are refused, so caches and build output do not end up in
+the history by accident; the error names the file and its size. Pass --force
+to add them anyway.
+
+Adding a file lnk already tracks fails; --force does not change that. A file
+that sits at the storage path in the repository without being tracked, such as
+a leftover from an interrupted add, also stops the add unless it has the same
+content. --force deletes that leftover and adds the file in its place; it never
+replaces the stored copy of a managed item or lnk's own files.
+
+The --date flag sets the author date of the commit, for scripted imports that
+should reflect when files were last changed: pass an RFC 3339 time, a local
+date such as 2019-05-01, or 'mtime' for the newest modification time among the
+added files. The committer date is still the current time. GIT_AUTHOR_DATE in
+the environment is honoured as well when --date is not given.
+
+Adding several files is all-or-nothing: if one cannot be moved, every file
+already added is put back. With --skip-errors, files that fail (no permission
+to read or move them, unsupported types such as sockets, over the size limit)
+are left where they are, the rest are committed together, and the skipped
+files are listed with the reason at the end.
+
+The --push flag pushes the add commit right away, for a quick "grab this config
+and sync it now". Only the added files are committed; other uncommitted changes
+in the repository stay out of the push. If the push fails, for example because
+the network is down, the files stay managed and committed locally and add
+fails with the push error; run lnk push later to send the commit.
+
+The --no-commit flag stops once the files are moved, linked, tracked and
+staged, so several adds and removes can be recorded together: run
+'lnk commit -m <message>' (or lnk push) afterwards to commit them.
+
+Pressing Ctrl+C (or sending SIGTERM) during a large add stops it after the
+file in progress: every file already moved is put back, the tracking file is
+restored, nothing stays staged, and the add fails without committing. With or
+without --skip-errors, an interrupted add leaves no partial state.
+
+Recursive adds only pick up regular files and symlinks; sockets, named pipes
+and devices in the tree are left alone, and so are directories named .git,
+node_modules or __pycache__, so a git checkout in ~/.config does not bring its
+history along (add.skip_dirs in config.toml changes the names). --verbose
+lists what was skipped.
+
+Git cannot track empty directories, so a recursive add leaves them out too.
+To keep one, such as ~/.config/app/cache, put an empty .lnkkeep file in it
+(lnk's .gitkeep): the marker is added like any other file, and restoring it
+on another machine creates the directory.
+
+Recursive adds also honour ignore files in gitignore syntax, from the
+broadest to the nearest: $XDG_CONFIG_HOME/lnk/ignore, .lnkignore at the
+repository root, and a .lnkignore in any directory from your home directory
+down. A nearer file overrides a further one, so a "!pattern" there brings back
+a file a global rule ignored; nothing inside an ignored directory comes back.
+
+The --into flag groups files under a repository directory of your choosing:
+each one is stored as <dir>/<name> instead of at its path relative to your
+home directory, while the symlink still replaces the original. The chosen
+location is recorded in the index, so pull, status and remove follow it.
+
+--chmod sets permissions such as 0600 on the stored files right after the
+move. Git only keeps the executable bit, so the mode is recorded in the index
+as well and put back on each pull, on this machine and every other one.
+
+The --hostname-suffix flag stores host-specific files next to the common
+ones instead of in a <host>.lnk/ directory: 'lnk add --host work
+--hostname-suffix ~/.bashrc' keeps the file as .bashrc.work at the repository
+root, and ~/.bashrc links to it. Each item records its layout, so 'lnk pull
+--host work' links the .work variant either way, and both layouts can be
+mixed in one repository. Set add.host_suffix = true in config.toml to make it
+the default for host adds.
+
+Symlinks are relative by default, so the repository and $HOME can move
+together. The --absolute-symlink flag links the added files by the absolute
+path of their stored copy instead, for targets a relative link cannot reach
+reliably, such as one on another mount or behind a bind mount. The mode is
+recorded per item, so pull recreates an absolute link for it and replaces a
+relative one.
+
+Git does not carry extended attributes. The --xattrs flag records the
+user.* attributes of each added file in the index, and pull and rm set them
+again on the stored file or the copy in $HOME. Other namespaces, such as
+security.* labels, are not kept, and only Linux reads and sets them.
+
+The --comment flag attaches a free-text note to each added item, such as
+why an obscure config is tracked. It is stored in the index and shown by
+'lnk list --long' and 'lnk list --json'.
+
+Adding your home directory itself, or a directory that holds it such as
+/home, is refused: it would sweep up every cache, key and checkout you have.
+Add the dotfiles you want by name instead. If you really mean to add every
+file below it, combine --recursive with --i-know-what-im-doing. A recursive
+add of 1000 files or more warns before it starts, so there is time to
+interrupt it.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+				return nil
+			}
+			if stdin0, _ := cmd.Flags().GetBool("stdin0"); stdin0 {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			host, _ := cmd.Flags().GetString("host")
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
 			recursive, _ := cmd.Flags().GetBool("recursive")
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
-			l := lnk.NewLnk(lnk.WithHost(host))
+			copyMode, _ := cmd.Flags().GetBool("copy")
+			date, _ := cmd.Flags().GetString("date")
+			force, _ := cmd.Flags().GetBool("force")
+			secret, _ := cmd.Flags().GetBool("secret")
+			into, _ := cmd.Flags().GetString("into")
+			chmod, _ := cmd.Flags().GetString("chmod")
+			skipErrors, _ := cmd.Flags().GetBool("skip-errors")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			push, _ := cmd.Flags().GetBool("push")
+			noCommit, _ := cmd.Flags().GetBool("no-commit")
+			allowHome, _ := cmd.Flags().GetBool("i-know-what-im-doing")
+			absolute, _ := cmd.Flags().GetBool("absolute-symlink")
+			xattrs, _ := cmd.Flags().GetBool("xattrs")
+			comment, _ := cmd.Flags().GetString("comment")
+			suffix, _ := cmd.Flags().GetBool("hostname-suffix")
+			if !cmd.Flags().Changed("hostname-suffix") {
+				suffix = fileConfig.HostSuffix
+			} else if suffix && host == "" {
+				return fmt.Errorf("invalid --hostname-suffix: it names host-specific files, so pass --host too")
+			}
+
+			// Ctrl+C stops a large add between files and rolls it back
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			opts := []lnk.Option{lnk.WithHost(host), lnk.WithCopy(copyMode), lnk.WithForceAdd(force), lnk.WithSecret(secret), lnk.WithInto(into), lnk.WithChmod(chmod), lnk.WithHostnameSuffix(suffix), lnk.WithNoCommit(noCommit), lnk.WithAllowHome(allowHome), lnk.WithAbsoluteSymlink(absolute), lnk.WithXattrs(xattrs), lnk.WithComment(comment), lnk.WithContext(ctx)}
+
+			// The tree may be walked twice (preview, then add); list each once
+			var special []string
+			specialKinds := make(map[string]string)
+			if verbose {
+				opts = append(opts, lnk.WithSkipHandler(func(path, kind string) {
+					if _, seen := specialKinds[path]; !seen {
+						special = append(special, path)
+					}
+					specialKinds[path] = kind
+				}))
+			}
+			l := lnk.NewLnk(opts...)
 			w := GetWriter(cmd)
 
+			if args, err = stdinPaths(cmd, args); err != nil {
+				return err
+			}
+
+			// Expand glob patterns the shell passed through (quoted or unmatched)
+			args, err = l.ExpandPaths(args)
+			if err != nil {
+				return err
+			}
+
+			// Backdate the commit; mtime needs the expanded paths
+			if date != "" {
+				authorDate, err := addAuthorDate(date, args)
+				if err != nil {
+					return err
+				}
+				l = lnk.NewLnk(append(opts, lnk.WithAuthorDate(authorDate))...)
+			}
+
 			// Handle dry-run mode
 			if dryRun {
-				files, err := l.PreviewAdd(args, recursive)
+				entries, err := l.PreviewAddEntries(args, recursive)
 				if err != nil {
 					return err
 				}
 
 				// Display preview output
 				if recursive {
-					w.Writeln(Message{Text: fmt.Sprintf("Would add %d files recursively:", len(files)), Emoji: "🔍", Bold: true})
+					w.Writeln(Message{Text: fmt.Sprintf("Would add %d files recursively:", len(entries)), Emoji: "🔍", Bold: true})
 				} else {
-					w.Writeln(Message{Text: fmt.Sprintf("Would add %d files:", len(files)), Emoji: "🔍", Bold: true})
+					w.Writeln(Message{Text: fmt.Sprintf("Would add %d files:", len(entries)), Emoji: "🔍", Bold: true})
 				}
 
-				// List files using home-relative paths so duplicate basenames remain distinguishable.
+				// List files using home-relative paths so duplicate basenames remain distinguishable,
+				// aligned with where each one would be stored in the repo.
 				// Dry-run is a preview for verification, so show all files.
-				for _, file := range files {
+				width := 0
+				for _, entry := range entries {
+					width = max(width, utf8.RuneCountInString(displaySourcePath(entry.Source)))
+				}
+				for _, entry := range entries {
+					source := displaySourcePath(entry.Source)
 					w.WriteString("   ").
-						Writeln(Message{Text: displaySourcePath(file), Emoji: "📄"})
+						Write(Message{Text: source, Emoji: "📄"}).
+						WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(source))).
+						WriteString(" → ").
+						Writeln(Colored(lnk.DisplayPath(entry.Destination), ColorCyan))
 				}
 
+				writeSkippedSpecial(w, special, specialKinds)
 				w.WritelnString("").
 					Writeln(Info("To proceed: run without --dry-run flag"))
 
 				return w.Err()
 			}
 
-			// Handle recursive mode
-			if recursive {
-				// Get preview to count files first for better output
-				previewFiles, err := l.PreviewAdd(args, recursive)
-				if err != nil {
+			// First run before `lnk init`: offer to create the repository
+			if !l.IsInitialized() {
+				initRepo, _ := cmd.Flags().GetBool("init")
+				if err := offerInit(cmd, l, w, initRepo); err != nil {
 					return err
 				}
+			}
 
-				// Only show carriage-return progress when output is a terminal;
-				// in piped/non-TTY contexts the redraw becomes noise.
-				var progressCallback lnk.ProgressCallback
-				if w.IsTerminal() {
-					progressCallback = func(current, total int, currentFile string) {
-						w.WriteString(fmt.Sprintf("\r⏳ Processing %d/%d: %s", current, total, currentFile))
+			// Only show carriage-return progress when output is a terminal;
+			// in piped/non-TTY contexts the redraw becomes noise. Batches of a
+			// few files finish without it.
+			var progressCallback lnk.ProgressCallback
+			if w.IsTerminal() {
+				progressCallback = func(current, total int, currentFile string) {
+					w.WriteString(fmt.Sprintf("\r⏳ Processing %d/%d: %s", current, total, currentFile))
+				}
+			}
+
+			// Handle recursive mode
+			var added *lnk.ManagedFile
+			var report *lnk.AddReport
+			if recursive {
+				// Get preview to count files first for better output; with
+				// --skip-errors the report lists them instead, since the
+				// preview would stop at the first unreadable entry
+				var previewFiles []string
+				if !skipErrors {
+					if previewFiles, err = l.PreviewAdd(args, recursive); err != nil {
+						return err
+					}
+					if len(previewFiles) >= largeAddFiles {
+						w.Writeln(Warning(fmt.Sprintf("Adding %d files in one go; if that is more than you meant, interrupt with Ctrl+C to put everything back", len(previewFiles))))
 					}
 				}
 
-				if err := l.AddRecursiveWithProgress(args, progressCallback); err != nil {
+				if skipErrors {
+					if report, err = l.AddRecursiveSkipErrors(args, progressCallback); err != nil {
+						return err
+					}
+					previewFiles = report.Added
+				} else if err := l.AddRecursiveWithProgress(args, progressCallback); err != nil {
 					return err
 				}
 
@@ -95,15 +337,25 @@ changes to your system - perfect for verification before bulk operations.`,
 				// Use appropriate method based on number of files
 				if len(args) == 1 {
 					// Single file - use existing Add method for backward compatibility
-					if err := l.Add(args[0]); err != nil {
+					if added, err = l.Add(args[0]); err != nil {
 						return err
 					}
+				} else if skipErrors {
+					// Multiple files, keeping whichever can be added
+					if report, err = l.AddMultipleSkipErrors(args, progressCallback); err != nil {
+						return err
+					}
+					args = report.Added
 				} else {
-					// Multiple files - use AddMultiple for atomic operation
-					if err := l.AddMultiple(args); err != nil {
+					// Multiple files - one atomic operation
+					if err := l.AddMultipleWithProgress(args, progressCallback); err != nil {
 						return err
 					}
 				}
+
+				if len(args) > 1 && w.IsTerminal() {
+					w.WriteString("\r")
+				}
 			}
 
 			// Display results
@@ -125,14 +377,14 @@ changes to your system - perfect for verification before bulk operations.`,
 					w.WriteString("   ").
 						Write(Link(displaySourcePath(args[i]))).
 						WriteString(" → ").
-						Writeln(Colored(lnk.FormatManagedPath(host, args[i]), ColorCyan))
+						Writeln(Colored(storedDisplayPath(l, host, args[i]), ColorCyan))
 				}
 
 				if len(args) > displayLimit {
 					w.WriteString("   ").
 						Writeln(Colored(fmt.Sprintf("... and %d more files", len(args)-displayLimit), ColorGray))
 				}
-			} else if len(args) == 1 {
+			} else if added != nil {
 				// Single file - maintain existing output format for backward compatibility
 				filePath := args[0]
 				basename := filepath.Base(filePath)
@@ -144,7 +396,7 @@ changes to your system - perfect for verification before bulk operations.`,
 				w.WriteString("   ").
 					Write(Link(filePath)).
 					WriteString(" → ").
-					Writeln(Colored(lnk.FormatManagedPath(host, filePath), ColorCyan))
+					Writeln(Colored(lnk.DisplayPath(added.RepoPath), ColorCyan))
 			} else {
 				// Multiple files - show summary
 				if host != "" {
@@ -163,7 +415,7 @@ changes to your system - perfect for verification before bulk operations.`,
 					w.WriteString("   ").
 						Write(Link(displaySourcePath(args[i]))).
 						WriteString(" → ").
-						Writeln(Colored(lnk.FormatManagedPath(host, args[i]), ColorCyan))
+						Writeln(Colored(storedDisplayPath(l, host, args[i]), ColorCyan))
 				}
 				if len(args) > displayLimit {
 					w.WriteString("   ").
@@ -171,6 +423,33 @@ changes to your system - perfect for verification before bulk operations.`,
 				}
 			}
 
+			if report != nil {
+				writeAddFailures(w, report.Failed)
+			}
+			writeSkippedSpecial(w, special, specialKinds)
+
+			if secret {
+				w.WriteString("   ").
+					Write(Message{Text: "Marked for git-crypt in ", Emoji: "🔒"}).
+					Write(Bold(".gitattributes")).
+					WritelnString(" — stored encrypted once committed")
+			}
+
+			if copyMode {
+				w.WriteString("   ").
+					Write(Message{Text: "Copied — originals stay in place; run ", Emoji: "📋"}).
+					Write(Bold("lnk push")).
+					WritelnString(" after editing them to sync the changes")
+			}
+
+			if noCommit {
+				writeStaged(w)
+				return w.Err()
+			}
+			if push {
+				return pushAdded(w, l, args)
+			}
+
 			w.WriteString("   ").
 				Write(Message{Text: "Use ", Emoji: "📝"}).
 				Write(Bold("lnk push")).
@@ -180,16 +459,256 @@ changes to your system - perfect for verification before bulk operations.`,
 		},
 	}
 
-	cmd.Flags().StringP("host", "H", "", "Manage file for specific host (default: common configuration)")
+	cmd.Flags().StringP("host", "H", "", "Manage file for specific host, or 'auto' for this machine's hostname (default: common configuration)")
 	cmd.Flags().BoolP("recursive", "r", false, "Add directory contents individually instead of the directory as a whole")
 	cmd.Flags().BoolP("dry-run", "n", false, "Show what would be added without making changes")
+	cmd.Flags().Bool("copy", false, "Copy files into the repo and keep the originals instead of symlinking (edits sync on push)")
+	cmd.Flags().Bool("init", false, "Create the lnk repository first if it does not exist yet")
+	cmd.Flags().Bool("secret", false, "Encrypt with git-crypt: mark the files filter=git-crypt in .gitattributes (needs 'git-crypt init' in the repo)")
+	cmd.Flags().BoolP("force", "f", false, "Add files over the size limit (add.max_size, default 10MB) or that look binary, replacing an untracked file at the storage path")
+	cmd.Flags().Bool("verbose", false, "List the sockets, named pipes, devices, excluded and empty directories a recursive add leaves out")
+	cmd.Flags().Bool("skip-errors", false, "Add the files that can be added and list the ones that fail, instead of rolling back all of them")
+	cmd.Flags().String("into", "", "Store the files as <dir>/<name> in the repo instead of at their home-relative path")
+	cmd.Flags().String("chmod", "", "Set this octal mode (e.g. 0600) on the files in the repo and restore it on every pull")
+	cmd.Flags().Bool("hostname-suffix", false, "With --host, store the files as <path>.<host> at the repo root instead of in <host>.lnk/ (default: add.host_suffix)")
+	cmd.Flags().String("date", "", "Author date for the commit (RFC 3339 or 2006-01-02), or 'mtime' for the newest file's modification time")
+	cmd.Flags().Bool("push", false, "Push the add commit to the remote right away, leaving other uncommitted changes alone")
+	cmd.Flags().Bool("no-commit", false, "Stage the files without committing them, to record several adds with one lnk commit")
+	cmd.Flags().Bool("i-know-what-im-doing", false, "With --recursive, allow adding every file under your home directory (or a directory holding it)")
+	cmd.Flags().Bool("absolute-symlink", false, "Link the files by the absolute path of their stored copy instead of a relative one")
+	cmd.Flags().Bool("xattrs", false, "Record the files' user.* extended attributes and set them again on restore (Linux only)")
+	cmd.Flags().String("comment", "", "Note why the files are tracked, shown by list --long and list --json")
+	cmd.Flags().Bool("stdin", false, "Read the paths to add from standard input, one per line (same as a - argument)")
+	cmd.Flags().Bool("stdin0", false, "Read the paths to add from standard input, NUL-separated (find -print0, fd -0)")
+	cmd.MarkFlagsMutuallyExclusive("push", "dry-run")
+	cmd.MarkFlagsMutuallyExclusive("stdin", "stdin0")
+	cmd.MarkFlagsMutuallyExclusive("absolute-symlink", "copy")
+	cmd.MarkFlagsMutuallyExclusive("no-commit", "push")
+	cmd.MarkFlagsMutuallyExclusive("no-commit", "date")
 	return cmd
 }
 
+// stdinPaths replaces a lone - argument, or adds to the arguments with
+// --stdin or --stdin0, the paths read from the command's input: one per line
+// (a trailing \r dropped) or NUL-separated. Empty entries are skipped;
+// input without any path is an error rather than an add of nothing.
+func stdinPaths(cmd *cobra.Command, args []string) ([]string, error) {
+	stdin, _ := cmd.Flags().GetBool("stdin")
+	stdin0, _ := cmd.Flags().GetBool("stdin0")
+	flag := "--stdin"
+	if stdin0 {
+		flag = "--stdin0"
+	}
+	if i := slices.Index(args, "-"); i >= 0 {
+		args = slices.Delete(slices.Clone(args), i, i+1)
+		if !stdin0 {
+			stdin, flag = true, "-"
+		}
+	}
+	if !stdin && !stdin0 {
+		return args, nil
+	}
+
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read paths from standard input: %w", err)
+	}
+	sep := "\n"
+	if stdin0 {
+		sep = "\x00"
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(data), sep) {
+		if !stdin0 {
+			path = strings.TrimSuffix(path, "\r")
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("invalid %s: standard input holds no paths to add", flag)
+	}
+	return append(args, paths...), nil
+}
+
+// pushAdded pushes the commit add just made, committing only the added
+// paths should anything about them still be uncommitted. When the push fails
+// the add stands, so the output says the files are managed and committed
+// locally before the push error is returned.
+func pushAdded(w *Writer, l *lnk.Lnk, paths []string) error {
+	message, err := l.RenderMessage(pushMessage(), paths...)
+	if err == nil {
+		err = l.PushWithOptions(message, lnk.PushOptions{Only: paths})
+	}
+	if err != nil {
+		w.WriteString("   ").
+			Writeln(Warning("Managed and committed locally, but not pushed")).
+			WriteString("   ").
+			Write(Info("Run ")).
+			Write(Bold("lnk push")).
+			WritelnString(" to retry once the remote is reachable")
+		if werr := w.Err(); werr != nil {
+			return werr
+		}
+		return err
+	}
+
+	w.WriteString("   ").
+		Writeln(Rocket("Pushed to remote"))
+	return w.Err()
+}
+
+// addAuthorDate resolves a --date value: "mtime" is the newest modification
+// time among paths (walking directories), anything else goes to lnk.ParseDate.
+func addAuthorDate(value string, paths []string) (time.Time, error) {
+	if value != "mtime" {
+		return lnk.ParseDate(value)
+	}
+
+	var newest time.Time
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read modification time of %s: %w", path, err)
+		}
+	}
+	return newest, nil
+}
+
+// offerInit creates the repository for an add that runs before `lnk init`:
+// straight away with --init, or after a confirmation when stdin is a terminal.
+// Otherwise nothing happens and the add fails with ErrNotInitialized, so
+// scripts keep the hard error.
+func offerInit(cmd *cobra.Command, l *lnk.Lnk, w *Writer, initRepo bool) error {
+	displayPath := lnk.DisplayPath(lnk.GetRepoPath())
+
+	if !initRepo {
+		in, ok := cmd.InOrStdin().(*os.File)
+		if !ok || !isTerminal(in) || w.Quiet() {
+			return nil
+		}
+
+		w.Writeln(Warning("No lnk repository yet")).
+			WriteString("   ").
+			WriteString(fmt.Sprintf("Create one at %s now? [Y/n] ", displayPath))
+		if err := w.Err(); err != nil {
+			return err
+		}
+
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read answer: %w", err)
+		}
+		// End of input (e.g. stdin is /dev/null) is not a yes
+		if err == io.EOF {
+			w.WritelnString("")
+			return w.Err()
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+		default:
+			return nil
+		}
+	}
+
+	if err := l.Init(); err != nil {
+		return err
+	}
+
+	w.Writeln(Target("Initialized empty lnk repository")).
+		WriteString("   ").
+		Write(Message{Text: "Location: ", Emoji: "📁"}).
+		Writeln(Colored(displayPath, ColorGray))
+	return w.Err()
+}
+
 // displayLimit caps the number of per-file entries shown in batch summaries
 // before collapsing the remainder into "... and N more files".
 const displayLimit = 5
 
+// largeAddFiles is the file count from which a recursive add warns before
+// it starts, since that many files usually means a cache or a whole tree
+// came along.
+const largeAddFiles = 1000
+
+// writeAddFailures lists the files a --skip-errors add left out, each with
+// the reason. No-op when nothing was skipped.
+func writeAddFailures(w *Writer, failed []lnk.AddFailure) {
+	if len(failed) == 0 {
+		return
+	}
+
+	w.WriteString("   ").
+		Writeln(Warning(fmt.Sprintf("Skipped %d file%s that could not be added:", len(failed), pluralS(len(failed)))))
+	for _, failure := range failed {
+		reason := failure.Err.Error()
+		var lnkErr *error2.Error
+		if errors.As(failure.Err, &lnkErr) {
+			reason = lnkErr.Err.Error()
+		}
+		w.WriteString("      ").
+			Write(Plain(displaySourcePath(failure.Path))).
+			Writeln(Colored(" — "+reason, ColorGray))
+	}
+}
+
+// writeSkippedSpecial lists the special files and excluded directories a
+// --verbose recursive add left out, each with its kind. No-op when there were
+// none.
+func writeSkippedSpecial(w *Writer, paths []string, kinds map[string]string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	noun := "special file"
+	empty := false
+	for _, path := range paths {
+		switch kinds[path] {
+		case lnk.ExcludedDirKind, lnk.IgnoredKind:
+			noun = "item"
+		case lnk.EmptyDirKind:
+			noun = "item"
+			empty = true
+		}
+	}
+	w.WriteString("   ").
+		Writeln(Info(fmt.Sprintf("Left out %d %s%s:", len(paths), noun, pluralS(len(paths)))))
+	for _, path := range paths {
+		w.WriteString("      ").
+			Write(Plain(displaySourcePath(path))).
+			Writeln(Colored(" ("+kinds[path]+")", ColorGray))
+	}
+	if empty {
+		w.WriteString("   ").
+			Write(Info("Put a ")).
+			Write(Bold(lnk.KeepFileName)).
+			WritelnString(" file in an empty directory to keep it")
+	}
+}
+
+// storedDisplayPath returns where the just-added item at path is stored,
+// read back from the index so a --into location is shown as recorded.
+func storedDisplayPath(l *lnk.Lnk, host, path string) string {
+	if stored, err := l.ManagedPath(path); err == nil {
+		return lnk.DisplayPath(stored)
+	}
+	return lnk.FormatManagedPath(host, path)
+}
+
 // displaySourcePath renders a path (relative or absolute) as a home-relative
 // (~/foo) display string, falling back to the original input on resolution
 // failure. Used so duplicate basenames in different directories remain
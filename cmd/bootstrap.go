@@ -3,6 +3,7 @@ package cmd
 import (
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -20,19 +21,33 @@ func bootstrapWriters(cmd *cobra.Command, w *Writer) (io.Writer, io.Writer) {
 }
 
 func newBootstrapCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:           "bootstrap",
-		Short:         "🚀 Run the bootstrap script to set up your environment",
-		Long:          "Executes the bootstrap script from your dotfiles repository to install dependencies and configure your system.",
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "🚀 Run the bootstrap script to set up your environment",
+		Long: `Executes the bootstrap script from your dotfiles repository to install
+dependencies and configure your system. 'lnk init -r' runs it after cloning;
+run it again after pulling changes to the script.
+
+The script is bootstrap.sh at the repository root. Use --script to run another
+script from the repository instead, e.g. --script setup/macos.sh; it is an
+error if that script does not exist.`,
+		Args:          cobra.NoArgs,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			lnk := lnk.NewLnk()
+			scriptPath, _ := cmd.Flags().GetString("script")
+			l := lnk.NewLnk()
 			w := GetWriter(cmd)
 
-			scriptPath, err := lnk.FindBootstrapScript()
-			if err != nil {
-				return err
+			if scriptPath != "" {
+				if err := l.CheckBootstrapScript(scriptPath); err != nil {
+					return err
+				}
+			} else {
+				var err error
+				if scriptPath, err = l.FindBootstrapScript(); err != nil {
+					return err
+				}
 			}
 
 			if scriptPath == "" {
@@ -53,7 +68,7 @@ func newBootstrapCmd() *cobra.Command {
 			w.Writeln(Rocket("Running bootstrap script")).
 				WriteString("   ").
 				Write(Message{Text: "Script: ", Emoji: "📄"}).
-				Writeln(Colored(scriptPath, ColorCyan)).
+				Writeln(Colored(lnk.DisplayPath(filepath.Join(lnk.GetRepoPath(), scriptPath)), ColorCyan)).
 				WritelnString("")
 
 			if err := w.Err(); err != nil {
@@ -61,7 +76,7 @@ func newBootstrapCmd() *cobra.Command {
 			}
 
 			scriptOut, scriptErr := bootstrapWriters(cmd, w)
-			if err := lnk.RunBootstrapScript(scriptPath, scriptOut, scriptErr, os.Stdin); err != nil {
+			if err := l.RunBootstrapScript(scriptPath, scriptOut, scriptErr, os.Stdin); err != nil {
 				return err
 			}
 
@@ -73,4 +88,7 @@ func newBootstrapCmd() *cobra.Command {
 			return w.Err()
 		},
 	}
+
+	cmd.Flags().String("script", "", "Run this script from the repository instead of bootstrap.sh")
+	return cmd
 }
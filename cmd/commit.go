@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newCommitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "💾 Commit staged adds and removes",
+		Long: `Records what is staged in the repository as one commit, without pushing.
+
+Pair it with 'lnk add --no-commit' and 'lnk rm --no-commit' to batch several
+adds and removes into a single commit:
+
+  lnk add --no-commit ~/.bashrc ~/.profile
+  lnk rm --no-commit ~/.zshrc
+  lnk commit -m "lnk: switch to bash"
+
+Only staged changes are committed; edits to managed files that were not staged
+stay uncommitted (lnk push commits everything). Commit fails when nothing is
+staged. Without -m, push.default_message from config.toml is used, or else
+"lnk: sync configuration files"; {date} and {host} are filled in as for lnk
+push. Keep the "lnk:" prefix if 'lnk undo' should be able to undo the commit.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			message, _ := cmd.Flags().GetString("message")
+			sign, _ := cmd.Flags().GetBool("sign")
+			if message == "" {
+				message = pushMessage()
+			}
+
+			l := lnk.NewLnk(lnk.WithSign(sign))
+			w := GetWriter(cmd)
+
+			message, err := l.RenderMessage(message)
+			if err != nil {
+				return err
+			}
+			if err := l.Commit(message); err != nil {
+				return err
+			}
+
+			w.Writeln(Message{Text: "Committed staged changes", Emoji: "💾", Bold: true}).
+				WriteString("   ").
+				Writeln(Colored(message, ColorGray)).
+				WriteString("   ").
+				Write(Message{Text: "Use ", Emoji: "📝"}).
+				Write(Bold("lnk push")).
+				WritelnString(" to sync to remote")
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("message", "m", "", "Commit message (default: push.default_message, or \"lnk: sync configuration files\")")
+	cmd.Flags().BoolP("sign", "S", false, "Sign the commit (git commit -S) using your configured signing key")
+	return cmd
+}
+
+// writeStaged tells the user that an add or remove made with --no-commit
+// left its changes staged, and how to record them.
+func writeStaged(w *Writer) {
+	w.WriteString("   ").
+		Write(Message{Text: "Staged without committing; run ", Emoji: "📝"}).
+		Write(Bold("lnk commit")).
+		WriteString(" or ").
+		Write(Bold("lnk push")).
+		WritelnString(" to record it")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "config",
+		Short:         "⚙️ Manage lnk repository settings",
+		Long:          "View and change settings of the lnk repository.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigIdentityCmd())
+	cmd.AddCommand(newConfigSetIdentityCmd())
+	return cmd
+}
+
+// configKeysHelp lists the config.toml keys for help text.
+func configKeysHelp() string {
+	width := 0
+	for _, key := range lnk.ConfigKeys() {
+		width = max(width, len(key.Name))
+	}
+
+	var b strings.Builder
+	for _, key := range lnk.ConfigKeys() {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, key.Name, key.Usage)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeUnknownKeyWarnings warns about each config.toml key the loaded
+// settings skipped, so a typo does not go unnoticed.
+func writeUnknownKeyWarnings(w *Writer, unknown []lnk.UnknownConfigKey) {
+	for _, key := range unknown {
+		w.Write(Warning(fmt.Sprintf("%s: unknown config key %s ignored; run ", lnk.DisplayPath(key.Location), key.Name))).
+			Writeln(Bold("lnk config get"))
+	}
+}
+
+func newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [key]",
+		Short: "🔎 Show settings from config.toml",
+		Long: `Prints the value of a config.toml setting, or every setting that is set when
+no key is given. Values come from the repository's shared config.toml and the
+machine-local $XDG_CONFIG_HOME/lnk/config.toml, the latter winning. An unset
+key prints nothing.
+
+Keys:
+` + configKeysHelp(),
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := GetWriter(cmd)
+
+			cfg, err := lnk.LoadConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.CheckKeys(); err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				value, err := cfg.Get(args[0])
+				if err != nil {
+					return err
+				}
+				if value != "" {
+					w.WritelnString(value)
+				}
+				return w.Err()
+			}
+
+			found := false
+			for _, key := range lnk.ConfigKeys() {
+				value, _ := cfg.Get(key.Name)
+				if value == "" {
+					continue
+				}
+				found = true
+				w.Write(Bold(key.Name)).
+					WriteString(" = ").
+					Writeln(Colored(value, ColorCyan))
+			}
+			if !found {
+				w.Writeln(Info("No settings in config.toml; built-in defaults apply"))
+			}
+			return w.Err()
+		},
+	}
+}
+
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "✏️ Change a config.toml setting",
+		Long: `Writes a setting to the repository's config.toml and commits it, so every
+machine gets it with the next pull. With --user, the setting goes to the
+machine-local $XDG_CONFIG_HOME/lnk/config.toml instead, which overrides the
+shared file and is not committed. When the repository lives in the default
+$XDG_CONFIG_HOME/lnk, both are the same file.
+
+Settings replace built-in defaults; command-line flags still win over them.
+
+Keys:
+` + configKeysHelp(),
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, _ := cmd.Flags().GetBool("user")
+			w := GetWriter(cmd)
+
+			path, err := lnk.NewLnk().SetConfig(args[0], args[1], user)
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Success(fmt.Sprintf("Set %s = %s", args[0], args[1]))).
+				WriteString("   ").
+				Writeln(Message{Text: "Location: " + lnk.DisplayPath(path), Emoji: "📁"})
+			if !user {
+				w.WriteString("   ").
+					Write(Message{Text: "Use ", Emoji: "📝"}).
+					Write(Bold("lnk push")).
+					WritelnString(" to share it with your other machines")
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().Bool("user", false, "Write the machine-local config file instead of the repository's")
+	return cmd
+}
+
+func newConfigIdentityCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "identity",
+		Short:         "👤 Show the name and email lnk commits with",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := GetWriter(cmd)
+
+			name, email, err := lnk.NewLnk().Identity()
+			if err != nil {
+				return err
+			}
+
+			if name == "" && email == "" {
+				w.Writeln(Message{Text: "No git identity configured", Emoji: "👤", Bold: true}).
+					WriteString("   ").
+					Write(Info("lnk will commit as Lnk User <lnk@localhost>. Use ")).
+					Write(Bold("lnk config set-identity <name> <email>")).
+					WritelnString(" to choose one")
+				return w.Err()
+			}
+
+			w.Writeln(Message{Text: "Commit identity", Emoji: "👤", Bold: true}).
+				WriteString("   ").
+				Writeln(Colored(name+" <"+email+">", ColorCyan))
+			return w.Err()
+		},
+	}
+}
+
+func newConfigSetIdentityCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-identity <name> <email>",
+		Short: "👤 Set a repository-specific commit identity",
+		Long: `Writes user.name and user.email to the lnk repository's local git config, so
+dotfiles commits use this identity instead of your global git one.
+
+Without it, lnk uses your global git identity if you have one, and falls back
+to Lnk User <lnk@localhost> otherwise.`,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			l := lnk.NewLnk()
+			w := GetWriter(cmd)
+
+			if err := l.SetIdentity(args[0], args[1]); err != nil {
+				return err
+			}
+
+			name, email, err := l.Identity()
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Success("Commit identity set for this repository")).
+				WriteString("   ").
+				Writeln(Colored(name+" <"+email+">", ColorCyan)).
+				WriteString("   ").
+				Writeln(Message{Text: "Location: " + lnk.DisplayPath(lnk.GetRepoPath()), Emoji: "📁"})
+			return w.Err()
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// addConflictFlags registers the flags that control what a restore does with
+// real files found where managed symlinks should go.
+func addConflictFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("interactive", "i", false, "Ask what to do with each existing file that is in the way of a symlink")
+	cmd.Flags().String("on-conflict", string(lnk.ConflictBackup), "What to do with existing files in the way of a symlink: overwrite, skip or backup")
+	cmd.MarkFlagsMutuallyExclusive("interactive", "on-conflict")
+}
+
+// conflictResolverFlag builds the ConflictResolver selected by --interactive
+// or --on-conflict, falling back to pull.on_conflict from config.toml.
+func conflictResolverFlag(cmd *cobra.Command) (lnk.ConflictResolver, error) {
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	if interactive {
+		return promptConflictResolver(GetPromptWriter(cmd), cmd.InOrStdin()), nil
+	}
+
+	policy, _ := cmd.Flags().GetString("on-conflict")
+	if !cmd.Flags().Changed("on-conflict") && fileConfig.OnConflict != "" {
+		policy = fileConfig.OnConflict
+	}
+	action, err := lnk.ParseConflictAction(policy)
+	if err != nil {
+		return nil, err
+	}
+	return lnk.ConflictPolicy(action), nil
+}
+
+// promptConflictResolver asks on w for each conflict and reads the answer from
+// in. [d]iff shows the difference and asks again; an empty answer or end of
+// input falls back to backup so nothing is lost.
+func promptConflictResolver(w *Writer, in io.Reader) lnk.ConflictResolver {
+	reader := bufio.NewReader(in)
+
+	return func(relativePath, existingPath, repoItem string) (lnk.ConflictAction, error) {
+		w.Writeln(Warning(fmt.Sprintf("~/%s already exists and is not managed by lnk", relativePath)))
+
+		for {
+			w.WriteString("   ").
+				WriteString("[o]verwrite, [s]kip, [b]ackup, [d]iff? (default: backup) ")
+			if err := w.Err(); err != nil {
+				return "", err
+			}
+
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return "", fmt.Errorf("failed to read answer: %w", err)
+			}
+			if err == io.EOF {
+				w.WritelnString("")
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "o", "overwrite":
+				return lnk.ConflictOverwrite, nil
+			case "s", "skip":
+				return lnk.ConflictSkip, nil
+			case "b", "backup", "":
+				return lnk.ConflictBackup, nil
+			case "d", "diff":
+				diff, err := lnk.NewLnk().DiffFiles(existingPath, repoItem, w.Colors())
+				if err != nil {
+					return "", err
+				}
+				if diff == "" {
+					w.WriteString("   ").
+						Writeln(Info("No differences"))
+				} else {
+					w.WriteString(diff)
+				}
+			default:
+				w.WriteString("   ").
+					Writeln(Warning(fmt.Sprintf("Unknown choice %q", strings.TrimSpace(answer))))
+			}
+
+			if err == io.EOF {
+				return lnk.ConflictBackup, nil
+			}
+		}
+	}
+}
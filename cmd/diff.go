@@ -10,7 +10,7 @@ import (
 
 // errDiffHasChanges is returned by `lnk diff --quiet` when the repo has
 // uncommitted changes. It exists only to drive a non-zero exit code;
-// quiet mode suppresses error display, so callers see only the exit code.
+// DisplayError never shows it, so callers see only the exit code.
 var errDiffHasChanges = errors.New("repository has uncommitted changes")
 
 func newDiffCmd() *cobra.Command {
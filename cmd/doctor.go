@@ -17,13 +17,22 @@ func newDoctorCmd() *cobra.Command {
 Checks performed:
   • Invalid entries: .lnk entries whose stored files no longer exist
   • Broken symlinks: managed files whose symlinks are missing or broken
+  • Colliding entries: two index paths that name the same file in $HOME,
+    e.g. through a symlinked directory or a case-insensitive volume; these
+    are reported only, since doctor cannot know which one to keep
+  • Repository state: a detached HEAD or a shallow clone, which make push
+    and pull fail in confusing ways; reported with the git command that
+    fixes it, since that means picking a branch or fetching all history
 
 Use --host to check a specific host configuration instead of the common one.
 Use --dry-run to preview what would be fixed without making changes.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			host, _ := cmd.Flags().GetString("host")
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
 			dryRun, _ := cmd.Flags().GetBool("dry-run")
 			lnk := lnk.NewLnk(lnk.WithHost(host))
 			w := GetWriter(cmd)
@@ -75,8 +84,14 @@ Use --dry-run to preview what would be fixed without making changes.`,
 					}
 				}
 
-				w.WritelnString("").
-					Writeln(Info("To proceed: run without --dry-run flag"))
+				writeCollisions(w, result.Collisions)
+
+				writeRepoState(w, result.Detached, result.Shallow)
+
+				if result.TotalIssues() > result.ReportOnly() {
+					w.WritelnString("").
+						Writeln(Info("To proceed: run without --dry-run flag"))
+				}
 
 				return w.Err()
 			}
@@ -102,7 +117,12 @@ Use --dry-run to preview what would be fixed without making changes.`,
 			if host != "" {
 				hostSuffix = fmt.Sprintf(" (host: %s)", host)
 			}
-			w.Writeln(Message{Text: fmt.Sprintf("Fixed %d issue%s%s", result.TotalIssues(), pluralS(result.TotalIssues()), hostSuffix), Emoji: "🩺", Bold: true})
+			fixed := result.TotalIssues() - result.ReportOnly()
+			if fixed > 0 {
+				w.Writeln(Message{Text: fmt.Sprintf("Fixed %d issue%s%s", fixed, pluralS(fixed), hostSuffix), Emoji: "🩺", Bold: true})
+			} else {
+				w.Writeln(Message{Text: fmt.Sprintf("Found %d issue%s%s:", result.TotalIssues(), pluralS(result.TotalIssues()), hostSuffix), Emoji: "🔍", Bold: true})
+			}
 
 			// Show fixed broken symlinks
 			if len(result.BrokenSymlinks) > 0 {
@@ -128,20 +148,74 @@ Use --dry-run to preview what would be fixed without making changes.`,
 				}
 			}
 
-			w.WritelnString("").
-				Write(Info("Use ")).
-				Write(Bold("lnk push")).
-				WritelnString(" to sync changes to remote")
+			writeCollisions(w, result.Collisions)
+			writeRepoState(w, result.Detached, result.Shallow)
+
+			if fixed > 0 {
+				w.WritelnString("").
+					Write(Info("Use ")).
+					Write(Bold("lnk push")).
+					WritelnString(" to sync changes to remote")
+			}
 
 			return w.Err()
 		},
 	}
 
-	cmd.Flags().StringP("host", "H", "", "Check specific host configuration (default: common configuration)")
+	cmd.Flags().StringP("host", "H", "", "Check specific host configuration, or 'auto' for this machine's hostname (default: common configuration)")
 	cmd.Flags().BoolP("dry-run", "n", false, "Show what would be fixed without making changes")
 	return cmd
 }
 
+// writeCollisions lists pairs of managed items linked at the same $HOME
+// location, which only the user can resolve by removing one of them.
+func writeCollisions(w *Writer, collisions []lnk.Collision) {
+	if len(collisions) == 0 {
+		return
+	}
+	w.WritelnString("")
+	w.WriteString("   ").
+		Writeln(Message{Text: fmt.Sprintf("Found %d colliding entr%s (fix by hand with lnk rm):", len(collisions), pluralY(len(collisions))), Emoji: "⚠️", Bold: true})
+	for _, collision := range collisions {
+		w.WriteString("      ").
+			Writeln(Message{Text: fmt.Sprintf("%s and %s", collisionPath(collision.First.Host, collision.First.Path), collisionPath(collision.Second.Host, collision.Second.Path)), Color: ColorYellow, Emoji: "⚠️"})
+	}
+}
+
+// writeRepoState warns about a detached HEAD or shallow clone, the states
+// that make push and pull misbehave, with the git command that gets the
+// repository back to normal.
+func writeRepoState(w *Writer, detached, shallow bool) {
+	repo := lnk.DisplayPath(lnk.GetRepoPath())
+	if detached {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning("HEAD is detached: push has no branch to update and pull none to merge into")).
+			WriteString("      ").
+			Write(Info("Check out your branch again: ")).
+			Writeln(Bold("git -C " + repo + " switch main")).
+			WriteString("      ").
+			Writeln(Colored("(or the branch your remote uses; git -C "+repo+" branch -a lists them)", ColorGray))
+	}
+	if shallow {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning("The repository is a shallow clone: pushes can be rejected and pulls fail to merge")).
+			WriteString("      ").
+			Write(Info("Fetch the full history: ")).
+			Writeln(Bold("git -C " + repo + " fetch --unshallow"))
+	}
+}
+
+// collisionPath shows one side of a collision home-relative, marked with its
+// host when it is not in the common configuration.
+func collisionPath(host, path string) string {
+	if host == "" {
+		return "~/" + path
+	}
+	return fmt.Sprintf("~/%s (host: %s)", path, host)
+}
+
 // pluralS returns "s" for counts != 1, "" for count == 1.
 func pluralS(count int) string {
 	if count == 1 {
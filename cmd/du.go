@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// usageRow is one line of lnk du: an item, or a group of items with --by.
+type usageRow struct {
+	Name  string `json:"name"`
+	Host  string `json:"host,omitempty"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+	Items int    `json:"items,omitempty"`
+}
+
+// usageReport is what lnk du --json prints.
+type usageReport struct {
+	Size    int64      `json:"size"`
+	Files   int        `json:"files"`
+	Items   int        `json:"items"`
+	By      string     `json:"by,omitempty"`
+	Entries []usageRow `json:"entries"`
+}
+
+func newDuCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "du",
+		Short: "💾 Show how much space the managed files take in the repository",
+		Long: `Adds up the files stored in the repository for each managed item, largest
+first, to spot something big that crept in (a cache directory, a database).
+Only what the indexes track is counted: .git and untracked files are not.
+
+The common configuration is always included, with --host that host's files
+too, and with --all every host's. --by dir adds the items up per top-level
+directory under $HOME (.config, .ssh, ...), --by host per configuration.
+--top N shows only the N largest rows; the total always covers everything.
+--json prints the same report as JSON, sizes in bytes; --output <file> writes
+it to a file instead.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			all, _ := cmd.Flags().GetBool("all")
+			by, _ := cmd.Flags().GetString("by")
+			top, _ := cmd.Flags().GetInt("top")
+			asJSON, err := jsonFlag(cmd)
+			if err != nil {
+				return err
+			}
+			if by != "" && by != "dir" && by != "host" {
+				return fmt.Errorf("invalid --by value: %s (valid: dir, host)", by)
+			}
+			if top < 0 {
+				return fmt.Errorf("invalid --top value: %d (use a positive number, or 0 for all)", top)
+			}
+
+			usage, err := lnk.NewLnk(lnk.WithHost(host)).Usage(all)
+			if err != nil {
+				return err
+			}
+
+			report := usageReport{Size: usage.Size, Files: usage.Files, Items: len(usage.Items), By: by, Entries: usageRows(usage.Items, by)}
+			if top > 0 && len(report.Entries) > top {
+				report.Entries = report.Entries[:top]
+			}
+
+			if asJSON {
+				return writeJSON(cmd, report)
+			}
+
+			w := GetWriter(cmd)
+			writeUsage(w, report)
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Include this host's files, or 'auto' for this machine's hostname")
+	cmd.Flags().BoolP("all", "a", false, "Include every host's files")
+	cmd.Flags().String("by", "", "Add items up per top-level directory ('dir') or per configuration ('host')")
+	cmd.Flags().Int("top", 0, "Show only the N largest entries (default: all)")
+	addJSONFlags(cmd, "Print the report as JSON, sizes in bytes")
+	cmd.MarkFlagsMutuallyExclusive("host", "all")
+	return cmd
+}
+
+// usageRows turns items, already largest first, into report rows: one per
+// item, or one per top-level directory or configuration with by.
+func usageRows(items []lnk.ItemUsage, by string) []usageRow {
+	rows := []usageRow{}
+	if by == "" {
+		for _, item := range items {
+			rows = append(rows, usageRow{Name: item.Path, Host: item.Host, Size: item.Size, Files: item.Files})
+		}
+		return rows
+	}
+
+	index := make(map[string]int)
+	for _, item := range items {
+		name := item.Host
+		if by == "dir" {
+			name, _, _ = strings.Cut(filepath.ToSlash(item.Path), "/")
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(rows)
+			index[name] = i
+			rows = append(rows, usageRow{Name: name})
+		}
+		rows[i].Size += item.Size
+		rows[i].Files += item.Files
+		rows[i].Items++
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Size > rows[j].Size })
+	return rows
+}
+
+// writeUsage prints the du report as a total followed by aligned rows.
+func writeUsage(w *Writer, report usageReport) {
+	w.Writeln(Message{Text: fmt.Sprintf("Managed content: %s in %d file%s (%d item%s)", lnk.FormatSize(report.Size), report.Files, pluralS(report.Files), report.Items, pluralS(report.Items)), Emoji: "💾", Bold: true})
+	if len(report.Entries) == 0 {
+		return
+	}
+	w.WritelnString("")
+
+	width := 0
+	for _, row := range report.Entries {
+		width = max(width, len(lnk.FormatSize(row.Size)))
+	}
+	for _, row := range report.Entries {
+		name := "~/" + filepath.ToSlash(row.Name)
+		if report.By == "host" {
+			name = row.Name
+			if name == "" {
+				name = "common"
+			}
+		}
+
+		detail := fmt.Sprintf(" (%d file%s)", row.Files, pluralS(row.Files))
+		if report.By != "" {
+			detail = fmt.Sprintf(" (%d item%s, %d file%s)", row.Items, pluralS(row.Items), row.Files, pluralS(row.Files))
+		} else if row.Host != "" {
+			detail = fmt.Sprintf(" (%d file%s, host: %s)", row.Files, pluralS(row.Files), row.Host)
+		}
+
+		w.WriteString("   ").
+			Write(Bold(fmt.Sprintf("%*s", width, lnk.FormatSize(row.Size)))).
+			WriteString("  ").
+			Write(Plain(name)).
+			Writeln(Colored(detail, ColorGray))
+	}
+}
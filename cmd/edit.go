@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <file>",
+		Short: "✏️ Open a managed file in your editor",
+		Long: `Opens the repository copy of a managed file in $VISUAL or $EDITOR (falling back
+to vi, or notepad on Windows), then reports whether the repository now has
+uncommitted changes so you remember to push.
+
+The stored file is opened directly rather than through the symlink, so editors
+that save by replacing the file cannot turn the symlink into a regular file.
+Files added with --copy are edited in place in your home directory instead;
+lnk push copies the edits into the repository.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filePath := args[0]
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			l := lnk.NewLnk(lnk.WithHost(host))
+			w := GetWriter(cmd)
+
+			entry, managedPath, err := l.LookupManaged(filePath)
+			if err != nil {
+				return err
+			}
+			if entry.Copy {
+				// The original is the live copy; the repo one is refreshed on push.
+				if managedPath, err = filepath.Abs(filePath); err != nil {
+					return fmt.Errorf("failed to get absolute path: %w", err)
+				}
+			}
+
+			editor := editorCommand()
+			editorCmd := exec.Command(editor[0], append(editor[1:], managedPath)...)
+			editorCmd.Stdin = cmd.InOrStdin()
+			editorCmd.Stdout = cmd.OutOrStdout()
+			editorCmd.Stderr = cmd.ErrOrStderr()
+			if err := editorCmd.Run(); err != nil {
+				return fmt.Errorf("failed to run editor %q: %w", strings.Join(editor, " "), err)
+			}
+
+			w.Writeln(Message{Text: fmt.Sprintf("Edited %s", filepath.Base(filePath)), Emoji: "✏️", Bold: true}).
+				WriteString("   ").
+				Writeln(Message{Text: lnk.DisplayPath(managedPath), Emoji: "📁"})
+
+			if entry.Copy {
+				w.WriteString("   ").
+					Write(Info("Copy-managed file — run ")).
+					Write(Bold("lnk push")).
+					WritelnString(" to copy your edits into the repository")
+				return w.Err()
+			}
+
+			dirty, err := l.HasChanges()
+			if err != nil {
+				return err
+			}
+
+			if dirty {
+				w.WriteString("   ").
+					Write(Warning("Repository has uncommitted changes — run ")).
+					Write(Bold("lnk push")).
+					WritelnString(" to sync them")
+			} else {
+				w.WriteString("   ").
+					Writeln(Success("No changes to sync"))
+			}
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Edit file from specific host configuration, or 'auto' for this machine's hostname (default: common configuration)")
+	return cmd
+}
+
+// editorCommand returns the user's editor split into program and arguments,
+// preferring $VISUAL over $EDITOR.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newExportChezmoiCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-chezmoi <dir>",
+		Short: "📤 Export managed files as a chezmoi source directory",
+		Long: `Writes every managed file into <dir> using chezmoi's source-state naming, so
+the result can be used with 'chezmoi --source <dir>' to compare with or migrate
+to chezmoi. The lnk repository is only read; <dir> must be empty or missing.
+
+Names are translated per path component:
+  .name         → dot_name
+  owner-only    → private_          (no group or other permissions)
+  not writable  → readonly_
+  empty file    → empty_
+  executable    → executable_
+  symlink       → symlink_          (file content is the link target)
+  name already starting with a chezmoi prefix (dot_, run_, ...) → literal_
+  file ending in .tmpl or .literal → .literal suffix added
+
+With --host, that host's files are exported on top of the common ones. Nothing
+else is translated: lnk has no templates or scripts, host-specific files are
+not turned into templates, and bootstrap.sh is not exported.
+
+Files added with --secret are copied as the working tree holds them:
+decrypted while the repository is unlocked with git-crypt, ciphertext while
+it is locked. They are not encrypted in <dir> and keep plain chezmoi names,
+not encrypted_; a warning lists them so you can re-add them with
+'chezmoi add --encrypt'.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			dir := args[0]
+			l := lnk.NewLnk(lnk.WithHost(host))
+			w := GetWriter(cmd)
+
+			if dryRun {
+				files, err := l.PlanChezmoiExport()
+				if err != nil {
+					return err
+				}
+
+				w.Writeln(Message{Text: fmt.Sprintf("Would export %d file%s:", len(files), pluralS(len(files))), Emoji: "🔍", Bold: true})
+				width := 0
+				for _, file := range files {
+					width = max(width, utf8.RuneCountInString(file.RelativePath))
+				}
+				for _, file := range files {
+					w.WriteString("   ").
+						Write(Message{Text: "~/" + filepath.ToSlash(file.RelativePath), Emoji: "📄"}).
+						WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(file.RelativePath))).
+						WriteString(" → ").
+						Write(Colored(filepath.ToSlash(file.Name), ColorCyan))
+					if file.Secret {
+						w.WriteString(" ").
+							Write(Colored("(secret)", ColorGray))
+					}
+					w.WritelnString("")
+				}
+				writeSecretExportWarning(w, files)
+				w.WritelnString("").
+					Writeln(Info("To proceed: run without --dry-run flag"))
+				return w.Err()
+			}
+
+			files, err := l.ExportChezmoi(dir)
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Success(fmt.Sprintf("Exported %d file%s to a chezmoi source directory", len(files), pluralS(len(files))))).
+				WriteString("   ").
+				Write(Message{Text: "Location: ", Emoji: "📁"}).
+				Writeln(Colored(displaySourcePath(dir), ColorGray)).
+				WriteString("   ").
+				Write(Info("Compare with: ")).
+				Writeln(Bold(fmt.Sprintf("chezmoi --source %s diff", displaySourcePath(dir))))
+			writeSecretExportWarning(w, files)
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Also export a host configuration, or 'auto' for this machine's hostname")
+	cmd.Flags().BoolP("dry-run", "n", false, "Show the chezmoi name of each file without writing anything")
+	return cmd
+}
+
+// writeSecretExportWarning points out the exported files that lnk keeps
+// encrypted with git-crypt, since the export holds them unencrypted (or, from
+// a locked repository, as ciphertext chezmoi cannot read).
+func writeSecretExportWarning(w *Writer, files []lnk.ExportFile) {
+	var secrets []string
+	for _, file := range files {
+		if file.Secret {
+			secrets = append(secrets, filepath.ToSlash(file.RelativePath))
+		}
+	}
+	if len(secrets) == 0 {
+		return
+	}
+
+	w.WritelnString("").
+		Writeln(Warning(fmt.Sprintf("%d secret file%s exported without encryption:", len(secrets), pluralS(len(secrets)))))
+	for _, path := range secrets {
+		w.WriteString("   ").
+			Writeln(Plain("~/" + path))
+	}
+	w.WriteString("   ").
+		Writeln(Colored("They hold whatever the working tree does: plain text while git-crypt is unlocked, ciphertext while locked. Re-add them with 'chezmoi add --encrypt'.", ColorGray))
+}
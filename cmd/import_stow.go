@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newImportStowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-stow <dir>",
+		Short: "📦 Migrate GNU Stow packages into lnk",
+		Long: `Reads a GNU Stow directory (~/dotfiles/<package>/...) and manages every package
+file with lnk instead: the file is copied into the lnk repository, its stow
+symlink in your home directory is replaced with an lnk symlink, and the .lnk
+manifest is updated, all in one commit.
+
+Files map to your home directory the way 'stow -t ~' lays them out. Directories
+that stow folded into a single symlink are unfolded first, so other files in
+them keep working. Stow's default ignore list (.git, README*, LICENSE*, editor
+backups, ...) is honoured; .stow-local-ignore is not read. Pass --dotfiles if
+you used 'stow --dotfiles' and your packages name files dot-bashrc.
+
+Package files over the size limit (add.max_size, default 10MB) or that look
+binary stop the import before anything changes, as they would for lnk add;
+--force imports them anyway.
+
+The stow directory is left untouched - remove it once you are happy. Use
+--dry-run to see the planned mapping first.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			dotfiles, _ := cmd.Flags().GetBool("dotfiles")
+			force, _ := cmd.Flags().GetBool("force")
+			stowDir := args[0]
+			l := lnk.NewLnk(lnk.WithHost(host), lnk.WithForceAdd(force))
+			w := GetWriter(cmd)
+
+			if dryRun {
+				entries, err := l.PlanStowImport(stowDir, dotfiles)
+				if err != nil {
+					return err
+				}
+				if len(entries) == 0 {
+					w.Writeln(Info("No stow package files found"))
+					return w.Err()
+				}
+
+				w.Writeln(Message{Text: fmt.Sprintf("Would import %d file%s from stow:", len(entries), pluralS(len(entries))), Emoji: "🔍", Bold: true})
+				writeStowPlan(w, entries)
+				w.WritelnString("").
+					Writeln(Info("To proceed: run without --dry-run flag"))
+				return w.Err()
+			}
+
+			entries, err := l.ImportStow(stowDir, dotfiles)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				w.Writeln(Info("No stow package files found"))
+				return w.Err()
+			}
+
+			if host != "" {
+				w.Writeln(Sparkles(fmt.Sprintf("Imported %d file%s from stow (host: %s)", len(entries), pluralS(len(entries)), host)))
+			} else {
+				w.Writeln(Sparkles(fmt.Sprintf("Imported %d file%s from stow", len(entries), pluralS(len(entries)))))
+			}
+
+			filesToShow := min(len(entries), displayLimit)
+			for _, entry := range entries[:filesToShow] {
+				w.WriteString("   ").
+					Write(Link(lnk.DisplayPath(entry.Target))).
+					WriteString(" → ").
+					Writeln(Colored(lnk.DisplayPath(entry.Destination), ColorCyan))
+			}
+			if len(entries) > displayLimit {
+				w.WriteString("   ").
+					Writeln(Colored(fmt.Sprintf("... and %d more files", len(entries)-displayLimit), ColorGray))
+			}
+
+			w.WriteString("   ").
+				Write(Info("The stow directory was left in place: ")).
+				Writeln(Colored(displaySourcePath(stowDir), ColorGray)).
+				WriteString("   ").
+				Write(Message{Text: "Use ", Emoji: "📝"}).
+				Write(Bold("lnk push")).
+				WritelnString(" to sync to remote")
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Import into a specific host configuration, or 'auto' for this machine's hostname (default: common configuration)")
+	cmd.Flags().BoolP("dry-run", "n", false, "Show the planned mapping without making changes")
+	cmd.Flags().Bool("dotfiles", false, "Translate stow's dot- prefix to a leading dot, as 'stow --dotfiles' does")
+	cmd.Flags().BoolP("force", "f", false, "Import files over the size limit (add.max_size, default 10MB) or that look binary")
+	return cmd
+}
+
+// writeStowPlan lists the planned mapping grouped by package, as aligned
+// home path → repository path columns.
+func writeStowPlan(w *Writer, entries []lnk.StowEntry) {
+	width := 0
+	for _, entry := range entries {
+		width = max(width, utf8.RuneCountInString(lnk.DisplayPath(entry.Target)))
+	}
+
+	pkg := ""
+	for _, entry := range entries {
+		if entry.Package != pkg {
+			pkg = entry.Package
+			w.WriteString("   ").
+				Writeln(Message{Text: pkg, Emoji: "📦", Bold: true})
+		}
+		target := lnk.DisplayPath(entry.Target)
+		w.WriteString("      ").
+			Write(Message{Text: target, Emoji: "📄"}).
+			WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(target))).
+			WriteString(" → ").
+			Writeln(Colored(lnk.DisplayPath(entry.Destination), ColorCyan))
+	}
+}
@@ -1,30 +1,65 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yarlson/lnk/internal/lnk"
+	error2 "github.com/yarlson/lnk/internal/lnkerror"
 )
 
 func newInitCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "init",
-		Short:         "🎯 Initialize a new lnk repository",
-		Long:          "Creates the lnk directory and initializes a Git repository for managing dotfiles.",
+		Use:   "init",
+		Short: "🎯 Initialize a new lnk repository",
+		Long: `Creates the lnk directory and initializes a Git repository for managing dotfiles.
+
+With --import-existing, adopts a dotfiles repository that was not created by lnk
+(already cloned to the lnk directory, or cloned with --remote): its files, laid
+out relative to your home directory, become the manifest and are symlinked into
+place. The inferred file list is shown for confirmation before anything is
+written; --yes skips the prompt.
+
+When the lnk directory already holds a repository, or one is cloned with
+--remote, init checks it against its tracking files and warns about a
+missing or unreadable .lnk, items listed but not stored, and stored files no
+tracking file lists. --strict turns those warnings into an error, before any
+bootstrap script runs.
+
+With --template, seeds a new repository from a starter dotfiles repository: its
+files (bootstrap script, .gitattributes, example configs, ...) are copied in and
+committed, but its history is not kept and it is not set as origin. Unlike
+--remote, the repository has no remote afterwards until you add one.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			remote, _ := cmd.Flags().GetString("remote")
 			noBootstrap, _ := cmd.Flags().GetBool("no-bootstrap")
 			force, _ := cmd.Flags().GetBool("force")
+			importExisting, _ := cmd.Flags().GetBool("import-existing")
+			strict, _ := cmd.Flags().GetBool("strict")
+			template, _ := cmd.Flags().GetString("template")
 
 			displayPath := lnk.DisplayPath(lnk.GetRepoPath())
 			l := lnk.NewLnk()
 			w := GetWriter(cmd)
 
+			if importExisting {
+				yes, _ := cmd.Flags().GetBool("yes")
+				return runImport(cmd, l, w, remote, force, yes)
+			}
+
+			if template != "" {
+				return runTemplate(l, w, template, displayPath)
+			}
+
 			// Show warning when force is used and there are managed files to overwrite
 			if force && remote != "" && l.HasUserContent() {
 				w.Writeln(Warning("Using --force flag: This will overwrite existing managed files")).
@@ -36,6 +71,7 @@ func newInitCmd() *cobra.Command {
 				}
 			}
 
+			existing := l.IsInitialized()
 			if err := l.InitWithRemoteForce(remote, force); err != nil {
 				return err
 			}
@@ -49,7 +85,7 @@ func newInitCmd() *cobra.Command {
 					Write(Message{Text: "Location: ", Emoji: "📁"}).
 					Writeln(Colored(displayPath, ColorGray))
 
-				if err := w.Err(); err != nil {
+				if err := verifyRepo(w, l, strict); err != nil {
 					return err
 				}
 
@@ -130,6 +166,13 @@ func newInitCmd() *cobra.Command {
 					Writeln(Plain(" to manage new files"))
 
 				return w.Err()
+			} else if existing {
+				w.Writeln(Target("Reinitialized existing lnk repository")).
+					WriteString("   ").
+					Write(Message{Text: "Location: ", Emoji: "📁"}).
+					Writeln(Colored(displayPath, ColorGray))
+
+				return verifyRepo(w, l, strict)
 			} else {
 				w.Writeln(Target("Initialized empty lnk repository")).
 					WriteString("   ").
@@ -151,5 +194,168 @@ func newInitCmd() *cobra.Command {
 	cmd.Flags().StringP("remote", "r", "", "Clone from remote URL instead of creating empty repository")
 	cmd.Flags().Bool("no-bootstrap", false, "Skip automatic execution of bootstrap script after cloning")
 	cmd.Flags().Bool("force", false, "Force initialization even if directory contains managed files (WARNING: This will overwrite existing content)")
+	cmd.Flags().Bool("import-existing", false, "Adopt an existing dotfiles repository that has no lnk manifest")
+	cmd.Flags().BoolP("yes", "y", false, "Write the inferred manifest without asking (with --import-existing)")
+	cmd.Flags().Bool("strict", false, "Fail instead of warning when an existing repository does not match its tracking files")
+	cmd.Flags().String("template", "", "Seed the new repository with a starter repository's files, without setting it as remote")
+	cmd.MarkFlagsMutuallyExclusive("template", "remote")
+	cmd.MarkFlagsMutuallyExclusive("template", "import-existing")
 	return cmd
 }
+
+// verifyRepo checks the repository init found or cloned against its tracking
+// files and lists what does not match. The problems are warnings, unless
+// strict makes them an error.
+func verifyRepo(w *Writer, l *lnk.Lnk, strict bool) error {
+	if err := w.Err(); err != nil {
+		return err
+	}
+
+	problems, err := l.Verify()
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	w.WritelnString("").
+		Writeln(Warning(fmt.Sprintf("Found %d problem%s in the repository:", len(problems), pluralS(len(problems)))))
+	for _, problem := range problems {
+		w.WriteString("   • ").
+			Writeln(Plain(problem))
+	}
+	if err := w.Err(); err != nil {
+		return err
+	}
+
+	if strict {
+		return error2.WithSuggestion(error2.ErrRepoInconsistent, "fix the problems above, or run 'lnk init' without --strict")
+	}
+	return nil
+}
+
+// runTemplate handles init --template: create the repository, copy the
+// starter's files into it and explain that they are now local.
+func runTemplate(l *lnk.Lnk, w *Writer, template, displayPath string) error {
+	files, err := l.InitFromTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	w.Writeln(Target("Initialized lnk repository from template")).
+		WriteString("   ").
+		Write(Message{Text: "Template: ", Emoji: "📦"}).
+		Writeln(Colored(template, ColorCyan)).
+		WriteString("   ").
+		Write(Message{Text: "Location: ", Emoji: "📁"}).
+		Writeln(Colored(displayPath, ColorGray)).
+		WriteString("   ").
+		Writeln(Message{Text: fmt.Sprintf("Copied %d file%s — they are local to this repository and yours to edit", len(files), pluralS(len(files))), Emoji: "📋"}).
+		WriteString("   ").
+		Writeln(Info("The template is not a remote; nothing is pulled from it again"))
+
+	for i, file := range files {
+		if i == 5 {
+			w.WriteString("   ").
+				Writeln(Plain(fmt.Sprintf("... and %d more files", len(files)-5)))
+			break
+		}
+		w.WriteString("   ").
+			Writeln(Message{Text: filepath.ToSlash(file), Emoji: "📄"})
+	}
+
+	w.WritelnString("").
+		Writeln(Info("Next steps:"))
+	if slices.Contains(files, "bootstrap.sh") {
+		w.WriteString("   • Run ").
+			Write(Bold("lnk bootstrap")).
+			Writeln(Plain(" to run the template's setup script"))
+	}
+	w.WriteString("   • Use ").
+		Write(Bold("lnk add <file>")).
+		Writeln(Plain(" to start managing dotfiles")).
+		WriteString("   • Add a remote with: ").
+		Writeln(Bold("git remote add origin <url>"))
+
+	return w.Err()
+}
+
+// runImport handles init --import-existing: optionally clone, show the
+// inferred manifest, confirm, then write it and restore symlinks.
+func runImport(cmd *cobra.Command, l *lnk.Lnk, w *Writer, remote string, force, yes bool) error {
+	if remote != "" {
+		if err := l.InitWithRemoteForce(remote, force); err != nil {
+			return err
+		}
+	}
+
+	items, err := l.ImportCandidates()
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		w.Writeln(Warning("No dotfiles found to import")).
+			WriteString("   ").
+			Writeln(Info("Only repository files (README, bootstrap.sh, .gitignore, ...) are tracked"))
+		return w.Err()
+	}
+
+	w.Writeln(Message{Text: fmt.Sprintf("Inferred manifest (%d file%s):", len(items), pluralS(len(items))), Emoji: "🔍", Bold: true})
+	for _, item := range items {
+		w.WriteString("   ").
+			Writeln(Message{Text: "~/" + filepath.ToSlash(item), Emoji: "📄"})
+	}
+	if err := w.Err(); err != nil {
+		return err
+	}
+
+	if !yes {
+		w.WritelnString("").
+			WriteString("Write the manifest and create symlinks? [y/N] ")
+		if err := w.Err(); err != nil {
+			return err
+		}
+
+		answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read answer: %w", err)
+		}
+		if err == io.EOF {
+			w.WritelnString("")
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			w.Writeln(Info("Import cancelled — nothing was written"))
+			return w.Err()
+		}
+	}
+
+	info, err := l.ImportExisting(items)
+	if err != nil {
+		return err
+	}
+
+	w.WritelnString("").
+		Writeln(Target(fmt.Sprintf("Imported %d file%s into lnk", len(items), pluralS(len(items))))).
+		WriteString("   ").
+		Write(Message{Text: "Location: ", Emoji: "📁"}).
+		Writeln(Colored(lnk.DisplayPath(lnk.GetRepoPath()), ColorGray)).
+		WriteString("   ").
+		Writeln(Link(fmt.Sprintf("Restored %d symlink%s", len(info.Restored), pluralS(len(info.Restored)))))
+	writeConflictNotices(w, info)
+
+	w.WritelnString("").
+		Writeln(Info("Next steps:")).
+		WriteString("   • Run ").
+		Write(Bold("lnk bootstrap")).
+		Writeln(Plain(" if the repository has a setup script")).
+		WriteString("   • Use ").
+		Write(Bold("lnk push")).
+		Writeln(Plain(" to sync the manifest to remote"))
+
+	return w.Err()
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// errRepoClean is returned by `lnk is-dirty` when the repository has no
+// uncommitted changes. Like errDiffHasChanges it only sets the exit code.
+var errRepoClean = errors.New("repository has no uncommitted changes")
+
+func newIsDirtyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "is-dirty",
+		Short: "🚦 Exit 0 if the repository has uncommitted changes, 1 if it is clean",
+		Long: `Checks the lnk repository for uncommitted changes, untracked files included,
+and reports the answer through the exit code so it reads naturally in shell
+conditionals:
+
+  if lnk is-dirty -q; then echo "dotfiles not pushed"; fi
+
+Exits 0 when there are changes and 1 when the repository is clean. It also
+exits 1 on an error, such as a missing repository, which is still printed to
+stderr. With --quiet nothing is printed on stdout.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := GetWriter(cmd)
+
+			dirty, err := lnk.NewLnk().HasChanges()
+			if err != nil {
+				return err
+			}
+
+			if !dirty {
+				w.Writeln(Success("Repository is clean"))
+				if err := w.Err(); err != nil {
+					return err
+				}
+				return errRepoClean
+			}
+
+			w.Writeln(Warning("Repository has uncommitted changes")).
+				WriteString("   ").
+				Write(Info("Run ")).
+				Write(Bold("lnk push")).
+				Writeln(Plain(" to commit and push them"))
+			return w.Err()
+		},
+	}
+}
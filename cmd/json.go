@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// addJSONFlags adds --json, and --output to send the JSON to a file, to a
+// command whose report writeJSON prints.
+func addJSONFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().Bool("json", false, usage)
+	cmd.Flags().StringP("output", "o", "", "With --json, write the report to this file instead of stdout")
+}
+
+// jsonFlag reports whether --json was given, failing when --output was given
+// without it.
+func jsonFlag(cmd *cobra.Command) (bool, error) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if output, _ := cmd.Flags().GetString("output"); output != "" && !asJSON {
+		return false, fmt.Errorf("invalid --output: it writes the JSON report, so pass --json too")
+	}
+	return asJSON, nil
+}
+
+// writeJSON prints v as indented JSON, or writes it to the --output file
+// instead. The file is replaced atomically, so a dashboard reading it never
+// sees half a report, and errors still reach stderr rather than the file.
+func writeJSON(cmd *cobra.Command, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		w := GetWriter(cmd)
+		w.WritelnString(string(data))
+		return w.Err()
+	}
+	if err := writeFileAtomic(output, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes content to a temp file next to path and renames it
+// over path, so path holds either the old or the new content.
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
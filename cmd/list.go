@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,38 +16,245 @@ func newListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "list",
 		Short:         "📋 List files managed by lnk",
-		Long:          "Display all files and directories currently managed by lnk.",
+		Long:          "Display all files and directories currently managed by lnk.\n\nWith --long, also show when each item was first added and its comment\n(add --comment). With --tree, group\nthe items by directory like tree(1); a managed directory is one leaf.\n\nWith --unmanaged, look the other way: check common dotfile locations in $HOME\n(.bashrc, .vimrc, .config/*, ...; list.candidates in config.toml replaces the\nlist) and show the ones no lnk configuration manages, with the lnk add command\nto start. Symlinks into another tool's directory, such as a stow package, are\nshown with their target.\n\nWith --missing, show the managed items of the common configuration (and of\n--host) that are not in place in $HOME on this machine: what pull would\nrestore, worked out locally without contacting the remote. It ends with the\npull --only command that restores exactly those.\n\nWith --json, print the managed items (of the common configuration, --host,\nor --all of them) as JSON for scripts and dashboards; --output <file> writes\nthat to a file instead, replaced atomically.",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			host, _ := cmd.Flags().GetString("host")
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
 			all, _ := cmd.Flags().GetBool("all")
+			long, _ := cmd.Flags().GetBool("long")
+			tree, _ := cmd.Flags().GetBool("tree")
+			unmanaged, _ := cmd.Flags().GetBool("unmanaged")
+			missing, _ := cmd.Flags().GetBool("missing")
+			asJSON, err := jsonFlag(cmd)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				return listJSON(cmd, host, all)
+			}
+
+			if unmanaged {
+				return listUnmanaged(cmd)
+			}
+
+			if missing {
+				return listMissing(cmd, host)
+			}
 
 			if host != "" {
 				// Show specific host configuration
-				return listHostConfig(cmd, host)
+				return listHostConfig(cmd, host, long, tree)
 			}
 
 			if all {
 				// Show all configurations (common + all hosts)
-				return listAllConfigs(cmd)
+				return listAllConfigs(cmd, long, tree)
 			}
 
 			// Default: show common configuration
-			return listCommonConfig(cmd)
+			return listCommonConfig(cmd, long, tree)
 		},
 	}
 
-	cmd.Flags().StringP("host", "H", "", "List files for specific host")
+	cmd.Flags().StringP("host", "H", "", "List files for specific host, or 'auto' for this machine's hostname")
 	cmd.Flags().BoolP("all", "a", false, "List files for all hosts and common configuration")
+	cmd.Flags().BoolP("long", "l", false, "Show when each file was added and its comment")
+	cmd.Flags().BoolP("tree", "t", false, "Show files as a directory tree")
+	cmd.Flags().Bool("unmanaged", false, "List common dotfiles in $HOME that lnk does not manage yet")
+	cmd.Flags().Bool("missing", false, "List managed files that are not in place in $HOME on this machine")
+	cmd.MarkFlagsMutuallyExclusive("unmanaged", "host")
+	cmd.MarkFlagsMutuallyExclusive("unmanaged", "all")
+	cmd.MarkFlagsMutuallyExclusive("missing", "unmanaged")
+	cmd.MarkFlagsMutuallyExclusive("missing", "all")
+	addJSONFlags(cmd, "Print the managed items as JSON")
+	cmd.MarkFlagsMutuallyExclusive("json", "unmanaged")
+	cmd.MarkFlagsMutuallyExclusive("json", "missing")
+	cmd.MarkFlagsMutuallyExclusive("json", "tree")
 	return cmd
 }
 
-func listCommonConfig(cmd *cobra.Command) error {
+// listItem is one managed item in lnk list --json.
+type listItem struct {
+	Path      string    `json:"path"`
+	Host      string    `json:"host,omitempty"`
+	AddedAt   time.Time `json:"added_at,omitzero"`
+	Copy      bool      `json:"copy,omitempty"`
+	Secret    bool      `json:"secret,omitempty"`
+	Directory bool      `json:"directory,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+}
+
+// listReport is what lnk list --json prints.
+type listReport struct {
+	Items []listItem `json:"items"`
+}
+
+// listJSON handles list --json: the items of host's configuration (the
+// common one when empty), or with all and no host those of every
+// configuration, as the plain listing picks them.
+func listJSON(cmd *cobra.Command, host string, all bool) error {
+	hosts := []string{host}
+	if all && host == "" {
+		found, err := findHostConfigs()
+		if err != nil {
+			return err
+		}
+		hosts = append([]string{""}, found...)
+	}
+
+	report := listReport{Items: []listItem{}}
+	for _, h := range hosts {
+		l := lnk.NewLnk(lnk.WithHost(h))
+		entries, err := l.ListEntries()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			report.Items = append(report.Items, listItem{
+				Path:      filepath.ToSlash(entry.Path),
+				Host:      h,
+				AddedAt:   entry.AddedAt,
+				Copy:      entry.Copy,
+				Secret:    entry.Secret,
+				Directory: l.IsManagedDirectory(entry.Path),
+				Comment:   entry.Comment,
+			})
+		}
+	}
+	return writeJSON(cmd, report)
+}
+
+// listUnmanaged handles list --unmanaged: candidate dotfiles no lnk
+// configuration tracks, and the add command that would start managing them.
+func listUnmanaged(cmd *cobra.Command) error {
+	w := GetWriter(cmd)
+
+	items, err := lnk.NewLnk().Unmanaged(fileConfig.Candidates)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		w.Writeln(Message{Text: "No unmanaged dotfiles found", Emoji: "📋", Bold: true}).
+			WriteString("   ").
+			Writeln(Info("Every candidate location is managed by lnk or missing"))
+		return w.Err()
+	}
+
+	w.Writeln(Message{Text: fmt.Sprintf("Dotfiles not managed by lnk (%d item%s):", len(items), pluralS(len(items))), Emoji: "📋", Bold: true}).
+		WritelnString("")
+
+	var addable []string
+	linked := 0
+	for _, item := range items {
+		path := "~/" + filepath.ToSlash(item.RelativePath)
+		if item.LinkTarget == "" {
+			w.WriteString("   ").
+				Writeln(Message{Text: path, Emoji: "📄"})
+			if strings.ContainsAny(path, " \t'\"") {
+				path = fmt.Sprintf("%q", path)
+			}
+			addable = append(addable, path)
+			continue
+		}
+
+		linked++
+		w.WriteString("   ").
+			Write(Message{Text: path, Emoji: "🔗"}).
+			WriteString(" → ").
+			Write(Colored(item.LinkTarget, ColorCyan)).
+			Writeln(Colored(" (linked by another tool)", ColorGray))
+	}
+
+	if len(addable) > 0 {
+		w.WritelnString("").
+			Write(Info("Start managing them with: ")).
+			Writeln(Bold("lnk add " + strings.Join(addable, " ")))
+	}
+	if linked > 0 {
+		w.WritelnString("").
+			Write(Info("For files linked from a GNU Stow directory, use ")).
+			Write(Bold("lnk import-stow <dir>")).
+			WritelnString(" to migrate them")
+	}
+	return w.Err()
+}
+
+// listMissing handles list --missing: managed items of the common
+// configuration and host that a pull would restore, and the pull command
+// that restores just those.
+func listMissing(cmd *cobra.Command, host string) error {
+	w := GetWriter(cmd)
+
+	items, err := lnk.NewLnk(lnk.WithHost(host)).Missing(host)
+	if err != nil {
+		return err
+	}
+
+	scope := "common"
+	if host != "" {
+		scope = "common + host: " + host
+	}
+	if len(items) == 0 {
+		w.Writeln(Message{Text: fmt.Sprintf("Every managed file is in place (%s)", scope), Emoji: "📋", Bold: true}).
+			WriteString("   ").
+			Writeln(Info("Nothing for pull to restore on this machine"))
+		return w.Err()
+	}
+
+	w.Writeln(Message{Text: fmt.Sprintf("Managed files not in place on this machine (%s) (%d item%s):", scope, len(items), pluralS(len(items))), Emoji: "📋", Bold: true}).
+		WritelnString("")
+
+	pull := []string{"lnk pull"}
+	if host != "" {
+		pull = append(pull, "--host "+host)
+	}
+	for _, item := range items {
+		path := "~/" + filepath.ToSlash(item.Path)
+		w.WriteString("   ").
+			Write(Link(path))
+		if item.Host != "" {
+			w.WriteString(" ").
+				Write(Colored("(host: "+item.Host+")", ColorGray))
+		}
+		switch {
+		case item.Blocker == "":
+			w.WriteString(" ").
+				Write(Colored("(missing)", ColorGray))
+		case item.Copy:
+			w.WriteString(" ").
+				Write(Colored("(copy differs from the repository)", ColorGray))
+		case item.Blocker == "symlink":
+			w.WriteString(" ").
+				Write(Colored("(symlink points elsewhere)", ColorGray))
+		default:
+			w.WriteString(" ").
+				Write(Colored(fmt.Sprintf("(%s in the way)", item.Blocker), ColorGray))
+		}
+		w.WritelnString("")
+
+		if strings.ContainsAny(path, " \t'\"") {
+			path = fmt.Sprintf("%q", path)
+		}
+		pull = append(pull, "--only "+path)
+	}
+
+	w.WritelnString("").
+		Write(Info("Restore exactly these with: ")).
+		Writeln(Bold(strings.Join(pull, " ")))
+	return w.Err()
+}
+
+func listCommonConfig(cmd *cobra.Command, long, tree bool) error {
 	lnk := lnk.NewLnk()
 	w := GetWriter(cmd)
 
-	managedItems, err := lnk.List()
+	managedItems, err := lnk.ListEntries()
 	if err != nil {
 		return err
 	}
@@ -68,10 +277,7 @@ func listCommonConfig(cmd *cobra.Command) error {
 	w.Writeln(Message{Text: countText, Emoji: "📋", Bold: true}).
 		WritelnString("")
 
-	for _, item := range managedItems {
-		w.WriteString("   ").
-			Writeln(Link(item))
-	}
+	writeListEntries(w, lnk, managedItems, long, tree)
 
 	w.WritelnString("").
 		Write(Info("Use ")).
@@ -80,11 +286,11 @@ func listCommonConfig(cmd *cobra.Command) error {
 	return w.Err()
 }
 
-func listHostConfig(cmd *cobra.Command, host string) error {
+func listHostConfig(cmd *cobra.Command, host string, long, tree bool) error {
 	lnk := lnk.NewLnk(lnk.WithHost(host))
 	w := GetWriter(cmd)
 
-	managedItems, err := lnk.List()
+	managedItems, err := lnk.ListEntries()
 	if err != nil {
 		return err
 	}
@@ -107,10 +313,7 @@ func listHostConfig(cmd *cobra.Command, host string) error {
 	w.Writeln(Message{Text: countText, Emoji: "📋", Bold: true}).
 		WritelnString("")
 
-	for _, item := range managedItems {
-		w.WriteString("   ").
-			Writeln(Link(item))
-	}
+	writeListEntries(w, lnk, managedItems, long, tree)
 
 	w.WritelnString("").
 		Write(Info("Use ")).
@@ -119,7 +322,7 @@ func listHostConfig(cmd *cobra.Command, host string) error {
 	return w.Err()
 }
 
-func listAllConfigs(cmd *cobra.Command) error {
+func listAllConfigs(cmd *cobra.Command, long, tree bool) error {
 	w := GetWriter(cmd)
 
 	// List common configuration
@@ -127,7 +330,7 @@ func listAllConfigs(cmd *cobra.Command) error {
 		WritelnString("")
 
 	lnkApp := lnk.NewLnk()
-	commonItems, err := lnkApp.List()
+	commonItems, err := lnkApp.ListEntries()
 	if err != nil {
 		return err
 	}
@@ -144,10 +347,7 @@ func listAllConfigs(cmd *cobra.Command) error {
 		w.WriteString("   ").
 			Writeln(Colored("(no files)", ColorGray))
 	} else {
-		for _, item := range commonItems {
-			w.WriteString("   ").
-				Writeln(Link(item))
-		}
+		writeListEntries(w, lnkApp, commonItems, long, tree)
 	}
 
 	// Find all host-specific configurations
@@ -161,7 +361,7 @@ func listAllConfigs(cmd *cobra.Command) error {
 			Write(Message{Text: fmt.Sprintf("Host: %s", host), Emoji: "🖥️", Bold: true})
 
 		hostLnk := lnk.NewLnk(lnk.WithHost(host))
-		hostItems, err := hostLnk.List()
+		hostItems, err := hostLnk.ListEntries()
 		if err != nil {
 			w.WriteString(" ").
 				Writeln(Colored(fmt.Sprintf("(error: %v)", err), ColorRed))
@@ -181,10 +381,7 @@ func listAllConfigs(cmd *cobra.Command) error {
 			w.WriteString("   ").
 				Writeln(Colored("(no files)", ColorGray))
 		} else {
-			for _, item := range hostItems {
-				w.WriteString("   ").
-					Writeln(Link(item))
-			}
+			writeListEntries(w, hostLnk, hostItems, long, tree)
 		}
 
 		w.WriteString("   ").
@@ -200,28 +397,125 @@ func listAllConfigs(cmd *cobra.Command) error {
 	return w.Err()
 }
 
-func findHostConfigs() ([]string, error) {
-	repoPath := lnk.GetRepoPath()
+// writeListEntries renders managed items one per line, or with tree as a
+// directory tree.
+func writeListEntries(w *Writer, l *lnk.Lnk, entries []lnk.ManagedEntry, long, tree bool) {
+	if !tree {
+		for _, entry := range entries {
+			writeListEntry(w, entry, long)
+		}
+		return
+	}
 
-	// Check if repo exists
-	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
-		return []string{}, nil
+	root := &listTreeNode{}
+	for _, entry := range entries {
+		root.insert(entry, l.IsManagedDirectory(entry.Path))
 	}
+	writeListTree(w, root, "", long)
+}
 
-	entries, err := os.ReadDir(repoPath)
-	if err != nil {
-		return nil, err
+// writeListEntry renders one managed item.
+func writeListEntry(w *Writer, entry lnk.ManagedEntry, long bool) {
+	w.WriteString("   ").
+		Write(Link(entry.Path))
+	writeListEntryDetails(w, entry, long)
+}
+
+// writeListEntryDetails ends a managed item's line. It marks copy-managed
+// items, which are not symlinked and only sync on push, and secret items,
+// which git-crypt encrypts. With long, it then adds the mode set by add
+// --chmod, the local date the item was first added ("unknown" for entries
+// tracked before timestamps were recorded) and the item's comment.
+func writeListEntryDetails(w *Writer, entry lnk.ManagedEntry, long bool) {
+	if entry.Copy {
+		w.WriteString(" ").
+			Write(Colored("(copy)", ColorGray))
+	}
+	if entry.Secret {
+		w.WriteString(" ").
+			Write(Colored("(secret)", ColorGray))
+	}
+	if !long {
+		w.WritelnString("")
+		return
 	}
 
-	var hosts []string
-	for _, entry := range entries {
-		name := entry.Name()
-		// Look for .lnk.<hostname> files
-		if strings.HasPrefix(name, ".lnk.") && name != ".lnk" {
-			host := strings.TrimPrefix(name, ".lnk.")
-			hosts = append(hosts, host)
+	if entry.Mode != "" {
+		w.WriteString("  ").
+			Write(Colored("mode "+entry.Mode, ColorGray))
+	}
+	added := "added: unknown"
+	if !entry.AddedAt.IsZero() {
+		added = "added " + entry.AddedAt.Local().Format("2006-01-02 15:04")
+	}
+	w.WriteString("  ").
+		Write(Colored(added, ColorGray))
+	if entry.Comment != "" {
+		w.WriteString("  ").
+			Write(Plain("# " + strings.Join(strings.Fields(entry.Comment), " ")))
+	}
+	w.WritelnString("")
+}
+
+// listTreeNode is one path component in `lnk list --tree`. entry is set when
+// the component is itself a managed item; otherwise it only groups children.
+type listTreeNode struct {
+	name     string
+	entry    *lnk.ManagedEntry
+	isDir    bool
+	children []*listTreeNode
+}
+
+// insert adds entry below n, creating the directories on its path.
+func (n *listTreeNode) insert(entry lnk.ManagedEntry, isDir bool) {
+	node := n
+	for _, part := range strings.Split(filepath.ToSlash(entry.Path), "/") {
+		node = node.child(part)
+	}
+	node.entry = &entry
+	node.isDir = isDir
+}
+
+// child returns the child of n called name, adding it in sorted position if
+// missing.
+func (n *listTreeNode) child(name string) *listTreeNode {
+	i, found := slices.BinarySearchFunc(n.children, name, func(c *listTreeNode, name string) int {
+		return strings.Compare(c.name, name)
+	})
+	if !found {
+		n.children = slices.Insert(n.children, i, &listTreeNode{name: name})
+	}
+	return n.children[i]
+}
+
+// writeListTree renders the children of n with tree(1)-style branches.
+// Grouping directories end in "/"; a managed directory is a leaf marked
+// "(directory)".
+func writeListTree(w *Writer, n *listTreeNode, prefix string, long bool) {
+	for i, child := range n.children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		w.WriteString("   " + prefix + branch)
+
+		switch {
+		case child.entry == nil:
+			w.Writeln(Message{Text: child.name + "/", Emoji: "📁", Bold: true})
+		case child.isDir:
+			w.Write(Link(child.name + "/")).
+				WriteString(" ").
+				Write(Colored("(directory)", ColorGray))
+			writeListEntryDetails(w, *child.entry, long)
+		default:
+			w.Write(Link(child.name))
+			writeListEntryDetails(w, *child.entry, long)
 		}
+
+		writeListTree(w, child, prefix+indent, long)
 	}
+}
 
-	return hosts, nil
+func findHostConfigs() ([]string, error) {
+	return lnk.NewLnk().Hosts()
 }
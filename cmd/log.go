@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// logFileRow is one changed file in lnk log --json.
+type logFileRow struct {
+	Path string `json:"path"`
+	Home string `json:"home,omitempty"`
+	Host string `json:"host,omitempty"`
+}
+
+// logCommit is one commit in lnk log --json.
+type logCommit struct {
+	Hash    string       `json:"hash"`
+	Date    time.Time    `json:"date"`
+	Subject string       `json:"subject"`
+	Files   []logFileRow `json:"files"`
+}
+
+// logReport is what lnk log --json prints.
+type logReport struct {
+	Since   string      `json:"since,omitempty"`
+	Commits []logCommit `json:"commits"`
+}
+
+func newLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "📜 Show the history of the managed files",
+		Long: `Lists the repository's commits, newest first, to review what changed in your
+dotfiles, for example after a long gap on a machine.
+
+--since limits the list to what came after a commit (a hash, tag or branch)
+or a date (2006-01-02, 2006-01-02 15:04 or RFC 3339). --name-only adds the
+files each commit changed, mapped back to their place under $HOME through
+the indexes; files no index lists, such as the indexes themselves or items
+removed since, are shown by their repository path.
+
+--json prints the commits with their files as JSON for tooling; --output
+<file> writes it to a file instead.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			since, _ := cmd.Flags().GetString("since")
+			max, _ := cmd.Flags().GetInt("max-count")
+			nameOnly, _ := cmd.Flags().GetBool("name-only")
+			asJSON, err := jsonFlag(cmd)
+			if err != nil {
+				return err
+			}
+			if max < 0 {
+				return fmt.Errorf("invalid --max-count value: %d (use a positive number, or 0 for all)", max)
+			}
+
+			entries, err := lnk.NewLnk().Log(since, max)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				return writeJSON(cmd, logJSON(entries, since))
+			}
+
+			w := GetWriter(cmd)
+			writeLog(w, entries, since, nameOnly)
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().String("since", "", "Only show commits after this commit or date")
+	cmd.Flags().IntP("max-count", "n", 0, "Show at most N commits (default: all)")
+	cmd.Flags().Bool("name-only", false, "List the files each commit changed, as $HOME paths")
+	addJSONFlags(cmd, "Print the commits and their files as JSON")
+	return cmd
+}
+
+// logJSON builds the --json report for entries.
+func logJSON(entries []lnk.LogEntry, since string) logReport {
+	report := logReport{Since: since, Commits: []logCommit{}}
+	for _, entry := range entries {
+		commit := logCommit{Hash: entry.Hash, Date: entry.Date, Subject: entry.Subject, Files: []logFileRow{}}
+		for _, file := range entry.Files {
+			commit.Files = append(commit.Files, logFileRow(file))
+		}
+		report.Commits = append(report.Commits, commit)
+	}
+	return report
+}
+
+// writeLog renders entries one commit per line (short hash, local date,
+// subject), with nameOnly followed by the files each commit changed.
+func writeLog(w *Writer, entries []lnk.LogEntry, since string, nameOnly bool) {
+	if len(entries) == 0 {
+		text := "No commits yet"
+		if since != "" {
+			text = "No commits since " + since
+		}
+		w.Writeln(Message{Text: text, Emoji: "📜", Bold: true})
+		return
+	}
+
+	heading := fmt.Sprintf("History (%d commit%s):", len(entries), pluralS(len(entries)))
+	if since != "" {
+		heading = fmt.Sprintf("Commits since %s (%d commit%s):", since, len(entries), pluralS(len(entries)))
+	}
+	w.Writeln(Message{Text: heading, Emoji: "📜", Bold: true}).
+		WritelnString("")
+
+	for _, entry := range entries {
+		hash := entry.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		w.WriteString("   ").
+			Write(Colored(hash, ColorCyan)).
+			WriteString("  ").
+			Write(Colored(entry.Date.Local().Format("2006-01-02 15:04"), ColorGray)).
+			WriteString("  ").
+			Writeln(Plain(entry.Subject))
+		if !nameOnly {
+			continue
+		}
+
+		for _, file := range entry.Files {
+			w.WriteString("      ")
+			if file.Home == "" {
+				w.Writeln(Colored(file.Path+" (repository file)", ColorGray))
+				continue
+			}
+			w.Write(Plain("~/" + file.Home))
+			if file.Host != "" {
+				w.Write(Colored(" (host: "+file.Host+")", ColorGray))
+			}
+			w.WritelnString("")
+		}
+	}
+}
@@ -167,13 +167,14 @@ var (
 		Emoji:  true,
 	}
 	autoDetected bool
+	colorMode    = "auto"
 )
 
 // SetGlobalConfig updates the global output configuration
 func SetGlobalConfig(colors string, emoji, quiet bool) error {
 	switch colors {
 	case "auto":
-		globalConfig.Colors = isTerminal()
+		globalConfig.Colors = isTerminal(os.Stdout)
 	case "always":
 		globalConfig.Colors = true
 	case "never":
@@ -189,13 +190,14 @@ func SetGlobalConfig(colors string, emoji, quiet bool) error {
 
 	globalConfig.Emoji = emoji
 	globalConfig.Quiet = quiet
+	colorMode = colors
 	autoDetected = true
 	return nil
 }
 
-// isTerminal checks if stdout is a terminal
-func isTerminal() bool {
-	fileInfo, err := os.Stdout.Stat()
+// isTerminal checks if f is a terminal
+func isTerminal(f *os.File) bool {
+	fileInfo, err := f.Stat()
 	if err != nil {
 		return false
 	}
@@ -231,7 +233,7 @@ func autoDetectConfig() {
 		if os.Getenv("NO_COLOR") != "" {
 			globalConfig.Colors = false
 		} else {
-			globalConfig.Colors = isTerminal()
+			globalConfig.Colors = isTerminal(os.Stdout)
 		}
 		autoDetected = true
 	}
@@ -243,10 +245,28 @@ func GetWriter(cmd *cobra.Command) *Writer {
 	return NewWriter(cmd.OutOrStdout(), globalConfig)
 }
 
-// GetErrorWriter returns a writer for stderr
+// GetErrorWriter returns a writer for stderr. Errors are shown even in quiet
+// mode, which only silences regular output.
 func GetErrorWriter() *Writer {
 	autoDetectConfig()
-	return NewWriter(os.Stderr, globalConfig)
+	config := globalConfig
+	config.Quiet = false
+	// In auto mode stderr is detected on its own, so `lnk ... 2>err.log`
+	// keeps escape codes out of the log while stdout stays colored.
+	if colorMode == "auto" {
+		config.Colors = isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == ""
+	}
+	return NewWriter(os.Stderr, config)
+}
+
+// GetPromptWriter returns a writer for questions the command then reads an
+// answer to. Like errors, they are shown even in quiet mode: a command must
+// not wait on input for a question nobody saw.
+func GetPromptWriter(cmd *cobra.Command) *Writer {
+	autoDetectConfig()
+	config := globalConfig
+	config.Quiet = false
+	return NewWriter(cmd.OutOrStdout(), config)
 }
 
 // Err returns the first error encountered during writing
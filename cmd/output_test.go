@@ -267,3 +267,47 @@ func TestStructuredErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQuietModeStillShowsErrors(t *testing.T) {
+	if err := SetGlobalConfig("never", false, true); err != nil {
+		t.Fatalf("SetGlobalConfig: %v", err)
+	}
+	defer func() { _ = SetGlobalConfig("never", true, false) }()
+
+	if GetErrorWriter().Quiet() {
+		t.Error("error writer must ignore quiet mode")
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, OutputConfig{})
+	writeError(w, error2.WithSuggestion(errors.New("something went wrong"), "try this instead"))
+	if !strings.Contains(buf.String(), "something went wrong") || !strings.Contains(buf.String(), "try this instead") {
+		t.Errorf("expected the error and suggestion, got %q", buf.String())
+	}
+
+	buf.Reset()
+	writeError(w, errDiffHasChanges)
+	writeError(w, errRepoClean)
+	if buf.Len() != 0 {
+		t.Errorf("exit-code-only errors must not be shown, got %q", buf.String())
+	}
+}
+
+func TestErrorWriterColors(t *testing.T) {
+	defer func() { _ = SetGlobalConfig("never", true, false) }()
+
+	if err := SetGlobalConfig("always", true, false); err != nil {
+		t.Fatalf("SetGlobalConfig: %v", err)
+	}
+	if !GetErrorWriter().Colors() {
+		t.Error("--colors always must color stderr too")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if err := SetGlobalConfig("auto", true, false); err != nil {
+		t.Fatalf("SetGlobalConfig: %v", err)
+	}
+	if GetErrorWriter().Colors() {
+		t.Error("NO_COLOR must disable stderr colors in auto mode")
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune --normalize",
+		Short: "🧹 Clean up the tracking file",
+		Long: `Rewrites the lnk tracking file (.lnk, or .lnk.<host> with --host) in clean form.
+
+With --normalize, entries are trimmed and cleaned (./.vimrc becomes .vimrc),
+duplicates are dropped and the list is sorted, then the result is committed.
+lnk already reads the file this way; prune makes the file on disk match, which
+helps after hand edits or merges. Paths differing only in case are kept on
+case-sensitive filesystems, where they are different files; on a
+case-insensitive one (the macOS default) they are one file and the spelling it
+is stored under is kept.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			normalize, _ := cmd.Flags().GetBool("normalize")
+			if !normalize {
+				return errors.New("nothing to prune: pass --normalize to rewrite the tracking file")
+			}
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			l := lnk.NewLnk(lnk.WithHost(host))
+			w := GetWriter(cmd)
+
+			result, err := l.Normalize()
+			if err != nil {
+				return err
+			}
+
+			hostSuffix := ""
+			if host != "" {
+				hostSuffix = fmt.Sprintf(" (host: %s)", host)
+			}
+
+			if !result.Rewritten {
+				w.Writeln(Success("Tracking file is already normalized" + hostSuffix))
+				return w.Err()
+			}
+
+			w.Writeln(Success("Normalized tracking file" + hostSuffix))
+			if result.Removed > 0 {
+				w.WriteString("   ").
+					Writeln(Message{Text: fmt.Sprintf("Removed %d duplicate or empty entr%s", result.Removed, pluralY(result.Removed)), Emoji: "🧹"})
+			}
+			w.WriteString("   ").
+				Write(Message{Text: "Use ", Emoji: "📝"}).
+				Write(Bold("lnk push")).
+				WritelnString(" to sync to remote")
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().Bool("normalize", false, "Deduplicate, clean and sort the tracking file entries")
+	cmd.Flags().StringP("host", "H", "", "Prune the tracking file of a specific host, or 'auto' for this machine's hostname (default: common configuration)")
+	return cmd
+}
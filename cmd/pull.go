@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -10,27 +12,61 @@ import (
 
 func newPullCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "pull",
-		Short:         "⬇️ Pull changes from remote and restore symlinks",
-		Long:          "Fetches changes from remote repository and automatically restores symlinks for all managed files.",
+		Use:   "pull",
+		Short: "⬇️ Pull changes from remote and restore symlinks",
+		Long: `Fetches changes from remote repository and automatically restores symlinks for all managed files.
+
+Without flags only the common configuration is restored. With --host, the common
+configuration AND the named host are restored in one go. With --all-hosts, the
+common configuration and every host found in the repository are restored.
+
+Existing files that are in the way of a symlink are renamed to <path>.lnk-backup
+by default. Use --on-conflict to overwrite or skip them instead, or --interactive
+to decide per file (with the option to see a diff first).
+
+Use --only to restore just part of the repository, e.g. shell config on a server
+but not GUI app configs: only managed items at or below one of the given paths
+are linked, the rest are left unlinked and listed. Paths are matched by whole
+components, so --only ~/.config/nvim does not select ~/.config/nvim-old.
+
+Use --remote to pull from a remote other than the default (origin, or the only
+remote there is), e.g. a backup while the primary is down; see 'lnk remote'.
+
+Use --rebase to replay your local commits on top of the remote's instead of
+merging, so the history stays linear; set pull.rebase = true in config.toml
+to make it the default. Uncommitted changes are stashed around the rebase.
+If it stops on conflicts, the repository is left mid-rebase: resolve them
+and run 'git rebase --continue' in the repository, or 'git rebase --abort'
+to return to where you were.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			host, _ := cmd.Flags().GetString("host")
-			lnk := lnk.NewLnk(lnk.WithHost(host))
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			allHosts, _ := cmd.Flags().GetBool("all-hosts")
+			only, _ := cmd.Flags().GetStringSlice("only")
+			remote, _ := cmd.Flags().GetString("remote")
+			resolver, err := conflictResolverFlag(cmd)
+			if err != nil {
+				return err
+			}
 			w := GetWriter(cmd)
+			opts := []lnk.Option{lnk.WithConflictResolver(resolver), lnk.WithRestoreOnly(only), lnk.WithRemote(remote), lnk.WithRebase(rebaseFlag(cmd)), retryNotice(w)}
+
+			if host != "" || allHosts {
+				return pullHosts(cmd, host, allHosts, opts)
+			}
+
+			lnk := lnk.NewLnk(opts...)
 
 			result, err := lnk.Pull()
 			if err != nil {
 				return err
 			}
 
-			var successMsg string
-			if host != "" {
-				successMsg = fmt.Sprintf("Successfully pulled changes (host: %s)", host)
-			} else {
-				successMsg = "Successfully pulled changes"
-			}
+			successMsg := "Successfully pulled changes"
 
 			if len(result.Restored) > 0 {
 				symlinkText := fmt.Sprintf("Restored %d symlink", len(result.Restored))
@@ -48,7 +84,12 @@ func newPullCmd() *cobra.Command {
 						Writeln(Sparkles(file))
 				}
 
-				writeBackupNotice(w, result.BackedUp)
+				writeConflictNotices(w, result)
+				writeExcludedNotice(w, result.Excluded)
+				writeRestoreSummary(w, result)
+				if len(result.Failed) > 0 {
+					return restoreIncomplete(w, result)
+				}
 
 				w.WritelnString("").
 					WriteString("   ").
@@ -56,8 +97,16 @@ func newPullCmd() *cobra.Command {
 			} else {
 				w.Writeln(Message{Text: successMsg, Emoji: "⬇️", Color: ColorBrightGreen, Bold: true}).
 					WriteString("   ").
-					Writeln(Success("All symlinks already in place")).
-					WriteString("   ").
+					Writeln(Success("All symlinks already in place"))
+
+				writeConflictNotices(w, result)
+				writeExcludedNotice(w, result.Excluded)
+				writeRestoreSummary(w, result)
+				if len(result.Failed) > 0 {
+					return restoreIncomplete(w, result)
+				}
+
+				w.WriteString("   ").
 					Writeln(Message{Text: "Everything is up to date!", Emoji: "🎉"})
 			}
 
@@ -65,10 +114,264 @@ func newPullCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("host", "H", "", "Pull and restore symlinks for specific host (default: common configuration)")
+	cmd.Flags().StringP("host", "H", "", "Also restore symlinks for specific host, or 'auto' for this machine's hostname (common configuration is always restored)")
+	cmd.Flags().Bool("all-hosts", false, "Restore symlinks for the common configuration and every host in the repository")
+	cmd.Flags().StringSlice("only", nil, "Restore only managed items at or below these paths (repeatable)")
+	cmd.Flags().String("remote", "", "Pull from this remote instead of the default one")
+	cmd.MarkFlagsMutuallyExclusive("host", "all-hosts")
+	addConflictFlags(cmd)
+	addRebaseFlag(cmd)
 	return cmd
 }
 
+// addRebaseFlag registers --rebase on a command that pulls.
+func addRebaseFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("rebase", false, "Rebase local commits onto the remote's instead of merging (default from pull.rebase)")
+}
+
+// rebaseFlag reports whether to pull with --rebase: the flag when given,
+// otherwise pull.rebase from config.toml.
+func rebaseFlag(cmd *cobra.Command) bool {
+	if !cmd.Flags().Changed("rebase") {
+		return fileConfig.PullRebase
+	}
+	rebase, _ := cmd.Flags().GetBool("rebase")
+	return rebase
+}
+
+// pullHosts pulls once and restores the common configuration plus either the
+// named host or every host found in the repository, reporting results grouped
+// by scope. opts configure the pull: conflict resolver, --only and --remote.
+func pullHosts(cmd *cobra.Command, host string, allHosts bool, opts []lnk.Option) error {
+	w := GetWriter(cmd)
+
+	scopes := []string{""}
+	if allHosts {
+		hosts, err := findHostConfigs()
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, hosts...)
+	} else {
+		scopes = append(scopes, host)
+	}
+
+	results, err := lnk.NewLnk(opts...).PullHosts(scopes)
+	if err != nil {
+		return err
+	}
+
+	successMsg := "Successfully pulled changes (all hosts)"
+	if !allHosts {
+		successMsg = fmt.Sprintf("Successfully pulled changes (host: %s)", host)
+	}
+	w.Writeln(Message{Text: successMsg, Emoji: "⬇️", Color: ColorBrightGreen, Bold: true})
+
+	total := writeHostRestoreResults(w, results)
+	infos := restoreInfos(results)
+	writeRestoreSummary(w, infos...)
+	if err := restoreIncomplete(w, infos...); err != nil {
+		return err
+	}
+
+	w.WritelnString("").
+		WriteString("   ")
+	if total > 0 {
+		w.Writeln(Message{Text: "Your dotfiles are synced and ready!", Emoji: "🎉"})
+	} else {
+		w.Writeln(Message{Text: "Everything is up to date!", Emoji: "🎉"})
+	}
+
+	return w.Err()
+}
+
+// writeHostRestoreResults renders one restore section per scope, labelled
+// "(common)" or "(host: H)", and returns the total number of restored symlinks.
+func writeHostRestoreResults(w *Writer, results []lnk.HostRestoreInfo) int {
+	total := 0
+	for _, result := range results {
+		scopeLabel := "common"
+		if result.Host != "" {
+			scopeLabel = "host: " + result.Host
+		}
+
+		if len(result.Restored) == 0 {
+			w.WriteString("   ").
+				Writeln(Success(fmt.Sprintf("All symlinks already in place (%s)", scopeLabel)))
+			writeConflictNotices(w, result.RestoreInfo)
+			writeExcludedNotice(w, result.Excluded)
+			continue
+		}
+
+		total += len(result.Restored)
+		w.WriteString("   ").
+			Writeln(Link(fmt.Sprintf("Restored %d symlink%s (%s):", len(result.Restored), pluralS(len(result.Restored)), scopeLabel)))
+		for _, file := range result.Restored {
+			w.WriteString("      ").
+				Writeln(Sparkles(file))
+		}
+
+		writeConflictNotices(w, result.RestoreInfo)
+		writeExcludedNotice(w, result.Excluded)
+	}
+
+	return total
+}
+
+// restoreInfos returns the RestoreInfo of every scope in results.
+func restoreInfos(results []lnk.HostRestoreInfo) []*lnk.RestoreInfo {
+	infos := make([]*lnk.RestoreInfo, 0, len(results))
+	for _, result := range results {
+		infos = append(infos, result.RestoreInfo)
+	}
+	return infos
+}
+
+// writeRestoreSummary prints one line counting every outcome of the restores
+// in infos, so an item that was not restored is never passed over silently.
+// No-op when there were no managed items.
+func writeRestoreSummary(w *Writer, infos ...*lnk.RestoreInfo) {
+	var restored, inPlace, skipped, excluded, missing, failed int
+	for _, info := range infos {
+		restored += len(info.Restored)
+		inPlace += len(info.InPlace)
+		skipped += len(info.Skipped)
+		excluded += len(info.Excluded)
+		missing += len(info.Missing)
+		failed += len(info.Failed)
+	}
+	if restored+inPlace+skipped+excluded+missing+failed == 0 {
+		return
+	}
+
+	parts := []string{fmt.Sprintf("%d restored", restored), fmt.Sprintf("%d already in place", inPlace)}
+	for _, part := range []struct {
+		count int
+		label string
+	}{
+		{skipped, "skipped"},
+		{excluded, "outside --only"},
+		{missing, "missing from the repository"},
+		{failed, "failed"},
+	} {
+		if part.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.count, part.label))
+		}
+	}
+
+	w.WritelnString("").
+		WriteString("   ").
+		Writeln(Message{Text: "Summary: " + strings.Join(parts, ", "), Emoji: "📊"})
+}
+
+// restoreIncomplete returns ErrRestoreIncomplete when any restore in infos
+// left items it could not put in place, after a hint on retrying, so pull and
+// sync exit non-zero instead of claiming everything is ready.
+func restoreIncomplete(w *Writer, infos ...*lnk.RestoreInfo) error {
+	for _, info := range infos {
+		if len(info.Failed) > 0 {
+			w.WriteString("   ").
+				Write(Info("Fix the problems above, then run ")).
+				Writeln(Bold("lnk pull"))
+			if err := w.Err(); err != nil {
+				return err
+			}
+			return lnk.ErrRestoreIncomplete
+		}
+	}
+	return nil
+}
+
+// writeExcludedNotice lists the managed items that pull --only left
+// unlinked, the first 5 in detail. No-op without a filter.
+func writeExcludedNotice(w *Writer, excluded []string) {
+	if len(excluded) == 0 {
+		return
+	}
+
+	w.WritelnString("").
+		WriteString("   ").
+		Writeln(Info(fmt.Sprintf("Left %d managed item%s unlinked (outside --only):", len(excluded), pluralS(len(excluded)))))
+	for i, item := range excluded {
+		if i == 5 {
+			w.WriteString("      ").
+				Writeln(Plain(fmt.Sprintf("... and %d more files", len(excluded)-5)))
+			break
+		}
+		w.WriteString("      ").
+			Writeln(Plain("~/" + filepath.ToSlash(item)))
+	}
+}
+
+// writeConflictNotices reports what happened to existing files that were in
+// the way of a restored symlink: backed up, relinked, overwritten or skipped,
+// and which common items were left to a host's version of the same path.
+func writeConflictNotices(w *Writer, info *lnk.RestoreInfo) {
+	writeBackupNotice(w, info.BackedUp)
+
+	if len(info.Relinked) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Info(fmt.Sprintf("Relinked %d symlink%s that pointed elsewhere, e.g. the repository's old location:", len(info.Relinked), pluralS(len(info.Relinked)))))
+		for _, file := range info.Relinked {
+			w.WriteString("      ").
+				Writeln(Plain("~/" + file))
+		}
+	}
+
+	if len(info.Overridden) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Info(fmt.Sprintf("Kept the host version of %d common item%s:", len(info.Overridden), pluralS(len(info.Overridden)))))
+		for _, file := range info.Overridden {
+			w.WriteString("      ").
+				Writeln(Plain("~/" + file))
+		}
+	}
+
+	if len(info.Overwritten) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning(fmt.Sprintf("Overwrote %d existing file%s:", len(info.Overwritten), pluralS(len(info.Overwritten)))))
+		for _, file := range info.Overwritten {
+			w.WriteString("      ").
+				Writeln(Plain("~/" + file))
+		}
+	}
+
+	if len(info.Skipped) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning(fmt.Sprintf("Skipped %d file%s that already exist (symlink not restored):", len(info.Skipped), pluralS(len(info.Skipped)))))
+		for _, file := range info.Skipped {
+			w.WriteString("      ").
+				Writeln(Plain("~/" + file))
+		}
+	}
+
+	if len(info.Missing) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning(fmt.Sprintf("%d managed item%s missing from the repository (run lnk doctor):", len(info.Missing), pluralS(len(info.Missing)))))
+		for _, file := range info.Missing {
+			w.WriteString("      ").
+				Writeln(Plain("~/" + file))
+		}
+	}
+
+	if len(info.Failed) > 0 {
+		w.WritelnString("").
+			WriteString("   ").
+			Writeln(Warning(fmt.Sprintf("Failed to restore %d item%s:", len(info.Failed), pluralS(len(info.Failed)))))
+		for _, failure := range info.Failed {
+			w.WriteString("      ").
+				Write(Plain("~/" + failure.Path)).
+				WriteString(": ").
+				Writeln(Colored(failure.Err.Error(), ColorGray))
+		}
+	}
+}
+
 // writeBackupNotice renders a section listing files that were renamed to
 // <path>.lnk-backup so the user can decide what to do with them. No-op when
 // no backups occurred.
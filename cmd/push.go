@@ -1,42 +1,145 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/yarlson/lnk/internal/lnk"
 )
 
+// defaultPushMessage is the sync commit message used when none is given.
+const defaultPushMessage = "lnk: sync configuration files"
+
+// pushMessage returns the sync commit message to use when none is given:
+// push.default_message from config.toml, or defaultPushMessage.
+func pushMessage() string {
+	if fileConfig.PushMessage != "" {
+		return fileConfig.PushMessage
+	}
+	return defaultPushMessage
+}
+
+// retryNotice reports each network retry on w, so a push or pull over a
+// flaky connection says what it is waiting for; --quiet hides it.
+func retryNotice(w *Writer) lnk.Option {
+	return lnk.WithRetryHandler(func(op string, attempt, retries int, wait time.Duration) {
+		w.Writeln(Message{Text: fmt.Sprintf("Network error during %s, retrying in %s (%d of %d)", op, wait, attempt, retries), Emoji: "⏳"})
+	})
+}
+
 func newPushCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:           "push [message]",
-		Short:         "🚀 Push local changes to remote repository",
-		Long:          "Stages all changes, creates a sync commit with the provided message, and pushes to remote.",
+	cmd := &cobra.Command{
+		Use:   "push [message]",
+		Short: "🚀 Push local changes to remote repository",
+		Long: `Stages all changes, creates a sync commit with the provided message, and pushes to remote.
+
+Use --no-commit to push only work that is already committed; the push fails if
+the repository has uncommitted changes. Use --only to commit just the listed
+managed files (as paths in your home directory) and leave other changes in the
+repository uncommitted. Combine --only with --host for host-specific files.
+
+Without a message, push.default_message from config.toml is used, or else
+"lnk: sync configuration files". Messages can contain placeholders: {date}
+(today, 2006-01-02), {host} (the --host configuration, or this machine's
+hostname as --host auto spells it) and {count} (the number of files being
+committed), e.g. lnk push "sync from {host} {date}".
+
+Commits are signed when the repository's git config enables commit.gpgsign.
+Use --sign to sign this push's commit regardless.
+
+Use --remote to push to a remote other than the default (origin, or the only
+remote there is), or --all-remotes to push to every remote, e.g. GitHub and a
+self-hosted backup. Only the default remote becomes the branch's upstream.
+Register more remotes with 'lnk remote add <name> <url>'.
+
+Deleting a managed symlink from your home directory leaves the file tracked
+in the repository. --reconcile looks for such files first (in the common
+configuration and --host) and asks for each whether to restore the symlink,
+untrack the file (committing its removal, which the push then sends), or
+leave it for now.
+
+Copy-managed files (lnk add --copy) of the common configuration and --host
+are refreshed from your home directory before committing.`,
 		Args:          cobra.MaximumNArgs(1),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			message := "lnk: sync configuration files"
+			message := pushMessage()
 			if len(args) > 0 {
 				message = args[0]
 			}
+			noCommit, _ := cmd.Flags().GetBool("no-commit")
+			only, _ := cmd.Flags().GetStringSlice("only")
+			sign, _ := cmd.Flags().GetBool("sign")
+			remote, _ := cmd.Flags().GetString("remote")
+			allRemotes, _ := cmd.Flags().GetBool("all-remotes")
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := reconcile(cmd, host); err != nil {
+				return err
+			}
 
-			lnk := lnk.NewLnk()
 			w := GetWriter(cmd)
+			l := lnk.NewLnk(lnk.WithHost(host), lnk.WithSign(sign), lnk.WithRemote(remote), retryNotice(w))
+
+			if !noCommit {
+				if message, err = l.RenderMessage(message, only...); err != nil {
+					return err
+				}
+			}
 
-			if err := lnk.Push(message); err != nil {
+			if err := l.PushWithOptions(message, lnk.PushOptions{NoCommit: noCommit, Only: only, AllRemotes: allRemotes}); err != nil {
 				return err
 			}
 
+			synced := "Synced to remote"
+			switch {
+			case allRemotes:
+				remotes, err := l.Remotes()
+				if err != nil {
+					return err
+				}
+				names := make([]string, 0, len(remotes))
+				for _, r := range remotes {
+					names = append(names, r.Name)
+				}
+				synced = "Synced to " + strings.Join(names, ", ")
+			case remote != "":
+				synced = "Synced to " + remote
+			}
+
 			w.Writeln(Rocket("Successfully pushed changes")).
-				WriteString("   ").
-				Write(Message{Text: "Commit: ", Emoji: "💾"}).
-				Writeln(Colored(message, ColorGray)).
-				WriteString("   ").
-				Writeln(Message{Text: "Synced to remote", Emoji: "📡"}).
+				WriteString("   ")
+			if noCommit {
+				w.Writeln(Message{Text: "Pushed existing commits only", Emoji: "💾"})
+			} else {
+				w.Write(Message{Text: "Commit: ", Emoji: "💾"}).
+					Writeln(Colored(message, ColorGray))
+			}
+			w.WriteString("   ").
+				Writeln(Message{Text: synced, Emoji: "📡"}).
 				WriteString("   ").
 				Writeln(Sparkles("Your dotfiles are up to date!"))
 
 			return w.Err()
 		},
 	}
+
+	cmd.Flags().Bool("no-commit", false, "Push already-committed work only; fail if there are uncommitted changes")
+	cmd.Flags().StringSlice("only", nil, "Commit only these managed files before pushing (repeatable)")
+	cmd.Flags().StringP("host", "H", "", "Host scope for --only paths and copy-managed files, or 'auto' for this machine's hostname (default: common configuration)")
+	cmd.Flags().BoolP("sign", "S", false, "Sign the commit (git commit -S) using your configured signing key")
+	cmd.Flags().String("remote", "", "Push to this remote instead of the default one")
+	cmd.Flags().Bool("all-remotes", false, "Push to every configured remote")
+	addReconcileFlag(cmd)
+	cmd.MarkFlagsMutuallyExclusive("no-commit", "only")
+	cmd.MarkFlagsMutuallyExclusive("remote", "all-remotes")
+	cmd.MarkFlagsMutuallyExclusive("no-commit", "sign")
+	return cmd
 }
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// addReconcileFlag registers --reconcile on a command that checks, before it
+// does its own work, for managed items deleted from $HOME.
+func addReconcileFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("reconcile", false, "Ask whether to restore or untrack each managed file deleted from your home directory")
+}
+
+// reconcile handles --reconcile: every managed item of the common
+// configuration and host that is gone from $HOME (nothing at its path) is
+// offered for restoring, untracking or leaving as it is; the answers are read
+// from the command's input. Items something else stands in the way of are
+// left to pull, which knows how to back such files up.
+func reconcile(cmd *cobra.Command, host string) error {
+	if reconcileFlag, _ := cmd.Flags().GetBool("reconcile"); !reconcileFlag {
+		return nil
+	}
+
+	w := GetWriter(cmd)
+	items, err := lnk.NewLnk(lnk.WithHost(host)).Missing(host)
+	if err != nil {
+		return err
+	}
+	var deleted []lnk.MissingItem
+	for _, item := range items {
+		if item.Blocker == "" {
+			deleted = append(deleted, item)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	prompt := GetPromptWriter(cmd)
+	prompt.Writeln(Warning(fmt.Sprintf("%d managed file%s deleted from your home directory", len(deleted), pluralS(len(deleted)))))
+	reader := bufio.NewReader(cmd.InOrStdin())
+	for _, item := range deleted {
+		path := "~/" + filepath.ToSlash(item.Path)
+		if item.Host != "" {
+			path += " (host: " + item.Host + ")"
+		}
+
+		action, err := askReconcile(prompt, reader, path)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case "restore":
+			if _, err := lnk.NewLnk(lnk.WithHost(item.Host), lnk.WithRestoreOnly([]string{item.Location})).RestoreSymlinks(); err != nil {
+				return err
+			}
+			w.WriteString("   ").
+				Writeln(Success("Restored " + path))
+		case "untrack":
+			if _, err := lnk.NewLnk(lnk.WithHost(item.Host)).Remove(item.Location); err != nil {
+				return err
+			}
+			w.WriteString("   ").
+				Write(Message{Text: "Untracked " + path, Emoji: "🗑️"}).
+				Writeln(Colored(" (its last version stays in git history)", ColorGray))
+		}
+	}
+	w.WritelnString("")
+	return w.Err()
+}
+
+// askReconcile asks on w what to do with the deleted item at path and reads
+// the answer from reader: "restore", "untrack" or "skip". An empty answer or
+// end of input skips, so nothing changes without being asked for.
+func askReconcile(w *Writer, reader *bufio.Reader, path string) (string, error) {
+	for {
+		w.WriteString("   ").
+			Write(Plain(path)).
+			WriteString(": [r]estore, [u]ntrack, [s]kip? (default: skip) ")
+		if err := w.Err(); err != nil {
+			return "", err
+		}
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		if err == io.EOF {
+			w.WritelnString("")
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "r", "restore":
+			return "restore", nil
+		case "u", "untrack":
+			return "untrack", nil
+		case "s", "skip", "":
+			return "skip", nil
+		default:
+			w.WriteString("   ").
+				Writeln(Warning(fmt.Sprintf("Unknown choice %q", strings.TrimSpace(answer))))
+		}
+
+		if err == io.EOF {
+			return "skip", nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newRemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "📡 List or add remote repositories",
+		Long: `Lists the remotes of the lnk repository with their URLs. The default one,
+marked below, is what push, pull and status use: origin, or the first remote
+when there is no origin. Others are reached with push --remote <name>,
+pull --remote <name> and push --all-remotes.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := GetWriter(cmd)
+
+			remotes, err := lnk.NewLnk().Remotes()
+			if err != nil {
+				return err
+			}
+
+			if len(remotes) == 0 {
+				w.Writeln(Message{Text: "No remotes configured", Emoji: "📡", Bold: true}).
+					WriteString("   ").
+					Write(Info("Add one with ")).
+					Writeln(Bold("lnk remote add <name> <url>"))
+				return w.Err()
+			}
+
+			width := 0
+			for _, remote := range remotes {
+				width = max(width, len(remote.Name))
+			}
+			for _, remote := range remotes {
+				w.Write(Message{Text: fmt.Sprintf("%-*s", width, remote.Name), Emoji: "📡", Bold: true}).
+					WriteString("  ").
+					Write(Colored(lnk.RedactURL(remote.URL), ColorCyan))
+				if remote.Default {
+					w.Write(Colored(" (default)", ColorGray))
+				}
+				w.WritelnString("")
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.AddCommand(newRemoteAddCmd())
+	return cmd
+}
+
+func newRemoteAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <name> <url>",
+		Short: "➕ Add a remote repository",
+		Long: `Adds a remote to the lnk repository, e.g. a self-hosted backup next to GitHub.
+The first remote, or one called origin, stays the default; push to the new one
+with push --remote <name> or push --all-remotes. Adding a name that already
+exists with the same URL does nothing; with another URL it fails.`,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, url := args[0], args[1]
+			w := GetWriter(cmd)
+
+			if err := lnk.NewLnk().AddRemote(name, url); err != nil {
+				return err
+			}
+
+			w.Writeln(Success(fmt.Sprintf("Added remote %s", name))).
+				WriteString("   ").
+				Write(Message{Text: "URL: ", Emoji: "🔗"}).
+				Writeln(Colored(lnk.RedactURL(url), ColorCyan)).
+				WriteString("   ").
+				Write(Info("Push to it with ")).
+				Writeln(Bold("lnk push --remote " + name))
+			return w.Err()
+		},
+	}
+}
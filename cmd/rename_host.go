@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newRenameHostCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename-host <old> <new>",
+		Short: "🏷️ Rename a host configuration",
+		Long: `Renames a host configuration after the machine was renamed: the .lnk.<old>
+index becomes .lnk.<new> and the <old>.lnk directory becomes <new>.lnk. The
+symlinks in $HOME that pointed into the old directory are pointed at the new
+one, git-crypt lines in .gitattributes and a host = "<old>" in the repository's
+config.toml are rewritten, and everything is committed together.
+
+Either name may be 'auto' for this machine's hostname, e.g.
+'lnk rename-host old-laptop auto'. The new name must not have a configuration
+yet, and the old one's files must not have uncommitted changes.`,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldHost, err := lnk.ResolveHost(args[0])
+			if err != nil {
+				return err
+			}
+			newHost, err := lnk.ResolveHost(args[1])
+			if err != nil {
+				return err
+			}
+			w := GetWriter(cmd)
+
+			result, err := lnk.NewLnk().RenameHost(oldHost, newHost)
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Success(fmt.Sprintf("Renamed host %s to %s", oldHost, newHost))).
+				WriteString("   ").
+				Writeln(Info(fmt.Sprintf("%d managed item%s moved to %s.lnk/", len(result.Items), pluralS(len(result.Items)), newHost)))
+			if len(result.Relinked) > 0 {
+				w.WriteString("   ").
+					Writeln(Link(fmt.Sprintf("Relinked %d symlink%s:", len(result.Relinked), pluralS(len(result.Relinked)))))
+				for _, item := range result.Relinked {
+					w.WriteString("      ").
+						Writeln(Sparkles(item))
+				}
+			}
+			if result.Config {
+				w.WriteString("   ").
+					Writeln(Info(fmt.Sprintf("config.toml now sets host = %q", newHost)))
+			} else if fileConfig.Host == oldHost {
+				w.WriteString("   ").
+					Write(Warning("Your config.toml still sets host to " + oldHost + "; run ")).
+					Writeln(Bold("lnk config set --user host " + newHost))
+			}
+			w.WriteString("   ").
+				Write(Info("Run ")).
+				Write(Bold("lnk push")).
+				WritelnString(" to share the rename")
+			return w.Err()
+		},
+	}
+}
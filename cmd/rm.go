@@ -15,61 +15,147 @@ func newRemoveCmd() *cobra.Command {
 		Short: "🗑️ Remove a file from lnk management",
 		Long: `Removes a symlink and restores the original file from the lnk repository.
 
+If the symlink in your home directory was already deleted, rm still drops the
+entry from the .lnk index and the stored file from git, so a vanished file
+does not stay managed. The stored file is deleted from the repository (git
+history keeps it) unless you pass --restore, which moves it back to where the
+symlink was.
+
 Use --force for tracking cleanup only: it removes the entry from the .lnk index
 and the stored file from the repo without restoring anything in your home
 directory. This is intended for cases where the symlink is already missing
 (e.g., you deleted it manually) so the regular rm flow cannot run. --force
-does NOT recreate or move any file back into place.`,
+does NOT recreate or move any file back into place.
+
+Use --keep to stop tracking a file without touching it: the entry leaves the
+.lnk index and the stored file leaves git, but the symlink in your home
+directory and the file in the repository both stay, and the file is listed in
+.git/info/exclude so a later push does not commit it again. The symlink keeps
+working until you delete the repository file. Other machines drop the stored
+file on their next pull, which leaves their symlink dangling.
+
+Use --no-commit to stage the removal without committing it, and record it
+together with other staged changes later with 'lnk commit -m <message>'.`,
 		Args:          cobra.ExactArgs(1),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
-			host, _ := cmd.Flags().GetString("host")
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
 			force, _ := cmd.Flags().GetBool("force")
-			l := lnk.NewLnk(lnk.WithHost(host))
+			keep, _ := cmd.Flags().GetBool("keep")
+			restore, _ := cmd.Flags().GetBool("restore")
+			noCommit, _ := cmd.Flags().GetBool("no-commit")
+			l := lnk.NewLnk(lnk.WithHost(host), lnk.WithRestore(restore), lnk.WithNoCommit(noCommit))
 			w := GetWriter(cmd)
 
-			if force {
-				if err := l.RemoveForce(filePath); err != nil {
-					return err
-				}
-
-				basename := filepath.Base(filePath)
-				if host != "" {
-					w.Writeln(Message{Text: fmt.Sprintf("Force removed %s from lnk (host: %s)", basename, host), Emoji: "🗑️", Bold: true})
-				} else {
-					w.Writeln(Message{Text: fmt.Sprintf("Force removed %s from lnk", basename), Emoji: "🗑️", Bold: true})
-				}
-				w.WriteString("   ").
-					Writeln(Message{Text: "Tracking cleanup only — no file was restored to your home directory", Emoji: "📋"})
-
-				return w.Err()
-			}
-
-			if err := l.Remove(filePath); err != nil {
+			if err := removeFile(w, l, filePath, host, force, keep); err != nil {
 				return err
 			}
-
-			basename := filepath.Base(filePath)
-			if host != "" {
-				w.Writeln(Message{Text: fmt.Sprintf("Removed %s from lnk (host: %s)", basename, host), Emoji: "🗑️", Bold: true})
-			} else {
-				w.Writeln(Message{Text: fmt.Sprintf("Removed %s from lnk", basename), Emoji: "🗑️", Bold: true})
+			if noCommit {
+				writeStaged(w)
 			}
-			w.WriteString("   ").
-				Write(Message{Text: lnk.FormatManagedPath(host, filePath), Emoji: "↩️"}).
-				WriteString(" → ").
-				Writeln(Colored(filePath, ColorCyan))
-
-			w.WriteString("   ").
-				Writeln(Message{Text: "Original file restored", Emoji: "📄"})
 
 			return w.Err()
 		},
 	}
 
-	cmd.Flags().StringP("host", "H", "", "Remove file from specific host configuration (default: common configuration)")
+	cmd.Flags().StringP("host", "H", "", "Remove file from specific host configuration, or 'auto' for this machine's hostname (default: common configuration)")
 	cmd.Flags().BoolP("force", "f", false, "Tracking cleanup only: drop the entry and stored file without restoring anything in your home directory")
+	cmd.Flags().Bool("keep", false, "Stop tracking the file but leave the symlink and the repository file in place")
+	cmd.Flags().Bool("restore", false, "If the symlink was already deleted, move the stored file back instead of deleting it")
+	cmd.Flags().Bool("no-commit", false, "Stage the removal without committing it, to record several changes with one lnk commit")
+	cmd.MarkFlagsMutuallyExclusive("force", "keep")
+	cmd.MarkFlagsMutuallyExclusive("restore", "force")
+	cmd.MarkFlagsMutuallyExclusive("restore", "keep")
 	return cmd
 }
+
+// removeFile removes filePath from management the way the rm flags ask and
+// reports what happened to it.
+func removeFile(w *Writer, l *lnk.Lnk, filePath, host string, force, keep bool) error {
+	if force {
+		removed, err := l.RemoveForce(filePath)
+		if err != nil {
+			return err
+		}
+
+		basename := filepath.Base(removed.RelativePath)
+		if host != "" {
+			w.Writeln(Message{Text: fmt.Sprintf("Force removed %s from lnk (host: %s)", basename, host), Emoji: "🗑️", Bold: true})
+		} else {
+			w.Writeln(Message{Text: fmt.Sprintf("Force removed %s from lnk", basename), Emoji: "🗑️", Bold: true})
+		}
+		w.WriteString("   ").
+			Writeln(Message{Text: "Tracking cleanup only — no file was restored to your home directory", Emoji: "📋"})
+
+		return nil
+	}
+
+	if keep {
+		removed, err := l.RemoveKeep(filePath)
+		if err != nil {
+			return err
+		}
+
+		basename := filepath.Base(removed.RelativePath)
+		if host != "" {
+			w.Writeln(Message{Text: fmt.Sprintf("Untracked %s from lnk (host: %s)", basename, host), Emoji: "🗑️", Bold: true})
+		} else {
+			w.Writeln(Message{Text: fmt.Sprintf("Untracked %s from lnk", basename), Emoji: "🗑️", Bold: true})
+		}
+		w.WriteString("   ").
+			Writeln(Message{Text: "Symlink and repository file left in place; " + lnk.DisplayPath(removed.RepoPath) + " is excluded locally", Emoji: "📋"})
+
+		return nil
+	}
+
+	removed, err := l.Remove(filePath)
+	if err != nil {
+		return err
+	}
+
+	basename := filepath.Base(removed.RelativePath)
+	if host != "" {
+		w.Writeln(Message{Text: fmt.Sprintf("Removed %s from lnk (host: %s)", basename, host), Emoji: "🗑️", Bold: true})
+	} else {
+		w.Writeln(Message{Text: fmt.Sprintf("Removed %s from lnk", basename), Emoji: "🗑️", Bold: true})
+	}
+	if removed.Copy {
+		w.WriteString("   ").
+			Writeln(Message{Text: "Repository copy deleted; " + filePath + " was left as it is", Emoji: "📄"})
+		return nil
+	}
+	if removed.Missing {
+		if removed.Restored {
+			w.WriteString("   ").
+				Write(Message{Text: lnk.DisplayPath(removed.RepoPath), Emoji: "↩️"}).
+				WriteString(" → ").
+				Writeln(Colored(filePath, ColorCyan))
+			w.WriteString("   ").
+				Writeln(Message{Text: "The symlink was already gone; original file restored", Emoji: "📄"})
+			return nil
+		}
+
+		w.WriteString("   ").
+			Writeln(Message{Text: "The symlink was already gone; repository copy deleted (still in git history)", Emoji: "📋"})
+		w.WriteString("   ").
+			Write(Info("Run ")).
+			Write(Bold("lnk undo")).
+			Writeln(Info(" to track and link it again"))
+		return nil
+	}
+
+	w.WriteString("   ").
+		Write(Message{Text: lnk.DisplayPath(removed.RepoPath), Emoji: "↩️"}).
+		WriteString(" → ").
+		Writeln(Colored(filePath, ColorCyan))
+
+	w.WriteString("   ").
+		Writeln(Message{Text: "Original file restored", Emoji: "📄"})
+
+	return nil
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
 	error2 "github.com/yarlson/lnk/internal/lnkerror"
 )
 
@@ -15,6 +18,10 @@ var (
 	buildTime = "unknown"
 )
 
+// fileConfig holds the config.toml settings of the running command; flags
+// that were not given fall back to it.
+var fileConfig = &lnk.Config{}
+
 // NewRootCommand creates a new root command (testable)
 func NewRootCommand() *cobra.Command {
 	var (
@@ -30,7 +37,8 @@ func NewRootCommand() *cobra.Command {
 		Long: `🔗 Lnk - Git-native dotfiles management that doesn't suck.
 
 Move your dotfiles into a Git-managed repo (default: ~/.config/lnk; override with
-LNK_HOME or XDG_CONFIG_HOME), symlink them back, and use Git like normal.
+LNK_HOME or XDG_CONFIG_HOME, or rename the directory with LNK_REPO_NAME=dotfiles),
+symlink them back, and use Git like normal.
 Supports both common configurations, host-specific setups, and bulk operations for multiple files.
 
 ✨ Examples:
@@ -40,10 +48,19 @@ Supports both common configurations, host-specific setups, and bulk operations f
   lnk add --recursive ~/.config/nvim # Add directory contents individually
   lnk add --dry-run ~/.gitconfig     # Preview changes without applying
   lnk add --host work ~/.ssh/config  # Manage host-specific files
+  lnk import-stow ~/dotfiles         # Migrate GNU Stow packages
+  lnk export-chezmoi ~/chezmoi-src   # Write a chezmoi source directory
   lnk list --all                     # Show all configurations
-  lnk pull --host work               # Pull host-specific changes
+  lnk prune --normalize              # Clean up a hand-edited tracking file
+  lnk undo                           # Revert the last add, rm or other lnk commit
+  lnk edit ~/.bashrc                 # Open a managed file in $EDITOR
+  lnk which ~/.bashrc                # Print where a managed file is stored
+  lnk pull --host work               # Pull and restore common + host-specific files
   lnk push "setup complete"          # Sync to remote
+  lnk sync                           # Pull, restore symlinks, then push
+  lnk watch --push                   # Commit and push edits as they happen
   lnk bootstrap                      # Run bootstrap script manually
+  lnk config set host auto           # Always use this machine's host configuration
 
 🚀 Bootstrap Support:
   Automatically runs bootstrap.sh when cloning a repository.
@@ -54,6 +71,18 @@ Supports both common configurations, host-specific setups, and bulk operations f
 		SilenceErrors: true,
 		Version:       fmt.Sprintf("%s (built %s)", version, buildTime),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// LNK_QUIET enables quiet mode unless --quiet is given explicitly
+			if env := os.Getenv("LNK_QUIET"); env != "" && !cmd.Flags().Changed("quiet") {
+				envQuiet, err := strconv.ParseBool(env)
+				if err != nil {
+					return fmt.Errorf("invalid LNK_QUIET value: %s (valid: 1, true, 0, false)", env)
+				}
+				quiet = envQuiet
+			}
+			if _, err := lnk.RepoName(); err != nil {
+				return err
+			}
+
 			// Handle emoji flag logic
 			emojiEnabled := emoji
 			if noEmoji {
@@ -64,30 +93,64 @@ Supports both common configurations, host-specific setups, and bulk operations f
 				return err
 			}
 
+			// A broken config.toml fails every command except the config
+			// subcommands, which are how it gets fixed. Unknown keys only
+			// warn; config get reports them as errors.
+			isConfig := cmd.Parent() != nil && cmd.Parent().Name() == "config"
+			cfg, err := lnk.LoadConfig()
+			if err != nil {
+				if !isConfig {
+					return err
+				}
+				cfg = &lnk.Config{}
+			}
+			if !isConfig {
+				writeUnknownKeyWarnings(NewWriter(cmd.ErrOrStderr(), globalConfig), cfg.Unknown)
+			}
+			fileConfig = cfg
+
 			return nil
 		},
 	}
 
 	// Add global flags for output formatting
 	rootCmd.PersistentFlags().StringVar(&colors, "colors", "auto", "when to use colors (auto, always, never)")
+	rootCmd.PersistentFlags().StringVar(&colors, "color", "auto", "alias for --colors")
 	rootCmd.PersistentFlags().BoolVar(&emoji, "emoji", true, "enable emoji in output")
 	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "disable emoji in output")
-	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress all output (exit code only)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress all output except errors (also LNK_QUIET=1)")
 
-	// Mark emoji flags as mutually exclusive
+	// Mark emoji flags as mutually exclusive, and the two spellings of --colors
 	rootCmd.MarkFlagsMutuallyExclusive("emoji", "no-emoji")
+	rootCmd.MarkFlagsMutuallyExclusive("colors", "color")
 
 	// Add subcommands
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newAddCmd())
+	rootCmd.AddCommand(newImportStowCmd())
+	rootCmd.AddCommand(newExportChezmoiCmd())
 	rootCmd.AddCommand(newRemoveCmd())
+	rootCmd.AddCommand(newCommitCmd())
+	rootCmd.AddCommand(newUndoCmd())
+	rootCmd.AddCommand(newEditCmd())
+	rootCmd.AddCommand(newWhichCmd())
+	rootCmd.AddCommand(newRenameHostCmd())
 	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newDuCmd())
+	rootCmd.AddCommand(newLogCmd())
 	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newIsDirtyCmd())
 	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newPruneCmd())
 	rootCmd.AddCommand(newStatusCmd())
 	rootCmd.AddCommand(newPushCmd())
 	rootCmd.AddCommand(newPullCmd())
+	rootCmd.AddCommand(newRemoteCmd())
+	rootCmd.AddCommand(newVerifyRemoteCmd())
+	rootCmd.AddCommand(newSyncCmd())
+	rootCmd.AddCommand(newWatchCmd())
 	rootCmd.AddCommand(newBootstrapCmd())
+	rootCmd.AddCommand(newConfigCmd())
 
 	return rootCmd
 }
@@ -102,13 +165,57 @@ func Execute() {
 	rootCmd := NewRootCommand()
 	if err := rootCmd.Execute(); err != nil {
 		DisplayError(err)
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError is returned by a command whose result is an exit code other
+// than 1, such as lnk status --exit-code. Like errDiffHasChanges it is not
+// shown; the command has already printed what it found.
+type exitCodeError struct {
+	code   int
+	reason string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.reason
+}
+
+// hostFlag reads the --host flag of cmd, falling back to the host setting of
+// config.toml when the flag is not given, expanding "auto" to the current
+// machine's sanitized hostname and rejecting names that could escape the repo.
+func hostFlag(cmd *cobra.Command) (string, error) {
+	host, _ := cmd.Flags().GetString("host")
+	if !cmd.Flags().Changed("host") && fileConfig.Host != "" {
+		host = fileConfig.Host
+	}
+	host, err := lnk.ResolveHost(host)
+	if err != nil {
+		return "", err
+	}
+	if err := lnk.ValidateHost(host); err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
 // DisplayError formats and displays an error with appropriate styling
 func DisplayError(err error) {
-	w := GetErrorWriter()
+	writeError(GetErrorWriter(), err)
+}
+
+// writeError renders err on w. Errors that only carry an exit code, such as
+// the ones from `lnk diff --quiet`, `lnk is-dirty` and `lnk status
+// --exit-code`, are not shown.
+func writeError(w *Writer, err error) {
+	var exitErr *exitCodeError
+	if errors.Is(err, errDiffHasChanges) || errors.Is(err, errRepoClean) || errors.As(err, &exitErr) {
+		return
+	}
 
 	var lnkErr *error2.Error
 	if errors.As(err, &lnkErr) {
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -9,49 +11,305 @@ import (
 )
 
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:           "status",
-		Short:         "📊 Show repository sync status",
-		Long:          "Display how many commits ahead/behind the local repository is relative to the remote and check for uncommitted changes.",
+	var short bool
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "📊 Show repository sync status",
+		Long: `Display how many commits ahead/behind the local repository is relative to the remote and check for uncommitted changes.
+
+Ahead/behind counts compare against the remote as of the last fetch. Use --fetch
+to fetch first so the counts reflect the remote's current state.
+
+When the repository is both ahead and behind, its history has diverged from
+the remote's: a plain push would be rejected, so status says so and points
+to pulling (or rebasing) first; --json marks it as "diverged".
+
+--short (or --porcelain) prints one plain line for shell prompts and scripts:
+
+  dirty ahead=1 behind=0 branch=main
+
+The first word is "clean" or "dirty", followed by space-separated key=value
+fields. The format is stable: fields keep their order and meaning, and new ones
+are only ever appended. branch is "HEAD" when detached; without a remote,
+ahead counts every local commit and behind is 0.
+
+With uncommitted changes, the managed items they belong to are listed. A
+directory managed as one entry (such as ~/.ssh) is listed with the files that
+changed inside it, including new files that reached the repository through
+its symlink without being added one by one.
+
+A detached HEAD or a shallow clone, both of which make push and pull fail in
+confusing ways, is reported after the status with the git command that
+fixes it; --json marks them as "detached" and "shallow".
+
+--host narrows the uncommitted changes to one host's tracking file and
+storage directory, to see what a push would sync for that machine; --short
+then appends host=<name>. Ahead/behind counts stay repository-wide, since
+commits are shared by every host. The host setting in config.toml does not
+scope status; only an explicit --host does.
+
+--reconcile looks for managed files deleted from your home directory (in the
+common configuration and this machine's host: --host, or host in
+config.toml) before reporting, and asks for each whether to restore its
+symlink, untrack it (committing the removal), or leave it.
+
+--json prints the same state as JSON, with the changed managed items, for
+monitoring; --output <file> writes it to a file instead, replaced atomically.
+
+--exit-code reports the state through the exit code as well, for CI jobs and
+pre-commit hooks that check the dotfiles are synced:
+
+  0  clean and in sync with the remote
+  1  error (no repository, git failure, ...)
+  2  uncommitted changes
+  3  behind the remote
+  4  diverged from the remote (ahead and behind)
+  5  ahead of the remote (commits not pushed)
+
+When several apply, the first of 2, 4, 3, 5 wins. Without a remote only
+uncommitted changes count. Counts come from the last fetch, so combine it
+with --fetch in automation. The output is printed as usual; add --short or
+--quiet to keep it brief.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			fetch, _ := cmd.Flags().GetBool("fetch")
+			asJSON, err := jsonFlag(cmd)
+			if err != nil {
+				return err
+			}
+			host := ""
+			if cmd.Flags().Changed("host") {
+				if host, err = hostFlag(cmd); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("reconcile") {
+				reconcileHost, err := hostFlag(cmd)
+				if err != nil {
+					return err
+				}
+				if err := reconcile(cmd, reconcileHost); err != nil {
+					return err
+				}
+			}
 			l := lnk.NewLnk()
-			status, err := l.Status()
+			status, err := l.StatusWithOptions(lnk.StatusOptions{Fetch: fetch, Host: host})
 			if err != nil {
 				return err
 			}
 
-			if status.Remote == "" {
-				displayNoRemoteStatus(cmd, status)
-				return nil
+			exitCode, _ := cmd.Flags().GetBool("exit-code")
+			if asJSON {
+				if err := writeJSON(cmd, statusJSON(status, host)); err != nil || !exitCode {
+					return err
+				}
+				return statusExitCode(status)
 			}
 
-			if status.Dirty {
-				displayDirtyStatus(cmd, status)
-				return nil
+			if short {
+				w := GetWriter(cmd)
+				line := shortStatus(status)
+				if host != "" {
+					line += " host=" + host
+				}
+				w.WritelnString(line)
+				if err := w.Err(); err != nil || !exitCode {
+					return err
+				}
+				return statusExitCode(status)
 			}
 
-			if status.Ahead == 0 && status.Behind == 0 {
+			switch {
+			case status.Remote == "":
+				displayNoRemoteStatus(cmd, status, host)
+			case status.Dirty:
+				displayDirtyStatus(cmd, status, host)
+			case status.Ahead == 0 && status.Behind == 0:
 				displayUpToDateStatus(cmd, status)
-				return nil
+			default:
+				displaySyncStatus(cmd, status)
 			}
-
-			displaySyncStatus(cmd, status)
-			return nil
+			w := GetWriter(cmd)
+			writeRepoState(w, status.Detached, status.Shallow)
+			if err := w.Err(); err != nil || !exitCode {
+				return err
+			}
+			return statusExitCode(status)
 		},
 	}
+
+	cmd.Flags().StringP("host", "H", "", "Only count uncommitted changes to this host's files, or 'auto' for this machine's hostname")
+	cmd.Flags().Bool("fetch", false, "Fetch from the remote first so ahead/behind counts are current")
+	cmd.Flags().BoolVarP(&short, "short", "s", false, "Print one stable line such as 'dirty ahead=1 behind=0 branch=main'")
+	cmd.Flags().BoolVar(&short, "porcelain", false, "alias for --short")
+	addJSONFlags(cmd, "Print the status as JSON")
+	addReconcileFlag(cmd)
+	cmd.Flags().Bool("exit-code", false, "Exit 2 when dirty, 3 behind, 4 diverged, 5 ahead, 0 when in sync")
+	cmd.MarkFlagsMutuallyExclusive("reconcile", "json")
+	cmd.MarkFlagsMutuallyExclusive("reconcile", "short")
+	cmd.MarkFlagsMutuallyExclusive("reconcile", "porcelain")
+	cmd.MarkFlagsMutuallyExclusive("json", "short")
+	cmd.MarkFlagsMutuallyExclusive("json", "porcelain")
+	return cmd
+}
+
+// Exit codes of lnk status --exit-code; 1 stays the code of every error.
+const (
+	statusExitDirty    = 2
+	statusExitBehind   = 3
+	statusExitDiverged = 4
+	statusExitAhead    = 5
+)
+
+// statusExitCode is the --exit-code result for status: nil when the
+// repository is clean and in sync, otherwise an exitCodeError for the first
+// of dirty, diverged, behind and ahead that applies. Without a remote only
+// uncommitted changes count, as there is nothing to be in sync with.
+func statusExitCode(status *lnk.StatusInfo) error {
+	var reasons []string
+	code := 0
+	note := func(c int, reason string) {
+		if code == 0 {
+			code = c
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if status.Dirty {
+		note(statusExitDirty, "uncommitted changes")
+	}
+	if status.Remote != "" {
+		switch {
+		case status.Diverged:
+			note(statusExitDiverged, "diverged from "+status.Remote)
+		case status.Behind > 0:
+			note(statusExitBehind, "behind "+status.Remote)
+		case status.Ahead > 0:
+			note(statusExitAhead, "ahead of "+status.Remote)
+		}
+	}
+	if code == 0 {
+		return nil
+	}
+	return &exitCodeError{code: code, reason: "repository is not in sync: " + strings.Join(reasons, ", ")}
+}
+
+// changedRow is one managed item with uncommitted changes in lnk status
+// --json.
+type changedRow struct {
+	Path      string   `json:"path"`
+	Host      string   `json:"host,omitempty"`
+	Directory bool     `json:"directory,omitempty"`
+	Files     []string `json:"files,omitempty"`
+}
+
+// statusReport is what lnk status --json prints. State is "clean" or
+// "dirty", as in --short; Remote and RemoteURL are empty without a remote.
+type statusReport struct {
+	State     string       `json:"state"`
+	Branch    string       `json:"branch"`
+	Remote    string       `json:"remote,omitempty"`
+	RemoteURL string       `json:"remote_url,omitempty"`
+	Ahead     int          `json:"ahead"`
+	Behind    int          `json:"behind"`
+	Diverged  bool         `json:"diverged,omitempty"`
+	Fetched   bool         `json:"fetched"`
+	Detached  bool         `json:"detached,omitempty"`
+	Shallow   bool         `json:"shallow,omitempty"`
+	Host      string       `json:"host,omitempty"`
+	Changed   []changedRow `json:"changed"`
 }
 
-func displayDirtyStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
+// statusJSON builds the --json report for status, listing the changed
+// managed items (only host's when it is set) when the tree is dirty.
+func statusJSON(status *lnk.StatusInfo, host string) statusReport {
+	report := statusReport{
+		State:     "clean",
+		Branch:    status.Branch,
+		Remote:    status.Remote,
+		RemoteURL: status.RemoteURL,
+		Ahead:     status.Ahead,
+		Behind:    status.Behind,
+		Diverged:  status.Diverged,
+		Fetched:   status.Fetched,
+		Detached:  status.Detached,
+		Shallow:   status.Shallow,
+		Host:      host,
+		Changed:   []changedRow{},
+	}
+	if report.Branch == "" {
+		report.Branch = "HEAD"
+	}
+	if !status.Dirty {
+		return report
+	}
+
+	report.State = "dirty"
+	items, err := lnk.NewLnk().ChangedItems()
+	if err != nil {
+		return report
+	}
+	for _, item := range items {
+		if host == "" || item.Host == host {
+			report.Changed = append(report.Changed, changedRow{Path: filepath.ToSlash(item.Path), Host: item.Host, Directory: item.Directory, Files: item.Files})
+		}
+	}
+	return report
+}
+
+// shortStatus renders status as the stable one-line --short format.
+func shortStatus(status *lnk.StatusInfo) string {
+	state := "clean"
+	if status.Dirty {
+		state = "dirty"
+	}
+	branch := status.Branch
+	if branch == "" {
+		branch = "HEAD"
+	}
+	return fmt.Sprintf("%s ahead=%d behind=%d branch=%s", state, status.Ahead, status.Behind, branch)
+}
+
+// displayRemoteURL shows where the remote points, so the user can confirm
+// they push to the right place. Credentials are already redacted.
+func displayRemoteURL(cmd *cobra.Command, status *lnk.StatusInfo) {
+	if status.RemoteURL == "" {
+		return
+	}
+
+	w := GetWriter(cmd)
+	w.WriteString("   ").
+		Write(Message{Text: "URL: ", Emoji: "🔗"}).
+		Writeln(Colored(status.RemoteURL, ColorGray))
+}
+
+// displayFetchNote reminds the user that ahead/behind counts may be stale
+// when the remote was not fetched for this status.
+func displayFetchNote(cmd *cobra.Command, status *lnk.StatusInfo) {
+	if status.Fetched {
+		return
+	}
+
+	w := GetWriter(cmd)
+	w.WriteString("   ").
+		Write(Colored("Counts are based on the last fetch; run ", ColorGray)).
+		Write(Bold("lnk status --fetch")).
+		Writeln(Colored(" to check the remote", ColorGray))
+}
+
+func displayDirtyStatus(cmd *cobra.Command, status *lnk.StatusInfo, host string) {
 	w := GetWriter(cmd)
 
 	repoDisplay := lnk.DisplayPath(lnk.GetRepoPath())
 
-	w.Writeln(Warning("Repository has uncommitted changes")).
+	w.Writeln(Warning(dirtyHeading(host))).
 		WriteString("   ").
 		Write(Message{Text: "Remote: ", Emoji: "📡"}).
 		Writeln(Colored(status.Remote, ColorCyan))
+	displayRemoteURL(cmd, status)
+	displayFetchNote(cmd, status)
+	displayChangedItems(cmd, host)
 
 	if status.Ahead == 0 && status.Behind == 0 {
 		w.WritelnString("").
@@ -67,6 +325,9 @@ func displayDirtyStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 
 	w.WritelnString("")
 	displayAheadBehindInfo(cmd, status, true)
+	if status.Diverged {
+		displayDivergedAdvice(cmd, status)
+	}
 	w.WritelnString("").
 		Write(Info("Run ")).
 		Write(Bold("git add && git commit")).
@@ -77,6 +338,66 @@ func displayDirtyStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 		WritelnString(" to commit changes")
 }
 
+// dirtyHeading is the warning shown for uncommitted changes, naming the host
+// when status is scoped to one.
+func dirtyHeading(host string) string {
+	if host == "" {
+		return "Repository has uncommitted changes"
+	}
+	return fmt.Sprintf("Host %s has uncommitted changes", host)
+}
+
+// displayChangedItems lists the managed items with uncommitted changes, only
+// those of host when it is set; a managed directory shows the changed files
+// inside it, the first 5 in detail. Changes outside every managed item print
+// nothing here.
+func displayChangedItems(cmd *cobra.Command, host string) {
+	all, err := lnk.NewLnk().ChangedItems()
+	if err != nil {
+		return
+	}
+	var items []lnk.ChangedItem
+	for _, item := range all {
+		if host == "" || item.Host == host {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	w := GetWriter(cmd)
+	w.WritelnString("").
+		WriteString("   ").
+		Writeln(Message{Text: fmt.Sprintf("Changed managed item%s:", pluralS(len(items))), Emoji: "📝"})
+	for _, item := range items {
+		path := "~/" + filepath.ToSlash(item.Path)
+		if item.Directory {
+			path += "/"
+		}
+		w.WriteString("      ").
+			Write(Plain(path))
+		if item.Host != "" {
+			w.Write(Colored(" (host: "+item.Host+")", ColorGray))
+		}
+		if !item.Directory {
+			w.WritelnString("")
+			continue
+		}
+
+		w.Writeln(Colored(fmt.Sprintf(" (directory, %d file%s changed inside)", len(item.Files), pluralS(len(item.Files))), ColorGray))
+		for i, file := range item.Files {
+			if i == 5 {
+				w.WriteString("         ").
+					Writeln(Plain(fmt.Sprintf("... and %d more files", len(item.Files)-5)))
+				break
+			}
+			w.WriteString("         ").
+				Writeln(Plain(file))
+		}
+	}
+}
+
 func displayUpToDateStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 	w := GetWriter(cmd)
 
@@ -84,6 +405,8 @@ func displayUpToDateStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 		WriteString("   ").
 		Write(Message{Text: "Synced with ", Emoji: "📡"}).
 		Writeln(Colored(status.Remote, ColorCyan))
+	displayRemoteURL(cmd, status)
+	displayFetchNote(cmd, status)
 }
 
 func displaySyncStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
@@ -92,12 +415,16 @@ func displaySyncStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 	w.Writeln(Message{Text: "Repository Status", Emoji: "📊", Bold: true}).
 		WriteString("   ").
 		Write(Message{Text: "Remote: ", Emoji: "📡"}).
-		Writeln(Colored(status.Remote, ColorCyan)).
-		WritelnString("")
+		Writeln(Colored(status.Remote, ColorCyan))
+	displayRemoteURL(cmd, status)
+	w.WritelnString("")
 
 	displayAheadBehindInfo(cmd, status, false)
+	displayFetchNote(cmd, status)
 
-	if status.Ahead > 0 && status.Behind == 0 {
+	if status.Diverged {
+		displayDivergedAdvice(cmd, status)
+	} else if status.Ahead > 0 && status.Behind == 0 {
 		w.WritelnString("").
 			Write(Info("Run ")).
 			Write(Bold("lnk push")).
@@ -110,6 +437,22 @@ func displaySyncStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
 	}
 }
 
+// displayDivergedAdvice warns that local and remote history have both moved
+// on, so a plain push would be rejected, and says how to reconcile them.
+func displayDivergedAdvice(cmd *cobra.Command, status *lnk.StatusInfo) {
+	w := GetWriter(cmd)
+	w.WritelnString("").
+		Writeln(Warning("Local and remote history have diverged from " + status.Remote)).
+		WriteString("   ").
+		Write(Colored("A plain push will be rejected. Run ", ColorGray)).
+		Write(Bold("lnk pull")).
+		Write(Colored(" (or ", ColorGray)).
+		Write(Bold("git -C " + lnk.DisplayPath(lnk.GetRepoPath()) + " pull --rebase")).
+		Write(Colored(") first, then ", ColorGray)).
+		Write(Bold("lnk push")).
+		WritelnString("")
+}
+
 func displayAheadBehindInfo(cmd *cobra.Command, status *lnk.StatusInfo, isDirty bool) {
 	w := GetWriter(cmd)
 
@@ -146,17 +489,22 @@ func getCommitText(count int) string {
 // displayNoRemoteStatus renders status for a repository that has no remote
 // configured. We still report local state (dirty / clean, local commit count)
 // and guide the user toward adding a remote.
-func displayNoRemoteStatus(cmd *cobra.Command, status *lnk.StatusInfo) {
+func displayNoRemoteStatus(cmd *cobra.Command, status *lnk.StatusInfo, host string) {
 	w := GetWriter(cmd)
 
 	repoDisplay := lnk.DisplayPath(lnk.GetRepoPath())
 
 	if status.Dirty {
-		w.Writeln(Warning("Repository has uncommitted changes")).
+		w.Writeln(Warning(dirtyHeading(host))).
 			WriteString("   ").
 			Writeln(Message{Text: "No remote configured", Emoji: "📡", Color: ColorGray})
+		displayChangedItems(cmd, host)
 	} else {
-		w.Writeln(Success("Working tree is clean")).
+		clean := "Working tree is clean"
+		if host != "" {
+			clean = fmt.Sprintf("Host %s has no uncommitted changes", host)
+		}
+		w.Writeln(Success(clean)).
 			WriteString("   ").
 			Writeln(Message{Text: "No remote configured", Emoji: "📡", Color: ColorGray})
 	}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "🔄 Pull latest changes, restore symlinks, then push",
+		Long: `Pulls changes from the remote, restores symlinks, and then stages, commits and
+pushes local changes — the "get latest, then push my changes" routine in one step.
+
+Nothing is pushed unless the pull completed cleanly. If the pull stops on merge
+conflicts, lnk lists the conflicting files and leaves the repository for you to
+resolve before running 'lnk sync' again. With --rebase (or pull.rebase = true
+in config.toml) local commits are rebased onto the remote's instead; a rebase
+that stops on conflicts is left for 'git rebase --continue' or '--abort'.
+
+With --host, the common configuration and the named host are restored.
+--interactive and --on-conflict work as for 'lnk pull', and --sign as for 'lnk push'.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			message, _ := cmd.Flags().GetString("message")
+			if !cmd.Flags().Changed("message") {
+				message = pushMessage()
+			}
+			sign, _ := cmd.Flags().GetBool("sign")
+			resolver, err := conflictResolverFlag(cmd)
+			if err != nil {
+				return err
+			}
+			w := GetWriter(cmd)
+
+			scopes := []string{""}
+			if host != "" {
+				scopes = append(scopes, host)
+			}
+
+			l := lnk.NewLnk(lnk.WithHost(host), lnk.WithConflictResolver(resolver), lnk.WithSign(sign), lnk.WithRebase(rebaseFlag(cmd)), retryNotice(w))
+			if message, err = l.RenderMessage(message); err != nil {
+				return err
+			}
+			results, err := l.Sync(message, scopes)
+			if err != nil {
+				return err
+			}
+
+			successMsg := "Successfully synced changes"
+			if host != "" {
+				successMsg = fmt.Sprintf("Successfully synced changes (host: %s)", host)
+			}
+			w.Writeln(Message{Text: successMsg, Emoji: "🔄", Color: ColorBrightGreen, Bold: true})
+
+			writeHostRestoreResults(w, results)
+			infos := restoreInfos(results)
+			writeRestoreSummary(w, infos...)
+
+			w.WriteString("   ").
+				Write(Message{Text: "Commit: ", Emoji: "💾"}).
+				Writeln(Colored(message, ColorGray)).
+				WriteString("   ").
+				Writeln(Message{Text: "Synced to remote", Emoji: "📡"})
+			if err := restoreIncomplete(w, infos...); err != nil {
+				return err
+			}
+
+			w.WriteString("   ").
+				Writeln(Sparkles("Your dotfiles are up to date!"))
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Also restore symlinks for specific host, or 'auto' for this machine's hostname (common configuration is always restored)")
+	cmd.Flags().StringP("message", "m", defaultPushMessage, "Commit message for local changes, with {date}, {host} and {count} filled in (config: push.default_message)")
+	cmd.Flags().BoolP("sign", "S", false, "Sign the commit (git commit -S) using your configured signing key")
+	addConflictFlags(cmd)
+	addRebaseFlag(cmd)
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "↩️ Undo the last lnk commit",
+		Long: `Reverses the last commit in the repository, in git and in your home directory.
+Only commits made by lnk (subject starting with "lnk:") can be undone, and the
+repository must have no uncommitted changes.
+
+  lnk add       the files are moved back out of the repository, replacing
+                their symlinks, as 'lnk rm' would
+  lnk rm        the files are managed and symlinked again; if the restored
+                file is still in your home directory, that version is kept
+                and any edits since show as uncommitted changes
+  copy-managed  only the repository copy changes; your files stay as they are
+  other commits (push, sync, config set, ...) are reverted in the repository
+
+The commit is dropped from history. If it has already been pushed, undo
+refuses unless --force is given, and then records a new commit that reverts
+it instead, so the remote's history is not rewritten.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			w := GetWriter(cmd)
+
+			result, err := lnk.NewLnk().Undo(force)
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Message{Text: "Undid " + result.Subject, Emoji: "↩️", Bold: true})
+			for _, path := range result.Restored {
+				w.WriteString("   ").
+					Write(Message{Text: path, Emoji: "📄"}).
+					Writeln(Colored(" is a regular file again", ColorGray))
+			}
+			for _, path := range result.Relinked {
+				w.WriteString("   ").
+					Write(Link(path)).
+					Writeln(Colored(" is managed again", ColorGray))
+			}
+
+			if result.Reverted {
+				w.WriteString("   ").
+					Writeln(Message{Text: "Recorded a revert commit because it was already pushed", Emoji: "💾"}).
+					WritelnString("").
+					Write(Info("Run ")).
+					Write(Bold("lnk push")).
+					WritelnString(" to share the revert")
+			}
+
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().BoolP("force", "f", false, "Undo a commit that was already pushed by recording a revert commit")
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newVerifyRemoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-remote",
+		Short: "🔌 Check that the remote can be reached and read",
+		Long: `Runs 'git ls-remote' against the remote push and pull use and reports whether
+it answered, without fetching or changing anything. Use it to check a new
+machine's SSH key or token, or a URL typo, before the first push or pull.
+
+When the check fails, git's own output is shown below the error, so
+authentication failures and unreachable hosts can be told apart. Credential
+prompts are answered as for push and pull, and the check gets the same long
+timeout. Use --remote to check a remote other than the default one.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, _ := cmd.Flags().GetString("remote")
+			w := GetWriter(cmd)
+
+			check, err := lnk.NewLnk(lnk.WithRemote(remote)).VerifyRemote()
+			if err != nil {
+				if check != nil {
+					writeGitOutput(w, check.Output)
+					if werr := w.Err(); werr != nil {
+						return werr
+					}
+				}
+				return err
+			}
+
+			w.Write(Success(fmt.Sprintf("Remote %s is reachable", check.Remote.Name))).
+				WriteString("  ").
+				Writeln(Colored(lnk.RedactURL(check.Remote.URL), ColorCyan)).
+				WriteString("   ")
+			if check.Refs == 0 {
+				w.Writeln(Info("The repository is empty; lnk push will create its first branch"))
+			} else {
+				w.Writeln(Info(fmt.Sprintf("%d ref%s advertised", check.Refs, pluralS(check.Refs))))
+			}
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().String("remote", "", "Check this remote instead of the default one")
+	return cmd
+}
+
+// writeGitOutput shows what git printed for a failed check, indented and
+// dimmed so it reads as detail under the error that follows.
+func writeGitOutput(w *Writer, output string) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.WriteString("   ").Writeln(Colored(line, ColorGray))
+		}
+	}
+}
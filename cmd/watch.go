@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+// defaultWatchMessage is the commit message used by lnk watch.
+const defaultWatchMessage = "lnk: auto-commit configuration files"
+
+func newWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "👀 Commit changes to managed files as they happen",
+		Long: `Keeps running and commits changes to managed files automatically. Edits are
+picked up from the repository (managed files are symlinks into it) and from the
+originals of copy-managed files; once nothing has changed for the debounce
+period, everything is committed in one commit. With --push, each commit is
+pushed as well. Press Ctrl+C to stop.
+
+Copy-managed files of the common configuration are always watched; --host adds
+that host's. Files are checked by polling, about once a second, less often
+when the repository is large enough for a check to take a while.
+
+Each commit holds the repository lock, so watch never races a manual lnk
+command. A failed commit or push is reported and retried after the next quiet
+period; a commit whose push failed is pushed then even if nothing else changed.
+
+config.toml can set the defaults: watch.debounce and watch.push.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := hostFlag(cmd)
+			if err != nil {
+				return err
+			}
+			message, _ := cmd.Flags().GetString("message")
+			debounce, _ := cmd.Flags().GetDuration("debounce")
+			if !cmd.Flags().Changed("debounce") && fileConfig.WatchDebounce > 0 {
+				debounce = fileConfig.WatchDebounce
+			}
+			if debounce <= 0 {
+				return fmt.Errorf("invalid --debounce value: %s (use a positive duration such as 5s)", debounce)
+			}
+			push, _ := cmd.Flags().GetBool("push")
+			if !cmd.Flags().Changed("push") {
+				push = fileConfig.WatchPush
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			w := GetWriter(cmd)
+			after := "committed"
+			if push {
+				after = "committed and pushed"
+			}
+			w.Writeln(Message{Text: "Watching " + lnk.DisplayPath(lnk.GetRepoPath()) + " for changes", Emoji: "👀", Bold: true}).
+				WriteString("   ").
+				Writeln(Colored(fmt.Sprintf("Changes are %s after %s without further edits; press Ctrl+C to stop", after, debounce), ColorGray))
+
+			err = lnk.NewLnk(lnk.WithHost(host), lnk.WithContext(ctx)).Watch(ctx, lnk.WatchOptions{
+				Message:  message,
+				Debounce: debounce,
+				Push:     push,
+				OnEvent: func(event lnk.WatchEvent) {
+					if event.Err != nil {
+						DisplayError(event.Err)
+						return
+					}
+					text := "Committed changes"
+					switch {
+					case event.Committed && event.Pushed:
+						text = "Committed and pushed changes"
+					case event.Pushed:
+						text = "Pushed the earlier commit"
+					}
+					w.Writeln(Success(fmt.Sprintf("%s at %s", text, time.Now().Format("15:04:05"))))
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			w.Writeln(Info("Stopped watching"))
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("message", "m", defaultWatchMessage, "Commit message for automatic commits")
+	cmd.Flags().Duration("debounce", lnk.DefaultWatchDebounce, "Wait this long after the last change before committing (config: watch.debounce)")
+	cmd.Flags().Bool("push", false, "Push after each commit (config: watch.push)")
+	cmd.Flags().StringP("host", "H", "", "Also watch and refresh this host's copy-managed files, besides the common ones, or 'auto' for this machine's hostname")
+	return cmd
+}
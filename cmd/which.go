@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/lnk/internal/lnk"
+)
+
+func newWhichCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which <file>",
+		Short: "🔎 Print where a managed file is stored in the repository",
+		Long: `Prints the absolute path of the repository file backing a managed file — the
+target of its symlink — so you can inspect the raw file or cd next to it:
+
+  cd "$(dirname "$(lnk which ~/.bashrc)")"
+
+Without --host, the common configuration is checked first, then every host
+configuration. When a file is managed in several, the one its symlink points to
+wins. Only the path is printed, for use in scripts.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filePath := args[0]
+			w := GetWriter(cmd)
+
+			if cmd.Flags().Changed("host") {
+				host, err := hostFlag(cmd)
+				if err != nil {
+					return err
+				}
+				storagePath, err := lnk.NewLnk(lnk.WithHost(host)).ManagedPath(filePath)
+				if err != nil {
+					return err
+				}
+				w.WritelnString(storagePath)
+				return w.Err()
+			}
+
+			storagePath, err := whichStoragePath(filePath)
+			if err != nil {
+				return err
+			}
+			w.WritelnString(storagePath)
+			return w.Err()
+		},
+	}
+
+	cmd.Flags().StringP("host", "H", "", "Look only in a specific host configuration, or 'auto' for this machine's hostname")
+	return cmd
+}
+
+// whichStoragePath finds the repository file backing filePath across the
+// common and all host configurations, preferring the one its symlink targets.
+// If no scope manages it, the common configuration's ErrNotManaged is returned.
+func whichStoragePath(filePath string) (string, error) {
+	commonPath, commonErr := lnk.NewLnk().ManagedPath(filePath)
+	if commonErr != nil && !errors.Is(commonErr, lnk.ErrNotManaged) {
+		return "", commonErr
+	}
+
+	var candidates []string
+	if commonErr == nil {
+		candidates = append(candidates, commonPath)
+	}
+
+	hosts, err := findHostConfigs()
+	if err != nil {
+		return "", err
+	}
+	for _, host := range hosts {
+		hostPath, err := lnk.NewLnk(lnk.WithHost(host)).ManagedPath(filePath)
+		if errors.Is(err, lnk.ErrNotManaged) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, hostPath)
+	}
+
+	if len(candidates) == 0 {
+		return "", commonErr
+	}
+
+	if target, err := symlinkTarget(filePath); err == nil {
+		for _, candidate := range candidates {
+			if filepath.Clean(candidate) == target {
+				return candidate, nil
+			}
+		}
+	}
+
+	return candidates[0], nil
+}
+
+// symlinkTarget returns the absolute, cleaned target of the symlink at path.
+func symlinkTarget(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := os.Readlink(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(absPath), target)
+	}
+	return filepath.Clean(target), nil
+}
@@ -39,14 +39,33 @@ func (r *Runner) FindScript() (string, error) {
 	return "", nil
 }
 
-// RunScript executes the bootstrap script with configurable I/O.
-func (r *Runner) RunScript(scriptName string, stdout, stderr io.Writer, stdin io.Reader) error {
+// CheckScript reports whether scriptName names a runnable script: a file
+// relative to the repository root that does not leave it. A missing script
+// fails with ErrBootstrapNotFound, a missing repository with ErrNotInitialized.
+func (r *Runner) CheckScript(scriptName string) error {
+	if !r.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+	if !filepath.IsLocal(scriptName) {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrBootstrapNotFound, scriptName, "scripts are looked up relative to the lnk repository and must stay inside it")
+	}
 	scriptPath := filepath.Join(r.repoPath, scriptName)
 
-	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-		return lnkerror.WithPath(lnkerror.ErrBootstrapNotFound, scriptName)
+	if info, err := os.Stat(scriptPath); err != nil || info.IsDir() {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrBootstrapNotFound, scriptName, "check the name; scripts are looked up relative to the lnk repository")
 	}
 
+	return nil
+}
+
+// RunScript executes the bootstrap script with configurable I/O, after
+// checking it with CheckScript.
+func (r *Runner) RunScript(scriptName string, stdout, stderr io.Writer, stdin io.Reader) error {
+	if err := r.CheckScript(scriptName); err != nil {
+		return err
+	}
+	scriptPath := filepath.Join(r.repoPath, scriptName)
+
 	if err := os.Chmod(scriptPath, 0755); err != nil {
 		return lnkerror.Wrap(lnkerror.ErrBootstrapPerms)
 	}
@@ -0,0 +1,660 @@
+// Package config reads and writes lnk's config.toml files.
+//
+// Only the subset of TOML that lnk's settings need is understood: comments,
+// [table] headers, and key = value lines whose value is a basic ("...") or
+// literal ('...') string, a boolean or a number.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// FileName is the name of the config file at the repository root and in
+// $XDG_CONFIG_HOME/lnk.
+const FileName = "config.toml"
+
+// Sentinel errors for config operations.
+var (
+	ErrSyntax       = errors.New("Invalid config file")
+	ErrUnknownKey   = errors.New("Unknown config key")
+	ErrInvalidValue = errors.New("Invalid config value")
+)
+
+// Config holds the settings read from config.toml. Zero values mean unset,
+// so the built-in default applies.
+type Config struct {
+	Host        string        // Default --host; "auto" means this machine's hostname
+	LockTimeout time.Duration // How long mutating commands wait for the repository lock
+	OnConflict  string        // Default --on-conflict for pull and sync
+	PullRebase  bool          // Whether pull and sync rebase instead of merging, as --rebase
+	AddMaxSize  int64         // Largest file add accepts without --force, in bytes
+	SkipDirs    []string      // Directory names recursive adds leave out; non-nil and empty to leave out none
+	HostSuffix  bool          // Whether host adds store files as <path>.<host> at the repository root
+	PushMessage string        // Default commit message for push and sync; may hold placeholders
+	SSHCommand  string        // ssh command git runs for the repository's remote (GIT_SSH_COMMAND)
+	Retries     int           // Retries of push, pull and fetch after a network failure; -1 when set to none
+	RetryWait   time.Duration // Wait before the first retry, doubled for each further one
+	IndexFile   string        // Common tracking file, relative to the repository root; host files add .<host>
+	Candidates  []string      // $HOME globs list --unmanaged checks, replacing the built-in list
+
+	WatchDebounce time.Duration // How long watch waits after the last change before committing
+	WatchPush     bool          // Whether watch pushes after each commit
+
+	Unknown []UnknownKey // Keys Load did not recognise and skipped, in file order
+}
+
+// UnknownKey is a key = value line Load skipped because no setting has that
+// name, typically one written by a newer lnk.
+type UnknownKey struct {
+	Name     string // Dotted name as written
+	Location string // <path>:<line> of the assignment
+}
+
+// Key describes one setting that config.toml understands.
+type Key struct {
+	Name  string // Dotted name, e.g. pull.on_conflict
+	Usage string // One-line description for help output
+}
+
+// field binds a Key to the Config member it fills. Booleans and numbers
+// (bare) are written unquoted by Set.
+type field struct {
+	Key
+	get  func(c *Config) string
+	set  func(c *Config, value string) error
+	bare bool
+}
+
+var fields = []field{
+	{
+		Key: Key{Name: "host", Usage: "host configuration used when --host is not given; 'auto' for this machine's hostname"},
+		get: func(c *Config) string { return c.Host },
+		set: func(c *Config, value string) error {
+			c.Host = value
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "lock_timeout", Usage: "how long to wait for another lnk command to finish, e.g. 30s"},
+		get: func(c *Config) string {
+			if c.LockTimeout == 0 {
+				return ""
+			}
+			return c.LockTimeout.String()
+		},
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return errors.New("use a positive duration such as 30s or 2m")
+			}
+			c.LockTimeout = d
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "pull.on_conflict", Usage: "what pull and sync do with files in the way of a symlink: overwrite, skip or backup"},
+		get: func(c *Config) string { return c.OnConflict },
+		set: func(c *Config, value string) error {
+			switch value {
+			case "overwrite", "skip", "backup":
+				c.OnConflict = value
+				return nil
+			}
+			return errors.New("use overwrite, skip or backup")
+		},
+	},
+	{
+		Key: Key{Name: "pull.rebase", Usage: "whether pull and sync rebase local commits onto the remote's instead of merging: true or false"},
+		get: func(c *Config) string {
+			if !c.PullRebase {
+				return ""
+			}
+			return "true"
+		},
+		set: func(c *Config, value string) error {
+			switch value {
+			case "true", "false":
+				c.PullRebase = value == "true"
+				return nil
+			}
+			return errors.New("use true or false")
+		},
+		bare: true,
+	},
+	{
+		Key: Key{Name: "add.max_size", Usage: "largest file lnk add accepts without --force, e.g. 10MB"},
+		get: func(c *Config) string {
+			if c.AddMaxSize == 0 {
+				return ""
+			}
+			return fs.FormatSize(c.AddMaxSize)
+		},
+		set: func(c *Config, value string) error {
+			n, err := fs.ParseSize(value)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return errors.New("use a size above zero; pass --force to add a single large file")
+			}
+			c.AddMaxSize = n
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "add.skip_dirs", Usage: "comma-separated directory names lnk add --recursive leaves out (default .git, node_modules, __pycache__); \"\" for none"},
+		get: func(c *Config) string { return strings.Join(c.SkipDirs, ", ") },
+		set: func(c *Config, value string) error {
+			names := []string{}
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+					return fmt.Errorf("%q is not a directory name", name)
+				}
+				names = append(names, name)
+			}
+			c.SkipDirs = names
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "add.host_suffix", Usage: "whether lnk add --host stores files as <path>.<host> at the repository root instead of in <host>.lnk/: true or false"},
+		get: func(c *Config) string {
+			if !c.HostSuffix {
+				return ""
+			}
+			return "true"
+		},
+		set: func(c *Config, value string) error {
+			switch value {
+			case "true", "false":
+				c.HostSuffix = value == "true"
+				return nil
+			}
+			return errors.New("use true or false")
+		},
+		bare: true,
+	},
+	{
+		Key: Key{Name: "push.default_message", Usage: "commit message for push and sync when none is given; {date}, {host} and {count} are filled in"},
+		get: func(c *Config) string { return c.PushMessage },
+		set: func(c *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return errors.New("use a non-empty message such as \"sync from {host} {date}\"")
+			}
+			c.PushMessage = value
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "git.ssh_command", Usage: "ssh command git uses to reach the remote, e.g. \"ssh -i ~/.ssh/dotfiles\"; GIT_SSH_COMMAND still wins"},
+		get: func(c *Config) string { return c.SSHCommand },
+		set: func(c *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return errors.New("use a command such as \"ssh -i ~/.ssh/dotfiles\"")
+			}
+			c.SSHCommand = value
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "git.retries", Usage: "how many times push, pull and fetch retry after a network failure such as an unresolvable host (default 2); 0 for none"},
+		get: func(c *Config) string {
+			switch {
+			case c.Retries == 0:
+				return ""
+			case c.Retries < 0:
+				return "0"
+			}
+			return strconv.Itoa(c.Retries)
+		},
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return errors.New("use a whole number such as 3, or 0 to never retry")
+			}
+			if n == 0 {
+				n = -1
+			}
+			c.Retries = n
+			return nil
+		},
+		bare: true,
+	},
+	{
+		Key: Key{Name: "git.retry_backoff", Usage: "how long the first network retry waits, doubling for each one after, e.g. 5s (default 2s)"},
+		get: func(c *Config) string {
+			if c.RetryWait == 0 {
+				return ""
+			}
+			return c.RetryWait.String()
+		},
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return errors.New("use a positive duration such as 2s or 500ms")
+			}
+			c.RetryWait = d
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "index.file", Usage: "where the tracking file lives in the repository, e.g. meta/lnk-index (default .lnk); host files add .<host>"},
+		get: func(c *Config) string { return c.IndexFile },
+		set: func(c *Config, value string) error {
+			name := filepath.ToSlash(filepath.Clean(filepath.FromSlash(strings.TrimSpace(value))))
+			first, _, _ := strings.Cut(name, "/")
+			if value == "" || filepath.IsAbs(value) || name == "." || first == ".." || first == ".git" {
+				return errors.New("use a path inside the repository, such as meta/lnk-index")
+			}
+			if name == FileName || strings.HasSuffix(first, ".lnk") && name != ".lnk" {
+				return fmt.Errorf("%q is reserved for lnk's own files", name)
+			}
+			c.IndexFile = name
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "list.candidates", Usage: "comma-separated $HOME globs lnk list --unmanaged checks, e.g. \".bashrc, .config/*\""},
+		get: func(c *Config) string { return strings.Join(c.Candidates, ", ") },
+		set: func(c *Config, value string) error {
+			var patterns []string
+			for _, pattern := range strings.Split(value, ",") {
+				pattern = strings.TrimSpace(pattern)
+				if pattern == "" {
+					continue
+				}
+				if _, err := filepath.Match(pattern, ""); err != nil || filepath.IsAbs(pattern) || strings.HasPrefix(pattern, "..") {
+					return fmt.Errorf("%q is not a glob relative to $HOME", pattern)
+				}
+				patterns = append(patterns, pattern)
+			}
+			if len(patterns) == 0 {
+				return errors.New("list at least one path, e.g. \".bashrc, .config/*\"")
+			}
+			c.Candidates = patterns
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "watch.debounce", Usage: "how long lnk watch waits after the last change before committing, e.g. 5s"},
+		get: func(c *Config) string {
+			if c.WatchDebounce == 0 {
+				return ""
+			}
+			return c.WatchDebounce.String()
+		},
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return errors.New("use a positive duration such as 5s or 1m")
+			}
+			c.WatchDebounce = d
+			return nil
+		},
+	},
+	{
+		Key: Key{Name: "watch.push", Usage: "whether lnk watch pushes after each commit: true or false"},
+		get: func(c *Config) string {
+			if !c.WatchPush {
+				return ""
+			}
+			return "true"
+		},
+		set: func(c *Config, value string) error {
+			switch value {
+			case "true", "false":
+				c.WatchPush = value == "true"
+				return nil
+			}
+			return errors.New("use true or false")
+		},
+		bare: true,
+	},
+}
+
+// Keys returns every setting config.toml understands, in display order.
+func Keys() []Key {
+	keys := make([]Key, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return keys
+}
+
+// Get returns the value of the named setting, or "" when it is unset.
+func (c *Config) Get(name string) (string, error) {
+	f, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return f.get(c), nil
+}
+
+// Load reads the config files at paths in order, each overriding the keys
+// set by the ones before it. Missing files are skipped, as are repeated
+// paths. Unknown keys are recorded in Unknown rather than failing the load,
+// so a file shared with a newer lnk still works; CheckKeys reports them.
+func Load(paths ...string) (*Config, error) {
+	c := &Config{}
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		assignments, err := parse(path, string(data))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range assignments {
+			location := fmt.Sprintf("%s:%d", path, a.line)
+			f, err := lookup(a.key)
+			if err != nil {
+				c.Unknown = append(c.Unknown, UnknownKey{Name: a.key, Location: location})
+				continue
+			}
+			if err := f.set(c, a.value); err != nil {
+				return nil, lnkerror.WithPathAndSuggestion(ErrInvalidValue, location, fmt.Sprintf("%s: %s", a.key, err))
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// CheckKeys fails with ErrUnknownKey, naming the file and line, when Load
+// skipped an unknown key.
+func (c *Config) CheckKeys() error {
+	if len(c.Unknown) == 0 {
+		return nil
+	}
+	return lnkerror.WithPathAndSuggestion(ErrUnknownKey, c.Unknown[0].Location, knownKeys())
+}
+
+// Set validates value for the named setting and writes it to the config file
+// at path, creating the file if needed. An existing assignment is replaced in
+// place, so comments and other settings are kept.
+func Set(path, name, value string) error {
+	f, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	if err := f.set(&Config{}, value); err != nil {
+		return lnkerror.WithPathAndSuggestion(ErrInvalidValue, value, fmt.Sprintf("%s: %s", name, err))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if _, err := parse(path, string(data)); err != nil {
+		return err
+	}
+
+	encoded := quote(value)
+	if f.bare {
+		encoded = value
+	}
+	lines := setLine(splitLines(string(data)), name, encoded)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// lookup finds the field for a dotted key name.
+func lookup(name string) (field, error) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return field{}, lnkerror.WithPathAndSuggestion(ErrUnknownKey, name, knownKeys())
+}
+
+// knownKeys lists the valid key names for error suggestions.
+func knownKeys() string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return "known keys: " + strings.Join(names, ", ")
+}
+
+// assignment is one parsed key = value line, with its table prefix applied.
+type assignment struct {
+	key   string
+	value string
+	line  int
+}
+
+// parse reads the key = value assignments of a config file.
+func parse(path, content string) ([]assignment, error) {
+	var assignments []assignment
+	seen := make(map[string]bool)
+	table := ""
+
+	for i, raw := range splitLines(content) {
+		line := strings.TrimSpace(raw)
+		location := fmt.Sprintf("%s:%d", path, i+1)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, ok := tableHeader(line); ok {
+			if !validKey(name) {
+				return nil, lnkerror.WithPathAndSuggestion(ErrSyntax, location, "table names look like [pull]")
+			}
+			table = name
+			continue
+		}
+
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validKey(key) {
+			return nil, lnkerror.WithPathAndSuggestion(ErrSyntax, location, "expected key = value")
+		}
+		value, err := parseValue(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, lnkerror.WithPathAndSuggestion(ErrSyntax, location, err.Error())
+		}
+
+		if table != "" {
+			key = table + "." + key
+		}
+		if seen[key] {
+			return nil, lnkerror.WithPathAndSuggestion(ErrSyntax, location, key+" is set twice")
+		}
+		seen[key] = true
+
+		assignments = append(assignments, assignment{key: key, value: value, line: i + 1})
+	}
+
+	return assignments, nil
+}
+
+// parseValue decodes the value part of an assignment, dropping a trailing
+// comment.
+func parseValue(s string) (string, error) {
+	var value, rest string
+
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var b strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] != '\\' {
+				b.WriteByte(s[i])
+				continue
+			}
+			i++
+			if i == len(s) {
+				break
+			}
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return "", fmt.Errorf(`unsupported escape \%c`, s[i])
+			}
+		}
+		if i >= len(s) {
+			return "", errors.New("unterminated string")
+		}
+		value, rest = b.String(), s[i+1:]
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		value, rest = s[1:end+1], s[end+2:]
+	default:
+		value, _, _ = strings.Cut(s, "#")
+		value = strings.TrimSpace(value)
+		bare := value == "true" || value == "false" || value != "" && strings.ContainsRune("0123456789+-", rune(value[0]))
+		if !bare || strings.ContainsAny(value, " \t\"'") {
+			return "", errors.New(`quote text values, e.g. key = "value"`)
+		}
+	}
+
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", errors.New("unexpected text after the value")
+	}
+	return value, nil
+}
+
+// setLine replaces the assignment of name in lines, or adds one after the
+// last key of its table; the table is created if missing, and top-level keys
+// go before the first table.
+func setLine(lines []string, name, value string) []string {
+	table, leaf := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		table, leaf = name[:i], name[i+1:]
+	}
+
+	current := ""
+	firstTable, tableEnd := -1, -1
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if header, ok := tableHeader(line); ok {
+			if firstTable < 0 {
+				firstTable = i
+			}
+			current = header
+			if current == table {
+				tableEnd = i + 1
+			}
+			continue
+		}
+
+		key, _, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		full := key
+		if current != "" {
+			full = current + "." + key
+		}
+		if full == name {
+			lines[i] = key + " = " + value
+			return lines
+		}
+		if current == table {
+			tableEnd = i + 1
+		}
+	}
+
+	switch {
+	case tableEnd >= 0:
+		return insertLines(lines, tableEnd, leaf+" = "+value)
+	case table == "" && firstTable >= 0:
+		return insertLines(lines, firstTable, leaf+" = "+value, "")
+	case table == "":
+		return append(lines, leaf+" = "+value)
+	case len(lines) == 0:
+		return []string{"[" + table + "]", leaf + " = " + value}
+	default:
+		return append(lines, "", "["+table+"]", leaf+" = "+value)
+	}
+}
+
+// insertLines inserts extra into lines before index i.
+func insertLines(lines []string, i int, extra ...string) []string {
+	return append(lines[:i], append(extra, lines[i:]...)...)
+}
+
+// tableHeader returns the name of a [table] line.
+func tableHeader(line string) (string, bool) {
+	if !strings.HasPrefix(line, "[") {
+		return "", false
+	}
+	header, _, _ := strings.Cut(line, "#")
+	header = strings.TrimSpace(header)
+	if !strings.HasSuffix(header, "]") {
+		return "", false
+	}
+	return strings.TrimSpace(header[1 : len(header)-1]), true
+}
+
+// validKey reports whether s is a bare or dotted TOML key.
+func validKey(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitLines splits content into lines without the trailing empty ones.
+func splitLines(content string) []string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// quote encodes value as a TOML basic string.
+func quote(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
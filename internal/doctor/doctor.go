@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/yarlson/lnk/internal/fs"
 	"github.com/yarlson/lnk/internal/git"
 	"github.com/yarlson/lnk/internal/lnkerror"
 	"github.com/yarlson/lnk/internal/syncer"
@@ -16,21 +17,39 @@ import (
 // Result contains the results of a doctor scan or execution.
 // BackedUp is populated only by Fix (not Preview): it lists managed items
 // whose pre-existing real files were renamed to <path>.lnk-backup during
-// the symlink restoration step.
+// the symlink restoration step. Collisions are pairs of entries restored at
+// the same $HOME location; Fix reports them but cannot decide which to keep.
+// Detached and Shallow describe the repository itself (see git.IsDetached
+// and git.IsShallow); they are reported only, since fixing them means
+// choosing a branch or fetching the full history.
 type Result struct {
 	InvalidEntries []string
 	BrokenSymlinks []string
 	BackedUp       []string
+	Collisions     []tracker.Collision
+	Detached       bool
+	Shallow        bool
 }
 
 // HasIssues returns true if any issues were found.
 func (r *Result) HasIssues() bool {
-	return len(r.InvalidEntries) > 0 || len(r.BrokenSymlinks) > 0
+	return r.TotalIssues() > 0
 }
 
 // TotalIssues returns the total number of issues found.
 func (r *Result) TotalIssues() int {
-	return len(r.InvalidEntries) + len(r.BrokenSymlinks)
+	return len(r.InvalidEntries) + len(r.BrokenSymlinks) + r.ReportOnly()
+}
+
+// ReportOnly returns how many of the issues Fix leaves to the user.
+func (r *Result) ReportOnly() int {
+	count := len(r.Collisions)
+	for _, found := range []bool{r.Detached, r.Shallow} {
+		if found {
+			count++
+		}
+	}
+	return count
 }
 
 // Checker handles repository health scanning and repair.
@@ -38,16 +57,18 @@ type Checker struct {
 	repoPath string
 	host     string
 	git      *git.Git
+	fs       *fs.FileSystem
 	tracker  *tracker.Tracker
 	syncer   *syncer.Syncer
 }
 
 // New creates a new health Checker.
-func New(repoPath, host string, g *git.Git, t *tracker.Tracker, s *syncer.Syncer) *Checker {
+func New(repoPath, host string, g *git.Git, f *fs.FileSystem, t *tracker.Tracker, s *syncer.Syncer) *Checker {
 	return &Checker{
 		repoPath: repoPath,
 		host:     host,
 		git:      g,
+		fs:       f,
 		tracker:  t,
 		syncer:   s,
 	}
@@ -73,6 +94,14 @@ func (d *Checker) Preview() (*Result, error) {
 	}
 	result.BrokenSymlinks = brokenSymlinks
 
+	collisions, err := d.findCollisions()
+	if err != nil {
+		return nil, err
+	}
+	result.Collisions = collisions
+	result.Detached = d.git.IsDetached()
+	result.Shallow = d.git.IsShallow()
+
 	return result, nil
 }
 
@@ -93,6 +122,9 @@ func (d *Checker) Fix() (*Result, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to restore symlinks: %w", err)
 		}
+		if len(restoreInfo.Failed) > 0 {
+			return nil, fmt.Errorf("failed to restore symlinks: %w", restoreInfo.Failed[0].Err)
+		}
 		result.BackedUp = restoreInfo.BackedUp
 	}
 
@@ -136,28 +168,54 @@ func (d *Checker) Fix() (*Result, error) {
 	return result, nil
 }
 
+// Normalize rewrites the tracking file deduplicated, cleaned and sorted, and
+// commits the result. Nothing is committed when it was already normalized.
+func (d *Checker) Normalize() (*tracker.NormalizeResult, error) {
+	if !d.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	result, err := d.tracker.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize tracking file: %w", err)
+	}
+
+	if !result.Rewritten {
+		return result, nil
+	}
+
+	if err := d.git.Add(d.tracker.LnkFileName()); err != nil {
+		return nil, err
+	}
+
+	if err := d.git.Commit("lnk: normalized tracking file"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // findInvalidEntries returns .lnk entries whose stored files no longer exist in the repo.
 func (d *Checker) findInvalidEntries() ([]string, error) {
-	managedItems, err := d.tracker.GetManagedItems()
+	entries, err := d.tracker.GetEntries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	if len(managedItems) == 0 {
+	if len(entries) == 0 {
 		return []string{}, nil
 	}
 
-	storagePath := d.tracker.HostStoragePath()
 	var invalidItems []string
 
-	for _, relativePath := range managedItems {
-		cleaned := filepath.Clean(relativePath)
-		if strings.HasPrefix(cleaned, "..") || filepath.IsAbs(cleaned) {
+	for _, entry := range entries {
+		relativePath := entry.Path
+		if escapes(relativePath) || escapes(entry.StoredPath()) {
 			invalidItems = append(invalidItems, relativePath)
 			continue
 		}
 
-		storedFile := filepath.Join(storagePath, cleaned)
+		storedFile := d.tracker.StoragePath(entry)
 		if _, err := os.Stat(storedFile); os.IsNotExist(err) {
 			invalidItems = append(invalidItems, relativePath)
 			continue
@@ -168,40 +226,73 @@ func (d *Checker) findInvalidEntries() ([]string, error) {
 }
 
 // findBrokenSymlinks returns managed entries whose symlinks at $HOME are broken or missing.
+// Copy-managed entries count only when their file at $HOME is missing, and a
+// common entry whose path links to a host's version is overridden, not broken.
 func (d *Checker) findBrokenSymlinks() ([]string, error) {
-	managedItems, err := d.tracker.GetManagedItems()
+	entries, err := d.tracker.GetEntries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	if len(managedItems) == 0 {
+	if len(entries) == 0 {
 		return []string{}, nil
 	}
 
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := d.fs.HomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	storagePath := d.tracker.HostStoragePath()
 	var brokenSymlinks []string
 
-	for _, relativePath := range managedItems {
-		cleaned := filepath.Clean(relativePath)
-		if strings.HasPrefix(cleaned, "..") || filepath.IsAbs(cleaned) {
+	for _, entry := range entries {
+		relativePath := entry.Path
+		if escapes(relativePath) || escapes(entry.StoredPath()) {
 			continue
 		}
 
-		repoItem := filepath.Join(storagePath, cleaned)
+		repoItem := d.tracker.StoragePath(entry)
 		if _, err := os.Stat(repoItem); os.IsNotExist(err) {
 			continue
 		}
 
-		symlinkPath := filepath.Join(homeDir, relativePath)
-		if !d.syncer.IsValidSymlink(symlinkPath, repoItem) {
+		symlinkPath := d.fs.HomePath(homeDir, relativePath, entry.XDG)
+		if entry.Copy {
+			if _, err := os.Lstat(symlinkPath); os.IsNotExist(err) {
+				brokenSymlinks = append(brokenSymlinks, relativePath)
+			}
+			continue
+		}
+		if !d.syncer.IsValidSymlink(symlinkPath, repoItem) && (d.host != "" || !d.syncer.HostOverride(entry, symlinkPath)) {
 			brokenSymlinks = append(brokenSymlinks, relativePath)
 		}
 	}
 
 	return brokenSymlinks, nil
 }
+
+// findCollisions returns the pairs of entries, in the common configuration
+// and the checked host, that a restore would link at the same $HOME location.
+func (d *Checker) findCollisions() ([]tracker.Collision, error) {
+	scopes := []string{""}
+	if d.host != "" {
+		scopes = append(scopes, d.host)
+	}
+	entries, err := d.tracker.ScopedEntries(scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	homeDir, err := d.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return tracker.NewLocations(homeDir, entries).Collisions(), nil
+}
+
+// escapes reports whether the index path would resolve outside the directory
+// it is relative to.
+func escapes(path string) bool {
+	cleaned := filepath.Clean(path)
+	return strings.HasPrefix(cleaned, "..") || filepath.IsAbs(cleaned)
+}
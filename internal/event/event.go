@@ -0,0 +1,32 @@
+// Package event defines the structured events lnk reports as it changes
+// the repository and $HOME, so programs embedding it can observe what
+// happened without parsing the command's output.
+package event
+
+// Kind names what an Event reports.
+type Kind string
+
+// The kinds of Event.
+const (
+	FileAdded       Kind = "file_added"       // An item was put under management
+	FileRemoved     Kind = "file_removed"     // An item was released from management
+	SymlinkRestored Kind = "symlink_restored" // A managed item was put back in $HOME
+	GitCommitted    Kind = "git_committed"    // A commit was recorded
+	GitPushed       Kind = "git_pushed"       // The current branch was pushed
+)
+
+// Event is one thing lnk did. Only the fields that belong to its Kind are
+// set.
+type Event struct {
+	Kind    Kind
+	Path    string // File events: the index entry, relative to $HOME
+	Host    string // File events: the host configuration; empty for the common one
+	Copy    bool   // SymlinkRestored: the item is copy-managed, so it was copied rather than linked
+	Message string // GitCommitted: the commit message
+	Hash    string // GitCommitted: the new commit; empty if it could not be read
+	Remote  string // GitPushed: the remote pushed to
+}
+
+// Handler is called with each Event as it happens, on the goroutine doing
+// the work, so it should return quickly.
+type Handler func(Event)
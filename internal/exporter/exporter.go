@@ -0,0 +1,281 @@
+// Package exporter writes managed files out in other dotfiles managers' layouts.
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// Sentinel errors for export operations.
+var (
+	ErrExportDirNotEmpty = errors.New("Export directory is not empty")
+	ErrExportInsideRepo  = errors.New("Export directory is inside the lnk repository")
+)
+
+// File maps one repository file to its name in the exported layout.
+type File struct {
+	Source       string // Absolute path of the file in the lnk repository
+	RelativePath string // Home-relative target path, e.g. .config/nvim/init.lua
+	Name         string // Path inside the export directory, e.g. dot_config/nvim/init.lua
+	Secret       bool   // Added with --secret; copied as the working tree holds it, not encrypted
+}
+
+// Service exports the managed files of one scope.
+type Service struct {
+	repoPath string
+	host     string
+	fs       *fs.FileSystem
+	tracker  *tracker.Tracker
+}
+
+// New creates a new exporter Service.
+func New(repoPath, host string, f *fs.FileSystem, t *tracker.Tracker) *Service {
+	return &Service{
+		repoPath: repoPath,
+		host:     host,
+		fs:       f,
+		tracker:  t,
+	}
+}
+
+// PlanChezmoi maps every managed file to its chezmoi source-state name. The
+// common configuration is always included; with a host, that host's files
+// are layered on top and win where both manage the same path, as they do
+// after `lnk pull --host`. Managed directories are expanded to their files.
+// Secret items keep their plain chezmoi names and are marked Secret: their
+// git-crypt encryption does not carry over to chezmoi's own.
+func (s *Service) PlanChezmoi() ([]File, error) {
+	gitDir := filepath.Join(s.repoPath, ".git")
+	if _, err := os.Stat(gitDir); err != nil {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	scopes := []string{""}
+	if s.host != "" {
+		scopes = append(scopes, s.host)
+	}
+
+	byPath := make(map[string]File)
+	for _, host := range scopes {
+		t := s.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+
+		for _, entry := range entries {
+			root := t.StoragePath(entry)
+			if _, err := os.Lstat(root); os.IsNotExist(err) {
+				continue
+			}
+
+			err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+
+				sub, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				rel := filepath.Join(entry.Path, sub)
+				name, err := s.chezmoiEntryPath(t, entry, sub)
+				if err != nil {
+					return err
+				}
+
+				byPath[rel] = File{Source: path, RelativePath: rel, Name: name, Secret: entry.Secret}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", root, err)
+			}
+		}
+	}
+
+	files := make([]File, 0, len(byPath))
+	for _, file := range byPath {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	return files, nil
+}
+
+// ExportChezmoi writes the files from PlanChezmoi into dir, which must be
+// missing or empty. The lnk repository is only read.
+func (s *Service) ExportChezmoi(dir string) ([]File, error) {
+	files, err := s.PlanChezmoi()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+	if rel, err := filepath.Rel(s.repoPath, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, lnkerror.WithPathAndSuggestion(ErrExportInsideRepo, dir, "choose a directory outside the lnk repository")
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, lnkerror.WithPathAndSuggestion(ErrExportDirNotEmpty, dir, "choose a new directory or empty this one")
+	}
+
+	for _, file := range files {
+		dest := filepath.Join(dir, file.Name)
+		if err := s.writeFile(file.Source, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// writeFile copies src to dest; a symlink becomes a regular file holding its
+// target, which is how chezmoi stores symlink_ entries.
+func (s *Service) writeFile(src, dest string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return s.fs.CopyFile(src, dest)
+	}
+
+	target, err := os.Readlink(src)
+	if err != nil {
+		return lnkerror.WithPath(fs.ErrSymlinkRead, src)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return lnkerror.WithPath(fs.ErrDirCreate, filepath.Dir(dest))
+	}
+	return os.WriteFile(dest, []byte(target+"\n"), 0644)
+}
+
+// chezmoiPath converts a home-relative path under storageRoot to its chezmoi
+// source-state name, component by component, using each component's
+// attributes in the repository.
+func chezmoiPath(storageRoot, rel string) (string, error) {
+	parts := strings.Split(rel, string(filepath.Separator))
+	names := make([]string, len(parts))
+
+	current := storageRoot
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return "", err
+		}
+		names[i] = chezmoiName(part, info)
+	}
+
+	return filepath.Join(names...), nil
+}
+
+// chezmoiEntryPath returns the chezmoi name of the file at sub below the
+// stored item of entry. An item stored away from its home-relative path
+// (add --into, add --hostname-suffix) takes the names of its parent
+// directories from $HOME, where they exist, instead of from the repository,
+// and a suffixed item drops its .<host> suffix.
+func (s *Service) chezmoiEntryPath(t *tracker.Tracker, entry tracker.Entry, sub string) (string, error) {
+	if entry.Repo == "" && !entry.Suffix {
+		return chezmoiPath(t.HostStoragePath(), filepath.Join(entry.Path, sub))
+	}
+
+	root := t.StoragePath(entry)
+	name, err := chezmoiPath(filepath.Dir(root), filepath.Join(filepath.Base(root), sub))
+	if err != nil {
+		return "", err
+	}
+	if entry.Suffix {
+		info, err := os.Lstat(root)
+		if err != nil {
+			return "", err
+		}
+		_, rest, _ := strings.Cut(name, string(filepath.Separator))
+		name = filepath.Join(chezmoiName(filepath.Base(entry.StoredPath()), info), rest)
+	}
+
+	parents := filepath.Dir(entry.Path)
+	if parents == "." {
+		return name, nil
+	}
+	homeDir, err := s.fs.HomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	parentNames, err := chezmoiPath(homeDir, parents)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parentNames, name), nil
+}
+
+// attributePrefixes are the source-state prefixes chezmoi would parse off a
+// name; a name that already starts with one needs literal_.
+var attributePrefixes = []string{
+	"after_", "before_", "create_", "dot_", "empty_", "encrypted_", "exact_",
+	"executable_", "external_", "literal_", "modify_", "once_", "onchange_",
+	"private_", "readonly_", "remove_", "run_", "symlink_",
+}
+
+// chezmoiName returns the chezmoi source-state name for one path component.
+// Prefixes follow chezmoi's required order: for files private_, readonly_,
+// empty_, executable_; for symlinks symlink_; for directories private_,
+// readonly_; and dot_ last for names starting with a dot.
+func chezmoiName(name string, info os.FileInfo) string {
+	perm := info.Mode().Perm()
+
+	var prefix strings.Builder
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		prefix.WriteString("symlink_")
+	case info.IsDir():
+		if perm&0o077 == 0 {
+			prefix.WriteString("private_")
+		}
+		if perm&0o222 == 0 {
+			prefix.WriteString("readonly_")
+		}
+	default:
+		if perm&0o077 == 0 {
+			prefix.WriteString("private_")
+		}
+		if perm&0o222 == 0 {
+			prefix.WriteString("readonly_")
+		}
+		if info.Size() == 0 {
+			prefix.WriteString("empty_")
+		}
+		if perm&0o111 != 0 {
+			prefix.WriteString("executable_")
+		}
+	}
+
+	if strings.HasPrefix(name, ".") {
+		prefix.WriteString("dot_")
+		name = name[1:]
+	} else {
+		for _, p := range attributePrefixes {
+			if strings.HasPrefix(name, p) {
+				prefix.WriteString("literal_")
+				break
+			}
+		}
+	}
+
+	if !info.IsDir() && (strings.HasSuffix(name, ".tmpl") || strings.HasSuffix(name, ".literal")) {
+		name += ".literal"
+	}
+
+	return prefix.String() + name
+}
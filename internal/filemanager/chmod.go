@@ -0,0 +1,47 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// SetChmod makes adds set mode, octal permission bits such as "0600", on
+// each item once it is stored in the repository, and record it in the entry
+// so restores on other machines apply it again. A valid mode is recorded in
+// four-digit form ("600" becomes "0600"). An empty mode leaves permissions as
+// they are.
+func (fm *Manager) SetChmod(mode string) {
+	if perm, err := tracker.ParseMode(mode); err == nil {
+		mode = fmt.Sprintf("%04o", uint32(perm))
+	}
+	fm.chmod = mode
+}
+
+// validateChmod fails with ErrInvalidMode unless the SetChmod mode parses as
+// octal permission bits.
+func (fm *Manager) validateChmod() error {
+	if fm.chmod == "" {
+		return nil
+	}
+	if _, err := tracker.ParseMode(fm.chmod); err != nil {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidMode, fm.chmod, "give octal permissions such as 0600 or 644")
+	}
+	return nil
+}
+
+// applyChmod sets the entry's recorded mode on the item stored at destPath.
+// The rollback of the add moves the original back with the permissions it
+// had, so nothing needs undoing here.
+func applyChmod(entry tracker.Entry, destPath string) error {
+	mode, ok := entry.Perm()
+	if !ok {
+		return nil
+	}
+	if err := os.Chmod(destPath, mode); err != nil {
+		return fmt.Errorf("failed to set mode %s on %s: %w", entry.Mode, destPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// DefaultCandidates are the $HOME globs Unmanaged checks when no candidate
+// list is configured: common shell, editor and tool configs, and everything
+// directly under ~/.config.
+var DefaultCandidates = []string{
+	".bashrc", ".bash_profile", ".profile", ".zshrc", ".zprofile", ".zshenv",
+	".vimrc", ".gitconfig", ".tmux.conf", ".inputrc", ".ssh/config", ".config/*",
+}
+
+// UnmanagedFile is a dotfile in $HOME that no lnk index tracks.
+type UnmanagedFile struct {
+	RelativePath string // Path relative to $HOME
+	LinkTarget   string // Where the symlink points when another tool (e.g. GNU Stow) links it; "" for a plain file or directory
+}
+
+// Unmanaged returns the items matching patterns (globs relative to $HOME;
+// DefaultCandidates when empty) that lnk does not manage in the common or
+// any host configuration. Items that are managed, lie inside a managed
+// directory, contain a managed item, are symlinks into the lnk repository, or
+// hold the repository itself are left out, as are special files.
+func (fm *Manager) Unmanaged(patterns []string) ([]UnmanagedFile, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultCandidates
+	}
+
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	managed, err := fm.allManagedItems()
+	if err != nil {
+		return nil, err
+	}
+	repoPath, err := filepath.EvalSymlinks(fm.repoPath)
+	if err != nil {
+		repoPath = fm.repoPath
+	}
+
+	seen := make(map[string]bool)
+	var found []UnmanagedFile
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(homeDir, pattern))
+		if err != nil {
+			return nil, lnkerror.WithPath(lnkerror.ErrInvalidPattern, pattern)
+		}
+
+		for _, match := range matches {
+			relativePath, err := fm.fs.RelativePath(match)
+			if err != nil || seen[relativePath] {
+				continue
+			}
+			seen[relativePath] = true
+
+			if overlapsManaged(relativePath, managed) || isWithin(repoPath, match) || isWithin(fm.repoPath, match) {
+				continue
+			}
+
+			info, err := os.Lstat(match)
+			if err != nil {
+				continue
+			}
+			item := UnmanagedFile{RelativePath: relativePath}
+			if info.Mode()&os.ModeSymlink != 0 {
+				resolved, err := filepath.EvalSymlinks(match)
+				if err != nil || isWithin(resolved, repoPath) {
+					continue
+				}
+				if item.LinkTarget, err = os.Readlink(match); err != nil {
+					continue
+				}
+			} else if !info.Mode().IsRegular() && !info.IsDir() {
+				continue
+			}
+
+			found = append(found, item)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].RelativePath < found[j].RelativePath })
+	return found, nil
+}
+
+// allManagedItems returns the items tracked by the common index and by every
+// host index in the repository.
+func (fm *Manager) allManagedItems() ([]string, error) {
+	hosts, err := fm.tracker.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for _, host := range append([]string{""}, hosts...) {
+		hostItems, err := fm.tracker.ForHost(host).GetManagedItems()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+		items = append(items, hostItems...)
+	}
+	return items, nil
+}
+
+// overlapsManaged reports whether relativePath is a managed item, lies inside
+// one, or contains one.
+func overlapsManaged(relativePath string, managed []string) bool {
+	for _, item := range managed {
+		if isWithin(relativePath, item) || isWithin(item, relativePath) {
+			return true
+		}
+	}
+	return false
+}
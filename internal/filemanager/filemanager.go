@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
+	"github.com/yarlson/lnk/internal/event"
 	"github.com/yarlson/lnk/internal/fs"
 	"github.com/yarlson/lnk/internal/git"
 	"github.com/yarlson/lnk/internal/lnkerror"
@@ -16,6 +18,42 @@ import (
 // ProgressCallback defines the signature for progress reporting callbacks.
 type ProgressCallback func(current, total int, currentFile string)
 
+// SkipHandler is told about each entry a directory walk leaves out because
+// lnk cannot manage it, such as a socket or named pipe, with the kind of file
+// it is.
+type SkipHandler func(path, kind string)
+
+// ExcludedDirKind is the kind a SkipHandler hears for a directory a walk
+// leaves out because its name is on the skip list.
+const ExcludedDirKind = "excluded directory"
+
+// EmptyDirKind is the kind a SkipHandler hears for an empty directory below
+// the walked one: it holds nothing to add, and git could not track it.
+const EmptyDirKind = "empty directory"
+
+// KeepFileName is the marker file, lnk's .gitkeep, that keeps a directory
+// which is otherwise empty: a recursive add tracks it like any other file,
+// so restoring it on another machine creates the directory.
+const KeepFileName = ".lnkkeep"
+
+// DefaultSkipDirs are the directory names recursive adds leave out unless
+// SetSkipDirs replaces them: version-control metadata and caches that are
+// rebuilt rather than kept with dotfiles.
+var DefaultSkipDirs = []string{".git", "node_modules", "__pycache__"}
+
+// ManagedFile describes an item that Add put under management or Remove
+// released from it.
+type ManagedFile struct {
+	Path         string // Absolute location in $HOME: the symlink after Add, the restored item after Remove
+	RelativePath string // Index entry, relative to $HOME
+	RepoPath     string // Absolute path of the stored copy in the repository (removed again by Remove)
+	Host         string // Host configuration; empty for the common one
+	IsDirectory  bool   // Whether the item is a directory
+	Copy         bool   // Whether the item is copy-managed rather than symlinked
+	Missing      bool   // Whether Remove found the $HOME symlink already deleted
+	Restored     bool   // Whether Remove put the item back at Path
+}
+
 // Manager handles adding and removing files from lnk management.
 type Manager struct {
 	repoPath string
@@ -23,6 +61,24 @@ type Manager struct {
 	git      *git.Git
 	fs       *fs.FileSystem
 	tracker  *tracker.Tracker
+	copy     bool
+	secret   bool
+	maxSize  int64
+	force    bool
+	allHome  bool
+	into     string
+	chmod    string
+	suffix   bool
+	absolute bool
+	xattrs   bool
+	comment  string
+	skip     SkipHandler
+	skipDirs []string
+	restore  bool
+	noCommit bool
+	onEvent  event.Handler
+
+	globalIgnore string
 }
 
 // New creates a new file Manager.
@@ -33,87 +89,333 @@ func New(repoPath, host string, g *git.Git, f *fs.FileSystem, t *tracker.Tracker
 		git:      g,
 		fs:       f,
 		tracker:  t,
+		maxSize:  DefaultMaxFileSize,
+		skipDirs: DefaultSkipDirs,
+	}
+}
+
+// SetCopy switches adds to copy mode: files are copied into the repository
+// and tracked as copy-managed instead of being moved and replaced by a
+// symlink. Copy mode handles regular files only.
+func (fm *Manager) SetCopy(enabled bool) {
+	fm.copy = enabled
+}
+
+// SetRestore makes Remove move the stored item back to $HOME when the
+// symlink there is already gone, rather than deleting it from the repository.
+func (fm *Manager) SetRestore(enabled bool) {
+	fm.restore = enabled
+}
+
+// SetNoCommit makes adds and removes stop once their changes are staged,
+// without committing them, so several can be recorded in one later commit.
+func (fm *Manager) SetNoCommit(enabled bool) {
+	fm.noCommit = enabled
+}
+
+// SetEventHandler registers h to hear about each item an add put under
+// management or a remove released, once the change is committed (or staged,
+// with SetNoCommit). A nil handler reports nothing.
+func (fm *Manager) SetEventHandler(h event.Handler) {
+	fm.onEvent = h
+}
+
+// emit reports kind for the index entry relativePath of this manager's host.
+func (fm *Manager) emit(kind event.Kind, relativePath string) {
+	if fm.onEvent != nil {
+		fm.onEvent(event.Event{Kind: kind, Path: relativePath, Host: fm.host})
 	}
 }
 
+// SetSkipHandler registers h to hear about special files that recursive adds
+// and previews leave out. A nil handler skips them silently.
+func (fm *Manager) SetSkipHandler(h SkipHandler) {
+	fm.skip = h
+}
+
+// SetSkipDirs replaces DefaultSkipDirs as the directory names recursive adds
+// and previews leave out below the directories they walk. A nil names keeps
+// the defaults; an empty, non-nil one excludes nothing.
+func (fm *Manager) SetSkipDirs(names []string) {
+	if names == nil {
+		names = DefaultSkipDirs
+	}
+	fm.skipDirs = names
+}
+
+// validateCopyMode rejects directories when adding in copy mode, since only
+// individual files are copied and refreshed.
+func (fm *Manager) validateCopyMode(filePath string, info os.FileInfo) error {
+	if fm.copy && info.IsDir() {
+		return lnkerror.WithPathAndSuggestion(fs.ErrUnsupportedType, filePath, "copy mode manages regular files only; add the directory with --recursive to copy each file in it")
+	}
+	return nil
+}
+
+// place puts the item at absPath under management at destPath: moved there
+// and replaced by a symlink, or copied there in copy mode. A file already at
+// destPath with the same content, as left by an add that failed before the
+// symlink was made, is reused; anything else there fails with
+// ErrStorageOccupied rather than being overwritten, unless SetForce is on:
+// then it is deleted first, and a rollback does not bring it back. Callers
+// have already refused paths the index tracks, so what is there is stale.
+func (fm *Manager) place(absPath, destPath string, info os.FileInfo) error {
+	if existing, err := os.Lstat(destPath); err == nil {
+		reuse := !info.IsDir() && existing.Mode().IsRegular() && fm.fs.SameContent(absPath, destPath)
+		if !reuse && !fm.force {
+			return lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, destPath, occupiedSuggestion)
+		}
+		if !reuse {
+			if err := fm.checkReplaceable(destPath); err != nil {
+				return err
+			}
+			if err := os.RemoveAll(destPath); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", destPath, err)
+			}
+			return fm.place(absPath, destPath, info)
+		}
+		if fm.copy {
+			return nil
+		}
+
+		if err := os.Remove(absPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", absPath, err)
+		}
+		if err := fm.fs.Link(destPath, absPath, fm.absolute); err != nil {
+			_ = fm.fs.CopyFile(destPath, absPath)
+			return err
+		}
+		return nil
+	}
+
+	if fm.copy {
+		return fm.fs.CopyFile(absPath, destPath)
+	}
+
+	if err := fm.fs.Move(absPath, destPath, info); err != nil {
+		return err
+	}
+
+	if err := fm.fs.Link(destPath, absPath, fm.absolute); err != nil {
+		_ = fm.fs.Move(destPath, absPath, info)
+		return err
+	}
+
+	return nil
+}
+
+// alreadyManagedSuggestion and occupiedSuggestion tell the two reasons an add
+// can find its storage path taken apart, and which one --force resolves.
+const (
+	alreadyManagedSuggestion = "lnk already tracks it, so there is nothing to add; edit it through the symlink, or run 'lnk rm' first"
+	occupiedSuggestion       = "no lnk index tracks that file; move it out of the lnk repository, or add again with --force to replace it"
+)
+
 // Add moves a file or directory to the repository and creates a symlink.
-func (fm *Manager) Add(filePath string) error {
+// In copy mode the file is copied instead and the original left in place.
+func (fm *Manager) Add(filePath string) (*ManagedFile, error) {
 	if err := fm.fs.ValidateFileForAdd(filePath); err != nil {
-		return err
+		return nil, err
+	}
+	if err := fm.requireRepository(); err != nil {
+		return nil, err
+	}
+	if err := fm.requireGitCrypt(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateInto(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateChmod(); err != nil {
+		return nil, err
 	}
 
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if err := fm.checkNotHome(absPath, false); err != nil {
+		return nil, err
+	}
+	if err := fm.checkOutsideRepo(absPath); err != nil {
+		return nil, err
 	}
 
-	relativePath, err := fs.GetRelativePath(absPath)
+	relativePath, err := fm.fs.RelativePath(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to get relative path: %w", err)
+		return nil, fmt.Errorf("failed to get relative path: %w", err)
 	}
 
-	storagePath := fm.tracker.HostStoragePath()
-	destPath := filepath.Join(storagePath, relativePath)
+	entry := fm.newEntry(absPath, relativePath)
+	destPath := fm.tracker.StoragePath(entry)
 
 	destDir := filepath.Dir(destPath)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	managedItems, err := fm.tracker.GetManagedItems()
 	if err != nil {
-		return fmt.Errorf("failed to get managed items: %w", err)
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
 	}
 	if slices.Contains(managedItems, relativePath) {
-		return lnkerror.WithPath(lnkerror.ErrAlreadyManaged, relativePath)
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrAlreadyManaged, relativePath, alreadyManagedSuggestion)
+	}
+	locations, err := fm.restoredLocations()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCollision(locations, relativePath); err != nil {
+		return nil, err
+	}
+	if err := fm.checkStoredPath(entry); err != nil {
+		return nil, err
 	}
 
 	info, err := os.Stat(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to stat path: %w", err)
+		return nil, fmt.Errorf("failed to stat path: %w", err)
 	}
 
-	if err := fm.fs.Move(absPath, destPath, info); err != nil {
-		return err
+	if err := fm.validateCopyMode(filePath, info); err != nil {
+		return nil, err
+	}
+	if err := fm.checkFileSizes(absPath, info); err != nil {
+		return nil, err
+	}
+	if err := fm.captureXattrs(absPath, &entry); err != nil {
+		return nil, err
 	}
 
-	if err := fm.fs.CreateSymlink(destPath, absPath); err != nil {
-		_ = fm.fs.Move(destPath, absPath, info)
-		return err
+	relink, err := fm.takeOverCommon(absPath, relativePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := fm.place(absPath, destPath, info); err != nil {
+		_ = relink()
+		return nil, err
+	}
+	_ = fs.WriteXattrs(destPath, entry.Xattrs)
+	rollback := thenRelink(fm.CreateRollbackAction(absPath, destPath, relativePath, info), relink)
+	if err := applyChmod(entry, destPath); err != nil {
+		_ = rollback()
+		return nil, err
 	}
 
-	if err := fm.tracker.AddManagedItem(relativePath); err != nil {
-		_ = os.Remove(absPath)
-		_ = fm.fs.Move(destPath, absPath, info)
-		return fmt.Errorf("failed to update tracking file: %w", err)
+	if err := fm.tracker.AddEntry(entry); err != nil {
+		_ = rollback()
+		return nil, fmt.Errorf("failed to update tracking file: %w", err)
 	}
 
-	gitPath := relativePath
-	if fm.host != "" {
-		gitPath = filepath.Join(fm.host+".lnk", relativePath)
+	gitPath := fm.tracker.GitPath(entry)
+	if fm.secret {
+		restore, err := fm.markSecret([]string{attributePattern(gitPath, info.IsDir())})
+		if err != nil {
+			_ = rollback()
+			return nil, err
+		}
+		restoreItem := rollback
+		rollback = func() error {
+			_ = restore()
+			return restoreItem()
+		}
 	}
 	if err := fm.git.Add(gitPath); err != nil {
-		_ = os.Remove(absPath)
-		_ = fm.tracker.RemoveManagedItem(relativePath)
-		_ = fm.fs.Move(destPath, absPath, info)
-		return err
+		_ = rollback()
+		return nil, err
 	}
 
 	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
-		_ = os.Remove(absPath)
-		_ = fm.tracker.RemoveManagedItem(relativePath)
-		_ = fm.fs.Move(destPath, absPath, info)
-		return err
+		_ = rollback()
+		return nil, err
 	}
 
 	basename := filepath.Base(relativePath)
-	if err := fm.git.Commit(fmt.Sprintf("lnk: added %s", basename)); err != nil {
-		_ = os.Remove(absPath)
-		_ = fm.tracker.RemoveManagedItem(relativePath)
-		_ = fm.fs.Move(destPath, absPath, info)
-		return err
+	if err := fm.commit(fmt.Sprintf("lnk: added %s", basename)); err != nil {
+		_ = rollback()
+		return nil, err
+	}
+
+	fm.emit(event.FileAdded, entry.Path)
+	return fm.managedFile(absPath, entry, info.IsDir()), nil
+}
+
+// checkNotHome fails with ErrHomeDirectory when absPath is the home
+// directory or holds it, such as /home or /: managing it would sweep up
+// every file of the user's, caches, keys and the lnk repository's parent
+// included. A recursive walk is let through after SetAllowHome, to add each
+// file below it; the directory itself never becomes one managed item.
+func (fm *Manager) checkNotHome(absPath string, recursive bool) error {
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	realHome, err := filepath.EvalSymlinks(homeDir)
+	if err != nil {
+		realHome = homeDir
+	}
+	// A symlink to the home directory is an item like any other; only the
+	// directories on the way to absPath are resolved.
+	if !isWithin(homeDir, absPath) && !isWithin(realHome, fs.ResolvePath(absPath)) {
+		return nil
 	}
 
+	switch {
+	case recursive && fm.allHome:
+		return nil
+	case recursive:
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrHomeDirectory, absPath, "it holds your home directory; add the dotfiles you want by name, or pass --i-know-what-im-doing to add every file below it")
+	default:
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrHomeDirectory, absPath, "it holds your home directory; add the dotfiles you want by name, e.g. lnk add ~/.bashrc ~/.config/nvim")
+	}
+}
+
+// checkOutsideRepo fails with ErrInsideRepo when absPath is the repository,
+// lies inside it, or contains it; managing any of those would move the
+// repository into itself, or leave a symlink that leads back into itself.
+// Both are compared as spelled and with symlinks resolved, so a symlinked
+// parent directory, repository path or item cannot hide the overlap.
+func (fm *Manager) checkOutsideRepo(absPath string) error {
+	repoPath := filepath.Clean(fm.repoPath)
+	realRepo, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		realRepo = repoPath
+	}
+	// The item itself is followed only to see what it contains: a symlink
+	// into the repository is a managed item, not one inside the repository.
+	target, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		target = absPath
+	}
+
+	switch {
+	case isWithin(absPath, repoPath), isWithin(fs.ResolvePath(absPath), realRepo):
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInsideRepo, absPath, "files in the repository are already managed; add the original in your home directory instead")
+	case isWithin(repoPath, absPath), isWithin(realRepo, target):
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInsideRepo, absPath, "it contains the lnk repository; use --recursive to add its other files")
+	}
+	return nil
+}
+
+// managedFile describes the item at absPath, tracked by entry in this
+// manager's scope.
+func (fm *Manager) managedFile(absPath string, entry tracker.Entry, isDir bool) *ManagedFile {
+	return &ManagedFile{
+		Path:         absPath,
+		RelativePath: entry.Path,
+		RepoPath:     fm.tracker.StoragePath(entry),
+		Host:         fm.host,
+		IsDirectory:  isDir,
+		Copy:         fm.copy,
+	}
+}
+
+// requireRepository fails with ErrNotInitialized before anything is moved
+// when the repository has not been created yet.
+func (fm *Manager) requireRepository() error {
+	if !fm.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
 	return nil
 }
 
@@ -121,11 +423,25 @@ func (fm *Manager) Add(filePath string) error {
 type validatedFile struct {
 	absPath      string
 	relativePath string
+	entry        tracker.Entry
 	info         os.FileInfo
 }
 
-// AddMultiple adds multiple files in a single transaction with optional progress reporting.
+// progressThreshold is the file count above which batch adds report
+// progress; smaller batches finish before a progress line would help.
+const progressThreshold = 10
+
+// AddMultiple adds multiple files in a single transaction, reporting
+// progress for batches of more than progressThreshold files.
 func (fm *Manager) AddMultiple(paths []string, progress ProgressCallback) error {
+	if len(paths) <= progressThreshold {
+		progress = nil
+	}
+	return fm.addFiles(paths, progress, fmt.Sprintf("lnk: added %d files", len(paths)))
+}
+
+// addFiles is AddMultiple with the commit message chosen by the caller.
+func (fm *Manager) addFiles(paths []string, progress ProgressCallback, commitMessage string) error {
 	if len(paths) == 0 {
 		return nil
 	}
@@ -135,6 +451,12 @@ func (fm *Manager) AddMultiple(paths []string, progress ProgressCallback) error
 	if err != nil {
 		return err
 	}
+	if err := fm.requireRepository(); err != nil {
+		return err
+	}
+	if err := fm.requireGitCrypt(); err != nil {
+		return err
+	}
 
 	// Phase 2: Process files (move, symlink, track) with optional progress.
 	rollbackActions, err := fm.processFiles(files, progress)
@@ -143,7 +465,7 @@ func (fm *Manager) AddMultiple(paths []string, progress ProgressCallback) error
 	}
 
 	// Phase 3: Git operations.
-	if err := fm.commitFiles(files, rollbackActions, progress != nil); err != nil {
+	if err := fm.commitFiles(files, rollbackActions, commitMessage); err != nil {
 		return err
 	}
 
@@ -152,7 +474,20 @@ func (fm *Manager) AddMultiple(paths []string, progress ProgressCallback) error
 
 // validatePaths validates all paths and returns validated file info.
 func (fm *Manager) validatePaths(paths []string) ([]validatedFile, error) {
+	if err := fm.validateInto(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateChmod(); err != nil {
+		return nil, err
+	}
+
+	locations, err := fm.restoredLocations()
+	if err != nil {
+		return nil, err
+	}
+
 	var files []validatedFile
+	stored := make(map[string]string, len(paths))
 
 	for _, filePath := range paths {
 		if err := fm.fs.ValidateFileForAdd(filePath); err != nil {
@@ -163,8 +498,14 @@ func (fm *Manager) validatePaths(paths []string) ([]validatedFile, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to get absolute path for %s: %w", filePath, err)
 		}
+		if err := fm.checkNotHome(absPath, false); err != nil {
+			return nil, err
+		}
+		if err := fm.checkOutsideRepo(absPath); err != nil {
+			return nil, err
+		}
 
-		relativePath, err := fs.GetRelativePath(absPath)
+		relativePath, err := fm.fs.RelativePath(absPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get relative path for %s: %w", filePath, err)
 		}
@@ -174,7 +515,10 @@ func (fm *Manager) validatePaths(paths []string) ([]validatedFile, error) {
 			return nil, fmt.Errorf("failed to get managed items: %w", err)
 		}
 		if slices.Contains(managedItems, relativePath) {
-			return nil, lnkerror.WithPath(lnkerror.ErrAlreadyManaged, relativePath)
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrAlreadyManaged, relativePath, alreadyManagedSuggestion)
+		}
+		if err := checkCollision(locations, relativePath); err != nil {
+			return nil, err
 		}
 
 		info, err := os.Stat(absPath)
@@ -182,9 +526,30 @@ func (fm *Manager) validatePaths(paths []string) ([]validatedFile, error) {
 			return nil, fmt.Errorf("failed to stat path %s: %w", filePath, err)
 		}
 
+		if err := fm.validateCopyMode(filePath, info); err != nil {
+			return nil, err
+		}
+		if err := fm.checkFileSizes(absPath, info); err != nil {
+			return nil, err
+		}
+
+		entry := fm.newEntry(absPath, relativePath)
+		if err := fm.checkStoredPath(entry); err != nil {
+			return nil, err
+		}
+		if err := fm.captureXattrs(absPath, &entry); err != nil {
+			return nil, err
+		}
+		if other, ok := stored[fm.tracker.GitPath(entry)]; ok {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, fm.tracker.StoragePath(entry), fmt.Sprintf("%s and %s would both be stored there; add them with different --into directories", other, filePath))
+		}
+		stored[fm.tracker.GitPath(entry)] = filePath
+		locations.Add(tracker.ScopedEntry{Host: fm.host, Entry: entry})
+
 		files = append(files, validatedFile{
 			absPath:      absPath,
 			relativePath: relativePath,
+			entry:        entry,
 			info:         info,
 		})
 	}
@@ -192,56 +557,111 @@ func (fm *Manager) validatePaths(paths []string) ([]validatedFile, error) {
 	return files, nil
 }
 
-// processFiles moves files to the repo, creates symlinks, and updates tracking.
+// processFiles moves files to the repo, creates symlinks (or copies files in
+// copy mode), and updates tracking.
 func (fm *Manager) processFiles(files []validatedFile, progress ProgressCallback) ([]func() error, error) {
 	var rollbackActions []func() error
 	total := len(files)
 
 	for i, f := range files {
+		if err := fm.interrupted(); err != nil {
+			fm.RollbackAll(rollbackActions)
+			return nil, err
+		}
 		if progress != nil {
 			progress(i+1, total, f.relativePath)
 		}
 
-		storagePath := fm.tracker.HostStoragePath()
-		destPath := filepath.Join(storagePath, f.relativePath)
-
-		destDir := filepath.Dir(destPath)
-		if err := os.MkdirAll(destDir, 0755); err != nil {
+		rollback, err := fm.processFile(f)
+		if err != nil {
 			fm.RollbackAll(rollbackActions)
-			return nil, fmt.Errorf("failed to create destination directory: %w", err)
+			return nil, err
 		}
 
-		if err := fm.fs.Move(f.absPath, destPath, f.info); err != nil {
-			fm.RollbackAll(rollbackActions)
-			return nil, fmt.Errorf("failed to move %s: %w", f.absPath, err)
-		}
+		rollbackActions = append(rollbackActions, rollback)
+	}
 
-		if err := fm.fs.CreateSymlink(destPath, f.absPath); err != nil {
-			_ = fm.fs.Move(destPath, f.absPath, f.info)
-			fm.RollbackAll(rollbackActions)
-			return nil, fmt.Errorf("failed to create symlink for %s: %w", f.absPath, err)
-		}
+	if err := fm.interrupted(); err != nil {
+		fm.RollbackAll(rollbackActions)
+		return nil, err
+	}
+	return rollbackActions, nil
+}
 
-		if err := fm.tracker.AddManagedItem(f.relativePath); err != nil {
-			_ = os.Remove(f.absPath)
-			_ = fm.fs.Move(destPath, f.absPath, f.info)
-			fm.RollbackAll(rollbackActions)
-			return nil, fmt.Errorf("failed to update tracking file for %s: %w", f.absPath, err)
-		}
+// interrupted fails with ErrInterrupted once the git context is done (lnk add
+// binds it to SIGINT and SIGTERM), so a batch stops between files and rolls
+// back instead of dying halfway through a move.
+func (fm *Manager) interrupted() error {
+	if fm.git.Context().Err() == nil {
+		return nil
+	}
+	return lnkerror.WithSuggestion(lnkerror.ErrInterrupted, "every file was put back and nothing was committed")
+}
+
+// processFile places one validated file and tracks it, returning the action
+// that undoes both. On failure nothing of this file is left changed.
+func (fm *Manager) processFile(f validatedFile) (func() error, error) {
+	destPath := fm.tracker.StoragePath(f.entry)
+
+	destDir := filepath.Dir(destPath)
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
 
-		rollbackActions = append(rollbackActions, fm.CreateRollbackAction(f.absPath, destPath, f.relativePath, f.info))
+	relink, err := fm.takeOverCommon(f.absPath, f.relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s: %w", f.absPath, err)
+	}
+	if err := fm.place(f.absPath, destPath, f.info); err != nil {
+		_ = relink()
+		return nil, fmt.Errorf("failed to add %s: %w", f.absPath, err)
+	}
+	_ = fs.WriteXattrs(destPath, f.entry.Xattrs)
+	rollback := thenRelink(fm.CreateRollbackAction(f.absPath, destPath, f.relativePath, f.info), relink)
+	if err := applyChmod(f.entry, destPath); err != nil {
+		_ = rollback()
+		return nil, err
 	}
 
-	return rollbackActions, nil
+	if err := fm.tracker.AddEntry(f.entry); err != nil {
+		_ = rollback()
+		return nil, fmt.Errorf("failed to update tracking file for %s: %w", f.absPath, err)
+	}
+
+	return rollback, nil
 }
 
 // commitFiles stages all files and creates a single git commit.
-func (fm *Manager) commitFiles(files []validatedFile, rollbackActions []func() error, recursive bool) error {
-	for _, f := range files {
-		gitPath := f.relativePath
-		if fm.host != "" {
-			gitPath = filepath.Join(fm.host+".lnk", f.relativePath)
+func (fm *Manager) commitFiles(files []validatedFile, rollbackActions []func() error, commitMessage string) error {
+	gitPaths := make([]string, len(files))
+	for i, f := range files {
+		gitPaths[i] = fm.tracker.GitPath(f.entry)
+	}
+
+	// Attributes first, so the clean filter sees them when the files are staged.
+	if fm.secret {
+		patterns := make([]string, len(files))
+		for i, f := range files {
+			patterns[i] = attributePattern(gitPaths[i], f.info.IsDir())
 		}
+		restore, err := fm.markSecret(patterns)
+		if err != nil {
+			fm.RollbackAll(rollbackActions)
+			return err
+		}
+		rollbackActions = append(rollbackActions, restore)
+	}
+
+	// A failed or interrupted commit must not leave the files staged for the
+	// next one once they are moved back.
+	staged := append(slices.Clone(gitPaths), fm.tracker.LnkFileName())
+	if fm.secret {
+		staged = append(staged, gitattributesFile)
+	}
+	rollbackActions = append(rollbackActions, func() error { return fm.git.Unstage(staged...) })
+
+	for i, f := range files {
+		gitPath := gitPaths[i]
 		if err := fm.git.Add(gitPath); err != nil {
 			fm.RollbackAll(rollbackActions)
 			return fmt.Errorf("failed to add %s to git: %w", f.absPath, err)
@@ -253,21 +673,36 @@ func (fm *Manager) commitFiles(files []validatedFile, rollbackActions []func() e
 		return fmt.Errorf("failed to add tracking file to git: %w", err)
 	}
 
-	suffix := "files"
-	if recursive {
-		suffix = "files recursively"
-	}
-	commitMessage := fmt.Sprintf("lnk: added %d %s", len(files), suffix)
-	if err := fm.git.Commit(commitMessage); err != nil {
+	if err := fm.commit(commitMessage); err != nil {
 		fm.RollbackAll(rollbackActions)
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
+	for _, f := range files {
+		fm.emit(event.FileAdded, f.entry.Path)
+	}
 	return nil
 }
 
+// commit records the staged changes of an add or remove as one commit with
+// message, unless SetNoCommit asked for them to stay staged.
+func (fm *Manager) commit(message string) error {
+	if fm.noCommit {
+		return nil
+	}
+	return fm.git.Commit(message)
+}
+
 // CreateRollbackAction creates a rollback function for a single file operation.
+// In copy mode the original was never touched, so only the copy is removed.
 func (fm *Manager) CreateRollbackAction(absPath, destPath, relativePath string, info os.FileInfo) func() error {
+	if fm.copy {
+		return func() error {
+			_ = fm.tracker.RemoveManagedItem(relativePath)
+			return os.Remove(destPath)
+		}
+	}
+
 	return func() error {
 		_ = os.Remove(absPath)
 		_ = fm.tracker.RemoveManagedItem(relativePath)
@@ -312,16 +747,134 @@ func (fm *Manager) AddRecursiveWithProgress(paths []string, progress ProgressCal
 		return fmt.Errorf("no files found to add")
 	}
 
-	const progressThreshold = 10
 	if len(allFiles) > progressThreshold && progress != nil {
-		return fm.AddMultiple(allFiles, progress)
+		return fm.addFiles(allFiles, progress, fmt.Sprintf("lnk: added %d files recursively", len(allFiles)))
 	}
 
 	return fm.AddMultiple(allFiles, nil)
 }
 
+// globMeta lists the characters that make an argument a filepath.Match pattern.
+const globMeta = "*?["
+
+// ExpandPaths expands arguments that are glob patterns, for patterns the
+// shell left alone (quoted, or unmatched). An argument that exists as a
+// literal path is kept as is, even if it contains pattern characters. Other
+// patterns are matched relative to the working directory, falling back to
+// $HOME for relative ones; a leading ~/ means $HOME. "**" is not supported —
+// use --recursive for directory trees. Matches the ignore files leave out are
+// dropped, as a recursive walk would drop them, and reported to the
+// SkipHandler. The result keeps argument order and drops repeats.
+func (fm *Manager) ExpandPaths(paths []string) ([]string, error) {
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	seen := make(map[string]bool, len(paths))
+	rules := make(map[string]ignoreRules)
+	var expanded []string
+	add := func(p string) {
+		key := p
+		if abs, err := filepath.Abs(p); err == nil {
+			key = abs
+		}
+		if !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, p)
+		}
+	}
+
+	for _, pattern := range paths {
+		if !strings.ContainsAny(pattern, globMeta) {
+			add(pattern)
+			continue
+		}
+		if _, err := os.Lstat(pattern); err == nil {
+			add(pattern)
+			continue
+		}
+		if strings.Contains(pattern, "**") {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidPattern, pattern, "** is not supported; use --recursive to add a directory tree")
+		}
+
+		if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+			pattern = filepath.Join(homeDir, rest)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, lnkerror.WithPath(lnkerror.ErrInvalidPattern, pattern)
+		}
+		if len(matches) == 0 && !filepath.IsAbs(pattern) {
+			if matches, err = filepath.Glob(filepath.Join(homeDir, pattern)); err != nil {
+				return nil, lnkerror.WithPath(lnkerror.ErrInvalidPattern, pattern)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrNoMatch, pattern, "check the pattern and that the files exist")
+		}
+
+		kept := 0
+		for _, match := range matches {
+			abs, err := filepath.Abs(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get absolute path for %s: %w", match, err)
+			}
+			info, err := os.Stat(abs)
+			ignored, ierr := fm.ignoredMatch(abs, err == nil && info.IsDir(), rules)
+			if ierr != nil {
+				return nil, ierr
+			}
+			if ignored {
+				fm.skipped(match, IgnoredKind)
+				continue
+			}
+			add(match)
+			kept++
+		}
+		if kept == 0 {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrNoMatch, pattern, "every match is left out by an ignore file (.lnkignore)")
+		}
+	}
+
+	return expanded, nil
+}
+
+// PreviewEntry describes one file a previewed add would manage: its absolute
+// Source path, its path relative to $HOME as recorded in the index, and the
+// Destination it would be moved to inside the repository.
+type PreviewEntry struct {
+	Source       string
+	RelativePath string
+	Destination  string
+}
+
 // PreviewAdd simulates an add operation and returns files that would be affected.
 func (fm *Manager) PreviewAdd(paths []string, recursive bool) ([]string, error) {
+	entries, err := fm.PreviewAddEntries(paths, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, entry.Source)
+	}
+
+	return files, nil
+}
+
+// PreviewAddEntries is PreviewAdd with the computed repository destination
+// of each file, honouring the Manager's host scope.
+func (fm *Manager) PreviewAddEntries(paths []string, recursive bool) ([]PreviewEntry, error) {
+	if err := fm.validateInto(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateChmod(); err != nil {
+		return nil, err
+	}
+
 	var allFiles []string
 
 	for _, path := range paths {
@@ -346,13 +899,31 @@ func (fm *Manager) PreviewAdd(paths []string, recursive bool) ([]string, error)
 		}
 	}
 
-	var validFiles []string
+	locations, err := fm.restoredLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []PreviewEntry
 	for _, filePath := range allFiles {
 		if err := fm.fs.ValidateFileForAdd(filePath); err != nil {
 			return nil, fmt.Errorf("validation failed for %s: %w", filePath, err)
 		}
+		if err := fm.checkNotHome(filePath, false); err != nil {
+			return nil, err
+		}
+		if err := fm.checkOutsideRepo(filePath); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat path %s: %w", filePath, err)
+		}
+		if err := fm.checkFileSizes(filePath, info); err != nil {
+			return nil, err
+		}
 
-		relativePath, err := fs.GetRelativePath(filePath)
+		relativePath, err := fm.fs.RelativePath(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get relative path for %s: %w", filePath, err)
 		}
@@ -362,66 +933,190 @@ func (fm *Manager) PreviewAdd(paths []string, recursive bool) ([]string, error)
 			return nil, fmt.Errorf("failed to get managed items: %w", err)
 		}
 		if slices.Contains(managedItems, relativePath) {
-			return nil, lnkerror.WithPath(lnkerror.ErrAlreadyManaged, relativePath)
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrAlreadyManaged, relativePath, alreadyManagedSuggestion)
 		}
+		if err := checkCollision(locations, relativePath); err != nil {
+			return nil, err
+		}
+		locations.Add(tracker.ScopedEntry{Host: fm.host, Entry: tracker.Entry{Path: relativePath}})
 
-		validFiles = append(validFiles, filePath)
+		entries = append(entries, PreviewEntry{
+			Source:       filePath,
+			RelativePath: relativePath,
+			Destination:  fm.tracker.StoragePath(fm.newEntry(filePath, relativePath)),
+		})
 	}
 
-	return validFiles, nil
+	return entries, nil
 }
 
 // Remove removes a symlink and restores the original file or directory.
-func (fm *Manager) Remove(filePath string) error {
+// Copy-managed files are untracked and their repository copy deleted; the
+// original in $HOME is left as it is. A managed item whose symlink was
+// already deleted is untracked all the same: see removeMissing.
+func (fm *Manager) Remove(filePath string) (*ManagedFile, error) {
+	return fm.emitRemoved(fm.remove(filePath))
+}
+
+func (fm *Manager) remove(filePath string) (*ManagedFile, error) {
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	if err := fm.fs.ValidateSymlinkForRemove(absPath, fm.repoPath); err != nil {
-		return err
+	if relativePath, err := fm.managedPath(absPath); err == nil {
+		entry, managed, err := fm.tracker.GetEntry(relativePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+		if managed && entry.Copy {
+			if err := fm.removeCopy(entry); err != nil {
+				return nil, err
+			}
+			return fm.removedFile(absPath, entry, false), nil
+		}
 	}
 
-	relativePath, err := fs.GetRelativePath(absPath)
-	if err != nil {
-		return fmt.Errorf("failed to get relative path: %w", err)
+	if _, err := os.Lstat(absPath); os.IsNotExist(err) {
+		if relativePath, err := fm.managedPath(absPath); err == nil {
+			entry, managed, err := fm.tracker.GetEntry(relativePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get managed items: %w", err)
+			}
+			if managed {
+				return fm.removeMissing(absPath, entry)
+			}
+		}
 	}
 
-	managedItems, err := fm.tracker.GetManagedItems()
-	if err != nil {
-		return fmt.Errorf("failed to get managed items: %w", err)
+	if err := fm.fs.ValidateSymlinkForRemove(absPath, fm.repoPath); err != nil {
+		return nil, err
 	}
 
-	if !slices.Contains(managedItems, relativePath) {
-		return lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
+	relativePath, err := fm.managedPath(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative path: %w", err)
 	}
 
-	target, err := os.Readlink(absPath)
+	entry, managed, err := fm.tracker.GetEntry(relativePath)
 	if err != nil {
-		return fmt.Errorf("failed to read symlink: %w", err)
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	if !managed {
+		return nil, lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
 	}
 
-	if !filepath.IsAbs(target) {
-		target = filepath.Join(filepath.Dir(absPath), target)
+	// Only this scope's stored item moves back. A link to a host's version
+	// of the path belongs to that host, which overrides the common item.
+	target := fm.tracker.StoragePath(entry)
+	if fm.host == "" && !fm.fs.IsSymlinkTo(absPath, target) {
+		if host := fm.overridingHost(absPath, relativePath); host != "" {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrHostOverride, relativePath,
+				fmt.Sprintf("it links to the %s host's copy; pass --host %s to remove that one", host, host))
+		}
 	}
 
 	info, err := os.Stat(target)
 	if err != nil {
-		return fmt.Errorf("failed to stat target: %w", err)
+		return nil, fmt.Errorf("failed to stat target: %w", err)
 	}
 
 	if err := os.Remove(absPath); err != nil {
-		return fmt.Errorf("failed to remove symlink: %w", err)
+		return nil, fmt.Errorf("failed to remove symlink: %w", err)
 	}
 
 	if err := fm.tracker.RemoveManagedItem(relativePath); err != nil {
-		return fmt.Errorf("failed to update tracking file: %w", err)
+		return nil, fmt.Errorf("failed to update tracking file: %w", err)
+	}
+
+	gitPath := fm.tracker.GitPath(entry)
+	if err := fm.git.Remove(gitPath); err != nil {
+		return nil, err
 	}
 
-	gitPath := relativePath
-	if fm.host != "" {
-		gitPath = filepath.Join(fm.host+".lnk", relativePath)
+	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
+		return nil, err
 	}
+	if err := fm.unmarkSecret(gitPath); err != nil {
+		return nil, err
+	}
+
+	basename := filepath.Base(relativePath)
+	if err := fm.commit(fmt.Sprintf("lnk: removed %s", basename)); err != nil {
+		return nil, err
+	}
+
+	if err := fm.fs.Move(target, absPath, info); err != nil {
+		return nil, err
+	}
+	_ = fs.WriteXattrs(absPath, entry.Xattrs)
+
+	return fm.removedFile(absPath, entry, info.IsDir()), nil
+}
+
+// removeMissing untracks a managed item whose $HOME symlink is gone, so the
+// index no longer lists a file that is not there. The removal is committed
+// as for Remove; then, with SetRestore, the stored item is moved back to
+// absPath, and otherwise it is deleted from the repository and survives only
+// in git history. A stored item that is missing too is only untracked.
+func (fm *Manager) removeMissing(absPath string, entry tracker.Entry) (*ManagedFile, error) {
+	storagePath := fm.tracker.StoragePath(entry)
+	info, statErr := os.Lstat(storagePath)
+
+	if err := fm.tracker.RemoveManagedItem(entry.Path); err != nil {
+		return nil, fmt.Errorf("failed to update tracking file: %w", err)
+	}
+
+	gitPath := fm.tracker.GitPath(entry)
+	if statErr == nil {
+		if err := fm.git.Remove(gitPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
+		return nil, err
+	}
+	if err := fm.unmarkSecret(gitPath); err != nil {
+		return nil, err
+	}
+
+	basename := filepath.Base(entry.Path)
+	if err := fm.commit(fmt.Sprintf("lnk: removed %s", basename)); err != nil {
+		return nil, err
+	}
+
+	file := fm.removedFile(absPath, entry, statErr == nil && info.IsDir())
+	file.Missing = true
+	if statErr != nil {
+		return file, nil
+	}
+
+	if fm.restore {
+		if err := fm.fs.Move(storagePath, absPath, info); err != nil {
+			return nil, err
+		}
+		_ = fs.WriteXattrs(absPath, entry.Xattrs)
+		file.Restored = true
+		return file, nil
+	}
+
+	if err := os.RemoveAll(storagePath); err != nil {
+		return nil, fmt.Errorf("failed to remove repository copy: %w", err)
+	}
+	return file, nil
+}
+
+// removeCopy stops managing a copy-managed file: it is untracked, the removal
+// committed, and the repository copy deleted.
+func (fm *Manager) removeCopy(entry tracker.Entry) error {
+	relativePath := entry.Path
+	if err := fm.tracker.RemoveManagedItem(relativePath); err != nil {
+		return fmt.Errorf("failed to update tracking file: %w", err)
+	}
+
+	gitPath := fm.tracker.GitPath(entry)
 	if err := fm.git.Remove(gitPath); err != nil {
 		return err
 	}
@@ -429,95 +1124,282 @@ func (fm *Manager) Remove(filePath string) error {
 	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
 		return err
 	}
+	if err := fm.unmarkSecret(gitPath); err != nil {
+		return err
+	}
 
 	basename := filepath.Base(relativePath)
-	if err := fm.git.Commit(fmt.Sprintf("lnk: removed %s", basename)); err != nil {
+	if err := fm.commit(fmt.Sprintf("lnk: removed %s", basename)); err != nil {
 		return err
 	}
 
-	if err := fm.fs.Move(target, absPath, info); err != nil {
-		return err
+	if err := os.Remove(filepath.Join(fm.repoPath, gitPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove repository copy: %w", err)
 	}
 
 	return nil
 }
 
 // RemoveForce removes a file from lnk tracking even if the symlink no longer exists.
-func (fm *Manager) RemoveForce(filePath string) error {
+func (fm *Manager) RemoveForce(filePath string) (*ManagedFile, error) {
+	return fm.emitRemoved(fm.removeForce(filePath))
+}
+
+func (fm *Manager) removeForce(filePath string) (*ManagedFile, error) {
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	relativePath, err := fs.GetRelativePath(absPath)
+	relativePath, err := fm.managedPath(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to get relative path: %w", err)
+		return nil, fmt.Errorf("failed to get relative path: %w", err)
 	}
 
-	managedItems, err := fm.tracker.GetManagedItems()
+	entry, managed, err := fm.tracker.GetEntry(relativePath)
 	if err != nil {
-		return fmt.Errorf("failed to get managed items: %w", err)
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	if !slices.Contains(managedItems, relativePath) {
-		return lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
+	if !managed {
+		return nil, lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
 	}
 
-	// Remove symlink if it exists (ignore errors - it may already be gone)
-	_ = os.Remove(absPath)
+	// Remove symlink if it exists (ignore errors - it may already be gone).
+	// A copy-managed original is the user's file and is kept.
+	if !entry.Copy {
+		_ = os.Remove(absPath)
+	}
 
 	if err := fm.tracker.RemoveManagedItem(relativePath); err != nil {
-		return fmt.Errorf("failed to update tracking file: %w", err)
+		return nil, fmt.Errorf("failed to update tracking file: %w", err)
 	}
 
-	gitPath := relativePath
-	if fm.host != "" {
-		gitPath = filepath.Join(fm.host+".lnk", relativePath)
-	}
+	gitPath := fm.tracker.GitPath(entry)
 
 	// Remove from git (ignore errors - file may not be in git index)
 	_ = fm.git.Remove(gitPath)
 
 	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
-		return err
+		return nil, err
+	}
+	if err := fm.unmarkSecret(gitPath); err != nil {
+		return nil, err
 	}
 
 	basename := filepath.Base(relativePath)
-	if err := fm.git.Commit(fmt.Sprintf("lnk: force removed %s", basename)); err != nil {
-		return err
+	if err := fm.commit(fmt.Sprintf("lnk: force removed %s", basename)); err != nil {
+		return nil, err
 	}
 
 	// Try to delete the repository copy if it exists
 	repoFilePath := filepath.Join(fm.repoPath, gitPath)
-	if _, err := os.Stat(repoFilePath); err == nil {
+	isDir := false
+	if info, err := os.Stat(repoFilePath); err == nil {
+		isDir = info.IsDir()
 		if err := os.RemoveAll(repoFilePath); err != nil {
-			return fmt.Errorf("failed to remove repository copy: %w", err)
+			return nil, fmt.Errorf("failed to remove repository copy: %w", err)
 		}
 	}
 
-	return nil
+	return fm.removedFile(absPath, entry, isDir), nil
 }
 
-// WalkDirectory walks through a directory and returns all regular files.
+// RemoveKeep stops tracking the item at filePath without touching it: the
+// index entry goes and the stored copy is removed from git, but the $HOME
+// symlink (or copy-managed original) and the file in the repository stay.
+// The stored path is added to .git/info/exclude so a later push does not
+// commit it again. The symlink keeps working until the repository file is
+// deleted; after a pull on other machines, where git removes the stored file,
+// their symlink is left dangling.
+func (fm *Manager) RemoveKeep(filePath string) (*ManagedFile, error) {
+	return fm.emitRemoved(fm.removeKeep(filePath))
+}
+
+func (fm *Manager) removeKeep(filePath string) (*ManagedFile, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	relativePath, err := fm.managedPath(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative path: %w", err)
+	}
+
+	entry, managed, err := fm.tracker.GetEntry(relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	if !managed {
+		return nil, lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
+	}
+
+	if err := fm.tracker.RemoveManagedItem(relativePath); err != nil {
+		return nil, fmt.Errorf("failed to update tracking file: %w", err)
+	}
+
+	gitPath := fm.tracker.GitPath(entry)
+	if err := fm.git.Remove(gitPath); err != nil {
+		return nil, err
+	}
+	if err := fm.git.ExcludeLocally(gitPath); err != nil {
+		return nil, err
+	}
+
+	if err := fm.git.Add(fm.tracker.LnkFileName()); err != nil {
+		return nil, err
+	}
+	if err := fm.unmarkSecret(gitPath); err != nil {
+		return nil, err
+	}
+
+	basename := filepath.Base(relativePath)
+	if err := fm.commit(fmt.Sprintf("lnk: untracked %s", basename)); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fm.tracker.StoragePath(entry))
+	return fm.removedFile(absPath, entry, err == nil && info.IsDir()), nil
+}
+
+// emitRemoved reports the item a remove released, when it succeeded, and
+// passes its result on.
+func (fm *Manager) emitRemoved(file *ManagedFile, err error) (*ManagedFile, error) {
+	if err == nil {
+		fm.emit(event.FileRemoved, file.RelativePath)
+	}
+	return file, err
+}
+
+// removedFile describes an item Remove or RemoveForce released; Copy reports
+// how it was managed, which may differ from this manager's add mode.
+func (fm *Manager) removedFile(absPath string, entry tracker.Entry, isDir bool) *ManagedFile {
+	file := fm.managedFile(absPath, entry, isDir)
+	file.Copy = entry.Copy
+	return file
+}
+
+// ManagedPath returns where the managed item at filePath is stored in the
+// repository, failing with ErrNotManaged if it is not tracked in this scope.
+func (fm *Manager) ManagedPath(filePath string) (string, error) {
+	_, storagePath, err := fm.LookupManaged(filePath)
+	return storagePath, err
+}
+
+// LookupManaged is ManagedPath that also returns the item's tracking entry,
+// so callers can tell copy-managed items apart.
+func (fm *Manager) LookupManaged(filePath string) (tracker.Entry, string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return tracker.Entry{}, "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	relativePath, err := fm.managedPath(absPath)
+	if err != nil {
+		return tracker.Entry{}, "", fmt.Errorf("failed to get relative path: %w", err)
+	}
+
+	entry, managed, err := fm.tracker.GetEntry(relativePath)
+	if err != nil {
+		return tracker.Entry{}, "", fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	if !managed {
+		return tracker.Entry{}, "", lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
+	}
+
+	return entry, fm.tracker.StoragePath(entry), nil
+}
+
+// WalkDirectory walks through a directory and returns all regular files and
+// symlinks. Sockets, named pipes, devices and symlinks to them are left out
+// and passed to the SkipHandler, so a recursive add never tries to move one.
+// The lnk repository is skipped when dirPath contains it, and so are
+// directories below dirPath named on the skip list (SetSkipDirs), such as the
+// .git of a checkout inside ~/.config, reported as ExcludedDirKind. Entries
+// the ignore files match (see ignoreRulesFor) are reported as IgnoredKind;
+// an ignored directory is not entered, so a negation cannot bring back what
+// lies below it. Empty directories are reported as EmptyDirKind; one that
+// holds only a KeepFileName marker is kept through the marker.
 func (fm *Manager) WalkDirectory(dirPath string) ([]string, error) {
+	return fm.walkDirectory(dirPath, nil)
+}
+
+// walkDirectory is WalkDirectory that, when onError is set, hands it each
+// entry that cannot be read and carries on with the rest.
+func (fm *Manager) walkDirectory(dirPath string, onError func(path string, err error)) ([]string, error) {
+	if err := fm.checkNotHome(dirPath, true); err != nil {
+		return nil, err
+	}
+
 	var files []string
+	repoPath := filepath.Clean(fm.repoPath)
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+	rootRules, err := fm.ignoreRulesFor(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]ignoreRules{dirPath: rootRules}
+
+	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			if onError == nil {
+				return err
+			}
+			onError(path, err)
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
 		if info.IsDir() {
+			if path == repoPath && path != dirPath {
+				return filepath.SkipDir
+			}
+			if path != dirPath && slices.Contains(fm.skipDirs, info.Name()) {
+				fm.skipped(path, ExcludedDirKind)
+				return filepath.SkipDir
+			}
+			if path != dirPath {
+				parent := rules[filepath.Dir(path)]
+				if parent.ignored(path, true) {
+					fm.skipped(path, IgnoredKind)
+					return filepath.SkipDir
+				}
+				own, err := readIgnore(filepath.Join(path, IgnoreFileName), path)
+				if err != nil {
+					return err
+				}
+				rules[path] = append(slices.Clip(parent), own...)
+				if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+					fm.skipped(path, EmptyDirKind)
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		if rules[filepath.Dir(path)].ignored(path, false) {
+			fm.skipped(path, IgnoredKind)
 			return nil
 		}
 
 		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(path); err == nil && !target.Mode().IsRegular() && !target.IsDir() {
+				fm.skipped(path, "symlink to a "+fs.TypeName(target.Mode()))
+				return nil
+			}
 			files = append(files, path)
 			return nil
 		}
 
 		if info.Mode().IsRegular() {
 			files = append(files, path)
+		} else {
+			fm.skipped(path, fs.TypeName(info.Mode()))
 		}
 
 		return nil
@@ -529,3 +1411,10 @@ func (fm *Manager) WalkDirectory(dirPath string) ([]string, error) {
 
 	return files, nil
 }
+
+// skipped reports a special file a walk left out to the SkipHandler, if any.
+func (fm *Manager) skipped(path, kind string) {
+	if fm.skip != nil {
+		fm.skip(path, kind)
+	}
+}
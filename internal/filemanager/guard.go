@@ -0,0 +1,156 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// DefaultMaxFileSize is the largest file add accepts without force when
+// add.max_size is not configured.
+const DefaultMaxFileSize = 10 << 20
+
+// SetMaxFileSize changes the size above which add refuses a file unless
+// forced. A size of zero or less keeps DefaultMaxFileSize.
+func (fm *Manager) SetMaxFileSize(size int64) {
+	if size <= 0 {
+		size = DefaultMaxFileSize
+	}
+	fm.maxSize = size
+}
+
+// SetForce makes add accept files over the size limit and binary files, and
+// replace an untracked file or directory occupying the storage path.
+func (fm *Manager) SetForce(force bool) {
+	fm.force = force
+}
+
+// SetAllowHome lets recursive adds walk the home directory, or a directory
+// holding it, instead of failing with ErrHomeDirectory.
+func (fm *Manager) SetAllowHome(enabled bool) {
+	fm.allHome = enabled
+}
+
+// checkFileSizes fails with ErrFileTooLarge or ErrBinaryFile for the first
+// file at absPath — the path itself, or any file below it for a directory —
+// that is over the size limit or looks binary, so a stray cache or build
+// output never lands in the history. Forced adds skip the check.
+func (fm *Manager) checkFileSizes(absPath string, info os.FileInfo) error {
+	if fm.force {
+		return nil
+	}
+	if !info.IsDir() {
+		return fm.checkFileSize(absPath, info)
+	}
+
+	return filepath.Walk(absPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk directory %s: %w", absPath, err)
+		}
+		return fm.checkFileSize(path, info)
+	})
+}
+
+// checkFileSize applies the size and binary checks to one regular file.
+func (fm *Manager) checkFileSize(path string, info os.FileInfo) error {
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	if info.Size() > fm.maxSize {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrFileTooLarge, path,
+			fmt.Sprintf("it is %s, over the %s limit; pass --force to add it anyway or raise add.max_size", fs.FormatSize(info.Size()), fs.FormatSize(fm.maxSize)))
+	}
+
+	binary, err := fm.fs.IsBinary(path)
+	if err != nil {
+		return lnkerror.WithPath(fs.ErrFileCheck, path)
+	}
+	if binary {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrBinaryFile, path,
+			fmt.Sprintf("it is %s of binary data; pass --force to add it anyway", fs.FormatSize(info.Size())))
+	}
+	return nil
+}
+
+// checkReplaceable fails with ErrStorageOccupied unless destPath can be
+// deleted for a forced add: it must not be lnk's own bookkeeping (.git, an
+// index file, a host storage directory) or hold, or lie inside, the stored
+// copy of an item any configuration tracks.
+func (fm *Manager) checkReplaceable(destPath string) error {
+	rel, err := filepath.Rel(fm.repoPath, destPath)
+	if err != nil {
+		return err
+	}
+	first := strings.Split(filepath.ToSlash(rel), "/")[0]
+	_, index := fm.tracker.IndexHost(rel)
+	index = index || isWithin(filepath.Join(fm.repoPath, fm.tracker.ForHost("").LnkFileName()), destPath)
+	if first == ".git" || first == ".lnk" || strings.HasPrefix(first, ".lnk.") || (strings.HasSuffix(first, ".lnk") && rel == first) || index {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, destPath, "lnk keeps its own files there; --force does not replace them")
+	}
+
+	hosts, err := fm.tracker.Hosts()
+	if err != nil {
+		return err
+	}
+	for _, host := range append([]string{""}, hosts...) {
+		t := fm.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return fmt.Errorf("failed to get managed items: %w", err)
+		}
+		for _, entry := range entries {
+			stored := t.StoragePath(entry)
+			if isWithin(stored, destPath) || isWithin(destPath, stored) {
+				return lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, destPath, "~/"+entry.Path+" is stored there; --force only replaces files no lnk index tracks")
+			}
+		}
+	}
+	return nil
+}
+
+// restoredLocations indexes the items restored together with this
+// configuration's: the common configuration and this host, or for the common
+// configuration, every host.
+func (fm *Manager) restoredLocations() (*tracker.Locations, error) {
+	scopes := []string{""}
+	if fm.host != "" {
+		scopes = append(scopes, fm.host)
+	} else {
+		hosts, err := fm.tracker.Hosts()
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, hosts...)
+	}
+
+	entries, err := fm.tracker.ScopedEntries(scopes...)
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return tracker.NewLocations(homeDir, entries), nil
+}
+
+// checkCollision fails with ErrSymlinkCollision when relativePath names the
+// same $HOME location as another item in locations under a different path,
+// so restoring both would replace one symlink with the other.
+func checkCollision(locations *tracker.Locations, relativePath string) error {
+	other, ok := locations.Collides(relativePath)
+	if !ok {
+		return nil
+	}
+	scope := ""
+	if other.Host != "" {
+		scope = " (host: " + other.Host + ")"
+	}
+	return lnkerror.WithPathAndSuggestion(lnkerror.ErrSymlinkCollision, "~/"+relativePath,
+		fmt.Sprintf("~/%s%s is the same file; it is already managed under that path", other.Path, scope))
+}
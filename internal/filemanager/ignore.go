@@ -0,0 +1,212 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the name of the ignore files recursive adds honour: one
+// at the repository root and one in any directory of the tree being added.
+const IgnoreFileName = ".lnkignore"
+
+// IgnoredKind is the kind a SkipHandler hears for a file or directory a walk
+// left out because an ignore file matched it.
+const IgnoredKind = "ignored"
+
+// ignoreRule is one pattern line of an ignore file, in gitignore syntax.
+type ignoreRule struct {
+	base     string   // Directory the pattern is relative to
+	segments []string // Pattern split at "/"
+	negate   bool     // "!pattern": re-include what an earlier rule ignored
+	dirOnly  bool     // "pattern/": match directories only
+	anchored bool     // Pattern contains a "/": match relative to base, not at any depth
+}
+
+// ignoreRules are the rules in effect for a directory, lowest precedence
+// first: the last rule that matches a path decides.
+type ignoreRules []ignoreRule
+
+// ignored reports whether the rules leave out path.
+func (rules ignoreRules) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.matches(path, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule's pattern names path.
+func (r ignoreRule) matches(target string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(r.base, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if !r.anchored {
+		parts = parts[len(parts)-1:]
+	}
+	return matchSegments(r.segments, parts)
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// stands for any number of segments: none or more in front and in the middle,
+// one or more at the end.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			// A trailing "dir/**" matches what is inside dir, not dir itself.
+			return len(parts) > 0
+		}
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// parseIgnore reads gitignore-style rules relative to base from content:
+// blank lines and "#" comments are skipped, "!" negates, a trailing "/"
+// matches directories only, and a "/" elsewhere anchors the pattern to base.
+// "\#" and "\!" start a pattern with a literal "#" or "!".
+func parseIgnore(content, base string) ignoreRules {
+	var rules ignoreRules
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if trimmed, ok := strings.CutSuffix(line, "/"); ok {
+			rule.dirOnly = true
+			line = trimmed
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// readIgnore returns the rules in the ignore file at path, relative to base;
+// a missing file has none.
+func readIgnore(path, base string) (ignoreRules, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return parseIgnore(string(content), base), nil
+}
+
+// SetGlobalIgnore sets the user's own ignore file, e.g.
+// $XDG_CONFIG_HOME/lnk/ignore. Its patterns are relative to $HOME and have
+// the lowest precedence; "" leaves it out.
+func (fm *Manager) SetGlobalIgnore(path string) {
+	fm.globalIgnore = path
+}
+
+// ignoreRulesFor returns the rules in effect for entries of dir: the global
+// ignore file, the repository's .lnkignore (both relative to $HOME), then
+// the .lnkignore of every directory from $HOME down to dir, so nearer files
+// override further ones.
+func (fm *Manager) ignoreRulesFor(dir string) (ignoreRules, error) {
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	var rules ignoreRules
+	sources := []string{filepath.Join(fm.repoPath, IgnoreFileName)}
+	if fm.globalIgnore != "" {
+		sources = append([]string{fm.globalIgnore}, sources...)
+	}
+	for _, source := range sources {
+		found, err := readIgnore(source, homeDir)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, found...)
+	}
+
+	var dirs []string
+	if isWithin(dir, homeDir) {
+		for d := dir; ; d = filepath.Dir(d) {
+			dirs = append(dirs, d)
+			if d == homeDir || filepath.Dir(d) == d {
+				break
+			}
+		}
+	} else {
+		dirs = []string{dir}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		found, err := readIgnore(filepath.Join(dirs[i], IgnoreFileName), dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, found...)
+	}
+	return rules, nil
+}
+
+// ignoredMatch reports whether the ignore files leave out path, or one of the
+// directories above it up to $HOME, the way a recursive walk from $HOME would.
+// cache holds the rules already read, by directory.
+func (fm *Manager) ignoredMatch(path string, isDir bool, cache map[string]ignoreRules) (bool, error) {
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return false, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	for p, dir := path, isDir; ; p, dir = filepath.Dir(p), true {
+		parent := filepath.Dir(p)
+		rules, ok := cache[parent]
+		if !ok {
+			if rules, err = fm.ignoreRulesFor(parent); err != nil {
+				return false, err
+			}
+			cache[parent] = rules
+		}
+		if rules.ignored(p, dir) {
+			return true, nil
+		}
+		if parent == homeDir || parent == p || !isWithin(parent, homeDir) {
+			return false, nil
+		}
+	}
+}
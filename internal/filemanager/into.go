@@ -0,0 +1,129 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// SetInto makes adds store each item as <dir>/<basename> inside the storage
+// root instead of at its home-relative path. The symlink still replaces the
+// original, and the chosen location is recorded in the entry's Repo field.
+// An empty dir restores the default layout.
+func (fm *Manager) SetInto(dir string) {
+	fm.into = dir
+}
+
+// SetHostSuffix makes host adds store each item at the repository root as
+// <stored path>.<host>, e.g. .bashrc.work, instead of inside <host>.lnk/. The
+// layout is recorded per entry, so restores find either kind. Adds to the
+// common configuration are not affected.
+func (fm *Manager) SetHostSuffix(enabled bool) {
+	fm.suffix = enabled
+}
+
+// SetAbsoluteSymlink makes adds link each item to the absolute path of its
+// stored copy instead of a relative one. The mode is recorded per entry, so
+// restores recreate the same kind of link. Copy mode is not affected.
+func (fm *Manager) SetAbsoluteSymlink(enabled bool) {
+	fm.absolute = enabled
+}
+
+// SetComment records comment as the note of every entry adds create.
+func (fm *Manager) SetComment(comment string) {
+	fm.comment = comment
+}
+
+// validateInto fails with ErrInvalidInto unless the SetInto directory is a
+// relative path that stays inside the storage root and clear of .git and the
+// host storage directories.
+func (fm *Manager) validateInto() error {
+	if fm.into == "" {
+		return nil
+	}
+
+	dir := filepath.Clean(fm.into)
+	first, _, _ := strings.Cut(filepath.ToSlash(dir), "/")
+	if filepath.IsAbs(dir) || dir == "." || first == ".." {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidInto, fm.into, "give a directory relative to the repository root, such as 'shell'")
+	}
+	if first == ".git" || strings.HasPrefix(first, ".lnk") || strings.HasSuffix(first, ".lnk") {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidInto, fm.into, "that directory is reserved for lnk's own files; pick another name")
+	}
+	return nil
+}
+
+// storedPath returns where the item at relativePath goes inside the storage
+// root: its home-relative path, or <into>/<basename> with SetInto.
+func (fm *Manager) storedPath(relativePath string) string {
+	if fm.into == "" {
+		return relativePath
+	}
+	return filepath.Join(filepath.Clean(fm.into), filepath.Base(relativePath))
+}
+
+// newEntry is the tracking entry for the item at absPath, added at
+// relativePath with the manager's current modes.
+func (fm *Manager) newEntry(absPath, relativePath string) tracker.Entry {
+	entry := tracker.Entry{Path: relativePath, Copy: fm.copy, Secret: fm.secret, Mode: fm.chmod, Suffix: fm.suffix && fm.host != "", XDG: fm.fs.IsConfigPath(absPath), Absolute: fm.absolute && !fm.copy, Comment: fm.comment}
+	if stored := fm.storedPath(relativePath); stored != relativePath {
+		entry.Repo = stored
+	}
+	return entry
+}
+
+// managedPath is the relative path an item at absPath is looked up by. It is
+// fs.RelativePath, except for an item under a custom $XDG_CONFIG_HOME that is
+// tracked only under its plain $HOME-relative form, the way such items were
+// recorded before they were mapped onto .config/.
+func (fm *Manager) managedPath(absPath string) (string, error) {
+	relativePath, err := fm.fs.RelativePath(absPath)
+	if err != nil || !fm.fs.IsConfigPath(absPath) {
+		return relativePath, err
+	}
+	if _, managed, err := fm.tracker.GetEntry(relativePath); err != nil || managed {
+		return relativePath, nil
+	}
+	legacy, err := fm.fs.HomeRelativePath(absPath)
+	if err != nil {
+		return relativePath, nil
+	}
+	if _, managed, err := fm.tracker.GetEntry(legacy); err == nil && managed {
+		return legacy, nil
+	}
+	return relativePath, nil
+}
+
+// checkStoredPath fails with ErrStorageOccupied when another tracked item is
+// already stored where entry would be, which --into makes possible for files
+// that share a basename. Items of other configurations count when they hold
+// or lie inside that location, which the suffix layout makes possible: a
+// host's .bashrc.work sits among the common configuration's files.
+func (fm *Manager) checkStoredPath(entry tracker.Entry) error {
+	hosts, err := fm.tracker.Hosts()
+	if err != nil {
+		return err
+	}
+
+	stored := fm.tracker.StoragePath(entry)
+	for _, host := range append([]string{""}, hosts...) {
+		t := fm.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return err
+		}
+		for _, other := range entries {
+			otherStored := t.StoragePath(other)
+			clash := otherStored == stored
+			if host != fm.host {
+				clash = isWithin(otherStored, stored) || isWithin(stored, otherStored)
+			}
+			if clash {
+				return lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, stored, "~/"+other.Path+" is stored there; pick another --into directory")
+			}
+		}
+	}
+	return nil
+}
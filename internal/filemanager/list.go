@@ -0,0 +1,49 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+)
+
+// ListAllHosts returns the managed items of the common configuration, keyed
+// by "", and of every host with a tracking file in the repository, keyed by
+// the host name. A host whose tracking file lists nothing maps to an empty
+// slice, so every configuration found is in the map.
+func (fm *Manager) ListAllHosts() (map[string][]ManagedFile, error) {
+	if err := fm.requireRepository(); err != nil {
+		return nil, err
+	}
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	hosts, err := fm.tracker.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string][]ManagedFile, len(hosts)+1)
+	for _, host := range append([]string{""}, hosts...) {
+		t := fm.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+
+		files := make([]ManagedFile, 0, len(entries))
+		for _, entry := range entries {
+			info, err := os.Stat(t.StoragePath(entry))
+			files = append(files, ManagedFile{
+				Path:         fm.fs.HomePath(homeDir, entry.Path, entry.XDG),
+				RelativePath: entry.Path,
+				RepoPath:     t.StoragePath(entry),
+				Host:         host,
+				IsDirectory:  err == nil && info.IsDir(),
+				Copy:         entry.Copy,
+			})
+		}
+		all[host] = files
+	}
+	return all, nil
+}
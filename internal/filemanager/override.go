@@ -0,0 +1,77 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+)
+
+// takeOverCommon prepares a host add of relativePath when absPath is still
+// the symlink to the common configuration's item there: the host version is
+// meant to override the common one, so the link is replaced with a copy of
+// the common content, which the add then stores for the host. Moving the
+// link itself would leave the host with a symlink instead of a file.
+// The returned function puts the common symlink back, for rollbacks; it does
+// nothing when there was no common item to take over.
+func (fm *Manager) takeOverCommon(absPath, relativePath string) (func() error, error) {
+	noop := func() error { return nil }
+	if fm.host == "" {
+		return noop, nil
+	}
+	common := fm.tracker.ForHost("")
+	entry, ok, err := common.GetEntry(relativePath)
+	if err != nil || !ok || entry.Copy {
+		return noop, err
+	}
+	commonItem := common.StoragePath(entry)
+	if !fm.fs.IsSymlinkTo(absPath, commonItem) {
+		return noop, nil
+	}
+
+	relink := func() error {
+		if err := os.RemoveAll(absPath); err != nil {
+			return err
+		}
+		return fm.fs.Link(commonItem, absPath, entry.Absolute)
+	}
+	if err := os.Remove(absPath); err != nil {
+		return nil, fmt.Errorf("failed to remove symlink %s: %w", absPath, err)
+	}
+	if err := fm.fs.CopyTree(commonItem, absPath); err != nil {
+		_ = relink()
+		return nil, fmt.Errorf("failed to copy %s for the host: %w", commonItem, err)
+	}
+	return relink, nil
+}
+
+// thenRelink runs rollback and then relink, which puts back the common
+// symlink takeOverCommon replaced. rollback's error wins.
+func thenRelink(rollback, relink func() error) func() error {
+	return func() error {
+		err := rollback()
+		if relinkErr := relink(); err == nil {
+			err = relinkErr
+		}
+		return err
+	}
+}
+
+// overridingHost returns the host whose version of relativePath absPath
+// links to, overriding the common item there, or "" when it links to no
+// host's version.
+func (fm *Manager) overridingHost(absPath, relativePath string) string {
+	hosts, err := fm.tracker.Hosts()
+	if err != nil {
+		return ""
+	}
+	for _, host := range hosts {
+		t := fm.tracker.ForHost(host)
+		entry, ok, err := t.GetEntry(relativePath)
+		if err != nil || !ok || entry.Copy {
+			continue
+		}
+		if fm.fs.IsSymlinkTo(absPath, t.StoragePath(entry)) {
+			return host
+		}
+	}
+	return ""
+}
@@ -0,0 +1,294 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/config"
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// RenameHostResult reports what RenameHost changed.
+type RenameHostResult struct {
+	Items    []string // Index entries of the renamed host, relative to $HOME
+	Relinked []string // Entries whose $HOME symlink was pointed at the new storage
+	Config   bool     // Whether the repository's config.toml named the old host and was updated
+}
+
+// RenameHost renames the host configuration oldHost to newHost: the
+// .lnk.<old> index becomes .lnk.<new>, the <old>.lnk storage directory
+// becomes <new>.lnk, and everything that named them follows: $HOME symlinks
+// into the old storage, git-crypt lines in .gitattributes, .git/info/exclude
+// entries for leftovers, and a host = "<old>" in the repository's
+// config.toml. It all goes into one "lnk: renamed host <old> to <new>"
+// commit. Both names must already be valid; newHost must not have a
+// configuration yet, and oldHost's files must have no uncommitted changes.
+func (fm *Manager) RenameHost(oldHost, newHost string) (*RenameHostResult, error) {
+	if !fm.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+	if oldHost == "" || newHost == "" {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrInvalidHost, "the common configuration has no host name to rename")
+	}
+
+	from, to := fm.tracker.ForHost(oldHost), fm.tracker.ForHost(newHost)
+	oldIndex, newIndex := from.LnkFileName(), to.LnkFileName()
+	oldRoot, newRoot := oldHost+".lnk", newHost+".lnk"
+
+	if _, err := os.Stat(filepath.Join(fm.repoPath, oldIndex)); err != nil {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrHostNotFound, oldHost, "run 'lnk list --all' to see the hosts in the repository")
+	}
+	for _, path := range []string{newIndex, newRoot} {
+		if _, err := os.Lstat(filepath.Join(fm.repoPath, path)); err == nil {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrHostExists, newHost, "pick another name, or remove that host's files first")
+		}
+	}
+
+	entries, err := from.GetEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	// Items in the suffix layout sit at the repository root as
+	// <path>.<oldHost> and are renamed one by one.
+	var suffixed []tracker.Entry
+	for _, entry := range entries {
+		if !entry.Suffix {
+			continue
+		}
+		if _, err := os.Lstat(to.StoragePath(entry)); err == nil {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, to.StoragePath(entry), "move that file away, or pick another host name")
+		}
+		if _, err := os.Lstat(from.StoragePath(entry)); err == nil {
+			suffixed = append(suffixed, entry)
+		}
+	}
+
+	hasStorage := false
+	if info, err := os.Stat(from.HostStoragePath()); err == nil && info.IsDir() {
+		hasStorage = true
+	}
+	paths := []string{oldIndex}
+	if hasStorage {
+		paths = append(paths, oldRoot)
+	}
+	for _, entry := range suffixed {
+		paths = append(paths, from.GitPath(entry))
+	}
+	dirty, err := fm.git.HasChanges(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrUncommitted, oldHost, "run 'lnk push' first so the rename does not commit unrelated edits")
+	}
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	// Find the links into the old storage before it moves away under them.
+	result := &RenameHostResult{}
+	var relink []tracker.Entry
+	for _, entry := range entries {
+		result.Items = append(result.Items, entry.Path)
+		if !entry.Copy && fm.fs.IsSymlinkTo(fm.fs.HomePath(homeDir, entry.Path, entry.XDG), from.StoragePath(entry)) {
+			relink = append(relink, entry)
+		}
+	}
+
+	// Every step records how to undo it, so a failure anywhere up to the
+	// commit leaves the old host exactly as it was.
+	var rollbackActions []func() error
+	fail := func(err error) (*RenameHostResult, error) {
+		fm.RollbackAll(rollbackActions)
+		return nil, err
+	}
+	for _, path := range []string{gitattributesFile, config.FileName, filepath.Join(".git", "info", "exclude")} {
+		restore, err := snapshotFile(filepath.Join(fm.repoPath, path))
+		if err != nil {
+			return nil, err
+		}
+		rollbackActions = append(rollbackActions, restore)
+	}
+	commitPaths := slices.Clone(paths)
+	rollbackActions = append(rollbackActions, func() error { return fm.git.Unstage(commitPaths...) })
+
+	for _, path := range paths {
+		if err := fm.git.Remove(path); err != nil {
+			return fail(err)
+		}
+	}
+	if err := renameBack(&rollbackActions, filepath.Join(fm.repoPath, oldIndex), filepath.Join(fm.repoPath, newIndex)); err != nil {
+		return fail(fmt.Errorf("failed to rename %s: %w", oldIndex, err))
+	}
+	if hasStorage {
+		if err := renameBack(&rollbackActions, from.HostStoragePath(), to.HostStoragePath()); err != nil {
+			return fail(fmt.Errorf("failed to rename %s: %w", oldRoot, err))
+		}
+		commitPaths = append(commitPaths, newRoot)
+		if err := fm.git.Add(newRoot); err != nil {
+			return fail(err)
+		}
+	}
+	commitPaths = append(commitPaths, newIndex)
+	if err := fm.git.Add(newIndex); err != nil {
+		return fail(err)
+	}
+	secretMoved := false
+	for _, entry := range suffixed {
+		oldPath, newPath := from.GitPath(entry), to.GitPath(entry)
+		if entry.Secret {
+			if err := fm.renameSecret(oldPath, newPath, from.StoragePath(entry)); err != nil {
+				return fail(err)
+			}
+			if !secretMoved {
+				commitPaths = append(commitPaths, gitattributesFile)
+			}
+			secretMoved = true
+		}
+		if err := renameBack(&rollbackActions, from.StoragePath(entry), to.StoragePath(entry)); err != nil {
+			return fail(fmt.Errorf("failed to rename %s: %w", oldPath, err))
+		}
+		commitPaths = append(commitPaths, newPath)
+		if err := fm.git.Add(newPath); err != nil {
+			return fail(err)
+		}
+	}
+
+	for _, entry := range relink {
+		homePath := fm.fs.HomePath(homeDir, entry.Path, entry.XDG)
+		dest, err := os.Readlink(homePath)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read symlink %s: %w", homePath, err))
+		}
+		if err := os.Remove(homePath); err != nil {
+			return fail(fmt.Errorf("failed to remove symlink %s: %w", homePath, err))
+		}
+		rollbackActions = append(rollbackActions, func() error {
+			_ = os.Remove(homePath)
+			return os.Symlink(dest, homePath)
+		})
+		if err := fm.fs.Link(to.StoragePath(entry), homePath, entry.Absolute); err != nil {
+			return fail(err)
+		}
+		result.Relinked = append(result.Relinked, entry.Path)
+	}
+
+	changed, err := fm.renameAttributes(oldRoot, newRoot)
+	if err != nil {
+		return fail(err)
+	}
+	if changed && !secretMoved {
+		commitPaths = append(commitPaths, gitattributesFile)
+	}
+	if err := fm.git.RenameExcluded(oldRoot, newRoot); err != nil {
+		return fail(err)
+	}
+
+	configPath := filepath.Join(fm.repoPath, config.FileName)
+	if cfg, err := config.Load(configPath); err == nil && cfg.Host == oldHost {
+		if err := config.Set(configPath, "host", newHost); err != nil {
+			return fail(err)
+		}
+		commitPaths = append(commitPaths, config.FileName)
+		if err := fm.git.Add(config.FileName); err != nil {
+			return fail(err)
+		}
+		result.Config = true
+	}
+
+	if err := fm.git.CommitPaths(fmt.Sprintf("lnk: renamed host %s to %s", oldHost, newHost), commitPaths); err != nil {
+		return fail(err)
+	}
+
+	return result, nil
+}
+
+// renameBack renames oldPath to newPath and records the rename back in
+// rollbackActions.
+func renameBack(rollbackActions *[]func() error, oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	*rollbackActions = append(*rollbackActions, func() error { return os.Rename(newPath, oldPath) })
+	return nil
+}
+
+// snapshotFile reads path and returns a function that puts that content
+// back, or removes the file if it did not exist.
+func snapshotFile(path string) (func() error, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if os.IsNotExist(err) {
+		return func() error {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}, nil
+	}
+	return func() error { return os.WriteFile(path, content, 0644) }, nil
+}
+
+// renameSecret moves the git-crypt line of the secret item stored at oldPath,
+// a file or directory at stored, to newPath, staging .gitattributes. It runs
+// before newPath is staged, so git-crypt's filter applies to it.
+func (fm *Manager) renameSecret(oldPath, newPath, stored string) error {
+	info, err := os.Stat(stored)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", stored, err)
+	}
+	if err := fm.unmarkSecret(oldPath); err != nil {
+		return err
+	}
+	_, err = fm.markSecret([]string{attributePattern(newPath, info.IsDir())})
+	return err
+}
+
+// renameAttributes points the git-crypt lines for items below the oldRoot
+// storage directory at newRoot instead and stages .gitattributes when that
+// changed it.
+func (fm *Manager) renameAttributes(oldRoot, newRoot string) (bool, error) {
+	path := filepath.Join(fm.repoPath, gitattributesFile)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", gitattributesFile, err)
+	}
+
+	oldPrefix := "/" + escapeGlob(oldRoot) + "/"
+	newPrefix := "/" + escapeGlob(newRoot) + "/"
+	lines := attributeLines(content)
+	changed := false
+	for i, line := range lines {
+		pattern, ok := strings.CutSuffix(line, " "+gitCryptAttributes)
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(pattern); err == nil {
+			pattern = unquoted
+		}
+		if rest, ok := strings.CutPrefix(pattern, oldPrefix); ok {
+			lines[i] = quoteAttribute(newPrefix+rest) + " " + gitCryptAttributes
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", gitattributesFile, err)
+	}
+	return true, fm.git.Add(gitattributesFile)
+}
@@ -0,0 +1,148 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// gitattributesFile is the attributes file at the repository root that
+// git-crypt reads its filter assignments from.
+const gitattributesFile = ".gitattributes"
+
+// gitCryptAttributes are the attributes that hand a path to git-crypt.
+const gitCryptAttributes = "filter=git-crypt diff=git-crypt"
+
+// SetSecret makes adds mark their items for git-crypt: each one gets a
+// filter=git-crypt line in .gitattributes, committed with it, and is tracked
+// as secret. lnk does no encryption itself; the repository must already be
+// set up with `git-crypt init`.
+func (fm *Manager) SetSecret(enabled bool) {
+	fm.secret = enabled
+}
+
+// requireGitCrypt fails with ErrNoGitCrypt when a secret add runs
+// in a repository git-crypt has not been initialized in, since the attributes
+// would then commit the files in plain text.
+func (fm *Manager) requireGitCrypt() error {
+	if !fm.secret || fm.git.IsGitCryptInitialized() {
+		return nil
+	}
+	return lnkerror.WithPathAndSuggestion(lnkerror.ErrNoGitCrypt, fm.repoPath, "run 'git-crypt init' in the repository first, or add without --secret")
+}
+
+// markSecret adds a git-crypt line for each pattern to .gitattributes and
+// stages it. It must run before the items themselves are staged, so git's
+// clean filter encrypts them. The returned rollback restores the previous
+// content.
+func (fm *Manager) markSecret(patterns []string) (func() error, error) {
+	path := filepath.Join(fm.repoPath, gitattributesFile)
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", gitattributesFile, err)
+	}
+	existed := err == nil
+
+	lines := attributeLines(original)
+	for _, pattern := range patterns {
+		line := pattern + " " + gitCryptAttributes
+		if !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+
+	rollback := func() error {
+		if !existed {
+			return os.Remove(path)
+		}
+		return os.WriteFile(path, original, 0644)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", gitattributesFile, err)
+	}
+	if err := fm.git.Add(gitattributesFile); err != nil {
+		_ = rollback()
+		return nil, err
+	}
+
+	return rollback, nil
+}
+
+// unmarkSecret drops the git-crypt lines markSecret wrote for gitPath and
+// stages .gitattributes if that changed it. Items that were never secret
+// leave the file alone.
+func (fm *Manager) unmarkSecret(gitPath string) error {
+	path := filepath.Join(fm.repoPath, gitattributesFile)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", gitattributesFile, err)
+	}
+
+	drop := []string{
+		attributePattern(gitPath, false) + " " + gitCryptAttributes,
+		attributePattern(gitPath, true) + " " + gitCryptAttributes,
+	}
+	lines := attributeLines(content)
+	kept := slices.DeleteFunc(slices.Clone(lines), func(line string) bool { return slices.Contains(drop, line) })
+	if len(kept) == len(lines) {
+		return nil
+	}
+
+	data := ""
+	if len(kept) > 0 {
+		data = strings.Join(kept, "\n") + "\n"
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", gitattributesFile, err)
+	}
+	return fm.git.Add(gitattributesFile)
+}
+
+// attributePattern is the .gitattributes pattern that matches exactly the
+// item stored at gitPath: the path itself, or everything below it for a
+// directory. Glob characters are escaped, and a path with whitespace or
+// quotes is written as a C-style quoted string.
+func attributePattern(gitPath string, isDir bool) string {
+	pattern := "/" + escapeGlob(filepath.ToSlash(gitPath))
+	if isDir {
+		pattern += "/**"
+	}
+	return quoteAttribute(pattern)
+}
+
+// escapeGlob escapes the characters .gitattributes patterns treat as glob
+// syntax.
+func escapeGlob(path string) string {
+	for _, meta := range []string{`\`, "*", "?", "["} {
+		path = strings.ReplaceAll(path, meta, `\`+meta)
+	}
+	return path
+}
+
+// quoteAttribute writes pattern as a C-style quoted string when it holds
+// whitespace or quotes, which would otherwise end it early.
+func quoteAttribute(pattern string) string {
+	if strings.ContainsAny(pattern, " \t\"") {
+		return strconv.Quote(pattern)
+	}
+	return pattern
+}
+
+// attributeLines splits .gitattributes content into lines without trailing
+// empty ones.
+func attributeLines(content []byte) []string {
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,145 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AddFailure is one path a skip-errors add left out, with the reason.
+type AddFailure struct {
+	Path string
+	Err  error
+}
+
+// AddReport lists what a skip-errors add managed and what it left out.
+// Added holds absolute paths in argument (or walk) order.
+type AddReport struct {
+	Added  []string
+	Failed []AddFailure
+}
+
+// AddMultipleSkipErrors is AddMultiple that gives up only on the files that
+// fail: each one that cannot be validated, moved or tracked is rolled back on
+// its own and reported in Failed, and the rest are committed together. When
+// every file fails nothing is committed and the first failure is returned.
+// Problems with the repository itself still fail the whole add.
+func (fm *Manager) AddMultipleSkipErrors(paths []string, progress ProgressCallback) (*AddReport, error) {
+	if len(paths) <= progressThreshold {
+		progress = nil
+	}
+	return fm.addFilesSkipErrors(paths, nil, progress, "files")
+}
+
+// AddRecursiveSkipErrors is AddRecursiveWithProgress with the same per-file
+// handling as AddMultipleSkipErrors; entries the walk cannot read, such as
+// directories without read permission, are reported as failures too.
+func (fm *Manager) AddRecursiveSkipErrors(paths []string, progress ProgressCallback) (*AddReport, error) {
+	var allFiles []string
+	var failed []AddFailure
+	skip := func(path string, err error) {
+		failed = append(failed, AddFailure{Path: path, Err: err})
+	}
+
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+
+		info, err := os.Stat(absPath)
+		if err != nil {
+			skip(path, err)
+			continue
+		}
+
+		if info.IsDir() {
+			files, err := fm.walkDirectory(absPath, skip)
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk directory %s: %w", path, err)
+			}
+			allFiles = append(allFiles, files...)
+		} else {
+			allFiles = append(allFiles, absPath)
+		}
+	}
+
+	if len(allFiles) == 0 && len(failed) == 0 {
+		return nil, fmt.Errorf("no files found to add")
+	}
+
+	if len(allFiles) <= progressThreshold || progress == nil {
+		return fm.addFilesSkipErrors(allFiles, failed, nil, "files")
+	}
+	return fm.addFilesSkipErrors(allFiles, failed, progress, "files recursively")
+}
+
+// addFilesSkipErrors validates and processes each path on its own, appending
+// failures to failed, then commits the files that made it as "lnk: added
+// <count> <what>".
+func (fm *Manager) addFilesSkipErrors(paths []string, failed []AddFailure, progress ProgressCallback, what string) (*AddReport, error) {
+	if err := fm.requireRepository(); err != nil {
+		return nil, err
+	}
+	if err := fm.requireGitCrypt(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateInto(); err != nil {
+		return nil, err
+	}
+	if err := fm.validateChmod(); err != nil {
+		return nil, err
+	}
+
+	report := &AddReport{Failed: failed}
+	stored := make(map[string]bool, len(paths))
+	var files []validatedFile
+	var rollbackActions []func() error
+
+	for i, path := range paths {
+		if err := fm.interrupted(); err != nil {
+			fm.RollbackAll(rollbackActions)
+			return nil, err
+		}
+		if progress != nil {
+			progress(i+1, len(paths), path)
+		}
+
+		validated, err := fm.validatePaths([]string{path})
+		if err == nil && stored[validated[0].entry.StoredPath()] {
+			err = fmt.Errorf("another file in this add is stored at %s", validated[0].entry.StoredPath())
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, AddFailure{Path: path, Err: err})
+			continue
+		}
+
+		rollback, err := fm.processFile(validated[0])
+		if err != nil {
+			report.Failed = append(report.Failed, AddFailure{Path: path, Err: err})
+			continue
+		}
+
+		stored[validated[0].entry.StoredPath()] = true
+		files = append(files, validated[0])
+		rollbackActions = append(rollbackActions, rollback)
+		report.Added = append(report.Added, validated[0].absPath)
+	}
+
+	if err := fm.interrupted(); err != nil {
+		fm.RollbackAll(rollbackActions)
+		return nil, err
+	}
+	if len(files) == 0 {
+		if len(report.Failed) == 0 {
+			return report, nil
+		}
+		return nil, report.Failed[0].Err
+	}
+
+	if err := fm.commitFiles(files, rollbackActions, fmt.Sprintf("lnk: added %d %s", len(files), what)); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,345 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// StowEntry maps one file of a GNU Stow package to where lnk will manage it.
+type StowEntry struct {
+	Package      string // Stow package (top-level directory) the file belongs to
+	Source       string // Absolute path of the file inside the stow directory
+	Target       string // Absolute path in $HOME that stow links to Source
+	RelativePath string // Index-relative path of Target
+	Destination  string // Where the file will be stored in the lnk repository
+}
+
+// PlanStowImport maps every file of every package in stowDir to its place in
+// $HOME and in the repository, the way `stow -t ~` would lay them out. With
+// dotfiles set, stow's --dotfiles naming applies: a "dot-" prefix on any path
+// component stands for ".". Nothing is modified; the plan fails with
+// ErrStowConflict if a target is occupied by something other than the stow
+// file, with ErrAlreadyManaged or ErrSymlinkCollision if lnk already tracks
+// it, and with ErrFileTooLarge or ErrBinaryFile as add would.
+func (fm *Manager) PlanStowImport(stowDir string, dotfiles bool) ([]StowEntry, error) {
+	stowDir, err := filepath.Abs(stowDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", stowDir, err)
+	}
+	if info, err := os.Stat(stowDir); err != nil || !info.IsDir() {
+		return nil, lnkerror.WithPathAndSuggestion(fs.ErrFileNotExists, stowDir, "pass the stow directory that holds your packages")
+	}
+
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	packages, err := os.ReadDir(stowDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stow directory %s: %w", stowDir, err)
+	}
+
+	managedItems, err := fm.tracker.GetManagedItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+	locations, err := fm.restoredLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []StowEntry
+	seen := make(map[string]string)
+	for _, pkg := range packages {
+		if !pkg.IsDir() || strings.HasPrefix(pkg.Name(), ".") || stowIgnored(pkg.Name(), false) {
+			continue
+		}
+		pkgDir := filepath.Join(stowDir, pkg.Name())
+
+		err := filepath.WalkDir(pkgDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == pkgDir {
+				return nil
+			}
+			if stowIgnored(d.Name(), filepath.Dir(path) == pkgDir) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(pkgDir, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(homeDir, stowName(rel, dotfiles))
+
+			relativePath, err := fm.fs.RelativePath(target)
+			if err != nil {
+				return fmt.Errorf("failed to get relative path for %s: %w", target, err)
+			}
+			if other, ok := seen[relativePath]; ok {
+				return lnkerror.WithPathAndSuggestion(lnkerror.ErrStowConflict, target, fmt.Sprintf("packages %s and %s both provide it", other, pkg.Name()))
+			}
+			seen[relativePath] = pkg.Name()
+
+			if slices.Contains(managedItems, relativePath) {
+				return lnkerror.WithPathAndSuggestion(lnkerror.ErrAlreadyManaged, relativePath, alreadyManagedSuggestion)
+			}
+			if err := checkCollision(locations, relativePath); err != nil {
+				return err
+			}
+			if err := checkStowTarget(fm.fs, target, path); err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", path, err)
+			}
+			if err := fm.checkFileSizes(path, info); err != nil {
+				return err
+			}
+
+			entries = append(entries, StowEntry{
+				Package:      pkg.Name(),
+				Source:       path,
+				Target:       target,
+				RelativePath: relativePath,
+				Destination:  filepath.Join(fm.tracker.HostStoragePath(), relativePath),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// ImportStow migrates the packages in stowDir to lnk. Folded directory
+// symlinks that stow created into stowDir are unfolded into real
+// directories, each stow file is copied over its symlink in $HOME, and the
+// copies are then added like AddMultiple does, in a single commit. The stow
+// directory itself is left untouched. The whole plan is checked before
+// $HOME changes, and if the add fails anyway, every symlink and folded
+// directory is put back as stow left it.
+func (fm *Manager) ImportStow(stowDir string, dotfiles bool) ([]StowEntry, error) {
+	entries, err := fm.PlanStowImport(stowDir, dotfiles)
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+	if err := fm.requireRepository(); err != nil {
+		return nil, err
+	}
+
+	stowRoot, err := filepath.EvalSymlinks(stowDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", stowDir, err)
+	}
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	var rollbackActions []func() error
+	targets := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		undo, err := fm.unfoldParents(homeDir, entry.Target, stowRoot, dotfiles)
+		rollbackActions = append(rollbackActions, undo...)
+		if err != nil {
+			fm.RollbackAll(rollbackActions)
+			return nil, err
+		}
+
+		relink, err := fm.replaceStowLink(entry)
+		if err != nil {
+			fm.RollbackAll(rollbackActions)
+			return nil, err
+		}
+		if relink != nil {
+			rollbackActions = append(rollbackActions, relink)
+		}
+
+		targets = append(targets, entry.Target)
+	}
+
+	if err := fm.addFiles(targets, nil, fmt.Sprintf("lnk: imported %d files from stow", len(targets))); err != nil {
+		fm.RollbackAll(rollbackActions)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// replaceStowLink puts a copy of the stow file at entry.Target, in place of
+// stow's symlink, and returns the action that puts the symlink back. A
+// regular file there already holds the stow content and is kept, with
+// nothing to undo.
+func (fm *Manager) replaceStowLink(entry StowEntry) (func() error, error) {
+	info, err := os.Lstat(entry.Target)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(entry.Target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stow symlink %s: %w", entry.Target, err)
+		}
+		if err := os.Remove(entry.Target); err != nil {
+			return nil, fmt.Errorf("failed to remove stow symlink %s: %w", entry.Target, err)
+		}
+		undo := func() error {
+			_ = os.Remove(entry.Target)
+			return os.Symlink(link, entry.Target)
+		}
+		if err := fm.fs.CopyFile(entry.Source, entry.Target); err != nil {
+			_ = undo()
+			return nil, err
+		}
+		return undo, nil
+	case os.IsNotExist(err):
+		if err := fm.fs.CopyFile(entry.Source, entry.Target); err != nil {
+			return nil, err
+		}
+		return func() error { return os.Remove(entry.Target) }, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to stat %s: %w", entry.Target, err)
+	}
+	return nil, nil
+}
+
+// unfoldParents replaces every directory symlink between homeDir and target
+// that resolves into stowRoot — stow's directory folding — with a real
+// directory whose children are symlinked back into the package, so the
+// target can be replaced on its own. It returns the actions that fold each
+// directory again, also when it fails part-way.
+func (fm *Manager) unfoldParents(homeDir, target, stowRoot string, dotfiles bool) ([]func() error, error) {
+	rel, err := filepath.Rel(homeDir, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return nil, err
+	}
+
+	var undo []func() error
+	current := homeDir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return undo, nil
+		}
+		if err != nil {
+			return undo, fmt.Errorf("failed to stat %s: %w", current, err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		resolved, err := filepath.EvalSymlinks(current)
+		if err != nil || !isWithin(resolved, stowRoot) {
+			continue
+		}
+
+		children, err := os.ReadDir(resolved)
+		if err != nil {
+			return undo, fmt.Errorf("failed to read %s: %w", resolved, err)
+		}
+		link, err := os.Readlink(current)
+		if err != nil {
+			return undo, fmt.Errorf("failed to read folded directory %s: %w", current, err)
+		}
+		if err := os.Remove(current); err != nil {
+			return undo, fmt.Errorf("failed to remove folded directory %s: %w", current, err)
+		}
+		dir := current
+		undo = append(undo, func() error {
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+			return os.Symlink(link, dir)
+		})
+		if err := os.Mkdir(current, 0755); err != nil {
+			return undo, lnkerror.WithPath(fs.ErrDirCreate, current)
+		}
+		for _, child := range children {
+			link := filepath.Join(current, stowName(child.Name(), dotfiles))
+			if err := fm.fs.CreateSymlink(filepath.Join(resolved, child.Name()), link); err != nil {
+				return undo, fmt.Errorf("failed to unfold %s: %w", link, err)
+			}
+		}
+	}
+
+	return undo, nil
+}
+
+// checkStowTarget reports ErrStowConflict unless target is free, resolves to
+// source (directly or through a folded directory), or is a regular file with
+// the same content.
+func checkStowTarget(files *fs.FileSystem, target, source string) error {
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", target, err)
+	}
+
+	resolvedTarget, targetErr := filepath.EvalSymlinks(target)
+	resolvedSource, sourceErr := filepath.EvalSymlinks(source)
+	if targetErr == nil && sourceErr == nil && resolvedTarget == resolvedSource {
+		return nil
+	}
+	if info.Mode().IsRegular() && files.SameContent(target, source) {
+		return nil
+	}
+
+	return lnkerror.WithPathAndSuggestion(lnkerror.ErrStowConflict, target, "move it out of the way or unstow the package first")
+}
+
+// stowName applies stow's --dotfiles translation ("dot-x" → ".x") to every
+// component of rel when dotfiles is set.
+func stowName(rel string, dotfiles bool) string {
+	if !dotfiles {
+		return rel
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i, part := range parts {
+		if strings.HasPrefix(part, "dot-") {
+			parts[i] = "." + strings.TrimPrefix(part, "dot-")
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// stowIgnored reports whether stow's default ignore list skips name. top marks
+// entries directly inside a package, where README, LICENSE and COPYING are
+// ignored as well.
+func stowIgnored(name string, top bool) bool {
+	switch name {
+	case ".git", ".gitignore", ".gitmodules", ".hg", ".svn", "CVS", "RCS", "_darcs", ".cvsignore", ".stow-local-ignore":
+		return true
+	}
+	if strings.HasSuffix(name, "~") || strings.HasPrefix(name, ".#") || (len(name) > 1 && strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")) {
+		return true
+	}
+
+	return top && (strings.HasPrefix(name, "README") || strings.HasPrefix(name, "LICENSE") || name == "COPYING")
+}
+
+// isWithin reports whether path is dir or lies below it.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
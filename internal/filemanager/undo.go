@@ -0,0 +1,314 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// UndoResult reports what Undo reversed.
+type UndoResult struct {
+	Subject  string   // Subject of the commit that was undone
+	Reverted bool     // A revert commit was recorded because the commit was already pushed
+	Restored []string // Index entries the commit added, now plain files in $HOME again
+	Relinked []string // Index entries the commit removed, managed and symlinked again
+}
+
+// undoItem is one index entry that the last commit added or removed.
+type undoItem struct {
+	entry   tracker.Entry
+	storage string // Absolute path of the stored copy in the repository
+	home    string // Absolute path in $HOME
+}
+
+// Undo reverses the last commit, which must be one lnk made (its subject
+// starts with "lnk:"), in git and in $HOME. The index files it changed say
+// what to reconcile:
+//
+//   - An entry it added (lnk add, import-stow) is moved back out of the
+//     repository in place of its symlink, as lnk rm would.
+//   - An entry it removed (lnk rm, --force too) is symlinked again. If the
+//     item is back in $HOME as a real file or directory, that version is moved
+//     into the repository, so edits made since show as uncommitted changes.
+//   - Copy-managed entries only change in the repository; the originals in
+//     $HOME are left alone.
+//   - Everything else the commit changed (push, sync, config set, ...) is
+//     only reverted in the repository.
+//
+// An unpushed commit is dropped with git reset; a pushed one is refused
+// with ErrAlreadyPushed unless force is set, in which case a revert commit
+// is recorded so shared history is not rewritten. Undo refuses to run with
+// uncommitted changes, and fails with ErrUndoConflict before touching
+// anything if a file in $HOME is not what the commit left there.
+func (fm *Manager) Undo(force bool) (*UndoResult, error) {
+	if err := fm.requireRepository(); err != nil {
+		return nil, err
+	}
+
+	commits, err := fm.git.GetCommits()
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrCannotUndo, "the repository has no commits yet")
+	}
+	subject := commits[0]
+	if !strings.HasPrefix(subject, "lnk:") {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrCannotUndo, subject, "it was not made by lnk; use git revert in the repository instead")
+	}
+	if !fm.git.HasParentCommit() {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrCannotUndo, subject, "it is the repository's first commit")
+	}
+
+	dirty, err := fm.git.HasChanges()
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrUncommitted, fm.repoPath, "commit or discard them before undoing")
+	}
+
+	pushed, err := fm.git.IsHeadPushed()
+	if err != nil {
+		return nil, err
+	}
+	if pushed && !force {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrAlreadyPushed, subject, "use --force to record a commit that reverts it instead")
+	}
+
+	added, removed, err := fm.lastCommitEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range added {
+		if err := checkUndoAdded(item); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range removed {
+		if err := checkUndoRemoved(item); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &UndoResult{Subject: subject, Reverted: pushed}
+
+	// Added items leave the repository before git forgets them.
+	for _, item := range added {
+		if item.entry.Copy {
+			continue
+		}
+		restored, err := fm.restoreFromStorage(item)
+		if err != nil {
+			return nil, err
+		}
+		if restored {
+			result.Restored = append(result.Restored, item.entry.Path)
+		}
+	}
+
+	if pushed {
+		err = fm.git.RevertLastCommit(fmt.Sprintf("lnk: reverted %q", strings.TrimSpace(strings.TrimPrefix(subject, "lnk:"))))
+	} else {
+		err = fm.git.DropLastCommit()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Removed items are back in storage now; link them again.
+	for _, item := range removed {
+		if item.entry.Copy {
+			continue
+		}
+		relinked, err := fm.relinkToStorage(item)
+		if err != nil {
+			return nil, err
+		}
+		if relinked {
+			result.Relinked = append(result.Relinked, item.entry.Path)
+		}
+	}
+
+	return result, nil
+}
+
+// lastCommitEntries compares every index file the last commit changed with
+// its parent version and returns the entries it added and removed.
+func (fm *Manager) lastCommitEntries() (added, removed []undoItem, err error) {
+	paths, err := fm.git.LastCommitPaths()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	homeDir, err := fm.fs.HomeDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	for _, path := range paths {
+		host, ok := fm.tracker.IndexHost(path)
+		if !ok {
+			continue
+		}
+
+		before, err := fm.entriesAt("HEAD~1", path)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := fm.entriesAt("HEAD", path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		t := fm.tracker.ForHost(host)
+		item := func(entry tracker.Entry) undoItem {
+			return undoItem{
+				entry:   entry,
+				storage: t.StoragePath(entry),
+				home:    fm.fs.HomePath(homeDir, entry.Path, entry.XDG),
+			}
+		}
+		for path, entry := range after {
+			if _, ok := before[path]; !ok {
+				added = append(added, item(entry))
+			}
+		}
+		for path, entry := range before {
+			if _, ok := after[path]; !ok {
+				removed = append(removed, item(entry))
+			}
+		}
+	}
+
+	sortUndoItems(added)
+	sortUndoItems(removed)
+	return added, removed, nil
+}
+
+// entriesAt reads the index file at path as of rev, keyed by entry path.
+func (fm *Manager) entriesAt(rev, path string) (map[string]tracker.Entry, error) {
+	content, err := fm.git.FileAt(rev, path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := tracker.ParseEntries(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+
+	byPath := make(map[string]tracker.Entry, len(entries))
+	for _, entry := range entries {
+		byPath[entry.Path] = entry
+	}
+	return byPath, nil
+}
+
+// sortUndoItems orders items by path, so parents are handled before children.
+func sortUndoItems(items []undoItem) {
+	slices.SortFunc(items, func(a, b undoItem) int {
+		return strings.Compare(a.entry.Path, b.entry.Path)
+	})
+}
+
+// checkUndoAdded fails unless $HOME holds nothing, or the symlink to storage,
+// where an added item will be moved back.
+func checkUndoAdded(item undoItem) error {
+	if item.entry.Copy {
+		return nil
+	}
+	info, err := os.Lstat(item.home)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", item.home, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 || !pointsTo(item.home, item.storage) {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrUndoConflict, item.home, "it is no longer the symlink lnk created; move it out of the way first")
+	}
+	return nil
+}
+
+// checkUndoRemoved fails if $HOME holds a symlink somewhere other than
+// storage where a removed item will be linked again.
+func checkUndoRemoved(item undoItem) error {
+	if item.entry.Copy {
+		return nil
+	}
+	info, err := os.Lstat(item.home)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 || pointsTo(item.home, item.storage) {
+		return nil
+	}
+	return lnkerror.WithPathAndSuggestion(lnkerror.ErrUndoConflict, item.home, "it is a symlink lnk did not create; move it out of the way first")
+}
+
+// pointsTo reports whether the symlink at link resolves to target.
+func pointsTo(link, target string) bool {
+	dest, err := os.Readlink(link)
+	if err != nil {
+		return false
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(link), dest)
+	}
+	return filepath.Clean(dest) == filepath.Clean(target)
+}
+
+// restoreFromStorage replaces the symlink of an added item with the stored
+// item, as Remove does. It reports false when nothing was stored.
+func (fm *Manager) restoreFromStorage(item undoItem) (bool, error) {
+	info, err := os.Lstat(item.storage)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", item.storage, err)
+	}
+
+	if err := os.Remove(item.home); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to remove symlink %s: %w", item.home, err)
+	}
+	if err := fm.fs.Move(item.storage, item.home, info); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// relinkToStorage symlinks a removed item again. A real item in $HOME takes
+// the place of the stored one first. It reports false when nothing is stored
+// to link to.
+func (fm *Manager) relinkToStorage(item undoItem) (bool, error) {
+	info, err := os.Lstat(item.home)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return false, fmt.Errorf("failed to stat %s: %w", item.home, err)
+	case info.Mode()&os.ModeSymlink != 0:
+		return false, nil
+	default:
+		if err := os.RemoveAll(item.storage); err != nil {
+			return false, fmt.Errorf("failed to replace %s: %w", item.storage, err)
+		}
+		if err := fm.fs.Move(item.home, item.storage, info); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := os.Lstat(item.storage); err != nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(item.home), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory for %s: %w", item.home, err)
+	}
+	if err := fm.fs.Link(item.storage, item.home, item.entry.Absolute); err != nil {
+		return false, fmt.Errorf("failed to create symlink %s: %w", item.home, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,39 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// SetXattrs makes adds record each file's user.* extended attributes in its
+// entry, so restores and removes can set them again where git, which does
+// not carry them, left a file without. Only Linux reads and sets them.
+func (fm *Manager) SetXattrs(enabled bool) {
+	fm.xattrs = enabled
+}
+
+// captureXattrs records the extended attributes of the file at absPath in
+// entry when SetXattrs is on. Directories and symlinks are left alone: only
+// a file's own attributes are kept.
+func (fm *Manager) captureXattrs(absPath string, entry *tracker.Entry) error {
+	if !fm.xattrs {
+		return nil
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat path: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	attrs, err := fs.ReadXattrs(absPath)
+	if err != nil {
+		return err
+	}
+	entry.Xattrs = attrs
+	return nil
+}
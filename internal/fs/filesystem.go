@@ -2,11 +2,14 @@
 package fs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/yarlson/lnk/internal/lnkerror"
 )
@@ -20,16 +23,34 @@ var (
 	ErrSymlinkRead     = errors.New("Unable to read symlink. The file may be corrupted or have invalid permissions.")
 	ErrDirCreate       = errors.New("Failed to create directory. Please check permissions and available disk space.")
 	ErrRelativePath    = errors.New("Unable to create symlink due to path configuration issues. Please check file locations.")
+	ErrCrossDevice     = errors.New("Unable to copy the file to another filesystem. Please check permissions and available disk space.")
+	ErrSymlinkLoop     = errors.New("The symlink would point into itself")
 )
 
 // FileSystem handles file system operations
-type FileSystem struct{}
+type FileSystem struct {
+	home string
+}
 
 // New creates a new FileSystem instance
 func New() *FileSystem {
 	return &FileSystem{}
 }
 
+// SetHome makes HomeDir return dir instead of the process's home directory.
+func (fs *FileSystem) SetHome(dir string) {
+	fs.home = dir
+}
+
+// HomeDir returns the directory managed paths are relative to: the one given
+// to SetHome, or else the process's home directory ($HOME).
+func (fs *FileSystem) HomeDir() (string, error) {
+	if fs.home != "" {
+		return fs.home, nil
+	}
+	return os.UserHomeDir()
+}
+
 // ValidateFileForAdd validates that a file or directory can be added to lnk
 func (fs *FileSystem) ValidateFileForAdd(filePath string) error {
 	// Check if file exists and get its info
@@ -44,12 +65,35 @@ func (fs *FileSystem) ValidateFileForAdd(filePath string) error {
 
 	// Allow both regular files and directories
 	if !info.Mode().IsRegular() && !info.IsDir() {
-		return lnkerror.WithPathAndSuggestion(ErrUnsupportedType, filePath, "lnk can only manage regular files and directories")
+		return lnkerror.WithPathAndSuggestion(ErrUnsupportedType, filePath, "this is a "+TypeName(info.Mode())+"; lnk can only manage regular files and directories")
 	}
 
 	return nil
 }
 
+// TypeName names the kind of file mode describes, for messages about files
+// lnk will not manage: "socket", "named pipe", "device" and so on.
+func TypeName(mode os.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "regular file"
+	case mode.IsDir():
+		return "directory"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&os.ModeCharDevice != 0:
+		return "character device"
+	case mode&os.ModeDevice != 0:
+		return "block device"
+	default:
+		return "special file"
+	}
+}
+
 // ValidateSymlinkForRemove validates that a symlink can be removed from lnk
 func (fs *FileSystem) ValidateSymlinkForRemove(filePath, repoPath string) error {
 	// Check if file exists and is a symlink
@@ -72,7 +116,11 @@ func (fs *FileSystem) ValidateSymlinkForRemove(filePath, repoPath string) error
 		return lnkerror.WithPath(ErrSymlinkRead, filePath)
 	}
 
+	// A relative target starts from the link's real directory, which is not
+	// its lexical parent when $HOME is a symlink.
+	resolved := target
 	if !filepath.IsAbs(target) {
+		resolved = filepath.Join(filepath.Dir(ResolvePath(filePath)), target)
 		target = filepath.Join(filepath.Dir(filePath), target)
 	}
 
@@ -80,19 +128,37 @@ func (fs *FileSystem) ValidateSymlinkForRemove(filePath, repoPath string) error
 	target = filepath.Clean(target)
 	repoPath = filepath.Clean(repoPath)
 
-	if !strings.HasPrefix(target, repoPath+string(filepath.Separator)) && target != repoPath {
+	if !within(target, repoPath) && !within(ResolvePath(resolved), resolveDir(repoPath)) {
 		return lnkerror.WithPathAndSuggestion(ErrNotManaged, filePath, "use 'lnk add' to manage this file first")
 	}
 
 	return nil
 }
 
-// Move moves a file or directory from source to destination based on the file info
+// Move moves a file or directory from source to destination based on the file
+// info, and leaves dst with the permissions recorded in info. A read-only
+// directory is made writable for the move: renaming it into another parent
+// rewrites its ".." entry, which needs write permission on the directory.
 func (fs *FileSystem) Move(src, dst string, info os.FileInfo) error {
-	if info.IsDir() {
-		return fs.MoveDirectory(src, dst)
+	mode := info.Mode().Perm()
+
+	if !info.IsDir() {
+		if err := fs.MoveFile(src, dst); err != nil {
+			return err
+		}
+		return os.Chmod(dst, mode)
 	}
-	return fs.MoveFile(src, dst)
+
+	if mode&0200 == 0 {
+		if err := os.Chmod(src, mode|0200); err != nil {
+			return fmt.Errorf("failed to make %s writable: %w", src, err)
+		}
+	}
+	if err := fs.MoveDirectory(src, dst); err != nil {
+		_ = os.Chmod(src, mode)
+		return err
+	}
+	return os.Chmod(dst, mode)
 }
 
 // MoveFile moves a file from source to destination
@@ -103,13 +169,121 @@ func (fs *FileSystem) MoveFile(src, dst string) error {
 	}
 
 	// Move the file
-	return os.Rename(src, dst)
+	return rename(src, dst)
 }
 
-// CreateSymlink creates a relative symlink from target to linkPath
+// CopyFile copies the regular file at src to dst, keeping its permissions.
+// dst is replaced if it exists.
+func (fs *FileSystem) CopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return lnkerror.WithPath(ErrFileCheck, src)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return lnkerror.WithPath(ErrDirCreate, filepath.Dir(dst))
+	}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	if err := os.WriteFile(dst, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+// SameContent reports whether the regular files at a and b exist and hold
+// the same bytes.
+func (fs *FileSystem) SameContent(a, b string) bool {
+	contentA, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+
+	contentB, err := os.ReadFile(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(contentA, contentB)
+}
+
+// SameTree reports whether a and b hold the same thing: regular files with
+// the same bytes, directories whose entries have the same names and are the
+// same tree in turn, or symlinks with the same target. Permissions are not
+// compared.
+func (fs *FileSystem) SameTree(a, b string) bool {
+	infoA, err := os.Lstat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Lstat(b)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case infoA.Mode().IsRegular() && infoB.Mode().IsRegular():
+		return fs.SameContent(a, b)
+	case infoA.IsDir() && infoB.IsDir():
+		entriesA, err := os.ReadDir(a)
+		if err != nil {
+			return false
+		}
+		entriesB, err := os.ReadDir(b)
+		if err != nil || len(entriesA) != len(entriesB) {
+			return false
+		}
+		for i, entry := range entriesA {
+			if entry.Name() != entriesB[i].Name() || !fs.SameTree(filepath.Join(a, entry.Name()), filepath.Join(b, entry.Name())) {
+				return false
+			}
+		}
+		return true
+	case infoA.Mode()&os.ModeSymlink != 0 && infoB.Mode()&os.ModeSymlink != 0:
+		targetA, errA := os.Readlink(a)
+		targetB, errB := os.Readlink(b)
+		return errA == nil && errB == nil && targetA == targetB
+	}
+	return false
+}
+
+// binarySniffLen is how much of a file IsBinary reads, the same amount git
+// looks at when deciding whether to diff a file as text.
+const binarySniffLen = 8000
+
+// IsBinary reports whether the regular file at path looks binary to git: a
+// NUL byte within its first 8000 bytes.
+func (fs *FileSystem) IsBinary(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// CreateSymlink creates a relative symlink from target to linkPath. It fails
+// with ErrSymlinkLoop when target is linkPath or lies below it, since such a
+// link could only ever resolve to itself.
 func (fs *FileSystem) CreateSymlink(target, linkPath string) error {
-	// Calculate relative path from linkPath to target
-	relTarget, err := filepath.Rel(filepath.Dir(linkPath), target)
+	if SamePath(target, linkPath) || within(ResolvePath(target), ResolvePath(linkPath)) {
+		return lnkerror.WithPath(ErrSymlinkLoop, linkPath)
+	}
+
+	// Calculate relative path from linkPath to target, between their real
+	// locations so a symlinked $HOME doesn't send ".." somewhere else
+	relTarget, err := filepath.Rel(resolveDir(filepath.Dir(linkPath)), ResolvePath(target))
 	if err != nil {
 		return lnkerror.Wrap(ErrRelativePath)
 	}
@@ -118,6 +292,65 @@ func (fs *FileSystem) CreateSymlink(target, linkPath string) error {
 	return os.Symlink(relTarget, linkPath)
 }
 
+// CreateAbsoluteSymlink is CreateSymlink with the link holding target's
+// absolute path, for links that must keep resolving when $HOME and the
+// repository are on different mounts or reached through different paths.
+func (fs *FileSystem) CreateAbsoluteSymlink(target, linkPath string) error {
+	if SamePath(target, linkPath) || within(ResolvePath(target), ResolvePath(linkPath)) {
+		return lnkerror.WithPath(ErrSymlinkLoop, linkPath)
+	}
+
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	return os.Symlink(absTarget, linkPath)
+}
+
+// Link creates the symlink from target to linkPath in the form an index
+// entry records: absolute when absolute is set, relative otherwise.
+func (fs *FileSystem) Link(target, linkPath string, absolute bool) error {
+	if absolute {
+		return fs.CreateAbsoluteSymlink(target, linkPath)
+	}
+	return fs.CreateSymlink(target, linkPath)
+}
+
+// IsAbsoluteSymlink reports whether linkPath is a symlink holding an
+// absolute target.
+func IsAbsoluteSymlink(linkPath string) bool {
+	dest, err := os.Readlink(linkPath)
+	return err == nil && filepath.IsAbs(dest)
+}
+
+// IsSymlinkTo reports whether linkPath is a symlink pointing to target,
+// comparing real locations rather than spellings.
+func (fs *FileSystem) IsSymlinkTo(linkPath, target string) bool {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		return false
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+
+	dest, err := os.Readlink(linkPath)
+	if err != nil {
+		return false
+	}
+
+	// Compare real locations: with a symlinked $HOME or repository path the
+	// same file has two spellings, and a relative target starts from the
+	// link's real directory rather than its lexical parent. On a
+	// case-insensitive volume the spellings may also differ only in case.
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(ResolvePath(linkPath)), dest)
+	}
+
+	return SamePath(dest, target)
+}
+
 // MoveDirectory moves a directory from source to destination recursively
 func (fs *FileSystem) MoveDirectory(src, dst string) error {
 	// Ensure destination parent directory exists
@@ -126,12 +359,201 @@ func (fs *FileSystem) MoveDirectory(src, dst string) error {
 	}
 
 	// Move the directory
-	return os.Rename(src, dst)
+	return rename(src, dst)
+}
+
+// rename moves src to dst with os.Rename. When they are on different
+// filesystems (EXDEV), such as a repository on another mount than $HOME, it
+// copies src to dst instead and then deletes src. Read-only directories in
+// src are made writable first so they can be emptied; a copy that fails
+// halfway, or a src that cannot be made removable, removes the copy again,
+// leaving src as it was.
+func rename(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		_ = removeTree(dst)
+		return lnkerror.WithPath(ErrCrossDevice, src)
+	}
+	restore, err := makeRemovable(src)
+	if err != nil {
+		_ = removeTree(dst)
+		return lnkerror.WithPath(ErrCrossDevice, src)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		// Part of src may be gone already, so the complete copy at dst stays.
+		_ = restore()
+		return fmt.Errorf("moved %s to %s but failed to remove the original: %w", src, dst, err)
+	}
+	return nil
+}
+
+// makeRemovable gives the owner write access to every directory in the tree
+// at path that lacks it, so os.RemoveAll can delete their entries. The
+// returned function puts the original modes back.
+func makeRemovable(path string) (func() error, error) {
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var changed []dirMode
+	restore := func() error {
+		var first error
+		for i := len(changed) - 1; i >= 0; i-- {
+			if err := os.Chmod(changed[i].path, changed[i].mode); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if mode := info.Mode().Perm(); mode&0700 != 0700 {
+			if err := os.Chmod(p, mode|0700); err != nil {
+				return err
+			}
+			changed = append(changed, dirMode{p, mode})
+		}
+		return nil
+	})
+	if err != nil {
+		_ = restore()
+		return nil, err
+	}
+	return restore, nil
+}
+
+// removeTree deletes the tree at path, read-only directories included.
+func removeTree(path string) error {
+	if _, err := makeRemovable(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// CopyTree copies the file or directory tree at src to dst, which must not
+// exist yet, keeping permissions and modification times.
+func (fs *FileSystem) CopyTree(src, dst string) error {
+	return copyTree(src, dst)
+}
+
+// copyTree copies the file, symlink or directory tree at src to dst, keeping
+// permissions and modification times. Directory permissions are applied once
+// their contents are in place, so read-only directories can be filled.
+func copyTree(src, dst string) error {
+	type dirMode struct {
+		path string
+		info os.FileInfo
+	}
+	var dirs []dirMode
+
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, dirMode{target, info})
+			return os.Mkdir(target, 0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyRegular(path, target, info)
+		default:
+			return lnkerror.WithPath(ErrUnsupportedType, path)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].info.ModTime(), dirs[i].info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyRegular streams the regular file at src to a new file at dst with the
+// permissions and modification time recorded in info.
+func copyRegular(src, dst string, info os.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
 }
 
 // GetRelativePath converts an absolute path to a relative path from the home directory.
 func GetRelativePath(absPath string) (string, error) {
-	homeDir, err := os.UserHomeDir()
+	return New().RelativePath(absPath)
+}
+
+// RelativePath converts an absolute path to a relative path from HomeDir.
+// A path under a custom $XDG_CONFIG_HOME (see ConfigHome) is recorded as if
+// it were under ~/.config, so it restores into the target machine's config
+// directory wherever that is. Other paths outside HomeDir are returned with
+// the leading "/" stripped.
+func (fs *FileSystem) RelativePath(absPath string) (string, error) {
+	if configHome := fs.ConfigHome(); configHome != "" && within(absPath, configHome) {
+		rel, err := filepath.Rel(configHome, absPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to get relative path: %w", err)
+		}
+		return filepath.Join(".config", rel), nil
+	}
+	return fs.HomeRelativePath(absPath)
+}
+
+// HomeRelativePath converts an absolute path to a relative path from HomeDir
+// without the $XDG_CONFIG_HOME mapping of RelativePath: the form items under
+// a custom config directory were recorded in before that mapping existed.
+func (fs *FileSystem) HomeRelativePath(absPath string) (string, error) {
+	homeDir, err := fs.HomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
@@ -142,8 +564,110 @@ func GetRelativePath(absPath string) (string, error) {
 	}
 
 	if strings.HasPrefix(relPath, "..") {
+		// $HOME may be a symlink, reached here through its resolved path
+		// (or the other way round); compare the resolved forms before
+		// treating absPath as outside it.
+		if resolved, err := filepath.Rel(resolveDir(homeDir), ResolvePath(absPath)); err == nil && !strings.HasPrefix(resolved, "..") {
+			return resolved, nil
+		}
 		return strings.TrimPrefix(absPath, "/"), nil
 	}
 
 	return relPath, nil
 }
+
+// IsConfigPath reports whether absPath lies under a custom $XDG_CONFIG_HOME,
+// so the entry recording it should restore against the target's config
+// directory rather than ~/.config.
+func (fs *FileSystem) IsConfigPath(absPath string) bool {
+	configHome := fs.ConfigHome()
+	return configHome != "" && within(absPath, configHome)
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME when it names a custom config
+// directory: an absolute path other than HomeDir/.config that does not hold
+// HomeDir itself. It returns "" otherwise, the default ~/.config applying.
+func (fs *FileSystem) ConfigHome() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" || !filepath.IsAbs(configHome) {
+		return ""
+	}
+	homeDir, err := fs.HomeDir()
+	if err != nil {
+		return ""
+	}
+	configHome = filepath.Clean(configHome)
+	if configHome == filepath.Join(homeDir, ".config") || within(homeDir, configHome) {
+		return ""
+	}
+	return configHome
+}
+
+// HomePath returns where an item recorded at relativePath lives: under
+// HomeDir, or for an xdg entry (see IsConfigPath) under this machine's
+// $XDG_CONFIG_HOME when that is custom, with the leading .config dropped.
+func (fs *FileSystem) HomePath(homeDir, relativePath string, xdg bool) string {
+	if xdg {
+		if rest, ok := strings.CutPrefix(filepath.ToSlash(relativePath), ".config/"); ok {
+			if configHome := fs.ConfigHome(); configHome != "" {
+				return filepath.Join(configHome, filepath.FromSlash(rest))
+			}
+		}
+	}
+	return filepath.Join(homeDir, relativePath)
+}
+
+// ResolvePath returns path made absolute with the symlinks in its parent
+// directories resolved, so a file reached through a symlinked $HOME and
+// through its real location compare equal. The last element is kept as it
+// is, since it is usually the symlink lnk manages; parents that don't exist
+// yet are kept as well.
+func ResolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+
+	dir := filepath.Dir(abs)
+	if dir == abs {
+		return abs
+	}
+	return filepath.Join(resolveDir(dir), filepath.Base(abs))
+}
+
+// SamePath reports whether a and b name the same file. Their ResolvePath
+// forms are compared first; when those differ, the two are compared with
+// os.SameFile, so spellings that differ only in case match on a
+// case-insensitive volume (the macOS default) while staying distinct on a
+// case-sensitive one. The last element is not followed, so a symlink and its
+// target are different files. Paths that don't exist only match by name.
+func SamePath(a, b string) bool {
+	a, b = ResolvePath(a), ResolvePath(b)
+	if a == b {
+		return true
+	}
+
+	aInfo, err := os.Lstat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Lstat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+// resolveDir returns dir with every symlink in it resolved, falling back to
+// ResolvePath when dir doesn't exist.
+func resolveDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return ResolvePath(dir)
+}
+
+// within reports whether path is dir or lies below it.
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
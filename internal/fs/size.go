@@ -0,0 +1,57 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the suffixes ParseSize accepts and FormatSize prints, largest
+// first. Units are powers of 1024, as in git's core.bigFileThreshold.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "10MB", "512 KB" or "1048576" (bytes).
+// Suffixes are case-insensitive and may also be written K, M or G.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if trimmed, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = trimmed, unit.bytes
+			break
+		}
+		if unit.bytes > 1 {
+			if trimmed, ok := strings.CutSuffix(value, unit.suffix[:1]); ok {
+				value, multiplier = trimmed, unit.bytes
+				break
+			}
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("use a size such as 10MB, 512KB or a number of bytes")
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// FormatSize renders n bytes with the largest unit that keeps the number at
+// least 1, e.g. "12.5 MB" or "300 B".
+func FormatSize(n int64) string {
+	for _, unit := range sizeUnits {
+		if n >= unit.bytes && unit.bytes > 1 {
+			value := strconv.FormatFloat(float64(n)/float64(unit.bytes), 'f', 1, 64)
+			return fmt.Sprintf("%s %s", strings.TrimSuffix(value, ".0"), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
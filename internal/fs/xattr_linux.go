@@ -0,0 +1,81 @@
+//go:build linux
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// XattrsSupported reports whether ReadXattrs and WriteXattrs do anything on
+// this platform.
+const XattrsSupported = true
+
+// xattrPrefix is the namespace lnk preserves: user attributes are the ones
+// an unprivileged owner can read and set again, unlike security.* labels or
+// trusted.* attributes.
+const xattrPrefix = "user."
+
+// ReadXattrs returns the user.* extended attributes of path, following a
+// symlink. A filesystem without extended attributes has none.
+func ReadXattrs(path string) (map[string][]byte, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil {
+		if errors.Is(err, syscall.ENOTSUP) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list extended attributes of %s: %w", path, err)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	if size, err = syscall.Listxattr(path, buf); err != nil {
+		return nil, fmt.Errorf("failed to list extended attributes of %s: %w", path, err)
+	}
+
+	var attrs map[string][]byte
+	for _, name := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		if !strings.HasPrefix(name, xattrPrefix) {
+			continue
+		}
+		value, err := readXattr(path, name)
+		if err != nil {
+			return nil, err
+		}
+		if attrs == nil {
+			attrs = make(map[string][]byte)
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// readXattr returns the value of the attribute name of path.
+func readXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extended attribute %s of %s: %w", name, path, err)
+	}
+	value := make([]byte, size)
+	if size, err = syscall.Getxattr(path, name, value); err != nil {
+		return nil, fmt.Errorf("failed to read extended attribute %s of %s: %w", name, path, err)
+	}
+	return value[:size], nil
+}
+
+// WriteXattrs sets each of attrs on path, leaving its other attributes
+// alone. A filesystem without extended attributes is not an error.
+func WriteXattrs(path string, attrs map[string][]byte) error {
+	for name, value := range attrs {
+		if err := syscall.Setxattr(path, name, value, 0); err != nil {
+			if errors.Is(err, syscall.ENOTSUP) {
+				return nil
+			}
+			return fmt.Errorf("failed to set extended attribute %s on %s: %w", name, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package fs
+
+// XattrsSupported reports whether ReadXattrs and WriteXattrs do anything on
+// this platform.
+const XattrsSupported = false
+
+// ReadXattrs finds no extended attributes on platforms lnk does not read
+// them on.
+func ReadXattrs(string) (map[string][]byte, error) {
+	return nil, nil
+}
+
+// WriteXattrs is a no-op on platforms lnk does not set extended attributes
+// on.
+func WriteXattrs(string, map[string][]byte) error {
+	return nil
+}
@@ -5,13 +5,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/yarlson/lnk/internal/event"
 	"github.com/yarlson/lnk/internal/lnkerror"
 )
 
@@ -26,11 +29,18 @@ var (
 	ErrGitConfig      = errors.New("Failed to configure git settings. Please check your git installation.")
 	ErrPush           = errors.New("Failed to push changes to remote repository. Please check your network connection and repository permissions.")
 	ErrPull           = errors.New("Failed to pull changes from remote repository. Please check your network connection and resolve any conflicts.")
+	ErrFetch          = errors.New("Failed to fetch from remote repository. Please check your network connection and repository permissions.")
 	ErrGitTimeout     = errors.New("git operation timed out")
 	ErrDirRemove      = errors.New("Failed to prepare directory for operation. Please check directory permissions.")
 	ErrDirCreate      = errors.New("Failed to create directory. Please check permissions and available disk space.")
+	ErrCommitSign     = errors.New("Failed to sign commit")
 	ErrUncommitted    = errors.New("Failed to check repository status. Please verify your git repository is valid.")
 	ErrDiff           = errors.New("Failed to get diff output. Please verify your git repository is valid.")
+	ErrMergeConflict  = errors.New("Pulled changes conflict with local changes")
+	ErrRebaseConflict = errors.New("Pulled changes conflict with local commits being rebased")
+	ErrInvalidDate    = errors.New("Invalid date")
+	ErrAuthRequired   = errors.New("Git needs credentials but cannot ask for them without a terminal")
+	ErrLsRemote       = errors.New("Could not reach the remote repository")
 )
 
 const (
@@ -41,21 +51,146 @@ const (
 	longTimeout = 5 * time.Minute
 )
 
+// DefaultRetries is how many more times push, pull and fetch are attempted
+// after a transient network failure, unless SetRetries says otherwise.
+const DefaultRetries = 2
+
+// DefaultRetryBackoff is the wait before the first retry; each further retry
+// waits twice as long as the one before.
+const DefaultRetryBackoff = 2 * time.Second
+
+// RetryHandler hears about a push, pull or fetch ("push", "pull", "fetch")
+// that failed on the network and is about to be retried: attempt counts the
+// retries from 1 up to retries, after a pause of wait.
+type RetryHandler func(op string, attempt, retries int, wait time.Duration)
+
 // Git handles Git operations
 type Git struct {
-	repoPath string
+	repoPath   string
+	sign       bool
+	authorDate time.Time
+	sshCommand string
+	remote     string
+	ctx        context.Context
+	retries    int
+	backoff    time.Duration
+	onRetry    RetryHandler
+	onEvent    event.Handler
+	rebase     bool
 }
 
 // New creates a new Git instance
 func New(repoPath string) *Git {
 	return &Git{
 		repoPath: repoPath,
+		retries:  DefaultRetries,
+		backoff:  DefaultRetryBackoff,
+	}
+}
+
+// SetRetries sets how many more times push, pull and fetch are attempted
+// after a transient network failure, and the wait before the first retry,
+// doubled for each one after. 0 retries fails at once; a backoff of zero or
+// less keeps DefaultRetryBackoff.
+func (g *Git) SetRetries(retries int, backoff time.Duration) {
+	g.retries = max(retries, 0)
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	g.backoff = backoff
+}
+
+// SetRetryHandler registers h to hear about each retry of a network
+// operation. A nil handler retries silently.
+func (g *Git) SetRetryHandler(h RetryHandler) {
+	g.onRetry = h
+}
+
+// SetEventHandler registers h to hear about every commit recorded and every
+// push that succeeded. A nil handler reports nothing.
+func (g *Git) SetEventHandler(h event.Handler) {
+	g.onEvent = h
+}
+
+// emitCommitted reports the commit just recorded with message, looking up
+// its hash only when someone listens.
+func (g *Git) emitCommitted(message string) {
+	if g.onEvent == nil {
+		return
+	}
+	hash, _ := g.execGitCommand(shortTimeout, "rev-parse", "HEAD").Output()
+	g.onEvent(event.Event{Kind: event.GitCommitted, Message: message, Hash: strings.TrimSpace(string(hash))})
+}
+
+// SetSign makes every commit pass -S, signing it with the configured key even
+// when commit.gpgsign is not set. Without it, git's own commit.gpgsign and
+// user.signingkey settings still apply.
+func (g *Git) SetSign(sign bool) {
+	g.sign = sign
+}
+
+// SetAuthorDate makes every commit record t as its author date instead of the
+// current time; the committer date stays the time of the commit. The zero
+// time restores the default, under which a GIT_AUTHOR_DATE in the environment
+// still applies.
+func (g *Git) SetAuthorDate(t time.Time) {
+	g.authorDate = t
+}
+
+// SetSSHCommand makes clone, push, pull and fetch run git with command as
+// GIT_SSH_COMMAND, so the dotfiles remote can use its own key or options
+// without touching the repository's git config. A GIT_SSH_COMMAND or GIT_SSH
+// in the environment still takes precedence; "" leaves the choice to git
+// (core.sshCommand, then plain ssh).
+func (g *Git) SetSSHCommand(command string) {
+	g.sshCommand = command
+}
+
+// SetRebase makes Pull rebase local commits onto the fetched ones (git pull
+// --rebase --autostash) instead of merging, keeping the history linear.
+// Without it, git's own pull.rebase setting decides.
+func (g *Git) SetRebase(enabled bool) {
+	g.rebase = enabled
+}
+
+// SetRemote makes push, pull and fetch use the remote called name. "" uses
+// the default remote: origin, or the first remote when there is no origin.
+func (g *Git) SetRemote(name string) {
+	g.remote = name
+}
+
+// SetContext makes every git command run under ctx, so cancelling it kills
+// the command, including a clone, push or pull still inside its timeout. A
+// nil ctx restores the default of context.Background.
+func (g *Git) SetContext(ctx context.Context) {
+	g.ctx = ctx
+}
+
+// dateLayouts are the formats ParseDate accepts, most specific first.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// ParseDate parses an author date given as RFC 3339 or as a local
+// "2006-01-02", "2006-01-02 15:04" or "2006-01-02T15:04".
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, lnkerror.WithPathAndSuggestion(ErrInvalidDate, value, "use RFC 3339 (2006-01-02T15:04:05Z07:00) or 2006-01-02")
+}
+
+// Context returns the SetContext context, or context.Background without one.
+func (g *Git) Context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
 	}
+	return g.ctx
 }
 
 // execGitCommand creates a git command with timeout context
 func (g *Git) execGitCommand(timeout time.Duration, args ...string) *exec.Cmd {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(g.Context(), timeout)
 	// Note: cancel is not deferred here because the command takes ownership
 	// of the context. The context will be automatically cleaned up when the
 	// command completes or the timeout expires.
@@ -126,6 +261,91 @@ func (g *Git) AddRemote(name, url string) error {
 	return nil
 }
 
+// Remotes returns the names of the repository's remotes, as git lists them.
+func (g *Git) Remotes() ([]string, error) {
+	cmd := g.execGitCommand(shortTimeout, "remote")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	return strings.Fields(string(output)), nil
+}
+
+// RemoteURL returns the URL of the remote called name, failing with
+// ErrRemoteNotFound when there is no such remote.
+func (g *Git) RemoteURL(name string) (string, error) {
+	url, err := g.getRemoteURL(name)
+	if err != nil {
+		if errors.Is(err, ErrGitTimeout) {
+			return "", err
+		}
+		return "", lnkerror.WithPathAndSuggestion(ErrRemoteNotFound, name, "run 'lnk remote' to see the configured remotes")
+	}
+	return url, nil
+}
+
+// DefaultRemote returns the remote push, pull and fetch use without
+// SetRemote: origin, or the first remote when there is no origin.
+func (g *Git) DefaultRemote() (string, error) {
+	name, _, err := g.resolveRemote("")
+	return name, err
+}
+
+// CheckRemote fails with ErrRemoteNotFound when the SetRemote remote does
+// not exist, so callers can refuse before committing anything.
+func (g *Git) CheckRemote() error {
+	if _, _, err := g.remoteName(); errors.Is(err, ErrRemoteNotFound) {
+		return err
+	}
+	return nil
+}
+
+// remoteName returns the remote push, pull and fetch talk to, and whether it
+// is the default one: the SetRemote remote, which must exist, or else origin,
+// or else the first remote.
+func (g *Git) remoteName() (string, bool, error) {
+	return g.resolveRemote(g.remote)
+}
+
+// resolveRemote checks that the remote called name exists and reports
+// whether it is the default one; "" resolves to the default.
+func (g *Git) resolveRemote(name string) (string, bool, error) {
+	remotes, err := g.Remotes()
+	if err != nil {
+		return "", false, err
+	}
+	if len(remotes) == 0 {
+		return "", false, lnkerror.WithSuggestion(ErrNoRemote, "add a remote repository first")
+	}
+
+	defaultRemote := remotes[0]
+	if slices.Contains(remotes, "origin") {
+		defaultRemote = "origin"
+	}
+	if name == "" {
+		return defaultRemote, true, nil
+	}
+	if !slices.Contains(remotes, name) {
+		return "", false, lnkerror.WithPathAndSuggestion(ErrRemoteNotFound, name, "run 'lnk remote' to see the configured remotes")
+	}
+	return name, name == defaultRemote, nil
+}
+
+// remoteError reports a failure to pick the remote for op (ErrPush, ErrPull
+// or ErrFetch). A named remote that does not exist is returned as it is, so
+// the name reaches the user; anything else becomes op's suggestion.
+func remoteError(op, err error) error {
+	if errors.Is(err, ErrRemoteNotFound) {
+		return err
+	}
+	return lnkerror.WithSuggestion(op, err.Error())
+}
+
 // getRemoteURL returns the URL for a remote, or error if not found
 func (g *Git) getRemoteURL(name string) (string, error) {
 	cmd := g.execGitCommand(shortTimeout, "remote", "get-url", name)
@@ -148,6 +368,80 @@ func (g *Git) IsGitRepository() bool {
 	return err == nil
 }
 
+// IsGitCryptInitialized reports whether `git-crypt init` has been run in the
+// repository, which leaves its key under .git/git-crypt.
+func (g *Git) IsGitCryptInitialized() bool {
+	_, err := os.Stat(filepath.Join(g.repoPath, ".git", "git-crypt"))
+	return err == nil
+}
+
+// ExcludeLocally adds path, relative to the repository root, to
+// .git/info/exclude, so `git add -A` leaves the untracked file alone on this
+// machine without a committed .gitignore. A path already listed is not added
+// again.
+func (g *Git) ExcludeLocally(path string) error {
+	excludeFile := filepath.Join(g.repoPath, ".git", "info", "exclude")
+	pattern := "/" + filepath.ToSlash(path)
+
+	content, err := os.ReadFile(excludeFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", excludeFile, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, pattern+"\n"...)
+
+	if err := os.MkdirAll(filepath.Dir(excludeFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(excludeFile), err)
+	}
+	if err := os.WriteFile(excludeFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", excludeFile, err)
+	}
+	return nil
+}
+
+// RenameExcluded rewrites the .git/info/exclude patterns ExcludeLocally wrote
+// for from or paths below it to name the same paths under to, so leftovers
+// stay ignored after their directory is renamed. Other lines are kept.
+func (g *Git) RenameExcluded(from, to string) error {
+	excludeFile := filepath.Join(g.repoPath, ".git", "info", "exclude")
+	oldPattern := "/" + filepath.ToSlash(from)
+	newPattern := "/" + filepath.ToSlash(to)
+
+	content, err := os.ReadFile(excludeFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", excludeFile, err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	changed := false
+	for i, line := range lines {
+		pattern := strings.TrimSpace(line)
+		if pattern == oldPattern || strings.HasPrefix(pattern, oldPattern+"/") {
+			lines[i] = newPattern + strings.TrimPrefix(pattern, oldPattern)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := os.WriteFile(excludeFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", excludeFile, err)
+	}
+	return nil
+}
+
 // IsLnkRepository checks if the repository appears to be managed by lnk
 func (g *Git) IsLnkRepository() bool {
 	if !g.IsGitRepository() {
@@ -210,9 +504,10 @@ func (g *Git) RemoveAndCommit(filename, message string) error {
 	return nil
 }
 
-// Add stages a file
+// Add stages a file. The name is taken literally, after "--", so a file
+// called "-f" or "*.conf" stages just that file.
 func (g *Git) Add(filename string) error {
-	cmd := g.execGitCommand(shortTimeout, "add", filename)
+	cmd := g.execGitCommand(shortTimeout, "--literal-pathspecs", "add", "--", filename)
 
 	_, err := cmd.CombinedOutput()
 	if err != nil {
@@ -225,7 +520,27 @@ func (g *Git) Add(filename string) error {
 	return nil
 }
 
-// Remove removes a file from Git tracking
+// Unstage resets the staged state of paths to HEAD, undoing Add for a
+// commit that will not happen. It runs even when the SetContext context is
+// already cancelled, so an interrupted operation can still clean up.
+func (g *Git) Unstage(paths ...string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(g.Context()), shortTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--literal-pathspecs", "reset", "--quiet", "--"}, paths...)...)
+	cmd.Dir = g.repoPath
+	if _, err := cmd.CombinedOutput(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return lnkerror.Wrap(ErrGitTimeout)
+		}
+		return lnkerror.WithSuggestion(ErrGitCommand, "run 'git reset' in the repository to unstage the files by hand")
+	}
+
+	return nil
+}
+
+// Remove removes a file from Git tracking. Like Add, it takes the name
+// literally.
 func (g *Git) Remove(filename string) error {
 	// Check if it's a directory that needs -r flag
 	fullPath := filepath.Join(g.repoPath, filename)
@@ -234,10 +549,10 @@ func (g *Git) Remove(filename string) error {
 	var cmd *exec.Cmd
 	if err == nil && info.IsDir() {
 		// Use -r and --cached flags for directories (only remove from git, not filesystem)
-		cmd = g.execGitCommand(shortTimeout, "rm", "-r", "--cached", filename)
+		cmd = g.execGitCommand(shortTimeout, "--literal-pathspecs", "rm", "-r", "--cached", "--", filename)
 	} else {
 		// Regular file (only remove from git, not filesystem)
-		cmd = g.execGitCommand(shortTimeout, "rm", "--cached", filename)
+		cmd = g.execGitCommand(shortTimeout, "--literal-pathspecs", "rm", "--cached", "--", filename)
 	}
 
 	_, err = cmd.CombinedOutput()
@@ -258,19 +573,128 @@ func (g *Git) Commit(message string) error {
 		return err
 	}
 
-	cmd := g.execGitCommand(shortTimeout, "commit", "-m", message)
+	cmd := untranslated(g.execGitCommand(shortTimeout, g.commitArgs(message)...))
 
-	_, err := cmd.CombinedOutput()
+	output, err := cmd.CombinedOutput()
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return lnkerror.Wrap(ErrGitTimeout)
 		}
+		if isSigningFailure(output) {
+			return lnkerror.WithSuggestion(ErrCommitSign, signingSuggestion)
+		}
 		return lnkerror.WithSuggestion(ErrGitCommand, "ensure you have staged changes and try again")
 	}
 
+	g.emitCommitted(message)
+	return nil
+}
+
+// CommitPaths commits only the given repo-relative paths (staging their
+// current contents), leaving any other staged or unstaged changes alone.
+func (g *Git) CommitPaths(message string, paths []string) error {
+	if err := g.ensureGitConfig(); err != nil {
+		return err
+	}
+
+	args := append(append([]string{"--literal-pathspecs"}, g.commitArgs(message)...), "--")
+	cmd := untranslated(g.execGitCommand(shortTimeout, append(args, paths...)...))
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return lnkerror.Wrap(ErrGitTimeout)
+		}
+		if isSigningFailure(output) {
+			return lnkerror.WithSuggestion(ErrCommitSign, signingSuggestion)
+		}
+		return lnkerror.WithSuggestion(ErrGitCommand, "ensure the paths have changes and try again")
+	}
+
+	g.emitCommitted(message)
+	return nil
+}
+
+// signingSuggestion is shown when git could not sign a commit.
+const signingSuggestion = "check user.signingkey, gpg.format and that your gpg or ssh agent is available, or disable commit.gpgsign"
+
+// commitArgs returns the `git commit` arguments for message, adding -S when
+// signing was requested and --date when an author date was set.
+func (g *Git) commitArgs(message string) []string {
+	args := []string{"commit"}
+	if g.sign {
+		args = append(args, "-S")
+	}
+	if !g.authorDate.IsZero() {
+		args = append(args, "--date="+g.authorDate.Format(time.RFC3339))
+	}
+	return append(args, "-m", message)
+}
+
+// untranslated runs cmd in the C locale, so git and the signer it calls
+// report in English whatever LANG the user has and isSigningFailure can match
+// their messages.
+func untranslated(cmd *exec.Cmd) *exec.Cmd {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "LC_ALL=C")
+	return cmd
+}
+
+// isSigningFailure reports whether git commit output indicates the commit
+// could not be signed (gpg or ssh-keygen failed or is missing).
+func isSigningFailure(output []byte) bool {
+	out := string(output)
+	return strings.Contains(out, "failed to sign") || strings.Contains(out, "Couldn't load public key") ||
+		strings.Contains(out, "cannot run gpg")
+}
+
+// SetIdentity writes user.name and user.email to the repository's local git
+// config, overriding any global identity for commits made in this repo.
+func (g *Git) SetIdentity(name, email string) error {
+	for _, kv := range [][2]string{{"user.name", name}, {"user.email", email}} {
+		cmd := g.execGitCommand(shortTimeout, "config", "--local", kv[0], kv[1])
+		if err := cmd.Run(); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return lnkerror.Wrap(ErrGitTimeout)
+			}
+			return lnkerror.WithSuggestion(ErrGitConfig, "check your git installation")
+		}
+	}
+
 	return nil
 }
 
+// Identity returns the user.name and user.email git will use for commits in
+// the repository, taking system, global and local config into account. Unset
+// values are returned empty.
+func (g *Git) Identity() (name, email string, err error) {
+	get := func(key string) (string, error) {
+		output, err := g.execGitCommand(shortTimeout, "config", key).Output()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return "", lnkerror.Wrap(ErrGitTimeout)
+			}
+			// Exit code 1 means the key is unset.
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				return "", nil
+			}
+			return "", lnkerror.WithSuggestion(ErrGitConfig, "check your git installation")
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	if name, err = get("user.name"); err != nil {
+		return "", "", err
+	}
+	if email, err = get("user.email"); err != nil {
+		return "", "", err
+	}
+	return name, email, nil
+}
+
 // ensureGitConfig ensures that git user.name and user.email are configured
 func (g *Git) ensureGitConfig() error {
 	// Check if user.name is configured
@@ -339,6 +763,157 @@ func (g *Git) GetCommits() ([]string, error) {
 	return commits, nil
 }
 
+// Commit is one commit as Log reports it.
+type Commit struct {
+	Hash    string
+	Date    time.Time // Author date
+	Subject string
+	Paths   []string // Repo-relative paths the commit changed, slash-separated
+}
+
+// Log returns the commits reachable from HEAD, newest first, with the paths
+// each one changed. since limits them to the commits after it: a revision
+// when it names a commit (a hash, tag or branch), or else a date in a
+// ParseDate format. max caps the number of commits when positive. A
+// repository without commits has none.
+func (g *Git) Log(since string, max int) ([]Commit, error) {
+	if g.execGitCommand(shortTimeout, "rev-parse", "--verify", "--quiet", "HEAD").Run() != nil {
+		return nil, nil
+	}
+
+	args := []string{"log", "-z", "--name-only", "--format=%x1e%H%x1f%aI%x1f%s"}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+	if since != "" {
+		if g.execGitCommand(shortTimeout, "rev-parse", "--verify", "--quiet", "--end-of-options", since+"^{commit}").Run() == nil {
+			args = append(args, since+"..HEAD")
+		} else if t, err := ParseDate(since); err == nil {
+			args = append(args, "--since="+t.Format(time.RFC3339))
+		} else {
+			return nil, lnkerror.WithPathAndSuggestion(ErrInvalidDate, since, "give a commit, or a date as RFC 3339 (2006-01-02T15:04:05Z07:00) or 2006-01-02")
+		}
+	}
+	args = append(args, "--")
+
+	output, err := g.execGitCommand(shortTimeout, args...).Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		header, files, _ := strings.Cut(record, "\x00")
+		fields := strings.SplitN(header, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commit := Commit{Hash: fields[0], Subject: fields[2]}
+		commit.Date, _ = time.Parse(time.RFC3339, fields[1])
+		for _, path := range strings.Split(strings.TrimPrefix(files, "\n"), "\x00") {
+			if path != "" {
+				commit.Paths = append(commit.Paths, path)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// HasParentCommit reports whether HEAD has a parent, i.e. is not the first
+// commit.
+func (g *Git) HasParentCommit() bool {
+	cmd := g.execGitCommand(shortTimeout, "rev-parse", "--verify", "--quiet", "HEAD~1")
+	return cmd.Run() == nil
+}
+
+// LastCommitPaths returns the repo-relative paths HEAD changed from its
+// parent.
+func (g *Git) LastCommitPaths() ([]string, error) {
+	cmd := g.execGitCommand(shortTimeout, "diff", "--name-only", "-z", "HEAD~1", "HEAD")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// FileAt returns the content of the repo-relative path at rev (such as
+// "HEAD~1"), or nil when the file does not exist there.
+func (g *Git) FileAt(rev, path string) ([]byte, error) {
+	if err := g.execGitCommand(shortTimeout, "cat-file", "-e", rev+":"+filepath.ToSlash(path)).Run(); err != nil {
+		return nil, nil
+	}
+
+	output, err := g.execGitCommand(shortTimeout, "show", rev+":"+filepath.ToSlash(path)).Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+	return output, nil
+}
+
+// IsHeadPushed reports whether HEAD is contained in any remote-tracking
+// branch, as of the last fetch.
+func (g *Git) IsHeadPushed() (bool, error) {
+	output, err := g.execGitCommand(shortTimeout, "branch", "-r", "--contains", "HEAD").Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return false, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return false, lnkerror.Wrap(ErrGitCommand)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// DropLastCommit removes HEAD from history with `git reset --hard HEAD~1`,
+// resetting the index and working tree to its parent.
+func (g *Git) DropLastCommit() error {
+	cmd := g.execGitCommand(shortTimeout, "reset", "--hard", "--quiet", "HEAD~1")
+
+	if _, err := cmd.CombinedOutput(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return lnkerror.Wrap(ErrGitTimeout)
+		}
+		return lnkerror.WithSuggestion(ErrGitCommand, "check the repository with 'git status'")
+	}
+
+	return nil
+}
+
+// RevertLastCommit records a new commit with message that reverses HEAD,
+// keeping history intact. The working tree need not match HEAD for paths the
+// revert deletes anyway.
+func (g *Git) RevertLastCommit(message string) error {
+	cmd := g.execGitCommand(shortTimeout, "revert", "--no-commit", "HEAD")
+
+	if _, err := cmd.CombinedOutput(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return lnkerror.Wrap(ErrGitTimeout)
+		}
+		_ = g.execGitCommand(shortTimeout, "revert", "--abort").Run()
+		return lnkerror.WithSuggestion(ErrGitCommand, "check the repository with 'git status'")
+	}
+
+	return g.Commit(message)
+}
+
 // GetRemoteInfo returns information about the default remote
 func (g *Git) GetRemoteInfo() (string, error) {
 	// First try to get origin remote
@@ -370,12 +945,28 @@ func (g *Git) GetRemoteInfo() (string, error) {
 	return url, nil
 }
 
-// StatusInfo contains repository status information
+// StatusInfo contains repository status information.
+// Remote is the remote-tracking branch (e.g. origin/main) and RemoteURL the
+// default remote's URL with credentials redacted; both are empty when no
+// remote is configured, and then Behind is always 0. Fetched reports whether
+// the remote was fetched first; when false, Ahead and Behind are based on
+// whatever was last fetched. GetStatus never fetches, so callers that do set
+// it. Branch is the checked-out branch, empty when HEAD is detached.
+// Detached and Shallow flag a repository state push and pull do not handle
+// (see IsDetached and IsShallow). Diverged is set when the branch is both
+// ahead of and behind its remote: a plain push would be rejected as a
+// non-fast-forward until the remote commits are pulled or rebased onto.
 type StatusInfo struct {
-	Ahead  int
-	Behind int
-	Remote string
-	Dirty  bool
+	Ahead     int
+	Behind    int
+	Diverged  bool
+	Branch    string
+	Remote    string
+	RemoteURL string
+	Dirty     bool
+	Fetched   bool
+	Detached  bool
+	Shallow   bool
 }
 
 // GetStatus returns the repository status relative to remote.
@@ -388,19 +979,25 @@ func (g *Git) GetStatus() (*StatusInfo, error) {
 	if err != nil {
 		return nil, lnkerror.WithSuggestion(ErrUncommitted, "verify your git repository is valid")
 	}
+	detached, shallow := g.IsDetached(), g.IsShallow()
 
 	// Check if we have a remote — if not, fall back to local-only status.
-	if _, err := g.GetRemoteInfo(); err != nil {
+	remoteURL, err := g.GetRemoteInfo()
+	if err != nil {
 		if errors.Is(err, ErrNoRemote) {
 			return &StatusInfo{
-				Ahead:  g.getLocalCommitCount(),
-				Behind: 0,
-				Remote: "",
-				Dirty:  dirty,
+				Ahead:    g.getLocalCommitCount(),
+				Behind:   0,
+				Branch:   g.currentBranch(),
+				Remote:   "",
+				Dirty:    dirty,
+				Detached: detached,
+				Shallow:  shallow,
 			}, nil
 		}
 		return nil, err
 	}
+	remoteURL = RedactURL(remoteURL)
 
 	// Get the remote tracking branch
 	cmd := g.execGitCommand(shortTimeout, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
@@ -413,23 +1010,103 @@ func (g *Git) GetStatus() (*StatusInfo, error) {
 		// No upstream branch set, assume origin/main
 		remoteBranch := "origin/main"
 		return &StatusInfo{
-			Ahead:  g.getAheadCount(remoteBranch),
-			Behind: 0, // Can't be behind if no upstream
-			Remote: remoteBranch,
-			Dirty:  dirty,
+			Ahead:     g.getAheadCount(remoteBranch),
+			Behind:    0, // Can't be behind if no upstream
+			Branch:    g.currentBranch(),
+			Remote:    remoteBranch,
+			RemoteURL: remoteURL,
+			Dirty:     dirty,
+			Detached:  detached,
+			Shallow:   shallow,
 		}, nil
 	}
 
 	remoteBranch := strings.TrimSpace(string(output))
+	ahead, behind := g.getAheadCount(remoteBranch), g.getBehindCount(remoteBranch)
 
 	return &StatusInfo{
-		Ahead:  g.getAheadCount(remoteBranch),
-		Behind: g.getBehindCount(remoteBranch),
-		Remote: remoteBranch,
-		Dirty:  dirty,
+		Ahead:     ahead,
+		Behind:    behind,
+		Diverged:  ahead > 0 && behind > 0,
+		Branch:    g.currentBranch(),
+		Remote:    remoteBranch,
+		RemoteURL: remoteURL,
+		Dirty:     dirty,
+		Detached:  detached,
+		Shallow:   shallow,
 	}, nil
 }
 
+// RedactURL hides credentials embedded in a remote URL so it can be shown.
+// A password is replaced with "xxxxx"; so is a bare user name on http(s)
+// URLs, where it is usually an access token. scp-style addresses such as
+// git@github.com:user/dotfiles.git and local paths are returned unchanged.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return rawURL
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return u.Redacted()
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		u.User = url.User("xxxxx")
+		return u.String()
+	}
+	return rawURL
+}
+
+// IsDetached reports whether HEAD points at a commit instead of a branch
+// (git symbolic-ref -q HEAD fails), after checking out a tag or commit by
+// hand. Pushes then have no branch to update and pulls none to merge into.
+func (g *Git) IsDetached() bool {
+	err := g.execGitCommand(shortTimeout, "symbolic-ref", "--quiet", "HEAD").Run()
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+}
+
+// IsShallow reports whether the repository is a shallow clone (.git/shallow
+// exists), whose history stops at the commits it was cloned with. Pushes
+// from one can be rejected and merges fail to find a common ancestor.
+func (g *Git) IsShallow() bool {
+	_, err := os.Stat(filepath.Join(g.repoPath, ".git", "shallow"))
+	return err == nil
+}
+
+// IsRebasing reports whether a rebase stopped partway, e.g. on conflicts,
+// and is waiting for 'git rebase --continue' or '--abort'.
+func (g *Git) IsRebasing() bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(g.repoPath, ".git", dir)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rebaseConflict reports a pull --rebase that stopped on conflicts, naming
+// the conflicted files and how to finish or undo the rebase. The repository
+// is left mid-rebase so nothing is lost.
+func (g *Git) rebaseConflict() error {
+	files, err := g.ConflictedFiles()
+	if err != nil || len(files) == 0 {
+		files = []string{g.repoPath}
+	}
+	return lnkerror.WithPathAndSuggestion(ErrRebaseConflict, strings.Join(files, ", "),
+		fmt.Sprintf("resolve the conflicts in %s and 'git add' them, then run 'git -C %s rebase --continue'; or run 'git -C %s rebase --abort' to go back to before the pull", g.repoPath, g.repoPath, g.repoPath))
+}
+
+// currentBranch returns the short name of the checked-out branch, which may
+// not have commits yet, or "" when HEAD is detached.
+func (g *Git) currentBranch() string {
+	cmd := g.execGitCommand(shortTimeout, "symbolic-ref", "--quiet", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // getLocalCommitCount returns the total number of commits on HEAD, or 0 if
 // there are no commits yet (fresh repo).
 func (g *Git) getLocalCommitCount() int {
@@ -501,9 +1178,14 @@ func (g *Git) getBehindCount(remoteBranch string) int {
 	return behind
 }
 
-// HasChanges checks if there are uncommitted changes
-func (g *Git) HasChanges() (bool, error) {
-	cmd := g.execGitCommand(shortTimeout, "status", "--porcelain")
+// HasChanges checks if there are uncommitted changes, optionally limited to
+// the given repo-relative paths.
+func (g *Git) HasChanges(paths ...string) (bool, error) {
+	args := []string{"status", "--porcelain"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := g.execGitCommand(shortTimeout, args...)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -516,6 +1198,83 @@ func (g *Git) HasChanges() (bool, error) {
 	return len(strings.TrimSpace(string(output))) > 0, nil
 }
 
+// ChangedPaths returns the repo-relative paths `git status` reports as
+// changed, staged or untracked, listing each file inside an untracked
+// directory. With paths, only changes under them are reported.
+func (g *Git) ChangedPaths(paths ...string) ([]string, error) {
+	args := []string{"status", "--porcelain", "-z", "--untracked-files=all"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := g.execGitCommand(shortTimeout, args...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	var changed []string
+	records := strings.Split(string(output), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		changed = append(changed, record[3:])
+		// Renames and copies are followed by their original path.
+		if record[0] == 'R' || record[0] == 'C' {
+			i++
+		}
+	}
+	return changed, nil
+}
+
+// ConflictedFiles returns the repo-relative paths that are currently unmerged,
+// e.g. after a pull that stopped on merge conflicts.
+func (g *Git) ConflictedFiles() ([]string, error) {
+	cmd := g.execGitCommand(shortTimeout, "diff", "--name-only", "-z", "--diff-filter=U")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// TrackedFiles returns the repo-relative paths of every file in the Git index.
+func (g *Git) TrackedFiles() ([]string, error) {
+	cmd := g.execGitCommand(shortTimeout, "ls-files", "-z")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, lnkerror.Wrap(ErrGitTimeout)
+		}
+		return nil, lnkerror.Wrap(ErrGitCommand)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
 // Diff returns the diff output for uncommitted changes in the repository.
 // If color is true, the output will include ANSI color codes.
 func (g *Git) Diff(color bool) (string, error) {
@@ -556,6 +1315,51 @@ func (g *Git) HasDiff() (bool, error) {
 	return false, lnkerror.Wrap(ErrDiff)
 }
 
+// HasStagedChanges reports whether the index holds changes that the next
+// commit would record, using `git diff --cached --quiet`.
+func (g *Git) HasStagedChanges() (bool, error) {
+	cmd := g.execGitCommand(shortTimeout, "diff", "--cached", "--quiet")
+
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false, lnkerror.Wrap(ErrGitTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, lnkerror.Wrap(ErrGitCommand)
+}
+
+// DiffFiles returns a patch from pathA to pathB using `git diff --no-index`,
+// which works on arbitrary paths outside the repository. The result is empty
+// when both sides are identical.
+func (g *Git) DiffFiles(pathA, pathB string, color bool) (string, error) {
+	colorFlag := "--color=never"
+	if color {
+		colorFlag = "--color=always"
+	}
+
+	cmd := g.execGitCommand(shortTimeout, "diff", "--no-index", colorFlag, "--", pathA, pathB)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", lnkerror.Wrap(ErrGitTimeout)
+		}
+		// --no-index exits 1 when the inputs differ.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return "", lnkerror.Wrap(ErrDiff)
+		}
+	}
+
+	return string(output), nil
+}
+
 // AddAll stages all changes in the repository
 func (g *Git) AddAll() error {
 	cmd := g.execGitCommand(shortTimeout, "add", "-A")
@@ -571,48 +1375,295 @@ func (g *Git) AddAll() error {
 	return nil
 }
 
-// Push pushes changes to remote
+// authSuggestion is the fix ErrAuthRequired offers.
+const authSuggestion = "run the command in a terminal, or set up an SSH agent or a git credential helper"
+
+// stdinTerminal reports whether lnk's stdin is a terminal, so credential
+// helpers and ssh spawned by git can prompt on it. /dev/null is a character
+// device too, but nobody can answer a prompt there.
+func stdinTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// prepareRemote sets up cmd, a clone, push, pull or fetch, for credential
+// prompts and reports whether they can be answered. With a terminal on stdin,
+// git's stdin is connected to it so password, passphrase and host-key prompts
+// reach the user instead of hanging behind the buffered output. Without one,
+// git's own prompts are disabled (GIT_TERMINAL_PROMPT=0) and ssh runs with
+// BatchMode, so a missing credential fails at once rather than waiting for
+// input that never comes. An SSH agent or credential helper still works in
+// both cases; a user-set GIT_TERMINAL_PROMPT or GIT_SSH is left alone. The
+// SetSSHCommand command is used unless the environment names its own ssh.
+func (g *Git) prepareRemote(cmd *exec.Cmd) bool {
+	envSSH := os.Getenv("GIT_SSH_COMMAND")
+	userSSH := envSSH != "" || os.Getenv("GIT_SSH") != ""
+
+	if stdinTerminal() {
+		cmd.Stdin = os.Stdin
+		if g.sshCommand != "" && !userSSH {
+			cmd.Env = append(os.Environ(), "GIT_SSH_COMMAND="+g.sshCommand)
+		}
+		return true
+	}
+
+	env := os.Environ()
+	if _, ok := os.LookupEnv("GIT_TERMINAL_PROMPT"); !ok {
+		env = append(env, "GIT_TERMINAL_PROMPT=0")
+	}
+	if os.Getenv("GIT_SSH") == "" {
+		sshCommand := envSSH
+		if sshCommand == "" {
+			sshCommand = g.sshCommand
+		}
+		if sshCommand == "" {
+			sshCommand = g.configuredSSHCommand()
+		}
+		env = append(env, "GIT_SSH_COMMAND="+sshCommand+" -o BatchMode=yes")
+	}
+	cmd.Env = env
+	return false
+}
+
+// configuredSSHCommand returns core.sshCommand, or "ssh" when it is unset.
+// It reads the repository's config when the repository exists and the
+// global one otherwise, as a clone would.
+func (g *Git) configuredSSHCommand() string {
+	cmd := g.execGitCommand(shortTimeout, "config", "--get", "core.sshCommand")
+	if !g.IsGitRepository() {
+		cmd.Dir = ""
+	}
+	output, err := cmd.Output()
+	if command := strings.TrimSpace(string(output)); err == nil && command != "" {
+		return command
+	}
+	return "ssh"
+}
+
+// isAuthFailure reports whether git's output says a remote operation stopped
+// for a credential, passphrase or host key it could not ask for.
+func isAuthFailure(output []byte) bool {
+	text := string(output)
+	for _, marker := range []string{
+		"terminal prompts disabled",
+		"could not read Username",
+		"could not read Password",
+		"Host key verification failed",
+		"Permission denied (publickey",
+		"Authentication failed",
+	} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientFailure reports whether git's output says a remote operation
+// failed on the network in a way that may well work on a second try: the
+// host could not be resolved or reached, or the connection broke off.
+func isTransientFailure(output []byte) bool {
+	text := string(output)
+	for _, marker := range []string{
+		"Could not resolve host",
+		"Temporary failure in name resolution",
+		"Connection reset",
+		"Connection refused",
+		"Connection timed out",
+		"Operation timed out",
+		"Network is unreachable",
+		"No route to host",
+		"The remote end hung up unexpectedly",
+		"early EOF",
+		"RPC failed",
+	} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// runRemote runs the push, pull or fetch that newCmd builds and returns its
+// combined output, and whether git could prompt for credentials. A failure
+// that looks transient is retried, each try with a fresh command, up to the
+// SetRetries count and with a doubling wait; authentication failures,
+// rejections, timeouts and a cancelled context end it at once.
+func (g *Git) runRemote(op string, newCmd func() *exec.Cmd) ([]byte, bool, error) {
+	wait := g.backoff
+	for attempt := 1; ; attempt++ {
+		cmd := newCmd()
+		interactive := g.prepareRemote(cmd)
+		output, err := cmd.CombinedOutput()
+		if err == nil || attempt > g.retries || errors.Is(err, context.DeadlineExceeded) ||
+			isAuthFailure(output) || !isTransientFailure(output) {
+			return output, interactive, err
+		}
+
+		if g.onRetry != nil {
+			g.onRetry(op, attempt, g.retries, wait)
+		}
+		select {
+		case <-time.After(wait):
+		case <-g.Context().Done():
+			return output, interactive, err
+		}
+		wait *= 2
+	}
+}
+
+// Push pushes the current branch to the remote (see SetRemote). Pushing to
+// the default remote sets it as the branch's upstream; pushing to any other
+// remote leaves the upstream alone, so status and pull keep following the
+// default.
 func (g *Git) Push() error {
-	// First ensure we have a remote configured
-	_, err := g.GetRemoteInfo()
+	remote, isDefault, err := g.remoteName()
 	if err != nil {
-		return lnkerror.WithSuggestion(ErrPush, err.Error())
+		return remoteError(ErrPush, err)
 	}
+	return g.pushTo(remote, isDefault)
+}
 
-	cmd := g.execGitCommand(longTimeout, "push", "-u", "origin")
+// PushTo pushes the current branch to the remote called name, as Push does
+// after SetRemote(name).
+func (g *Git) PushTo(name string) error {
+	remote, isDefault, err := g.resolveRemote(name)
+	if err != nil {
+		return remoteError(ErrPush, err)
+	}
+	return g.pushTo(remote, isDefault)
+}
 
-	_, err = cmd.CombinedOutput()
+// pushTo runs the push to remote, setting it as upstream when asked. HEAD is
+// always named, so the first push of a branch with no upstream yet, such as
+// after 'lnk remote add', does not fail under push.default=simple.
+func (g *Git) pushTo(remote string, upstream bool) error {
+	args := []string{"push", remote, "HEAD"}
+	if upstream {
+		args = []string{"push", "-u", remote, "HEAD"}
+	}
+	output, interactive, err := g.runRemote("push", func() *exec.Cmd {
+		return g.execGitCommand(longTimeout, args...)
+	})
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return lnkerror.Wrap(ErrGitTimeout)
 		}
+		if !interactive && isAuthFailure(output) {
+			return lnkerror.WithSuggestion(ErrAuthRequired, authSuggestion)
+		}
 		return lnkerror.WithSuggestion(ErrPush, "check your network connection and repository permissions")
 	}
 
+	if g.onEvent != nil {
+		g.onEvent(event.Event{Kind: event.GitPushed, Remote: remote})
+	}
 	return nil
 }
 
-// Pull pulls changes from remote
+// Pull pulls changes from the remote (see SetRemote). From a remote other
+// than the default, the current branch's namesake is merged, since the
+// upstream configured for the branch belongs to the default remote.
 func (g *Git) Pull() error {
-	// First ensure we have a remote configured
-	_, err := g.GetRemoteInfo()
+	remote, isDefault, err := g.remoteName()
 	if err != nil {
-		return lnkerror.WithSuggestion(ErrPull, err.Error())
+		return remoteError(ErrPull, err)
 	}
 
-	cmd := g.execGitCommand(longTimeout, "pull", "origin")
+	// A pull may need to create a merge commit, or rewrite local ones.
+	if err := g.ensureGitConfig(); err != nil {
+		return err
+	}
 
-	_, err = cmd.CombinedOutput()
+	args := []string{"pull"}
+	if g.rebase {
+		// Uncommitted edits to managed files are common; stash them around
+		// the rebase rather than refusing to start.
+		args = append(args, "--rebase", "--autostash")
+	}
+	args = append(args, remote)
+	if branch := g.currentBranch(); !isDefault && branch != "" {
+		args = append(args, branch)
+	}
+	output, interactive, err := g.runRemote("pull", func() *exec.Cmd {
+		return g.execGitCommand(longTimeout, args...)
+	})
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return lnkerror.Wrap(ErrGitTimeout)
 		}
+		if !interactive && isAuthFailure(output) {
+			return lnkerror.WithSuggestion(ErrAuthRequired, authSuggestion)
+		}
+		if g.IsRebasing() {
+			return g.rebaseConflict()
+		}
 		return lnkerror.WithSuggestion(ErrPull, "check your network connection and resolve any conflicts")
 	}
 
 	return nil
 }
 
+// Fetch updates the remote-tracking refs from the remote (see SetRemote)
+// without touching the working tree, so ahead/behind counts reflect the
+// remote's current state.
+func (g *Git) Fetch() error {
+	remote, _, err := g.remoteName()
+	if err != nil {
+		return remoteError(ErrFetch, err)
+	}
+
+	output, interactive, err := g.runRemote("fetch", func() *exec.Cmd {
+		return g.execGitCommand(longTimeout, "fetch", remote)
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return lnkerror.Wrap(ErrGitTimeout)
+		}
+		if !interactive && isAuthFailure(output) {
+			return lnkerror.WithSuggestion(ErrAuthRequired, authSuggestion)
+		}
+		return lnkerror.WithSuggestion(ErrFetch, "check your network connection and try again")
+	}
+
+	return nil
+}
+
+// LsRemote lists the refs of the remote (see SetRemote) with `git ls-remote`,
+// which connects and authenticates to it without changing anything on either
+// side. It returns the remote's name and git's output, stderr included, also
+// on failure, so the reason can be shown as git gave it. Failures are not
+// retried: the point is to see how the remote answers now.
+func (g *Git) LsRemote() (string, string, error) {
+	remote, _, err := g.remoteName()
+	if err != nil {
+		return "", "", remoteError(ErrLsRemote, err)
+	}
+
+	cmd := g.execGitCommand(longTimeout, "ls-remote", remote)
+	interactive := g.prepareRemote(cmd)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return remote, string(output), lnkerror.Wrap(ErrGitTimeout)
+		}
+		if !interactive && isAuthFailure(output) {
+			return remote, string(output), lnkerror.WithSuggestion(ErrAuthRequired, authSuggestion)
+		}
+		if isTransientFailure(output) {
+			return remote, string(output), lnkerror.WithPathAndSuggestion(ErrLsRemote, remote, "check your network connection and the host name in the URL")
+		}
+		return remote, string(output), lnkerror.WithPathAndSuggestion(ErrLsRemote, remote, "check the URL and that your account can read the repository")
+	}
+
+	return remote, string(output), nil
+}
+
 // Clone clones a repository from the given URL
 func (g *Git) Clone(url string) error {
 	// Remove the directory if it exists to ensure clean clone
@@ -629,15 +1680,19 @@ func (g *Git) Clone(url string) error {
 	// Clone the repository
 	// Note: Can't use execGitCommand here because it sets cmd.Dir to g.repoPath,
 	// which doesn't exist yet. Clone needs to run from parent directory.
-	ctx, cancel := context.WithTimeout(context.Background(), longTimeout)
+	ctx, cancel := context.WithTimeout(g.Context(), longTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "git", "clone", url, g.repoPath)
-	_, err := cmd.CombinedOutput()
+	interactive := g.prepareRemote(cmd)
+	output, err := cmd.CombinedOutput()
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return lnkerror.Wrap(ErrGitTimeout)
 		}
+		if !interactive && isAuthFailure(output) {
+			return lnkerror.WithSuggestion(ErrAuthRequired, authSuggestion)
+		}
 		return lnkerror.WithSuggestion(ErrGitCommand, "check the repository URL and your network connection")
 	}
 
@@ -4,7 +4,13 @@ package initializer
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/yarlson/lnk/internal/config"
 	"github.com/yarlson/lnk/internal/git"
 	"github.com/yarlson/lnk/internal/lnkerror"
 	"github.com/yarlson/lnk/internal/tracker"
@@ -68,11 +74,318 @@ func (i *Service) Clone(url string) error {
 
 // AddRemote adds a remote to the repository.
 func (i *Service) AddRemote(name, url string) error {
+	if !i.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
 	return i.git.AddRemote(name, url)
 }
 
-// HasUserContent checks if the repository contains any user-managed content.
+// Remote is a remote repository configured for the lnk repository. Default
+// marks the one push, pull and fetch use when no remote is named.
+type Remote struct {
+	Name    string
+	URL     string
+	Default bool
+}
+
+// Remotes returns the repository's remotes in git's order.
+func (i *Service) Remotes() ([]Remote, error) {
+	if !i.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	names, err := i.git.Remotes()
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	defaultRemote, err := i.git.DefaultRemote()
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]Remote, 0, len(names))
+	for _, name := range names {
+		url, err := i.git.RemoteURL(name)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, Remote{Name: name, URL: url, Default: name == defaultRemote})
+	}
+	return remotes, nil
+}
+
+// RemoteCheck reports how a remote answered VerifyRemote.
+type RemoteCheck struct {
+	Remote Remote // The remote that was checked
+	Refs   int    // Branches and tags it advertises; 0 for an empty repository
+	Output string // git's output, stderr included
+}
+
+// VerifyRemote checks that the remote push and pull would use can be reached
+// and read with the credentials at hand, using `git ls-remote`, so nothing is
+// fetched or changed. When the remote exists, the check comes back with
+// git's output even if the error says it could not be reached.
+func (i *Service) VerifyRemote() (*RemoteCheck, error) {
+	if !i.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	name, output, err := i.git.LsRemote()
+	if name == "" {
+		return nil, err
+	}
+	defaultRemote, defaultErr := i.git.DefaultRemote()
+	if defaultErr != nil {
+		return nil, defaultErr
+	}
+	url, urlErr := i.git.RemoteURL(name)
+	if urlErr != nil {
+		return nil, urlErr
+	}
+
+	check := &RemoteCheck{Remote: Remote{Name: name, URL: url, Default: name == defaultRemote}, Output: output}
+	if err != nil {
+		return check, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if hash, _, ok := strings.Cut(line, "\t"); ok && len(hash) >= 40 {
+			check.Refs++
+		}
+	}
+	return check, nil
+}
+
+// SetIdentity records a repository-specific commit identity in the repo's
+// local git config, for users who want their dotfiles committed under a
+// different name or email than their global git identity.
+func (i *Service) SetIdentity(name, email string) error {
+	if !i.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if name == "" {
+		return lnkerror.WithSuggestion(lnkerror.ErrInvalidIdentity, "name must not be empty")
+	}
+	if !strings.Contains(email, "@") {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidIdentity, email, "email must look like user@example.com")
+	}
+
+	return i.git.SetIdentity(name, email)
+}
+
+// Identity returns the name and email lnk's commits will be authored with.
+func (i *Service) Identity() (name, email string, err error) {
+	if !i.git.IsGitRepository() {
+		return "", "", lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	return i.git.Identity()
+}
+
+// SetConfig writes name = value to the config file at path. The repository's
+// own config.toml is shared with other machines, so a change to it is
+// committed and goes out with the next push; a file elsewhere is only written.
+// Setting index.file there also moves the existing tracking files to the new
+// location in the same commit.
+func (i *Service) SetConfig(path, name, value string) error {
+	shared := path == filepath.Join(i.repoPath, config.FileName)
+	if shared && !i.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first, or use --user for a machine-local setting")
+	}
+
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := config.Set(path, name, value); err != nil {
+		return err
+	}
+	if !shared {
+		return nil
+	}
+
+	paths := []string{config.FileName}
+	if name == "index.file" {
+		moved, err := i.relocateIndex(path)
+		if err != nil {
+			// Leave the setting as it was, so the index is still found.
+			if previous == nil {
+				_ = os.Remove(path)
+			} else {
+				_ = os.WriteFile(path, previous, 0644)
+			}
+			return err
+		}
+		paths = append(paths, moved...)
+	}
+
+	changed, err := i.git.HasChanges(paths...)
+	if err != nil || !changed {
+		return err
+	}
+	if err := i.git.Add(config.FileName); err != nil {
+		return err
+	}
+	return i.git.CommitPaths("lnk: set "+name, paths)
+}
+
+// relocateIndex moves the common and host tracking files from where the
+// Tracker looks for them to the index.file location now in the config file
+// at path, staging both sides. It returns the repo-relative paths it
+// touched. Tracking files with uncommitted changes, or a file already at the
+// new location, stop it before anything moves.
+func (i *Service) relocateIndex(path string) ([]string, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	from := i.tracker.ForHost("")
+	to := i.tracker.ForHost("")
+	to.SetIndexFile(cfg.IndexFile)
+
+	hosts, err := from.Hosts()
+	if err != nil {
+		return nil, err
+	}
+	type move struct{ from, to string }
+	var moves []move
+	for _, host := range append([]string{""}, hosts...) {
+		m := move{from.ForHost(host).LnkFileName(), to.ForHost(host).LnkFileName()}
+		if m.from == m.to {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(i.repoPath, m.from)); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(i.repoPath, m.to)); err == nil {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrStorageOccupied, m.to, "move or remove that file first, or pick another index.file")
+		}
+		moves = append(moves, m)
+	}
+	if len(moves) == 0 {
+		i.tracker.SetIndexFile(cfg.IndexFile)
+		return nil, nil
+	}
+
+	var paths []string
+	for _, m := range moves {
+		paths = append(paths, m.from, m.to)
+	}
+	dirty, err := i.git.HasChanges(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrUncommitted, "run 'lnk push' first so moving the tracking files does not commit unrelated edits")
+	}
+
+	for _, m := range moves {
+		target := filepath.Join(i.repoPath, m.to)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", m.to, err)
+		}
+		if err := i.git.Remove(m.from); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(filepath.Join(i.repoPath, m.from), target); err != nil {
+			return nil, fmt.Errorf("failed to move %s to %s: %w", m.from, m.to, err)
+		}
+		if err := i.git.Add(m.to); err != nil {
+			return nil, err
+		}
+	}
+	i.tracker.SetIndexFile(cfg.IndexFile)
+	return paths, nil
+}
+
+// ImportCandidates infers the manifest for an existing dotfiles repository
+// that was not created by lnk: every file Git tracks, laid out relative to
+// $HOME, minus the files that belong to the repository itself. The repository
+// must not have a manifest yet.
+func (i *Service) ImportCandidates() ([]string, error) {
+	if !i.git.IsGitRepository() {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrNotInitialized, i.repoPath, "clone your dotfiles repository there first, or pass --remote")
+	}
+	if i.HasUserContent() {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrManagedFilesExist, i.repoPath, "the repository already has a manifest; use 'lnk pull' to restore symlinks")
+	}
+
+	files, err := i.git.TrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, file := range files {
+		if !isRepoInternal(file) {
+			candidates = append(candidates, filepath.FromSlash(file))
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates, nil
+}
+
+// Import writes items as the common manifest and commits it. Items are
+// repo-relative paths as returned by ImportCandidates; the files themselves
+// are already in place, so only the index changes.
+func (i *Service) Import(items []string) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	entries := make([]tracker.Entry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, tracker.Entry{Path: item, AddedAt: now})
+	}
+
+	if err := i.tracker.WriteEntries(tracker.NormalizeEntries(entries)); err != nil {
+		return fmt.Errorf("failed to update tracking file: %w", err)
+	}
+
+	if err := i.git.Add(i.tracker.LnkFileName()); err != nil {
+		return err
+	}
+
+	return i.git.Commit(fmt.Sprintf("lnk: imported %d files", len(items)))
+}
+
+// isRepoInternal reports whether a tracked, slash-separated path belongs to
+// the repository rather than to $HOME: Git metadata, the bootstrap script,
+// top-level docs and anything already in lnk's own layout.
+func isRepoInternal(file string) bool {
+	first, _, nested := strings.Cut(file, "/")
+	if nested {
+		return first == ".git" || first == ".github" || strings.HasSuffix(first, ".lnk")
+	}
+
+	switch file {
+	case ".gitignore", ".gitattributes", ".gitmodules", ".lnkignore", "bootstrap.sh", config.FileName:
+		return true
+	}
+	if file == ".lnk" || strings.HasPrefix(file, ".lnk.") {
+		return true
+	}
+
+	name := strings.ToUpper(strings.TrimSuffix(file, path.Ext(file)))
+	return name == "README" || name == "LICENSE" || name == "CHANGELOG"
+}
+
+// IsInitialized reports whether the repository has been created.
+func (i *Service) IsInitialized() bool {
+	return i.git.IsGitRepository()
+}
+
+// HasUserContent checks if the repository contains any user-managed content:
+// a tracking file at the index.file location or, for repositories written
+// before it was set, at the root.
 func (i *Service) HasUserContent() bool {
+	common := i.tracker.ForHost("")
+	if _, err := os.Stat(filepath.Join(i.repoPath, common.LnkFileName())); err == nil {
+		return true
+	}
+	if hosts, err := common.Hosts(); err == nil && len(hosts) > 0 {
+		return true
+	}
+
 	entries, err := os.ReadDir(i.repoPath)
 	if err != nil {
 		return false
@@ -0,0 +1,117 @@
+package initializer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yarlson/lnk/internal/git"
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// InitFromTemplate creates the lnk repository and seeds it with the files of
+// the starter repository at url: its bootstrap script, .gitattributes,
+// example configs and anything else outside .git. The template is cloned into
+// a temporary directory and only its files are copied, so the new repository
+// has none of its history and no remote. The copies are committed as
+// "lnk: seeded from template" and returned as sorted repo-relative paths.
+func (i *Service) InitFromTemplate(url string) ([]string, error) {
+	if i.git.IsGitRepository() {
+		return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrGitRepoExists, i.repoPath, "--template only seeds a new repository; move the existing one away first")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "lnk-template-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	templateDir := filepath.Join(tmpDir, "template")
+	template := git.New(templateDir)
+	template.SetContext(i.git.Context())
+	if err := template.Clone(url); err != nil {
+		return nil, err
+	}
+
+	if err := i.Init(); err != nil {
+		return nil, err
+	}
+
+	files, err := copyTemplate(templateDir, i.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+
+	if err := i.git.AddAll(); err != nil {
+		return nil, err
+	}
+	if err := i.git.Commit("lnk: seeded from template"); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// copyTemplate copies every regular file and symlink below src, except the
+// .git directory, to the same place below dst and returns their relative
+// paths. Regular files keep their permissions; anything else is skipped.
+func copyTemplate(src, dst string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == src {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", target, err)
+			}
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", path, err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", target, err)
+			}
+		default:
+			return nil
+		}
+
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
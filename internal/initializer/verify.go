@@ -0,0 +1,87 @@
+package initializer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Verify checks an existing repository against its tracking files, cheaply
+// enough to run on every init: the tracking files must parse, every item
+// they list must be stored in the repository, and every file Git tracks
+// outside lnk's own must be listed. It returns one problem per line, or none
+// when the repository is consistent.
+func (i *Service) Verify() ([]string, error) {
+	if !i.git.IsGitRepository() {
+		return nil, nil
+	}
+
+	files, err := i.git.TrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	common := i.tracker.ForHost("")
+	hosts, err := common.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	// A repository with only host configurations has no common index.
+	var problems []string
+	if _, err := os.Stat(filepath.Join(i.repoPath, common.LnkFileName())); os.IsNotExist(err) && len(hosts) == 0 && len(files) > 0 {
+		problems = append(problems, filepath.ToSlash(common.LnkFileName())+" is missing, so nothing in the repository is managed (run 'lnk init --import-existing' to adopt its files)")
+	}
+
+	var stored []string
+	unreadable := make(map[string]bool)
+	for _, host := range append([]string{""}, hosts...) {
+		t := common.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s cannot be read: %v", t.LnkFileName(), err))
+			unreadable[host] = true
+			continue
+		}
+		for _, entry := range entries {
+			if _, err := os.Lstat(t.StoragePath(entry)); err != nil {
+				problems = append(problems, fmt.Sprintf("~/%s is listed in %s but missing from the repository", filepath.ToSlash(entry.Path), t.LnkFileName()))
+			}
+			stored = append(stored, filepath.ToSlash(t.GitPath(entry)))
+		}
+	}
+
+	for _, file := range files {
+		if _, isIndex := common.IndexHost(file); isIndex || listed(file, stored) {
+			continue
+		}
+		host := ""
+		if first, _, nested := strings.Cut(file, "/"); nested && strings.HasSuffix(first, ".lnk") {
+			host = strings.TrimSuffix(first, ".lnk")
+		} else if isRepoInternal(file) {
+			continue
+		}
+		if unreadable[host] {
+			continue
+		}
+		if host != "" && !slices.Contains(hosts, host) {
+			problems = append(problems, fmt.Sprintf("%s is stored for host %s, which has no %s", file, host, filepath.ToSlash(common.ForHost(host).LnkFileName())))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s is in the repository but not listed in %s", file, filepath.ToSlash(common.ForHost(host).LnkFileName())))
+	}
+	return problems, nil
+}
+
+// listed reports whether the slash-separated, repo-relative path is one of
+// the stored items or inside a stored directory.
+func listed(path string, stored []string) bool {
+	for _, item := range stored {
+		if path == item || strings.HasPrefix(path, item+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,16 @@
 package lnk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
 )
 
 // Test core add functionality with files
@@ -19,7 +26,7 @@ func (suite *CoreTestSuite) TestCoreFileOperations() {
 	suite.Require().NoError(err)
 
 	// Add the file
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Verify symlink and repo file
@@ -40,7 +47,7 @@ func (suite *CoreTestSuite) TestCoreFileOperations() {
 	suite.Equal(content, string(repoContent))
 
 	// Test remove
-	err = suite.lnk.Remove(testFile)
+	_, err = suite.lnk.Remove(testFile)
 	suite.Require().NoError(err)
 
 	// Verify symlink is gone and regular file is restored
@@ -54,6 +61,39 @@ func (suite *CoreTestSuite) TestCoreFileOperations() {
 	suite.Equal(content, string(restoredContent))
 }
 
+// TestAddUnusualFileNames verifies that files whose names start with a dash
+// or contain spaces are added, committed and removed like any other, without
+// git reading the name as an option.
+func (suite *CoreTestSuite) TestAddUnusualFileNames() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	names := []string{"-rf", "my config file", "--all"}
+	for _, name := range names {
+		path := filepath.Join(suite.tempDir, name)
+		suite.Require().NoError(os.WriteFile(path, []byte(name), 0644))
+		_, err := suite.lnk.Add(path)
+		suite.Require().NoError(err, name)
+	}
+
+	out, err := exec.Command("git", "-C", repoPath, "ls-files").Output()
+	suite.Require().NoError(err)
+	for _, name := range names {
+		suite.Contains(strings.Split(string(out), "\n"), name)
+	}
+	status, err := suite.lnk.Status()
+	suite.Require().NoError(err)
+	suite.False(status.Dirty)
+
+	for _, name := range names {
+		_, err := suite.lnk.Remove(filepath.Join(suite.tempDir, name))
+		suite.Require().NoError(err, name)
+	}
+	out, err = exec.Command("git", "-C", repoPath, "ls-files").Output()
+	suite.Require().NoError(err)
+	suite.Equal(".lnk\n", string(out))
+}
+
 // Test core add/remove functionality with directories
 func (suite *CoreTestSuite) TestCoreDirectoryOperations() {
 	err := suite.lnk.Init()
@@ -70,7 +110,7 @@ func (suite *CoreTestSuite) TestCoreDirectoryOperations() {
 	suite.Require().NoError(err)
 
 	// Add the directory
-	err = suite.lnk.Add(testDir)
+	_, err = suite.lnk.Add(testDir)
 	suite.Require().NoError(err)
 
 	// Verify directory is now a symlink
@@ -86,7 +126,7 @@ func (suite *CoreTestSuite) TestCoreDirectoryOperations() {
 	suite.DirExists(repoDir)
 
 	// Remove the directory
-	err = suite.lnk.Remove(testDir)
+	_, err = suite.lnk.Remove(testDir)
 	suite.Require().NoError(err)
 
 	// Verify symlink is gone and regular directory is restored
@@ -126,7 +166,7 @@ func (suite *CoreTestSuite) TestSameBasenameFilesOverwrite() {
 	suite.Require().NoError(err)
 
 	// Add first file
-	err = suite.lnk.Add(fileA)
+	_, err = suite.lnk.Add(fileA)
 	suite.Require().NoError(err)
 
 	// Verify first file is managed correctly and preserves content
@@ -139,7 +179,7 @@ func (suite *CoreTestSuite) TestSameBasenameFilesOverwrite() {
 	suite.Equal(contentA, string(symlinkContentA), "First file should preserve its original content")
 
 	// Add second file - this should work without overwriting the first
-	err = suite.lnk.Add(fileB)
+	_, err = suite.lnk.Add(fileB)
 	suite.Require().NoError(err)
 
 	// Verify second file is managed
@@ -157,7 +197,7 @@ func (suite *CoreTestSuite) TestSameBasenameFilesOverwrite() {
 	suite.Equal(contentB, string(symlinkContentB), "Second file should keep its original content")
 
 	// Both files should be removable independently
-	err = suite.lnk.Remove(fileA)
+	_, err = suite.lnk.Remove(fileA)
 	suite.Require().NoError(err, "First file should be removable")
 
 	// First file should be restored with correct content
@@ -170,7 +210,7 @@ func (suite *CoreTestSuite) TestSameBasenameFilesOverwrite() {
 	suite.Equal(contentA, string(restoredContentA), "Restored file should have original content")
 
 	// Second file should still be manageable and removable
-	err = suite.lnk.Remove(fileB)
+	_, err = suite.lnk.Remove(fileB)
 	suite.Require().NoError(err, "Second file should also be removable without errors")
 
 	// Second file should be restored with correct content
@@ -209,11 +249,11 @@ func (suite *CoreTestSuite) TestSameBasenameSequentialAdd() {
 	suite.Require().NoError(err)
 
 	// Add first .bashrc
-	err = suite.lnk.Add(configBashrc)
+	_, err = suite.lnk.Add(configBashrc)
 	suite.Require().NoError(err)
 
 	// Add second .bashrc - should work without overwriting the first
-	err = suite.lnk.Add(backupBashrc)
+	_, err = suite.lnk.Add(backupBashrc)
 	suite.Require().NoError(err)
 
 	// Check .lnk tracking file should track both properly
@@ -235,10 +275,10 @@ func (suite *CoreTestSuite) TestSameBasenameSequentialAdd() {
 	suite.Equal(backupContent, string(content2), "Second file should keep its distinct content")
 
 	// Both should be removable independently
-	err = suite.lnk.Remove(configBashrc)
+	_, err = suite.lnk.Remove(configBashrc)
 	suite.Require().NoError(err, "First .bashrc should be removable")
 
-	err = suite.lnk.Remove(backupBashrc)
+	_, err = suite.lnk.Remove(backupBashrc)
 	suite.Require().NoError(err, "Second .bashrc should be removable")
 }
 
@@ -288,7 +328,7 @@ func (suite *CoreTestSuite) TestAdd() {
 					return "", err
 				}
 				// Add it once
-				err = suite.lnk.Add(testFile)
+				_, err = suite.lnk.Add(testFile)
 				return testFile, err
 			},
 			wantErr:     true,
@@ -322,10 +362,10 @@ func (suite *CoreTestSuite) TestAdd() {
 
 			// Execute Add (only if setup succeeded and file wasn't already added)
 			if !tt.wantErr || tt.errContains != "already managed" {
-				err = suite.lnk.Add(filePath)
+				_, err = suite.lnk.Add(filePath)
 			} else {
 				// For "already managed" test, try to add again
-				err = suite.lnk.Add(filePath)
+				_, err = suite.lnk.Add(filePath)
 			}
 
 			// Verify
@@ -385,10 +425,10 @@ func (suite *CoreTestSuite) TestAddMultiple() {
 	suite.FileExists(filepath.Join(lnkDir, "file3.txt"))
 
 	// Verify .lnk file contains all entries
-	lnkFile := filepath.Join(lnkDir, ".lnk")
-	lnkContent, err := os.ReadFile(lnkFile)
+	suite.FileExists(filepath.Join(lnkDir, ".lnk"))
+	items, err := suite.lnk.tracker.GetManagedItems()
 	suite.NoError(err)
-	suite.Equal("file1.txt\nfile2.txt\nfile3.txt\n", string(lnkContent))
+	suite.Equal([]string{"file1.txt", "file2.txt", "file3.txt"}, items)
 
 	// Verify Git commit was created
 	commits, err := suite.lnk.GetCommits()
@@ -417,7 +457,7 @@ func (suite *CoreTestSuite) TestAddMultipleWithConflicts() {
 	suite.Require().NoError(err)
 
 	// Add file2 individually first
-	err = suite.lnk.Add(file2)
+	_, err = suite.lnk.Add(file2)
 	suite.Require().NoError(err)
 
 	// Now try to add all three - should fail due to conflict with file2
@@ -487,6 +527,45 @@ func (suite *CoreTestSuite) TestAddMultipleRollback() {
 	}
 }
 
+// TestAddRecursiveInterruptedRollsBack verifies that cancelling the
+// WithContext context partway through a batch puts every file already moved
+// back, restores the tracking file and leaves nothing staged or committed.
+func (suite *CoreTestSuite) TestAddRecursiveInterruptedRollsBack() {
+	suite.Require().NoError(suite.lnk.Init())
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	suite.Require().NoError(os.MkdirAll(dir, 0755))
+	for i := range 12 {
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.conf", i)), []byte("content"), 0644))
+	}
+	commitsBefore, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = NewLnk(WithContext(ctx)).AddRecursiveWithProgress([]string{dir}, func(current, total int, name string) {
+		if current == 3 {
+			cancel()
+		}
+	})
+	suite.ErrorIs(err, ErrInterrupted)
+
+	for i := range 12 {
+		info, err := os.Lstat(filepath.Join(dir, fmt.Sprintf("file%02d.conf", i)))
+		suite.Require().NoError(err)
+		suite.True(info.Mode().IsRegular(), "file%02d.conf should be put back", i)
+	}
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+
+	commitsAfter, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(len(commitsBefore), len(commitsAfter))
+	staged, err := exec.Command("git", "-C", filepath.Join(suite.tempDir, "lnk"), "diff", "--cached", "--name-only").Output()
+	suite.Require().NoError(err)
+	suite.Empty(strings.TrimSpace(string(staged)))
+}
+
 func (suite *CoreTestSuite) TestValidateMultiplePaths() {
 	err := suite.lnk.Init()
 	suite.Require().NoError(err)
@@ -542,7 +621,7 @@ func (suite *CoreTestSuite) TestAtomicRollbackOnFailure() {
 	suite.Require().NoError(err)
 
 	// Add file2 individually first to create a conflict
-	err = suite.lnk.Add(file2)
+	_, err = suite.lnk.Add(file2)
 	suite.Require().NoError(err)
 
 	// Store original states
@@ -595,7 +674,7 @@ func (suite *CoreTestSuite) TestDetailedErrorMessages() {
 	suite.Require().NoError(err)
 
 	// Add one file first to create conflict
-	err = suite.lnk.Add(alreadyManagedFile)
+	_, err = suite.lnk.Add(alreadyManagedFile)
 	suite.Require().NoError(err)
 
 	// Test with nonexistent file
@@ -861,6 +940,40 @@ func (suite *CoreTestSuite) TestProgressThreshold() {
 	suite.Equal(15, largeProgressCalls, "Progress should be called for operations over threshold")
 }
 
+// TestAddMultipleWithProgress verifies that a plain multi-file add reports
+// progress past the same threshold as a recursive one, and still commits as
+// an add of that many files rather than a recursive one.
+func (suite *CoreTestSuite) TestAddMultipleWithProgress() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	files := func(prefix string, n int) []string {
+		var paths []string
+		for i := 0; i < n; i++ {
+			file := filepath.Join(suite.tempDir, fmt.Sprintf(".%s%d", prefix, i))
+			suite.Require().NoError(os.WriteFile(file, []byte(fmt.Sprintf("content %d", i)), 0644))
+			paths = append(paths, file)
+		}
+		return paths
+	}
+	var totals []int
+	progress := func(current, total int, currentFile string) {
+		suite.Equal(len(totals)+1, current)
+		suite.NotEmpty(currentFile)
+		totals = append(totals, total)
+	}
+
+	suite.Require().NoError(suite.lnk.AddMultipleWithProgress(files("small", 10), progress))
+	suite.Empty(totals, "ten files are at the threshold, not past it")
+
+	suite.Require().NoError(suite.lnk.AddMultipleWithProgress(files("large", 13), progress))
+	suite.Len(totals, 13)
+	suite.Equal(13, totals[0])
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: added 13 files", commits[0])
+}
+
 // Task 3.1: Dry-Run Mode Core Tests
 
 func (suite *CoreTestSuite) TestPreviewAdd() {
@@ -946,7 +1059,7 @@ func (suite *CoreTestSuite) TestPreviewAddValidation() {
 	// Create and add a file first
 	testFile := filepath.Join(suite.tempDir, "test.txt")
 	suite.Require().NoError(os.WriteFile(testFile, []byte("content"), 0644))
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Test preview with already managed file
@@ -955,6 +1068,387 @@ func (suite *CoreTestSuite) TestPreviewAddValidation() {
 	suite.Contains(err.Error(), "already managed", "Error should mention already managed")
 }
 
+// TestPreviewAddEntries tests that the preview reports where each file would be stored
+func (suite *CoreTestSuite) TestPreviewAddEntries() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	testFile := filepath.Join(suite.tempDir, ".config", "app", "config.json")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(testFile), 0755))
+	suite.Require().NoError(os.WriteFile(testFile, []byte("{}"), 0644))
+
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	relativePath := filepath.Join(".config", "app", "config.json")
+
+	entries, err := suite.lnk.PreviewAddEntries([]string{testFile}, false)
+	suite.Require().NoError(err)
+	suite.Equal([]PreviewEntry{{
+		Source:       testFile,
+		RelativePath: relativePath,
+		Destination:  filepath.Join(lnkDir, relativePath),
+	}}, entries)
+
+	hostEntries, err := NewLnk(WithHost("work")).PreviewAddEntries([]string{testFile}, false)
+	suite.Require().NoError(err)
+	suite.Require().Len(hostEntries, 1)
+	suite.Equal(filepath.Join(lnkDir, "work.lnk", relativePath), hostEntries[0].Destination)
+
+	// Preview must not touch the file system
+	info, err := os.Lstat(testFile)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0), info.Mode()&os.ModeSymlink)
+}
+
+// TestAddCopy tests copy mode: the original stays in place and restores copy
+func (suite *CoreTestSuite) TestAddCopy() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	testFile := filepath.Join(suite.tempDir, ".netrc")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("machine a"), 0600))
+
+	copyLnk := NewLnk(WithCopy(true))
+	_, err = copyLnk.Add(testFile)
+	suite.Require().NoError(err)
+
+	info, err := os.Lstat(testFile)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular(), "original should not be replaced by a symlink")
+
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	stored, err := os.ReadFile(filepath.Join(lnkDir, ".netrc"))
+	suite.Require().NoError(err)
+	suite.Equal("machine a", string(stored))
+
+	entries, err := suite.lnk.ListEntries()
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	suite.True(entries[0].Copy)
+
+	// A missing original is restored as a copy, not a symlink
+	suite.Require().NoError(os.Remove(testFile))
+	restored, err := suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".netrc"}, restored.Restored)
+	info, err = os.Lstat(testFile)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+
+	// An unchanged copy needs no restore
+	restored, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Empty(restored.Restored)
+
+	// Directories need --recursive in copy mode
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	suite.Require().NoError(os.MkdirAll(dir, 0755))
+	_, err = copyLnk.Add(dir)
+	suite.Error(err)
+	suite.Contains(err.Error(), "Cannot manage this type of file")
+}
+
+// TestExpandPaths tests native glob expansion of add arguments
+func (suite *CoreTestSuite) TestExpandPaths() {
+	configDir := filepath.Join(suite.tempDir, ".config")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(configDir, "app"), 0755))
+	for _, name := range []string{"a.conf", "b.conf", "c.txt", "app/d.conf", "weird[1].txt"} {
+		suite.Require().NoError(os.WriteFile(filepath.Join(configDir, name), []byte(name), 0644))
+	}
+	aConf := filepath.Join(configDir, "a.conf")
+	bConf := filepath.Join(configDir, "b.conf")
+	weird := filepath.Join(configDir, "weird[1].txt")
+
+	suite.Run("star matches in one directory", func() {
+		paths, err := suite.lnk.ExpandPaths([]string{filepath.Join(configDir, "*.conf")})
+		suite.Require().NoError(err)
+		suite.Equal([]string{aConf, bConf}, paths)
+	})
+
+	suite.Run("tilde and home fallback", func() {
+		paths, err := suite.lnk.ExpandPaths([]string{"~/.config/?.conf"})
+		suite.Require().NoError(err)
+		suite.Equal([]string{aConf, bConf}, paths)
+
+		suite.Require().NoError(os.Chdir(filepath.Join(configDir, "app")))
+		defer func() { suite.Require().NoError(os.Chdir(suite.tempDir)) }()
+		paths, err = suite.lnk.ExpandPaths([]string{".config/a.*"})
+		suite.Require().NoError(err)
+		suite.Equal([]string{aConf}, paths)
+	})
+
+	suite.Run("literal paths are kept and repeats dropped", func() {
+		paths, err := suite.lnk.ExpandPaths([]string{weird, aConf, filepath.Join(configDir, "a.*")})
+		suite.Require().NoError(err)
+		suite.Equal([]string{weird, aConf}, paths)
+	})
+
+	suite.Run("double star is unsupported", func() {
+		_, err := suite.lnk.ExpandPaths([]string{filepath.Join(configDir, "**", "*.conf")})
+		suite.ErrorIs(err, ErrInvalidPattern)
+	})
+
+	suite.Run("no match is an error", func() {
+		_, err := suite.lnk.ExpandPaths([]string{filepath.Join(configDir, "*.missing")})
+		suite.ErrorIs(err, ErrNoMatch)
+	})
+
+	suite.Run("ignored matches are dropped", func() {
+		ignore := filepath.Join(configDir, ".lnkignore")
+		suite.Require().NoError(os.WriteFile(ignore, []byte("b.conf\napp/\n"), 0644))
+		defer func() { suite.Require().NoError(os.Remove(ignore)) }()
+
+		skipped := make(map[string]string)
+		l := NewLnk(WithSkipHandler(func(path, kind string) { skipped[path] = kind }))
+		paths, err := l.ExpandPaths([]string{"~/.config/*.conf"})
+		suite.Require().NoError(err)
+		suite.Equal([]string{aConf}, paths)
+		suite.Equal(map[string]string{bConf: IgnoredKind}, skipped)
+
+		_, err = l.ExpandPaths([]string{"~/.config/app/*.conf"})
+		suite.ErrorIs(err, ErrNoMatch)
+	})
+}
+
+// TestAddRecursiveSkipsGitDirectories verifies that a recursive add leaves
+// the .git of a nested checkout (and the other default skip names) in place,
+// and that WithSkipDirs replaces the list.
+func (suite *CoreTestSuite) TestAddRecursiveSkipsGitDirectories() {
+	suite.Require().NoError(suite.lnk.Init())
+	dir := filepath.Join(suite.tempDir, ".config", "somerepo")
+	for _, sub := range []string{".git", "lib/node_modules/pkg", "tools"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	config := filepath.Join(dir, "init.lua")
+	suite.Require().NoError(os.WriteFile(config, []byte("-- config"), 0644))
+	head := filepath.Join(dir, ".git", "HEAD")
+	suite.Require().NoError(os.WriteFile(head, []byte("ref: refs/heads/main"), 0644))
+	pkg := filepath.Join(dir, "lib", "node_modules", "pkg", "index.js")
+	suite.Require().NoError(os.WriteFile(pkg, []byte("module.exports = {}"), 0644))
+	tool := filepath.Join(dir, "tools", "build.sh")
+	suite.Require().NoError(os.WriteFile(tool, []byte("#!/bin/sh"), 0755))
+
+	skipped := make(map[string]string)
+	l := NewLnk(WithSkipHandler(func(path, kind string) { skipped[path] = kind }))
+	preview, err := l.PreviewAdd([]string{dir}, true)
+	suite.Require().NoError(err)
+	suite.Equal([]string{config, tool}, preview)
+	suite.Equal(map[string]string{
+		filepath.Join(dir, ".git"):                ExcludedDirKind,
+		filepath.Join(dir, "lib", "node_modules"): ExcludedDirKind,
+	}, skipped)
+	preview, err = NewLnk(WithSkipDirs([]string{})).PreviewAdd([]string{dir}, true)
+	suite.Require().NoError(err)
+	suite.Equal([]string{head, config, pkg, tool}, preview)
+
+	suite.Require().NoError(l.AddRecursiveWithProgress([]string{dir}, nil))
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{
+		filepath.Join(".config", "somerepo", "init.lua"),
+		filepath.Join(".config", "somerepo", "tools", "build.sh"),
+	}, items)
+	suite.FileExists(head)
+	suite.NoFileExists(filepath.Join(suite.tempDir, "lnk", ".config", "somerepo", ".git", "HEAD"))
+}
+
+// TestAddRecursiveHonoursIgnoreFiles verifies the precedence of the user's
+// ignore file, the repository's .lnkignore and per-directory ones: the nearer
+// file wins, a negation re-includes what a further one ignored, and a pattern
+// with a slash is anchored to the directory of its file.
+func (suite *CoreTestSuite) TestAddRecursiveHonoursIgnoreFiles() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	for _, sub := range []string{"cache", "sub/deep"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	write := func(path, content string) string {
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	// XDG_CONFIG_HOME is the temp dir, so the user's file is lnk/ignore.
+	write(filepath.Join(repoPath, "ignore"), "*.log\ncache/\n")
+	write(filepath.Join(repoPath, ".lnkignore"), "# shared defaults\n!important.log\n*.bak\n")
+	appIgnore := write(filepath.Join(dir, ".lnkignore"), "!cache/\nsecret.txt\n")
+	subIgnore := write(filepath.Join(dir, "sub", ".lnkignore"), "!secret.txt\n/local.conf\n")
+
+	conf := write(filepath.Join(dir, "a.conf"), "a")
+	debug := write(filepath.Join(dir, "debug.log"), "noise")
+	important := write(filepath.Join(dir, "important.log"), "keep")
+	backup := write(filepath.Join(dir, "old.bak"), "old")
+	cached := write(filepath.Join(dir, "cache", "state"), "state")
+	secret := write(filepath.Join(dir, "secret.txt"), "secret")
+	subSecret := write(filepath.Join(dir, "sub", "secret.txt"), "not so secret")
+	local := write(filepath.Join(dir, "sub", "local.conf"), "local")
+	deepLocal := write(filepath.Join(dir, "sub", "deep", "local.conf"), "deep")
+
+	skipped := make(map[string]string)
+	l := NewLnk(WithSkipHandler(func(path, kind string) { skipped[path] = kind }))
+	preview, err := l.PreviewAdd([]string{dir}, true)
+	suite.Require().NoError(err)
+	suite.Equal([]string{appIgnore, conf, cached, important, subIgnore, deepLocal, subSecret}, preview)
+	suite.Equal(map[string]string{
+		debug:  IgnoredKind,
+		backup: IgnoredKind,
+		secret: IgnoredKind,
+		local:  IgnoredKind,
+	}, skipped)
+
+	suite.Require().NoError(l.AddRecursiveWithProgress([]string{dir}, nil))
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.Len(items, len(preview))
+	for _, path := range []string{debug, backup, secret, local} {
+		info, err := os.Lstat(path)
+		suite.Require().NoError(err)
+		suite.True(info.Mode().IsRegular(), "%s should be left in place", path)
+	}
+}
+
+// TestAddRecursiveKeepsMarkedEmptyDirectories verifies that a directory
+// holding only a .lnkkeep marker is tracked and comes back on restore, while
+// a bare empty directory is reported as left out.
+func (suite *CoreTestSuite) TestAddRecursiveKeepsMarkedEmptyDirectories() {
+	suite.Require().NoError(suite.lnk.Init())
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	for _, sub := range []string{"cache", "tmp"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "settings.json"), []byte("{}"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "cache", KeepFileName), nil, 0644))
+
+	skipped := make(map[string]string)
+	l := NewLnk(WithSkipHandler(func(path, kind string) { skipped[path] = kind }))
+	suite.Require().NoError(l.AddRecursiveWithProgress([]string{dir}, nil))
+	suite.Equal(map[string]string{filepath.Join(dir, "tmp"): EmptyDirKind}, skipped)
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{
+		filepath.Join(".config", "app", "cache", KeepFileName),
+		filepath.Join(".config", "app", "settings.json"),
+	}, items)
+
+	// A fresh machine has none of it; restoring brings the directory back.
+	suite.Require().NoError(os.RemoveAll(dir))
+	_, err = l.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.DirExists(filepath.Join(dir, "cache"))
+	suite.FileExists(filepath.Join(dir, "cache", KeepFileName))
+}
+
+// TestAddHostOverridesCommon verifies that adding a commonly managed path
+// for a host stores a copy of its content for the host and links $HOME to
+// it, leaving the common item alone, for single and multi-file adds, and
+// that a failed add puts the common symlink back.
+func (suite *CoreTestSuite) TestAddHostOverridesCommon() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath, err := filepath.EvalSymlinks(filepath.Join(suite.tempDir, "lnk"))
+	suite.Require().NoError(err)
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	suite.Require().NoError(suite.lnk.AddMultiple([]string{gitconfig, bashrc}))
+
+	linksTo := func(path, stored string) {
+		target, err := filepath.EvalSymlinks(path)
+		suite.Require().NoError(err)
+		suite.Equal(stored, target)
+	}
+
+	work := NewLnk(WithHost("work"))
+	_, err = work.Add(gitconfig)
+	suite.Require().NoError(err)
+	linksTo(gitconfig, filepath.Join(repoPath, "work.lnk", ".gitconfig"))
+	content, err := os.ReadFile(filepath.Join(repoPath, ".gitconfig"))
+	suite.Require().NoError(err)
+	suite.Equal("[user]", string(content), "the common item stays as it was")
+	info, err := os.Lstat(filepath.Join(repoPath, "work.lnk", ".gitconfig"))
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular(), "the host stores the content, not the link")
+
+	laptop := NewLnk(WithHost("laptop"))
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	suite.Require().NoError(laptop.AddMultiple([]string{bashrc, vimrc}))
+	linksTo(bashrc, filepath.Join(repoPath, "laptop.lnk", ".bashrc"))
+	linksTo(vimrc, filepath.Join(repoPath, "laptop.lnk", ".vimrc"))
+
+	common, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc", ".gitconfig"}, common)
+	hostItems, err := laptop.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc", ".vimrc"}, hostItems)
+
+	// A failing add puts the common symlink back.
+	inputrc := filepath.Join(suite.tempDir, ".inputrc")
+	suite.Require().NoError(os.WriteFile(inputrc, []byte("set bell-style none"), 0644))
+	_, err = suite.lnk.Add(inputrc)
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, "office.lnk", ".inputrc"), 0755))
+	_, err = NewLnk(WithHost("office")).Add(inputrc)
+	suite.Require().Error(err)
+	linksTo(inputrc, filepath.Join(repoPath, ".inputrc"))
+}
+
+// TestAddHostnameSuffix verifies the suffix layout: a host file is stored as
+// <path>.<host> at the repository root, restores link the host's variant,
+// and rename-host and remove follow the suffix.
+func (suite *CoreTestSuite) TestAddHostnameSuffix() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath, err := filepath.EvalSymlinks(filepath.Join(suite.tempDir, "lnk"))
+	suite.Require().NoError(err)
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("work"), 0644))
+
+	work := NewLnk(WithHost("work"), WithHostnameSuffix(true))
+	_, err = work.Add(bashrc)
+	suite.Require().NoError(err)
+	stored := filepath.Join(repoPath, ".bashrc.work")
+	suite.FileExists(stored)
+	suite.NoDirExists(filepath.Join(repoPath, "work.lnk"))
+	target, err := filepath.EvalSymlinks(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(stored, target)
+	status, err := work.StatusWithOptions(StatusOptions{Host: "work"})
+	suite.Require().NoError(err)
+	suite.False(status.Dirty)
+
+	// The common configuration cannot claim the suffixed file's location.
+	suite.Require().NoError(os.Remove(bashrc))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, ".bashrc.work"), []byte("work"), 0644))
+	_, err = suite.lnk.Add(filepath.Join(suite.tempDir, ".bashrc.work"))
+	suite.ErrorIs(err, ErrStorageOccupied)
+
+	// Restoring, with or without the option, links the host's variant.
+	restored, err := NewLnk(WithHost("work")).RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Len(restored.Restored, 1)
+	target, err = filepath.EvalSymlinks(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(stored, target)
+
+	_, err = suite.lnk.RenameHost("work", "office")
+	suite.Require().NoError(err)
+	stored = filepath.Join(repoPath, ".bashrc.office")
+	suite.NoFileExists(filepath.Join(repoPath, ".bashrc.work"))
+	target, err = filepath.EvalSymlinks(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(stored, target)
+
+	_, err = NewLnk(WithHost("office")).Remove(bashrc)
+	suite.Require().NoError(err)
+	suite.NoFileExists(stored)
+	content, err := os.ReadFile(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal("work", string(content))
+	status, err = suite.lnk.Status()
+	suite.Require().NoError(err)
+	suite.False(status.Dirty)
+}
+
 // TestAddRecursive tests recursive add operation
 func (suite *CoreTestSuite) TestAddRecursive() {
 	tests := []struct {
@@ -1179,3 +1673,798 @@ func (suite *CoreTestSuite) TestRollbackOperations() {
 		})
 	}
 }
+
+// TestImportStow verifies that stow packages are migrated, including a
+// directory stow folded into a single symlink, and that the stow directory
+// is left alone.
+func (suite *CoreTestSuite) TestImportStow() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	stowDir := filepath.Join(suite.tempDir, "dotfiles")
+	files := map[string]string{
+		"vim/.vimrc":                          "set number\n",
+		"vim/README.md":                       "ignored\n",
+		"nvim/.config/nvim/init.lua":          "require('plug')\n",
+		"nvim/.config/nvim/lua/plug.lua":      "-- plugins\n",
+		"zsh/dot-zshrc":                       "export ZDOTDIR\n",
+		"nvim/.config/nvim/lua/.#plug.lua.db": "lock\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(stowDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Lay the packages out the way stow does: a file link, and a folded
+	// directory link for nvim
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.Symlink(filepath.Join(stowDir, "vim", ".vimrc"), vimrc))
+	nvimDir := filepath.Join(suite.tempDir, ".config", "nvim")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(nvimDir), 0755))
+	suite.Require().NoError(os.Symlink(filepath.Join(stowDir, "nvim", ".config", "nvim"), nvimDir))
+
+	entries, err := suite.lnk.PlanStowImport(stowDir, true)
+	suite.Require().NoError(err)
+	var targets []string
+	for _, entry := range entries {
+		rel, err := filepath.Rel(suite.tempDir, entry.Target)
+		suite.Require().NoError(err)
+		targets = append(targets, entry.Package+":"+rel)
+	}
+	suite.Equal([]string{
+		"nvim:" + filepath.Join(".config", "nvim", "init.lua"),
+		"nvim:" + filepath.Join(".config", "nvim", "lua", "plug.lua"),
+		"vim:.vimrc",
+		"zsh:.zshrc",
+	}, targets)
+
+	// Planning changed nothing
+	info, err := os.Lstat(nvimDir)
+	suite.Require().NoError(err)
+	suite.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink)
+
+	_, err = suite.lnk.ImportStow(stowDir, true)
+	suite.Require().NoError(err)
+
+	info, err = os.Lstat(nvimDir)
+	suite.Require().NoError(err)
+	suite.True(info.IsDir(), "folded directory should be unfolded")
+
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	for _, rel := range []string{".vimrc", ".zshrc", filepath.Join(".config", "nvim", "init.lua"), filepath.Join(".config", "nvim", "lua", "plug.lua")} {
+		link := filepath.Join(suite.tempDir, rel)
+		info, err := os.Lstat(link)
+		suite.Require().NoError(err)
+		suite.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink, "%s should be a symlink", rel)
+
+		resolved, err := filepath.EvalSymlinks(link)
+		suite.Require().NoError(err)
+		expected, err := filepath.EvalSymlinks(filepath.Join(lnkDir, rel))
+		suite.Require().NoError(err)
+		suite.Equal(expected, resolved, "%s should point into the lnk repo", rel)
+	}
+
+	// The stow directory is untouched
+	content, err := os.ReadFile(filepath.Join(stowDir, "vim", ".vimrc"))
+	suite.Require().NoError(err)
+	suite.Equal("set number\n", string(content))
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: imported 4 files from stow", commits[0])
+}
+
+// TestImportStowConflict verifies that a real file in the way of a package
+// file aborts the plan before anything changes.
+func (suite *CoreTestSuite) TestImportStowConflict() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	stowDir := filepath.Join(suite.tempDir, "dotfiles")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(stowDir, "bash"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(stowDir, "bash", ".bashrc"), []byte("stow\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, ".bashrc"), []byte("local\n"), 0644))
+
+	_, err := suite.lnk.ImportStow(stowDir, false)
+	suite.Require().Error(err)
+	suite.ErrorIs(err, ErrStowConflict)
+
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+}
+
+// TestImportStowRollback verifies that a package file add would refuse
+// stops the import before $HOME changes, and that a failure once $HOME was
+// changed puts stow's symlinks and folded directories back.
+func (suite *CoreTestSuite) TestImportStowRollback() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	stowDir := filepath.Join(suite.tempDir, "dotfiles")
+	for name, content := range map[string]string{
+		"vim/.vimrc":                 "set number\n",
+		"nvim/.config/nvim/init.lua": "require('plug')\n",
+		"bin/.local/bin/tool":        "\x00\x01\x02",
+	} {
+		path := filepath.Join(stowDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.Symlink(filepath.Join(stowDir, "vim", ".vimrc"), vimrc))
+	nvimDir := filepath.Join(suite.tempDir, ".config", "nvim")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(nvimDir), 0755))
+	suite.Require().NoError(os.Symlink(filepath.Join(stowDir, "nvim", ".config", "nvim"), nvimDir))
+
+	unchanged := func() {
+		for link, target := range map[string]string{
+			vimrc:   filepath.Join(stowDir, "vim", ".vimrc"),
+			nvimDir: filepath.Join(stowDir, "nvim", ".config", "nvim"),
+		} {
+			got, err := os.Readlink(link)
+			suite.Require().NoError(err, "%s should still be stow's symlink", link)
+			suite.Equal(target, got)
+		}
+		suite.NoFileExists(filepath.Join(suite.tempDir, ".local", "bin", "tool"))
+		items, err := suite.lnk.List()
+		suite.Require().NoError(err)
+		suite.Empty(items)
+	}
+
+	_, err := suite.lnk.ImportStow(stowDir, false)
+	suite.ErrorIs(err, ErrBinaryFile)
+	unchanged()
+
+	// A failing commit comes after $HOME was changed.
+	suite.Require().NoError(os.Remove(filepath.Join(stowDir, "bin", ".local", "bin", "tool")))
+	hook := filepath.Join(suite.tempDir, "lnk", ".git", "hooks", "pre-commit")
+	suite.Require().NoError(os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755))
+	_, err = suite.lnk.ImportStow(stowDir, false)
+	suite.Error(err)
+	unchanged()
+}
+
+// TestAddBeforeInit verifies that adding without a repository fails up front
+// and leaves the file where it is.
+func (suite *CoreTestSuite) TestAddBeforeInit() {
+	suite.False(suite.lnk.IsInitialized())
+
+	testFile := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("export EDITOR=vim\n"), 0644))
+
+	_, err := suite.lnk.Add(testFile)
+	suite.ErrorIs(err, ErrNotInitialized)
+	suite.ErrorIs(suite.lnk.AddMultiple([]string{testFile}), ErrNotInitialized)
+
+	info, err := os.Lstat(testFile)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.NoDirExists(filepath.Join(suite.tempDir, "lnk"))
+
+	suite.Require().NoError(suite.lnk.Init())
+	suite.True(suite.lnk.IsInitialized())
+	_, err = suite.lnk.Add(testFile)
+	suite.NoError(err)
+}
+
+// TestAddRecoversHalfCompletedAdd verifies that a repository copy left behind
+// by an add that failed before creating the symlink is reused, while a
+// different file at that path is never overwritten.
+func (suite *CoreTestSuite) TestAddRecoversHalfCompletedAdd() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	addOne := func(path string) error {
+		_, err := suite.lnk.Add(path)
+		return err
+	}
+	addMany := func(path string) error { return suite.lnk.AddMultiple([]string{path}) }
+
+	for _, add := range []func(string) error{addOne, addMany} {
+		testFile := filepath.Join(suite.tempDir, ".config", "app", "settings")
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(testFile), 0755))
+		suite.Require().NoError(os.WriteFile(testFile, []byte("theme=dark\n"), 0644))
+
+		// The earlier attempt got as far as the repository copy.
+		stored := filepath.Join(repoPath, ".config", "app", "settings")
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(stored), 0755))
+		suite.Require().NoError(os.WriteFile(stored, []byte("theme=dark\n"), 0644))
+
+		suite.Require().NoError(add(testFile))
+
+		target, err := os.Readlink(testFile)
+		suite.Require().NoError(err)
+		suite.Equal(stored, filepath.Join(filepath.Dir(testFile), target))
+		content, err := os.ReadFile(testFile)
+		suite.Require().NoError(err)
+		suite.Equal("theme=dark\n", string(content))
+
+		entries, err := os.ReadDir(filepath.Dir(stored))
+		suite.Require().NoError(err)
+		suite.Len(entries, 1, "the repository must hold a single copy")
+
+		items, err := suite.lnk.List()
+		suite.Require().NoError(err)
+		suite.Equal([]string{filepath.Join(".config", "app", "settings")}, items)
+
+		_, err = suite.lnk.Remove(testFile)
+		suite.Require().NoError(err)
+	}
+
+	// Different content in the way is left alone on both sides.
+	testFile := filepath.Join(suite.tempDir, ".profile")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("umask 022\n"), 0644))
+	stored := filepath.Join(repoPath, ".profile")
+	suite.Require().NoError(os.WriteFile(stored, []byte("umask 077\n"), 0644))
+
+	_, err := suite.lnk.Add(testFile)
+	suite.ErrorIs(err, ErrStorageOccupied)
+	suite.ErrorIs(suite.lnk.AddMultiple([]string{testFile}), ErrStorageOccupied)
+
+	for path, want := range map[string]string{testFile: "umask 022\n", stored: "umask 077\n"} {
+		info, err := os.Lstat(path)
+		suite.Require().NoError(err)
+		suite.True(info.Mode().IsRegular(), path)
+		content, err := os.ReadFile(path)
+		suite.Require().NoError(err)
+		suite.Equal(want, string(content))
+	}
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+}
+
+// TestAddForceReplacesOccupiedStorage verifies that WithForceAdd replaces an
+// untracked leftover at the storage path, but never a managed item's stored
+// copy, and that the two refusals carry different suggestions.
+func (suite *CoreTestSuite) TestAddForceReplacesOccupiedStorage() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	profile := filepath.Join(suite.tempDir, ".profile")
+	suite.Require().NoError(os.WriteFile(profile, []byte("umask 022\n"), 0644))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, ".profile"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, ".profile", "stale"), []byte("old"), 0644))
+
+	_, err := suite.lnk.Add(profile)
+	suite.ErrorIs(err, ErrStorageOccupied)
+	var lnkErr *lnkerror.Error
+	suite.Require().ErrorAs(err, &lnkErr)
+	suite.Contains(lnkErr.Suggestion, "--force")
+
+	_, err = NewLnk(WithForceAdd(true)).Add(profile)
+	suite.Require().NoError(err)
+	content, err := os.ReadFile(profile)
+	suite.Require().NoError(err)
+	suite.Equal("umask 022\n", string(content))
+	info, err := os.Lstat(filepath.Join(repoPath, ".profile"))
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+
+	// A managed item is refused with or without force, and says so.
+	_, err = NewLnk(WithForceAdd(true)).Add(profile)
+	suite.ErrorIs(err, ErrAlreadyManaged)
+	suite.Require().ErrorAs(err, &lnkErr)
+	suite.NotContains(lnkErr.Suggestion, "--force")
+
+	// A directory holding a managed item's stored copy is not replaced.
+	nvim := filepath.Join(suite.tempDir, ".config", "nvim")
+	suite.Require().NoError(os.MkdirAll(nvim, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(nvim, "init.lua"), []byte("-- nvim"), 0644))
+	_, err = suite.lnk.Add(nvim)
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, ".config", "user-dirs.dirs"), []byte("XDG"), 0644))
+
+	_, err = NewLnk(WithForceAdd(true)).Add(filepath.Join(suite.tempDir, ".config"))
+	suite.ErrorIs(err, ErrStorageOccupied)
+	suite.FileExists(filepath.Join(repoPath, ".config", "nvim", "init.lua"))
+}
+
+// TestAddRemoveReportManagedFile verifies the ManagedFile that Add, Remove and
+// RemoveForce return for files, directories and host scopes.
+func (suite *CoreTestSuite) TestAddRemoveReportManagedFile() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	testFile := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("export EDITOR=vim\n"), 0644))
+	added, err := suite.lnk.Add(testFile)
+	suite.Require().NoError(err)
+	suite.Equal(&ManagedFile{
+		Path:         testFile,
+		RelativePath: ".bashrc",
+		RepoPath:     filepath.Join(repoPath, ".bashrc"),
+	}, added)
+
+	testDir := filepath.Join(suite.tempDir, ".config", "nvim")
+	suite.Require().NoError(os.MkdirAll(testDir, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(testDir, "init.lua"), []byte("-- nvim\n"), 0644))
+	hostLnk := NewLnk(WithHost("work"))
+	added, err = hostLnk.Add(testDir)
+	suite.Require().NoError(err)
+	suite.Equal(&ManagedFile{
+		Path:         testDir,
+		RelativePath: filepath.Join(".config", "nvim"),
+		RepoPath:     filepath.Join(repoPath, "work.lnk", ".config", "nvim"),
+		Host:         "work",
+		IsDirectory:  true,
+	}, added)
+
+	removed, err := hostLnk.Remove(testDir)
+	suite.Require().NoError(err)
+	suite.Equal(added, removed)
+	suite.DirExists(removed.Path)
+
+	profile := filepath.Join(suite.tempDir, ".profile")
+	suite.Require().NoError(os.WriteFile(profile, []byte("umask 022\n"), 0644))
+	added, err = NewLnk(WithCopy(true)).Add(profile)
+	suite.Require().NoError(err)
+	suite.True(added.Copy)
+
+	// A plain instance still reports how the item was managed.
+	removed, err = suite.lnk.RemoveForce(profile)
+	suite.Require().NoError(err)
+	suite.Equal(added, removed)
+	suite.NoFileExists(removed.RepoPath)
+}
+
+// TestAddWithAuthorDate verifies that WithAuthorDate backdates the author of
+// the add commit and leaves the committer date alone.
+func (suite *CoreTestSuite) TestAddWithAuthorDate() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	testFile := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("set number\n"), 0644))
+
+	date, err := ParseDate("2019-05-01T08:30:00Z")
+	suite.Require().NoError(err)
+	_, err = NewLnk(WithAuthorDate(date)).Add(testFile)
+	suite.Require().NoError(err)
+
+	out, err := exec.Command("git", "-C", filepath.Join(suite.tempDir, "lnk"), "log", "-1", "--format=%aI %cI").Output()
+	suite.Require().NoError(err)
+	dates := strings.Fields(string(out))
+	suite.Require().Len(dates, 2)
+	author, err := time.Parse(time.RFC3339, dates[0])
+	suite.Require().NoError(err)
+	suite.True(author.Equal(date), dates[0])
+	suite.NotEqual("2019", dates[1][:4])
+
+	_, err = ParseDate("last tuesday")
+	suite.True(errors.Is(err, ErrInvalidDate))
+}
+
+// TestAddSkipsRepository verifies that the repository is never managed by
+// itself: a recursive add of a directory containing it skips its files, and
+// adding that directory whole, the repository, or a file inside it fails.
+func (suite *CoreTestSuite) TestAddSkipsRepository() {
+	configDir := filepath.Join(suite.tempDir, ".config")
+	repoPath := filepath.Join(configDir, "lnk")
+	suite.T().Setenv("LNK_HOME", repoPath)
+	l := NewLnk()
+	suite.Require().NoError(l.Init())
+
+	suite.Require().NoError(os.MkdirAll(filepath.Join(configDir, "nvim"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(configDir, "nvim", "init.lua"), []byte("-- nvim\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(configDir, "starship.toml"), []byte("add_newline = false\n"), 0644))
+
+	preview, err := l.PreviewAdd([]string{configDir}, true)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{filepath.Join(configDir, "nvim", "init.lua"), filepath.Join(configDir, "starship.toml")}, preview)
+
+	suite.Require().NoError(l.AddRecursiveWithProgress([]string{configDir}, nil))
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{filepath.Join(".config", "nvim", "init.lua"), filepath.Join(".config", "starship.toml")}, items)
+	info, err := os.Lstat(filepath.Join(repoPath, ".lnk"))
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular(), "the index must not be replaced by a symlink")
+
+	for _, path := range []string{configDir, repoPath, filepath.Join(repoPath, ".lnk")} {
+		_, err := l.Add(path)
+		suite.True(errors.Is(err, ErrInsideRepo), "%s: %v", path, err)
+		suite.True(errors.Is(l.AddMultiple([]string{path}), ErrInsideRepo), path)
+	}
+	suite.DirExists(filepath.Join(repoPath, ".git"))
+}
+
+// TestAddRefusesHomeDirectory verifies that neither the home directory nor a
+// directory holding it can be added, and that a recursive add of it needs
+// WithAllowHome.
+func (suite *CoreTestSuite) TestAddRefusesHomeDirectory() {
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export A=1\n"), 0644))
+
+	for _, path := range []string{suite.tempDir, filepath.Dir(suite.tempDir)} {
+		_, err := suite.lnk.Add(path)
+		suite.True(errors.Is(err, ErrHomeDirectory), "%s: %v", path, err)
+		suite.True(errors.Is(suite.lnk.AddMultiple([]string{path}), ErrHomeDirectory), path)
+		suite.True(errors.Is(NewLnk(WithAllowHome(true)).AddMultiple([]string{path}), ErrHomeDirectory), path)
+	}
+	err := suite.lnk.AddRecursiveWithProgress([]string{suite.tempDir}, nil)
+	suite.True(errors.Is(err, ErrHomeDirectory), "%v", err)
+	_, err = suite.lnk.PreviewAdd([]string{suite.tempDir}, true)
+	suite.True(errors.Is(err, ErrHomeDirectory), "%v", err)
+	info, err := os.Lstat(bashrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+
+	suite.Require().NoError(NewLnk(WithAllowHome(true)).AddRecursiveWithProgress([]string{suite.tempDir}, nil))
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, items)
+}
+
+// TestAddRefusesCircularSymlink verifies that paths which reach the
+// repository only through a symlink are refused before anything moves: a
+// symlink to a directory holding the repository, whose link would end up
+// inside its own target, and a file reached through a symlink into the
+// repository.
+func (suite *CoreTestSuite) TestAddRefusesCircularSymlink() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	alias := filepath.Join(suite.tempDir, "home-alias")
+	suite.Require().NoError(os.Symlink(suite.tempDir, alias))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, ".config"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, ".config", "app.conf"), []byte("x = 1\n"), 0644))
+	shortcut := filepath.Join(suite.tempDir, "shortcut")
+	suite.Require().NoError(os.Symlink(filepath.Join(repoPath, ".config"), shortcut))
+
+	for _, path := range []string{alias, filepath.Join(shortcut, "app.conf"), filepath.Join(alias, "lnk", ".config", "app.conf")} {
+		_, err := suite.lnk.Add(path)
+		suite.True(errors.Is(err, ErrInsideRepo), "%s: %v", path, err)
+		suite.True(errors.Is(suite.lnk.AddMultiple([]string{path}), ErrInsideRepo), path)
+	}
+
+	info, err := os.Lstat(alias)
+	suite.Require().NoError(err)
+	suite.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	suite.NoFileExists(filepath.Join(repoPath, "home-alias"))
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+}
+
+// TestAddRejectsLargeAndBinaryFiles verifies that files over the size limit
+// and binary files are refused before anything moves, also inside a
+// directory, and that WithForceAdd lets them through.
+func (suite *CoreTestSuite) TestAddRejectsLargeAndBinaryFiles() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	large := filepath.Join(suite.tempDir, ".large")
+	suite.Require().NoError(os.WriteFile(large, []byte(strings.Repeat("x", 2048)), 0644))
+	cacheDir := filepath.Join(suite.tempDir, ".cache")
+	suite.Require().NoError(os.MkdirAll(cacheDir, 0755))
+	binary := filepath.Join(cacheDir, "index.bin")
+	suite.Require().NoError(os.WriteFile(binary, []byte{'d', 'b', 0, 1, 2}, 0644))
+
+	l := NewLnk(WithMaxFileSize(1024))
+	_, err := l.Add(large)
+	suite.True(errors.Is(err, ErrFileTooLarge), "%v", err)
+	suite.Contains(err.Error(), large)
+	suite.Contains(err.Error(), "2 KB, over the 1 KB limit")
+
+	_, err = l.Add(cacheDir)
+	suite.True(errors.Is(err, ErrBinaryFile), "%v", err)
+	suite.Contains(err.Error(), binary)
+	suite.True(errors.Is(l.AddMultiple([]string{large, cacheDir}), ErrFileTooLarge))
+
+	// Nothing was moved or tracked.
+	info, err := os.Lstat(large)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.DirExists(cacheDir)
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+
+	// The default limit is far above either file; only the binary is refused.
+	_, err = suite.lnk.Add(large)
+	suite.Require().NoError(err)
+
+	forced := NewLnk(WithForceAdd(true))
+	_, err = forced.Add(cacheDir)
+	suite.Require().NoError(err)
+	items, err = forced.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".large", ".cache"}, items)
+}
+
+// TestAddSecret verifies that secret adds need a git-crypt repository, hand
+// each item to git-crypt in .gitattributes within the add commit, and that
+// removing the item drops its line again.
+func (suite *CoreTestSuite) TestAddSecret() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	credentials := filepath.Join(suite.tempDir, ".aws", "credentials")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(credentials), 0755))
+	suite.Require().NoError(os.WriteFile(credentials, []byte("[default]\n"), 0644))
+
+	_, err := NewLnk(WithSecret(true)).Add(credentials)
+	suite.True(errors.Is(err, ErrNoGitCrypt), "%v", err)
+	info, err := os.Lstat(credentials)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+
+	// What `git-crypt init` leaves behind is all lnk looks for.
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, ".git", "git-crypt", "keys"), 0755))
+
+	secret := NewLnk(WithSecret(true))
+	_, err = secret.Add(credentials)
+	suite.Require().NoError(err)
+
+	token := filepath.Join(suite.tempDir, ".config", "my app", "token")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(token), 0755))
+	suite.Require().NoError(os.WriteFile(token, []byte("s3cr3t\n"), 0600))
+	sshDir := filepath.Join(suite.tempDir, ".ssh")
+	suite.Require().NoError(os.MkdirAll(sshDir, 0700))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sshDir, "id_ed25519"), []byte("key\n"), 0600))
+	suite.Require().NoError(NewLnk(WithSecret(true), WithHost("work")).AddMultiple([]string{token, sshDir}))
+
+	content, err := os.ReadFile(filepath.Join(repoPath, ".gitattributes"))
+	suite.Require().NoError(err)
+	suite.Equal("/.aws/credentials filter=git-crypt diff=git-crypt\n"+
+		"\"/work.lnk/.config/my app/token\" filter=git-crypt diff=git-crypt\n"+
+		"/work.lnk/.ssh/** filter=git-crypt diff=git-crypt\n", string(content))
+
+	// git resolves each pattern to exactly the stored item.
+	for _, path := range []string{".aws/credentials", "work.lnk/.config/my app/token", "work.lnk/.ssh/id_ed25519"} {
+		out, err := exec.Command("git", "-C", repoPath, "check-attr", "filter", "--", path).Output()
+		suite.Require().NoError(err)
+		suite.Equal(path+": filter: git-crypt\n", string(out))
+	}
+	out, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(out), "the attributes are committed with the files")
+
+	entries, err := secret.ListEntries()
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	suite.True(entries[0].Secret)
+
+	_, err = secret.Remove(credentials)
+	suite.Require().NoError(err)
+	content, err = os.ReadFile(filepath.Join(repoPath, ".gitattributes"))
+	suite.Require().NoError(err)
+	suite.NotContains(string(content), ".aws/credentials")
+	out, err = exec.Command("git", "-C", repoPath, "status", "--porcelain").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(out))
+}
+
+// TestSymlinkedHome verifies that a $HOME that is itself a symlink gives the
+// same relative paths through either spelling, and that the links lnk makes
+// there pass validation and can be removed.
+func (suite *CoreTestSuite) TestSymlinkedHome() {
+	realHome := filepath.Join(suite.tempDir, "data", "user")
+	home := filepath.Join(suite.tempDir, "user")
+	suite.Require().NoError(os.MkdirAll(realHome, 0755))
+	suite.Require().NoError(os.Symlink(realHome, home))
+	suite.T().Setenv("HOME", home)
+	suite.T().Setenv("LNK_HOME", filepath.Join(home, ".config", "lnk"))
+
+	l := NewLnk()
+	suite.Require().NoError(l.Init())
+	suite.Require().NoError(os.WriteFile(filepath.Join(realHome, ".vimrc"), []byte("set nu\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(realHome, ".zshrc"), []byte("setopt autocd\n"), 0644))
+
+	_, err := l.Add(filepath.Join(realHome, ".vimrc"))
+	suite.Require().NoError(err)
+	_, err = l.Add(filepath.Join(home, ".zshrc"))
+	suite.Require().NoError(err)
+
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".vimrc", ".zshrc"}, items)
+
+	result, err := l.PreviewDoctor()
+	suite.Require().NoError(err)
+	suite.Empty(result.BrokenSymlinks)
+	suite.Empty(result.InvalidEntries)
+
+	for _, path := range []string{filepath.Join(home, ".vimrc"), filepath.Join(realHome, ".zshrc")} {
+		content, err := os.ReadFile(path)
+		suite.Require().NoError(err, path)
+		suite.NotEmpty(content)
+	}
+
+	_, err = l.Remove(filepath.Join(realHome, ".zshrc"))
+	suite.Require().NoError(err)
+	_, err = l.Remove(filepath.Join(home, ".vimrc"))
+	suite.Require().NoError(err)
+	suite.FileExists(filepath.Join(realHome, ".vimrc"))
+}
+
+// TestAddInto verifies that WithInto stores items under the chosen
+// repository directory, records it in the index, and that restore and remove
+// follow the recorded location.
+func (suite *CoreTestSuite) TestAddInto() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	zshrc := filepath.Join(suite.tempDir, ".config", "zsh", ".zshrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("alias ll='ls -l'\n"), 0644))
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(zshrc), 0755))
+	suite.Require().NoError(os.WriteFile(zshrc, []byte("setopt autocd\n"), 0644))
+
+	l := NewLnk(WithInto("shell"))
+	added, err := l.Add(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(repoPath, "shell", ".bashrc"), added.RepoPath)
+	suite.Require().NoError(l.AddMultiple([]string{zshrc}))
+
+	entry, ok, err := suite.lnk.tracker.GetEntry(filepath.Join(".config", "zsh", ".zshrc"))
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	suite.Equal(filepath.Join("shell", ".zshrc"), entry.Repo)
+	suite.FileExists(filepath.Join(repoPath, "shell", ".zshrc"))
+	suite.NoDirExists(filepath.Join(repoPath, ".config"))
+
+	stored, err := suite.lnk.ManagedPath(zshrc)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(repoPath, "shell", ".zshrc"), stored)
+
+	// Restore links to the recorded location, not the home-relative one.
+	suite.Require().NoError(os.Remove(zshrc))
+	_, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	content, err := os.ReadFile(zshrc)
+	suite.Require().NoError(err)
+	suite.Equal("setopt autocd\n", string(content))
+	result, err := suite.lnk.PreviewDoctor()
+	suite.Require().NoError(err)
+	suite.Empty(result.InvalidEntries)
+	suite.Empty(result.BrokenSymlinks)
+
+	// A second .bashrc cannot share the stored path.
+	other := filepath.Join(suite.tempDir, "work", ".bashrc")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(other), 0755))
+	suite.Require().NoError(os.WriteFile(other, []byte("alias ll='ls -l'\n"), 0644))
+	_, err = l.Add(other)
+	suite.ErrorIs(err, ErrStorageOccupied)
+	suite.ErrorIs(l.AddMultiple([]string{other}), ErrStorageOccupied)
+
+	for _, dir := range []string{"/tmp", "..", "../elsewhere", ".git/hooks", "work.lnk"} {
+		_, err := NewLnk(WithInto(dir)).Add(other)
+		suite.ErrorIs(err, ErrInvalidInto, dir)
+	}
+
+	_, err = suite.lnk.Remove(bashrc)
+	suite.Require().NoError(err)
+	info, err := os.Lstat(bashrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.NoFileExists(filepath.Join(repoPath, "shell", ".bashrc"))
+}
+
+// TestAddChmod verifies that WithChmod sets the mode on the stored item,
+// records it in the index, and that restore puts it back after Git reset it.
+func (suite *CoreTestSuite) TestAddChmod() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	netrc := filepath.Join(suite.tempDir, ".netrc")
+	aws := filepath.Join(suite.tempDir, ".aws", "credentials")
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine example.com\n"), 0644))
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(aws), 0755))
+	suite.Require().NoError(os.WriteFile(aws, []byte("[default]\n"), 0644))
+
+	l := NewLnk(WithChmod("600"))
+	_, err := l.Add(netrc)
+	suite.Require().NoError(err)
+	suite.Require().NoError(l.AddMultiple([]string{aws}))
+
+	for _, item := range []string{".netrc", filepath.Join(".aws", "credentials")} {
+		info, err := os.Stat(filepath.Join(repoPath, item))
+		suite.Require().NoError(err)
+		suite.Equal(os.FileMode(0600), info.Mode().Perm(), item)
+		entry, ok, err := suite.lnk.tracker.GetEntry(item)
+		suite.Require().NoError(err)
+		suite.Require().True(ok)
+		suite.Equal("0600", entry.Mode, item)
+	}
+
+	// A checkout writes the file with default permissions; restore fixes it.
+	suite.Require().NoError(os.Chmod(filepath.Join(repoPath, ".netrc"), 0644))
+	_, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	info, err := os.Stat(filepath.Join(repoPath, ".netrc"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	other := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(other, []byte("set nu\n"), 0644))
+	for _, mode := range []string{"rw-------", "0800", "17777", "-1"} {
+		_, err := NewLnk(WithChmod(mode)).Add(other)
+		suite.ErrorIs(err, ErrInvalidMode, mode)
+	}
+	info, err = os.Lstat(other)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular(), "a rejected mode leaves the file in place")
+}
+
+// TestAddSkipErrors verifies that a skip-errors add commits the files it can
+// and reports each one it cannot, and that it fails outright when none of
+// them can be added.
+func (suite *CoreTestSuite) TestAddSkipErrors() {
+	suite.Require().NoError(suite.lnk.Init())
+	write := func(name, content string) string {
+		path := filepath.Join(suite.tempDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	managed := write(".profile", "export EDITOR=vi\n")
+	_, err := suite.lnk.Add(managed)
+	suite.Require().NoError(err)
+
+	bashrc := write(".bashrc", "alias ll='ls -l'\n")
+	binary := write(".cache.db", "SQLite\x00format")
+	vimrc := write(".vimrc", "set nu\n")
+	missing := filepath.Join(suite.tempDir, ".missing")
+
+	report, err := suite.lnk.AddMultipleSkipErrors([]string{bashrc, binary, managed, vimrc, missing}, nil)
+	suite.Require().NoError(err)
+	suite.Equal([]string{bashrc, vimrc}, report.Added)
+	suite.Require().Len(report.Failed, 3)
+	suite.Equal(binary, report.Failed[0].Path)
+	suite.ErrorIs(report.Failed[0].Err, ErrBinaryFile)
+	suite.ErrorIs(report.Failed[1].Err, ErrAlreadyManaged)
+	suite.Equal(missing, report.Failed[2].Path)
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: added 2 files", commits[0])
+	info, err := os.Lstat(binary)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".profile", ".bashrc", ".vimrc"}, items)
+
+	// Nothing addable: the first failure is the error and nothing is committed.
+	_, err = suite.lnk.AddMultipleSkipErrors([]string{binary, missing}, nil)
+	suite.ErrorIs(err, ErrBinaryFile)
+	after, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(len(commits), len(after))
+
+	// Recursive adds skip per file below the directory too.
+	init := write(filepath.Join(".config", "nvim", "init.lua"), "-- nvim\n")
+	write(filepath.Join(".config", "nvim", "spell.bin"), "\x00\x01")
+	report, err = suite.lnk.AddRecursiveSkipErrors([]string{filepath.Join(suite.tempDir, ".config")}, nil)
+	suite.Require().NoError(err)
+	suite.Equal([]string{init}, report.Added)
+	suite.Require().Len(report.Failed, 1)
+	suite.ErrorIs(report.Failed[0].Err, ErrBinaryFile)
+}
+
+// TestAddRefusesCollidingPath verifies that adding a file whose path differs
+// from a managed item's but names the same $HOME location fails, since
+// restoring both would replace one symlink with the other.
+func (suite *CoreTestSuite) TestAddRefusesCollidingPath() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	alias := filepath.Join(suite.tempDir, "alias")
+	suite.Require().NoError(os.MkdirAll(alias, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(alias, "app.conf"), []byte("a"), 0644))
+	_, err := suite.lnk.Add(filepath.Join(alias, "app.conf"))
+	suite.Require().NoError(err)
+
+	// ~/alias moves to ~/real and is linked back; the user then replaces the
+	// managed symlink with a new file and adds it under its real path.
+	real := filepath.Join(suite.tempDir, "real")
+	suite.Require().NoError(os.Rename(alias, real))
+	suite.Require().NoError(os.Symlink("real", alias))
+	suite.Require().NoError(os.Remove(filepath.Join(real, "app.conf")))
+	suite.Require().NoError(os.WriteFile(filepath.Join(real, "app.conf"), []byte("b"), 0644))
+
+	_, err = suite.lnk.Add(filepath.Join(real, "app.conf"))
+	suite.ErrorIs(err, ErrSymlinkCollision)
+	suite.Contains(err.Error(), "~/alias/app.conf")
+
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"alias/app.conf"}, items)
+}
@@ -0,0 +1,190 @@
+//go:build unix
+
+package lnk
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/yarlson/lnk/internal/fs"
+)
+
+// TestAddNewlineAndGlobFileNames verifies that a name git would otherwise
+// quote or expand, one with a newline and one with glob characters, is
+// tracked as exactly that file, and removing the glob-named one leaves the
+// files its pattern would match alone.
+func (suite *CoreTestSuite) TestAddNewlineAndGlobFileNames() {
+	suite.Require().NoError(suite.lnk.Init())
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	suite.Require().NoError(os.MkdirAll(dir, 0755))
+	for _, name := range []string{"other.conf", "line\nbreak", "*.conf"} {
+		path := filepath.Join(dir, name)
+		suite.Require().NoError(os.WriteFile(path, []byte(name), 0644))
+		_, err := suite.lnk.Add(path)
+		suite.Require().NoError(err)
+	}
+
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".config/app/other.conf", ".config/app/line\nbreak", ".config/app/*.conf"}, items)
+	status, err := suite.lnk.Status()
+	suite.Require().NoError(err)
+	suite.False(status.Dirty)
+
+	_, err = suite.lnk.Remove(filepath.Join(dir, "*.conf"))
+	suite.Require().NoError(err)
+	items, err = suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".config/app/other.conf", ".config/app/line\nbreak"}, items)
+	status, err = suite.lnk.Status()
+	suite.Require().NoError(err)
+	suite.False(status.Dirty, "removing *.conf must not unstage other.conf")
+}
+
+// TestAddRecursiveSkipsFIFO verifies that a named pipe inside a directory
+// being added recursively is left in place and reported, rather than moved,
+// and that adding one directly fails up front with its kind named.
+func (suite *CoreTestSuite) TestAddRecursiveSkipsFIFO() {
+	suite.Require().NoError(suite.lnk.Init())
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	suite.Require().NoError(os.MkdirAll(dir, 0755))
+	config := filepath.Join(dir, "config.toml")
+	suite.Require().NoError(os.WriteFile(config, []byte("theme = \"dark\"\n"), 0644))
+	fifo := filepath.Join(dir, "control.fifo")
+	suite.Require().NoError(syscall.Mkfifo(fifo, 0600))
+	link := filepath.Join(dir, "current.fifo")
+	suite.Require().NoError(os.Symlink(fifo, link))
+
+	skipped := make(map[string]string)
+	l := NewLnk(WithSkipHandler(func(path, kind string) { skipped[path] = kind }))
+
+	preview, err := l.PreviewAdd([]string{dir}, true)
+	suite.Require().NoError(err)
+	suite.Equal([]string{config}, preview)
+	suite.Require().NoError(l.AddRecursiveWithProgress([]string{dir}, nil))
+	suite.Equal(map[string]string{fifo: "named pipe", link: "symlink to a named pipe"}, skipped)
+
+	items, err := l.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{filepath.Join(".config", "app", "config.toml")}, items)
+	info, err := os.Lstat(fifo)
+	suite.Require().NoError(err)
+	suite.NotZero(info.Mode() & os.ModeNamedPipe)
+
+	err = l.AddMultiple([]string{fifo})
+	suite.ErrorIs(err, fs.ErrUnsupportedType)
+	suite.Contains(err.Error(), "named pipe")
+	suite.NoFileExists(filepath.Join(suite.tempDir, "lnk", ".config", "app", "control.fifo"))
+}
+
+// TestAddAndRemoveAcrossFilesystems verifies that adding and removing work
+// when the repository is on another filesystem than $HOME, where rename
+// fails with EXDEV and the move falls back to copying.
+func (suite *CoreTestSuite) TestAddAndRemoveAcrossFilesystems() {
+	var homeStat, shmStat syscall.Stat_t
+	if syscall.Stat(suite.tempDir, &homeStat) != nil || syscall.Stat("/dev/shm", &shmStat) != nil || homeStat.Dev == shmStat.Dev {
+		suite.T().Skip("no second filesystem at /dev/shm")
+	}
+	other, err := os.MkdirTemp("/dev/shm", "lnk-repo-*")
+	suite.Require().NoError(err)
+	defer func() { _ = os.RemoveAll(other) }()
+	repoPath := filepath.Join(other, "lnk")
+	suite.T().Setenv("LNK_HOME", repoPath)
+
+	l := NewLnk()
+	suite.Require().NoError(l.Init())
+
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0600))
+	dir := filepath.Join(suite.tempDir, ".config", "app")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(dir, "themes"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "themes", "dark.toml"), []byte("bg = 0"), 0644))
+	suite.Require().NoError(os.Symlink("themes/dark.toml", filepath.Join(dir, "theme.toml")))
+
+	_, err = l.Add(vimrc)
+	suite.Require().NoError(err)
+	_, err = l.Add(dir)
+	suite.Require().NoError(err)
+
+	info, err := os.Lstat(vimrc)
+	suite.Require().NoError(err)
+	suite.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	stored, err := os.Stat(filepath.Join(repoPath, ".vimrc"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), stored.Mode().Perm())
+	content, err := os.ReadFile(vimrc)
+	suite.Require().NoError(err)
+	suite.Equal("set number", string(content))
+	target, err := os.Readlink(filepath.Join(repoPath, ".config", "app", "theme.toml"))
+	suite.Require().NoError(err)
+	suite.Equal("themes/dark.toml", target)
+	content, err = os.ReadFile(filepath.Join(dir, "theme.toml"))
+	suite.Require().NoError(err)
+	suite.Equal("bg = 0", string(content))
+
+	_, err = l.Remove(vimrc)
+	suite.Require().NoError(err)
+	_, err = l.Remove(dir)
+	suite.Require().NoError(err)
+
+	info, err = os.Lstat(vimrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+	suite.NoFileExists(filepath.Join(repoPath, ".vimrc"))
+	info, err = os.Lstat(dir)
+	suite.Require().NoError(err)
+	suite.True(info.IsDir())
+	content, err = os.ReadFile(filepath.Join(dir, "themes", "dark.toml"))
+	suite.Require().NoError(err)
+	suite.Equal("bg = 0", string(content))
+	suite.NoDirExists(filepath.Join(repoPath, ".config", "app"))
+}
+
+// TestMoveReadOnlyAcrossFilesystems verifies that a directory with a
+// read-only subdirectory moves across filesystems in both directions: the
+// original is emptied even though its owner could not write to it, and the
+// modes come back as they were.
+func (suite *CoreTestSuite) TestMoveReadOnlyAcrossFilesystems() {
+	var homeStat, shmStat syscall.Stat_t
+	if syscall.Stat(suite.tempDir, &homeStat) != nil || syscall.Stat("/dev/shm", &shmStat) != nil || homeStat.Dev == shmStat.Dev {
+		suite.T().Skip("no second filesystem at /dev/shm")
+	}
+	other, err := os.MkdirTemp("/dev/shm", "lnk-repo-*")
+	suite.Require().NoError(err)
+	defer func() { _ = os.RemoveAll(other) }()
+	repoPath := filepath.Join(other, "lnk")
+	suite.T().Setenv("LNK_HOME", repoPath)
+
+	l := NewLnk()
+	suite.Require().NoError(l.Init())
+
+	dir := filepath.Join(suite.tempDir, ".vendor")
+	locked := filepath.Join(dir, "pinned")
+	suite.Require().NoError(os.MkdirAll(locked, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(locked, "init.lua"), []byte("-- pinned"), 0444))
+	suite.Require().NoError(os.Chmod(locked, 0555))
+	defer func() { _ = os.Chmod(locked, 0755) }() // so TearDownTest can delete it
+	stored := filepath.Join(repoPath, ".vendor", "pinned")
+	defer func() { _ = os.Chmod(stored, 0755) }()
+
+	_, err = l.Add(dir)
+	suite.Require().NoError(err)
+	info, err := os.Stat(stored)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0555), info.Mode().Perm())
+	info, err = os.Lstat(dir)
+	suite.Require().NoError(err)
+	suite.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink)
+
+	_, err = l.Remove(dir)
+	suite.Require().NoError(err)
+	suite.NoDirExists(filepath.Join(repoPath, ".vendor"))
+	info, err = os.Stat(locked)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0555), info.Mode().Perm())
+	content, err := os.ReadFile(filepath.Join(locked, "init.lua"))
+	suite.Require().NoError(err)
+	suite.Equal("-- pinned", string(content))
+}
@@ -0,0 +1,216 @@
+package lnk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// TestConfigPrecedence verifies that the machine-local config.toml overrides
+// the repository's shared one key by key, and that options override both.
+func (suite *CoreTestSuite) TestConfigPrecedence() {
+	repoPath := filepath.Join(suite.tempDir, "dotfiles")
+	suite.T().Setenv("LNK_HOME", repoPath)
+	suite.Require().NoError(NewLnk().Init())
+
+	shared := "# shared by every machine\nhost = \"work\"\nlock_timeout = \"30s\"\n\n[add]\nmax_size = \"1.5MB\"\n\n[pull]\non_conflict = 'skip' # keep local files\n"
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "config.toml"), []byte(shared), 0644))
+	local := "[pull]\non_conflict = \"overwrite\"\n"
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.tempDir, "lnk"), 0755))
+	suite.Require().NoError(os.WriteFile(UserConfigPath(), []byte(local), 0644))
+
+	cfg, err := LoadConfig()
+	suite.Require().NoError(err)
+	suite.Equal("work", cfg.Host)
+	suite.Equal(30*time.Second, cfg.LockTimeout)
+	suite.Equal("overwrite", cfg.OnConflict)
+
+	suite.Equal(int64(1536<<10), cfg.AddMaxSize)
+	suite.Equal(int64(1536<<10), NewLnk().maxSize)
+	suite.Equal(int64(1024), NewLnk(WithMaxFileSize(1024)).maxSize)
+
+	suite.Equal(30*time.Second, NewLnk().lockWait)
+	suite.Equal(time.Second, NewLnk(WithLockTimeout(time.Second)).lockWait)
+}
+
+// TestConfigErrors verifies that malformed files and bad values name the
+// file and line, and that unknown keys are skipped rather than failing.
+func (suite *CoreTestSuite) TestConfigErrors() {
+	suite.Require().NoError(suite.lnk.Init())
+	path := filepath.Join(suite.tempDir, "lnk", "config.toml")
+
+	cases := []struct {
+		content string
+		want    error
+		line    string
+	}{
+		{"host = work\n", ErrConfigSyntax, ":1"},
+		{"host = \"work\"\nhost = \"home\"\n", ErrConfigSyntax, ":2"},
+		{"[pull]\non_conflict = \"merge\"\n", ErrInvalidConfigValue, ":2"},
+		{"lock_timeout = \"soon\"\n", ErrInvalidConfigValue, ":1"},
+		{"[add]\nmax_size = \"huge\"\n", ErrInvalidConfigValue, ":2"},
+	}
+	for _, tc := range cases {
+		suite.Require().NoError(os.WriteFile(path, []byte(tc.content), 0644))
+
+		_, err := LoadConfig()
+		suite.Require().Error(err, tc.content)
+		suite.True(errors.Is(err, tc.want), "%q: %v", tc.content, err)
+
+		var lnkErr *lnkerror.Error
+		suite.Require().True(errors.As(err, &lnkErr))
+		suite.Equal(path+tc.line, lnkErr.Path, tc.content)
+	}
+
+	// A broken file falls back to the defaults rather than breaking NewLnk.
+	suite.Equal(DefaultLockTimeout, NewLnk().lockWait)
+
+	// An unknown key is skipped and recorded; CheckKeys reports it.
+	suite.Require().NoError(os.WriteFile(path, []byte("\ncolour = \"always\"\nlock_timeout = \"1m\"\n"), 0644))
+	cfg, err := LoadConfig()
+	suite.Require().NoError(err)
+	suite.Equal(time.Minute, cfg.LockTimeout)
+	suite.Equal([]UnknownConfigKey{{Name: "colour", Location: path + ":2"}}, cfg.Unknown)
+	suite.Equal(time.Minute, NewLnk().lockWait)
+	err = cfg.CheckKeys()
+	suite.True(errors.Is(err, ErrUnknownConfigKey))
+	var lnkErr *lnkerror.Error
+	suite.Require().True(errors.As(err, &lnkErr))
+	suite.Equal(path+":2", lnkErr.Path)
+}
+
+// TestSetConfig verifies that setting a key rewrites only its line, commits
+// the shared file, and leaves a machine-local file uncommitted.
+func (suite *CoreTestSuite) TestSetConfig() {
+	suite.Require().NoError(suite.lnk.Init())
+	path := filepath.Join(suite.tempDir, "lnk", "config.toml")
+	suite.Require().NoError(os.WriteFile(path, []byte("# lnk settings\nhost = \"auto\"\n\n[pull]\n# keep local edits\non_conflict = \"skip\"\n"), 0644))
+
+	written, err := suite.lnk.SetConfig("pull.on_conflict", "backup", false)
+	suite.Require().NoError(err)
+	suite.Equal(path, written)
+	_, err = suite.lnk.SetConfig("lock_timeout", "1m", false)
+	suite.Require().NoError(err)
+
+	content, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Equal("# lnk settings\nhost = \"auto\"\nlock_timeout = \"1m\"\n\n[pull]\n# keep local edits\non_conflict = \"backup\"\n", string(content))
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: set lock_timeout", commits[0])
+	suite.Equal("lnk: set pull.on_conflict", commits[1])
+
+	// Setting the same value again is not a new commit.
+	_, err = suite.lnk.SetConfig("lock_timeout", "1m", false)
+	suite.Require().NoError(err)
+	after, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(len(commits), len(after))
+
+	_, err = suite.lnk.SetConfig("pull.on_conflict", "merge", false)
+	suite.True(errors.Is(err, ErrInvalidConfigValue))
+	_, err = suite.lnk.SetConfig("colour", "always", false)
+	suite.True(errors.Is(err, ErrUnknownConfigKey))
+
+	// Booleans are written as TOML booleans.
+	_, err = suite.lnk.SetConfig("watch.push", "true", false)
+	suite.Require().NoError(err)
+	content, err = os.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.True(strings.HasSuffix(string(content), "\n[watch]\npush = true\n"), string(content))
+	_, err = suite.lnk.SetConfig("watch.push", "yes", false)
+	suite.True(errors.Is(err, ErrInvalidConfigValue))
+	commits, err = suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+
+	// With the repository elsewhere, --user writes a separate, uncommitted file.
+	suite.T().Setenv("LNK_HOME", filepath.Join(suite.tempDir, "lnk"))
+	suite.T().Setenv("XDG_CONFIG_HOME", filepath.Join(suite.tempDir, "xdg"))
+	written, err = NewLnk().SetConfig("host", "work", true)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(suite.tempDir, "xdg", "lnk", "config.toml"), written)
+
+	cfg, err := LoadConfig()
+	suite.Require().NoError(err)
+	suite.Equal("work", cfg.Host)
+	suite.Equal("backup", cfg.OnConflict)
+	after, err = suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(len(commits), len(after))
+}
+
+// TestSetIndexFile verifies that index.file moves the common and host
+// tracking files in the commit that sets it, and that every later operation
+// reads and stages them at the new location.
+func (suite *CoreTestSuite) TestSetIndexFile() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+	_, err = NewLnk(WithHost("work")).Add(vimrc)
+	suite.Require().NoError(err)
+
+	_, err = suite.lnk.SetConfig("index.file", "meta/lnk-index", false)
+	suite.Require().NoError(err)
+	suite.NoFileExists(filepath.Join(repoPath, ".lnk"))
+	suite.NoFileExists(filepath.Join(repoPath, ".lnk.work"))
+	suite.FileExists(filepath.Join(repoPath, "meta", "lnk-index"))
+	suite.FileExists(filepath.Join(repoPath, "meta", "lnk-index.work"))
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: set index.file", commits[0])
+
+	items, err := NewLnk().List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, items)
+	hosts, err := NewLnk().Hosts()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"work"}, hosts)
+	problems, err := NewLnk().Verify()
+	suite.Require().NoError(err)
+	suite.Empty(problems, "init's consistency check should find the relocated files")
+
+	// New entries go to the relocated file and are committed with it.
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	_, err = NewLnk().Add(gitconfig)
+	suite.Require().NoError(err)
+	suite.NoFileExists(filepath.Join(repoPath, ".lnk"))
+	dirty, err = suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+	content, err := os.ReadFile(filepath.Join(repoPath, "meta", "lnk-index"))
+	suite.Require().NoError(err)
+	suite.Contains(string(content), ".gitconfig")
+
+	// Undo recognises the relocated index.
+	_, err = NewLnk().Undo(false)
+	suite.Require().NoError(err)
+	suite.FileExists(gitconfig)
+
+	// A file already at the target location stops the move and keeps the
+	// setting as it was.
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "taken"), []byte("other"), 0644))
+	_, err = NewLnk().SetConfig("index.file", "taken", false)
+	suite.ErrorIs(err, ErrStorageOccupied)
+	cfg, err := LoadConfig()
+	suite.Require().NoError(err)
+	suite.Equal("meta/lnk-index", cfg.IndexFile)
+
+	for _, value := range []string{"/etc/lnk", "../outside", ".git/index", "work.lnk/index", "config.toml"} {
+		_, err = NewLnk().SetConfig("index.file", value, false)
+		suite.ErrorIs(err, ErrInvalidConfigValue, value)
+	}
+}
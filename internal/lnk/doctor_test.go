@@ -16,7 +16,7 @@ func (suite *CoreTestSuite) TestDoctorNoInvalidEntries() {
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Run doctor — nothing should be invalid
@@ -37,7 +37,7 @@ func (suite *CoreTestSuite) TestDoctorRemovesInvalidEntries() {
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Add a second file so we can make it invalid
@@ -45,7 +45,7 @@ func (suite *CoreTestSuite) TestDoctorRemovesInvalidEntries() {
 	err = os.WriteFile(testFile2, []byte("set number"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile2)
+	_, err = suite.lnk.Add(testFile2)
 	suite.Require().NoError(err)
 
 	// Now manually delete .vimrc from the repo (simulate file disappearing)
@@ -131,7 +131,7 @@ func (suite *CoreTestSuite) TestDoctorFixesBrokenSymlinks() {
 	testFile := filepath.Join(suite.tempDir, ".bashrc")
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Remove the symlink to simulate a broken symlink scenario
@@ -159,13 +159,13 @@ func (suite *CoreTestSuite) TestDoctorCombinedIssues() {
 	testFile := filepath.Join(suite.tempDir, ".bashrc")
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	testFile2 := filepath.Join(suite.tempDir, ".vimrc")
 	err = os.WriteFile(testFile2, []byte("set number"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile2)
+	_, err = suite.lnk.Add(testFile2)
 	suite.Require().NoError(err)
 
 	lnkDir := filepath.Join(suite.tempDir, "lnk")
@@ -197,7 +197,7 @@ func (suite *CoreTestSuite) TestPreviewDoctorNoInvalidEntries() {
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Preview doctor — nothing should be invalid
@@ -215,13 +215,13 @@ func (suite *CoreTestSuite) TestPreviewDoctorReturnsInvalidEntries() {
 	testFile := filepath.Join(suite.tempDir, ".bashrc")
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	testFile2 := filepath.Join(suite.tempDir, ".vimrc")
 	err = os.WriteFile(testFile2, []byte("set number"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile2)
+	_, err = suite.lnk.Add(testFile2)
 	suite.Require().NoError(err)
 
 	// Delete .vimrc from the repo to create an invalid entry
@@ -313,7 +313,7 @@ func (suite *CoreTestSuite) TestPreviewDoctorWithHost() {
 	testFile := filepath.Join(suite.tempDir, ".vimrc")
 	err = os.WriteFile(testFile, []byte("set number"), 0644)
 	suite.Require().NoError(err)
-	err = hostLnk.Add(testFile)
+	_, err = hostLnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Delete from host storage to create invalid entry
@@ -342,7 +342,7 @@ func (suite *CoreTestSuite) TestPreviewDoctorDetectsBrokenSymlinks() {
 	testFile := filepath.Join(suite.tempDir, ".bashrc")
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Remove the symlink to simulate a broken symlink
@@ -368,3 +368,35 @@ func (suite *CoreTestSuite) TestPreviewDoctorDetectsBrokenSymlinks() {
 	suite.Require().NoError(err)
 	suite.Equal(len(commitsBefore), len(commitsAfter))
 }
+
+// TestDoctorReportsCollisions verifies that two index entries naming the same
+// $HOME file through a symlinked directory are reported without touching the
+// index.
+func (suite *CoreTestSuite) TestDoctorReportsCollisions() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	// ~/alias/app.conf is added while ~/alias is a real directory...
+	alias := filepath.Join(suite.tempDir, "alias")
+	suite.Require().NoError(os.MkdirAll(alias, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(alias, "app.conf"), []byte("a"), 0644))
+	_, err := suite.lnk.Add(filepath.Join(alias, "app.conf"))
+	suite.Require().NoError(err)
+
+	// ...which later becomes a link to ~/real, whose app.conf is tracked too.
+	real := filepath.Join(suite.tempDir, "real")
+	suite.Require().NoError(os.Rename(alias, real))
+	suite.Require().NoError(os.Symlink("real", alias))
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, "real"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "real", "app.conf"), []byte("b"), 0644))
+	suite.Require().NoError(suite.lnk.tracker.WriteManagedItems([]string{"alias/app.conf", "real/app.conf"}))
+
+	result, err := suite.lnk.PreviewDoctor()
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Collisions, 1)
+	suite.Equal("alias/app.conf", result.Collisions[0].First.Path)
+	suite.Equal("real/app.conf", result.Collisions[0].Second.Path)
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"alias/app.conf", "real/app.conf"}, items)
+}
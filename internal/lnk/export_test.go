@@ -0,0 +1,77 @@
+package lnk
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// TestExportChezmoi verifies the chezmoi source-state names emitted for
+// managed files and that the lnk repository is left unchanged.
+func (suite *CoreTestSuite) TestExportChezmoi() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	files := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{".bashrc", "export EDITOR=vim\n", 0644},
+		{".ssh/config", "Host *\n", 0600},
+		{".local/bin/run_backup", "#!/bin/sh\n", 0755},
+		{".config/app/settings.tmpl", "{{ not a template }}\n", 0644},
+		{".hushlogin", "", 0644},
+	}
+	var paths []string
+	for _, f := range files {
+		path := filepath.Join(suite.tempDir, f.path)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(f.content), f.mode))
+		suite.Require().NoError(os.Chmod(path, f.mode))
+		paths = append(paths, path)
+	}
+	suite.Require().NoError(suite.lnk.AddMultiple(paths))
+
+	hostLnk := NewLnk(WithHost("work"))
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]\n"), 0644))
+	_, err := hostLnk.Add(gitconfig)
+	suite.Require().NoError(err)
+
+	commitsBefore, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+
+	exported, err := hostLnk.ExportChezmoi(filepath.Join(suite.tempDir, "chezmoi"))
+	suite.Require().NoError(err)
+
+	names := make(map[string]string)
+	for _, f := range exported {
+		names[f.RelativePath] = f.Name
+		suite.False(f.Secret, f.RelativePath)
+	}
+	suite.Equal(map[string]string{
+		".bashrc":                       "dot_bashrc",
+		filepath.Join(".ssh", "config"): filepath.Join("dot_ssh", "private_config"),
+		filepath.Join(".local", "bin", "run_backup"):     filepath.Join("dot_local", "bin", "executable_literal_run_backup"),
+		filepath.Join(".config", "app", "settings.tmpl"): filepath.Join("dot_config", "app", "settings.tmpl.literal"),
+		".hushlogin": "empty_dot_hushlogin",
+		".gitconfig": "dot_gitconfig",
+	}, names)
+
+	content, err := os.ReadFile(filepath.Join(suite.tempDir, "chezmoi", "dot_ssh", "private_config"))
+	suite.Require().NoError(err)
+	suite.Equal("Host *\n", string(content))
+
+	commitsAfter, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(commitsBefore, commitsAfter)
+	changed, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(changed, "export must not modify the repository")
+
+	// A second export into the same directory is refused
+	_, err = hostLnk.ExportChezmoi(filepath.Join(suite.tempDir, "chezmoi"))
+	suite.ErrorIs(err, ErrExportDirNotEmpty)
+
+	_, err = suite.lnk.ExportChezmoi(filepath.Join(suite.tempDir, "lnk", "export"))
+	suite.ErrorIs(err, ErrExportInsideRepo)
+}
@@ -1,6 +1,7 @@
 package lnk
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -130,3 +131,177 @@ func (suite *CoreTestSuite) TestInitWithRemoteForce() {
 		})
 	}
 }
+
+// TestImportExisting verifies that a dotfiles repository created without lnk
+// can be adopted: repository files are left out of the inferred manifest, the
+// rest are tracked and symlinked, and conflicts are backed up.
+func (suite *CoreTestSuite) TestImportExisting() {
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	files := map[string]string{
+		".bashrc":               "export EDITOR=vim\n",
+		".config/nvim/init.lua": "vim.o.number = true\n",
+		"README.md":             "# dotfiles\n",
+		"bootstrap.sh":          "#!/bin/bash\n",
+		".gitignore":            "*.swp\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(lnkDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "Initial dotfiles"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = lnkDir
+		out, err := cmd.CombinedOutput()
+		suite.Require().NoError(err, string(out))
+	}
+
+	// A plain init refuses to adopt a repository it didn't create
+	suite.Error(suite.lnk.Init())
+
+	items, err := suite.lnk.ImportCandidates()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc", filepath.Join(".config", "nvim", "init.lua")}, items)
+
+	existing := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(existing, []byte("local\n"), 0644))
+
+	info, err := suite.lnk.ImportExisting(items)
+	suite.Require().NoError(err)
+	suite.Len(info.Restored, 2)
+	suite.Equal([]string{".bashrc"}, info.BackedUp)
+	suite.FileExists(existing + ".lnk-backup")
+
+	for _, item := range items {
+		link := filepath.Join(suite.tempDir, item)
+		fi, err := os.Lstat(link)
+		suite.Require().NoError(err)
+		suite.Equal(os.ModeSymlink, fi.Mode()&os.ModeSymlink, "%s should be a symlink", item)
+	}
+
+	managed, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal(items, managed)
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: imported 2 files", commits[0])
+
+	// Once the manifest exists, importing again is refused
+	_, err = suite.lnk.ImportCandidates()
+	suite.Error(err)
+	suite.Contains(err.Error(), "lnk pull")
+}
+
+// TestVerify verifies that an existing repository is checked against its
+// tracking files: items listed but not stored, stored files nothing lists and
+// unreadable tracking files are reported, and a consistent repository passes.
+func (suite *CoreTestSuite) TestVerify() {
+	suite.Require().NoError(suite.lnk.Init())
+	for _, name := range []string{".bashrc", ".vimrc"} {
+		path := filepath.Join(suite.tempDir, name)
+		suite.Require().NoError(os.WriteFile(path, []byte(name+"\n"), 0644))
+		_, err := suite.lnk.Add(path)
+		suite.Require().NoError(err)
+	}
+
+	problems, err := suite.lnk.Verify()
+	suite.Require().NoError(err)
+	suite.Empty(problems)
+
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(os.Remove(filepath.Join(lnkDir, ".vimrc")))
+	suite.Require().NoError(os.WriteFile(filepath.Join(lnkDir, ".zshrc"), []byte("setopt autocd\n"), 0644))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(lnkDir, "laptop.lnk"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(lnkDir, "laptop.lnk", ".gitconfig"), []byte("[user]\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(lnkDir, ".lnk.work"), []byte("# lnk v2\n{broken\n"), 0644))
+	cmd := exec.Command("git", "add", ".zshrc", "laptop.lnk")
+	cmd.Dir = lnkDir
+	out, err := cmd.CombinedOutput()
+	suite.Require().NoError(err, string(out))
+
+	problems, err = suite.lnk.Verify()
+	suite.Require().NoError(err)
+	suite.Len(problems, 4)
+	suite.Contains(problems[0], "~/.vimrc is listed in .lnk but missing from the repository")
+	suite.Contains(problems[1], ".lnk.work cannot be read")
+	suite.Contains(problems[2], ".zshrc is in the repository but not listed in .lnk")
+	suite.Contains(problems[3], "laptop.lnk/.gitconfig is stored for host laptop, which has no .lnk.laptop")
+
+	// Without any tracking file nothing is managed; with only host ones the
+	// common index is simply not needed.
+	suite.Require().NoError(os.Remove(filepath.Join(lnkDir, ".lnk")))
+	problems, err = suite.lnk.Verify()
+	suite.Require().NoError(err)
+	suite.NotContains(problems[0], ".lnk is missing")
+	suite.Require().NoError(os.Remove(filepath.Join(lnkDir, ".lnk.work")))
+	problems, err = suite.lnk.Verify()
+	suite.Require().NoError(err)
+	suite.Contains(problems[0], ".lnk is missing")
+}
+
+// TestInitFromTemplate verifies that --template copies a starter's files
+// into a new repository without its history or a remote.
+func (suite *CoreTestSuite) TestInitFromTemplate() {
+	templateDir := filepath.Join(suite.tempDir, "starter")
+	files := map[string]string{
+		"bootstrap.sh":        "#!/bin/bash\n",
+		".gitattributes":      "*.secret filter=git-crypt\n",
+		"examples/.gitconfig": "[user]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(templateDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+	suite.Require().NoError(os.Chmod(filepath.Join(templateDir, "bootstrap.sh"), 0755))
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "Starter"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = templateDir
+		out, err := cmd.CombinedOutput()
+		suite.Require().NoError(err, string(out))
+	}
+
+	copied, err := suite.lnk.InitFromTemplate(templateDir)
+	suite.Require().NoError(err)
+	suite.Equal([]string{".gitattributes", "bootstrap.sh", filepath.Join("examples", ".gitconfig")}, copied)
+
+	lnkDir := filepath.Join(suite.tempDir, "lnk")
+	info, err := os.Stat(filepath.Join(lnkDir, "bootstrap.sh"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0755), info.Mode().Perm())
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"lnk: seeded from template"}, commits)
+
+	out, err := exec.Command("git", "-C", lnkDir, "remote").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(out))
+
+	// Only a new repository can be seeded.
+	_, err = suite.lnk.InitFromTemplate(templateDir)
+	suite.ErrorIs(err, ErrGitRepoExists)
+}
+
+// TestWithContextCancelsGit verifies that git commands run under the
+// WithContext context, so a cancelled one fails instead of running.
+func (suite *CoreTestSuite) TestWithContextCancelsGit() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewLnk(WithContext(ctx)).Init()
+	suite.Require().Error(err)
+	suite.NoDirExists(filepath.Join(suite.tempDir, "lnk", ".git"))
+
+	suite.Require().NoError(NewLnk(WithContext(context.Background())).Init())
+	suite.DirExists(filepath.Join(suite.tempDir, "lnk", ".git"))
+}
@@ -2,19 +2,25 @@
 package lnk
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/yarlson/lnk/internal/bootstrapper"
+	"github.com/yarlson/lnk/internal/config"
 	"github.com/yarlson/lnk/internal/doctor"
+	"github.com/yarlson/lnk/internal/event"
+	"github.com/yarlson/lnk/internal/exporter"
 	"github.com/yarlson/lnk/internal/filemanager"
 	"github.com/yarlson/lnk/internal/fs"
 	"github.com/yarlson/lnk/internal/git"
 	"github.com/yarlson/lnk/internal/initializer"
 	"github.com/yarlson/lnk/internal/lnkerror"
+	"github.com/yarlson/lnk/internal/lock"
 	"github.com/yarlson/lnk/internal/syncer"
 	"github.com/yarlson/lnk/internal/tracker"
 )
@@ -29,66 +35,670 @@ var (
 	ErrBootstrapNotFound = lnkerror.ErrBootstrapNotFound
 	ErrBootstrapFailed   = lnkerror.ErrBootstrapFailed
 	ErrBootstrapPerms    = lnkerror.ErrBootstrapPerms
+	ErrInvalidHost       = lnkerror.ErrInvalidHost
+	ErrUncommitted       = lnkerror.ErrUncommitted
+	ErrInvalidIdentity   = lnkerror.ErrInvalidIdentity
+	ErrNoMatch           = lnkerror.ErrNoMatch
+	ErrInvalidPattern    = lnkerror.ErrInvalidPattern
+	ErrStowConflict      = lnkerror.ErrStowConflict
+	ErrStorageOccupied   = lnkerror.ErrStorageOccupied
+	ErrCannotUndo        = lnkerror.ErrCannotUndo
+	ErrAlreadyPushed     = lnkerror.ErrAlreadyPushed
+	ErrUndoConflict      = lnkerror.ErrUndoConflict
+	ErrInsideRepo        = lnkerror.ErrInsideRepo
+	ErrFileTooLarge      = lnkerror.ErrFileTooLarge
+	ErrBinaryFile        = lnkerror.ErrBinaryFile
+	ErrNoGitCrypt        = lnkerror.ErrNoGitCrypt
+	ErrInvalidInto       = lnkerror.ErrInvalidInto
+	ErrInvalidMode       = lnkerror.ErrInvalidMode
+	ErrRepoInconsistent  = lnkerror.ErrRepoInconsistent
+	ErrHostNotFound      = lnkerror.ErrHostNotFound
+	ErrHostExists        = lnkerror.ErrHostExists
+	ErrSymlinkCollision  = lnkerror.ErrSymlinkCollision
+	ErrInterrupted       = lnkerror.ErrInterrupted
+	ErrNothingStaged     = lnkerror.ErrNothingStaged
+	ErrHomeDirectory     = lnkerror.ErrHomeDirectory
+	ErrInvalidRepoName   = lnkerror.ErrInvalidRepoName
+	ErrHostOverride      = lnkerror.ErrHostOverride
+
+	ErrInvalidConflictAction = syncer.ErrInvalidConflictAction
+	ErrEmptyMessage          = syncer.ErrEmptyMessage
+	ErrRestoreIncomplete     = syncer.ErrRestoreIncomplete
+	ErrLocked                = lock.ErrLocked
+	ErrExportDirNotEmpty     = exporter.ErrExportDirNotEmpty
+	ErrExportInsideRepo      = exporter.ErrExportInsideRepo
+	ErrConfigSyntax          = config.ErrSyntax
+	ErrUnknownConfigKey      = config.ErrUnknownKey
+	ErrInvalidConfigValue    = config.ErrInvalidValue
+	ErrInvalidDate           = git.ErrInvalidDate
+	ErrAuthRequired          = git.ErrAuthRequired
+	ErrRemoteNotFound        = git.ErrRemoteNotFound
+	ErrLsRemote              = git.ErrLsRemote
+	ErrRebaseConflict        = git.ErrRebaseConflict
 )
 
 // ProgressCallback defines the signature for progress reporting callbacks.
 type ProgressCallback = filemanager.ProgressCallback
 
+// SkipHandler hears about special files, such as sockets and named pipes,
+// and excluded directories that recursive adds leave out.
+type SkipHandler = filemanager.SkipHandler
+
+// ExcludedDirKind is the kind a SkipHandler hears for a directory left out
+// because its name is on the skip list (WithSkipDirs).
+const ExcludedDirKind = filemanager.ExcludedDirKind
+
+// EmptyDirKind is the kind a SkipHandler hears for an empty directory a
+// recursive add cannot keep; a KeepFileName marker in it keeps it.
+const EmptyDirKind = filemanager.EmptyDirKind
+
+// KeepFileName is the marker file that keeps an otherwise empty directory.
+const KeepFileName = filemanager.KeepFileName
+
+// IgnoredKind is the kind a SkipHandler hears for an item left out because a
+// .lnkignore file or the user's ignore file matched it.
+const IgnoredKind = filemanager.IgnoredKind
+
+// ManagedFile describes an item Add put under management or Remove released:
+// where it lives in $HOME and in the repository, its scope and its kind.
+type ManagedFile = filemanager.ManagedFile
+
+// AddReport lists the files a skip-errors add managed and the ones it left out.
+type AddReport = filemanager.AddReport
+
+// AddFailure is a path a skip-errors add left out and why.
+type AddFailure = filemanager.AddFailure
+
+// UndoResult reports what Undo reversed in the repository and in $HOME.
+type UndoResult = filemanager.UndoResult
+
+// PreviewEntry pairs a file a dry-run add would manage with where it would be
+// stored in the repository.
+type PreviewEntry = filemanager.PreviewEntry
+
+// StowEntry maps a file of a GNU Stow package to where lnk will manage it.
+type StowEntry = filemanager.StowEntry
+
+// RenameHostResult reports what RenameHost moved, relinked and rewrote.
+type RenameHostResult = filemanager.RenameHostResult
+
+// UnmanagedFile is a dotfile in $HOME that lnk does not manage yet, with the
+// target of its symlink when another tool links it.
+type UnmanagedFile = filemanager.UnmanagedFile
+
+// ExportFile maps a repository file to its name in an exported layout.
+type ExportFile = exporter.File
+
+// Remote is a remote repository of the lnk repository, with whether push
+// and pull use it by default.
+type Remote = initializer.Remote
+
+// RemoteCheck reports how a remote answered VerifyRemote: the remote, how
+// many refs it advertises and git's output.
+type RemoteCheck = initializer.RemoteCheck
+
+// Config holds the settings read from config.toml files.
+type Config = config.Config
+
+// ConfigKey describes one setting config.toml understands.
+type ConfigKey = config.Key
+
+// UnknownConfigKey is a config.toml key LoadConfig skipped.
+type UnknownConfigKey = config.UnknownKey
+
 // StatusInfo contains repository sync status information.
 type StatusInfo = syncer.StatusInfo
 
+// ChangedItem is a managed item with uncommitted changes, listing the changed
+// files inside it when it is a managed directory.
+type ChangedItem = syncer.ChangedItem
+
+// LogEntry is a commit of the repository with the files it changed.
+type LogEntry = syncer.LogEntry
+
+// LogFile is a path a commit changed, mapped to its place under $HOME when a
+// managed item holds it.
+type LogFile = syncer.LogFile
+
+// MissingItem is a managed item a restore would put in place on this
+// machine, with what occupies its path instead, if anything.
+type MissingItem = syncer.MissingItem
+
+// Usage is the size of the managed set in the repository, item by item,
+// largest first.
+type Usage = syncer.Usage
+
+// ItemUsage is the stored size and file count of one managed item.
+type ItemUsage = syncer.ItemUsage
+
 // RestoreInfo reports symlink restoration results, including which files
 // were renamed to <path>.lnk-backup to preserve user data.
 type RestoreInfo = syncer.RestoreInfo
 
+// RestoreFailure is a managed item a restore could not put in place, with
+// the reason.
+type RestoreFailure = syncer.RestoreFailure
+
+// WatchOptions controls Watch: debounce, poll interval, push and progress.
+type WatchOptions = syncer.WatchOptions
+
+// WatchEvent reports one commit attempt by Watch.
+type WatchEvent = syncer.WatchEvent
+
+// DefaultWatchDebounce is how long Watch waits after the last change before
+// committing, unless WatchOptions.Debounce is set.
+const DefaultWatchDebounce = syncer.DefaultWatchDebounce
+
+// RetryHandler hears about each retry of a push, pull or fetch that failed on
+// the network.
+type RetryHandler = git.RetryHandler
+
+// Event is one thing lnk did: an item added, removed or restored, a commit
+// recorded or a push made.
+type Event = event.Event
+
+// EventKind names what an Event reports.
+type EventKind = event.Kind
+
+// EventHandler hears about each Event as it happens.
+type EventHandler = event.Handler
+
+// The kinds of Event.
+const (
+	FileAdded       = event.FileAdded
+	FileRemoved     = event.FileRemoved
+	SymlinkRestored = event.SymlinkRestored
+	GitCommitted    = event.GitCommitted
+	GitPushed       = event.GitPushed
+)
+
+// DefaultRetries is how many more times push, pull and fetch are attempted
+// after a transient network failure when neither WithRetries nor git.retries
+// is set.
+const DefaultRetries = git.DefaultRetries
+
+// DefaultRetryBackoff is the wait before the first network retry when
+// neither WithRetries nor git.retry_backoff sets one.
+const DefaultRetryBackoff = git.DefaultRetryBackoff
+
+// StatusOptions controls whether Status fetches from the remote first and
+// whether uncommitted changes are counted for one host only.
+type StatusOptions = syncer.StatusOptions
+
+// PushOptions narrows what Push commits before pushing.
+type PushOptions = syncer.PushOptions
+
+// HostRestoreInfo reports symlink restoration results for one host scope
+// during a multi-host pull. Host is empty for the common configuration.
+type HostRestoreInfo = syncer.HostRestoreInfo
+
+// ConflictAction says what a restore does with a real file found where a
+// managed symlink should go.
+type ConflictAction = syncer.ConflictAction
+
+// Conflict actions re-exported from syncer.
+const (
+	ConflictBackup    = syncer.ConflictBackup
+	ConflictOverwrite = syncer.ConflictOverwrite
+	ConflictSkip      = syncer.ConflictSkip
+)
+
+// ConflictResolver decides per conflict during symlink restoration.
+type ConflictResolver = syncer.ConflictResolver
+
+// ConflictPolicy returns a ConflictResolver that always picks action.
+func ConflictPolicy(action ConflictAction) ConflictResolver { return syncer.ConflictPolicy(action) }
+
+// ParseConflictAction validates a conflict policy name (overwrite|skip|backup).
+func ParseConflictAction(name string) (ConflictAction, error) {
+	return syncer.ParseConflictAction(name)
+}
+
+// ParseDate parses a WithAuthorDate value given as RFC 3339 or as a local
+// date ("2006-01-02", optionally followed by " 15:04"), failing with
+// ErrInvalidDate otherwise.
+func ParseDate(value string) (time.Time, error) {
+	return git.ParseDate(value)
+}
+
+// RedactURL hides credentials embedded in a remote URL so it can be shown.
+func RedactURL(rawURL string) string {
+	return git.RedactURL(rawURL)
+}
+
+// FormatSize renders a byte count for display, e.g. "12.5 MB" or "300 B".
+func FormatSize(n int64) string {
+	return fs.FormatSize(n)
+}
+
+// ManagedEntry is a managed item together with its tracking metadata.
+type ManagedEntry = tracker.Entry
+
 // DoctorResult contains the results of a doctor scan or execution.
 type DoctorResult = doctor.Result
 
+// Collision is a pair of managed items that would be linked at the same
+// location in $HOME.
+type Collision = tracker.Collision
+
+// NormalizeResult reports what normalizing the tracking file changed.
+type NormalizeResult = tracker.NormalizeResult
+
 // Lnk is the facade that composes focused collaborators for dotfile management.
 type Lnk struct {
 	repoPath string
 	host     string
-	tracker  *tracker.Tracker
-	files    *filemanager.Manager
-	syncer   *syncer.Syncer
-	init     *initializer.Service
-	boot     *bootstrapper.Runner
-	health   *doctor.Checker
+	sign     bool
+	date     time.Time
+	copy     bool
+	secret   bool
+	into     string
+	chmod    string
+	suffix   bool
+	absolute bool
+	xattrs   bool
+	comment  string
+	force    bool
+	allHome  bool
+	restore  bool
+	noCommit bool
+	maxSize  int64
+	ssh      string
+	retries  int
+	backoff  time.Duration
+	onRetry  RetryHandler
+	onEvent  EventHandler
+	remote   string
+	rebase   bool
+	ctx      context.Context
+	home     string
+	lockWait time.Duration
+	// lockWaitSet records an explicit WithLockTimeout, which config.toml
+	// must not override.
+	lockWaitSet bool
+	// retriesSet records an explicit WithRetries, likewise.
+	retriesSet bool
+	tracker    *tracker.Tracker
+	files      *filemanager.Manager
+	syncer     *syncer.Syncer
+	resolve    ConflictResolver
+	only       []string
+	skip       SkipHandler
+	skipDirs   []string
+	init       *initializer.Service
+	boot       *bootstrapper.Runner
+	health     *doctor.Checker
+	export     *exporter.Service
 }
 
+// DefaultMaxFileSize is the largest file an add accepts without
+// WithForceAdd when neither WithMaxFileSize nor add.max_size is set.
+const DefaultMaxFileSize = filemanager.DefaultMaxFileSize
+
+// DefaultLockTimeout is how long a mutating operation waits for another lnk
+// process to release the repository lock before failing with ErrLocked.
+const DefaultLockTimeout = 10 * time.Second
+
 // Option configures a Lnk instance.
 type Option func(*Lnk)
 
 // WithHost sets the host for host-specific configuration.
+// The host is interpolated into tracking file and storage paths, so callers
+// taking it from user input must check it with ValidateHost first.
 func WithHost(host string) Option {
 	return func(l *Lnk) {
 		l.host = host
 	}
 }
 
+// WithSign makes commits created by this instance signed with git's -S flag.
+// Repositories with commit.gpgsign enabled are signed either way.
+func WithSign(sign bool) Option {
+	return func(l *Lnk) {
+		l.sign = sign
+	}
+}
+
+// WithAuthorDate makes commits created by this instance carry t as their
+// author date, so bulk imports can be backdated. The zero time keeps the
+// default of the current time (or GIT_AUTHOR_DATE, when set).
+func WithAuthorDate(t time.Time) Option {
+	return func(l *Lnk) {
+		l.date = t
+	}
+}
+
+// WithCopy makes adds by this instance copy files into the repository and
+// leave the originals in place, instead of moving them and symlinking back.
+// Edits to copy-managed files reach the repository on the next push or sync.
+func WithCopy(enabled bool) Option {
+	return func(l *Lnk) {
+		l.copy = enabled
+	}
+}
+
+// WithSecret makes adds by this instance mark their items for git-crypt in
+// .gitattributes, so git-crypt encrypts them in the repository. The
+// repository must already be set up with `git-crypt init`.
+func WithSecret(enabled bool) Option {
+	return func(l *Lnk) {
+		l.secret = enabled
+	}
+}
+
+// WithInto makes adds by this instance store each item as <dir>/<basename>
+// in the repository instead of at its home-relative path; the symlink still
+// replaces the original. dir is relative to the storage root and is checked
+// on add, failing with ErrInvalidInto.
+func WithInto(dir string) Option {
+	return func(l *Lnk) {
+		l.into = dir
+	}
+}
+
+// WithChmod makes adds by this instance set mode, octal permissions such as
+// "0600", on each item stored in the repository and record it in the index,
+// so restores re-apply it. The mode is checked on add, failing with
+// ErrInvalidMode.
+func WithChmod(mode string) Option {
+	return func(l *Lnk) {
+		l.chmod = mode
+	}
+}
+
+// WithHostnameSuffix makes host adds by this instance store each item at the
+// repository root as <path>.<host>, e.g. .bashrc.work, instead of inside
+// <host>.lnk/. The layout is recorded per item, so pull restores the right
+// variant for the host either way. Adds to the common configuration ignore it.
+func WithHostnameSuffix(enabled bool) Option {
+	return func(l *Lnk) {
+		l.suffix = enabled
+	}
+}
+
+// WithAbsoluteSymlink makes adds by this instance link each item to the
+// absolute path of its stored copy instead of a relative one, for targets a
+// relative link cannot reach reliably, such as ones on another mount. The
+// mode is recorded per item, so pull recreates the same kind of link.
+func WithAbsoluteSymlink(enabled bool) Option {
+	return func(l *Lnk) {
+		l.absolute = enabled
+	}
+}
+
+// WithXattrs makes adds by this instance record each file's user.* extended
+// attributes in the index, and restores and removes set them again. Only
+// Linux reads and sets them; elsewhere the option does nothing.
+func WithXattrs(enabled bool) Option {
+	return func(l *Lnk) {
+		l.xattrs = enabled
+	}
+}
+
+// WithComment makes adds by this instance record comment in each new index
+// entry, for list --long and list --json to show.
+func WithComment(comment string) Option {
+	return func(l *Lnk) {
+		l.comment = comment
+	}
+}
+
+// WithMaxFileSize sets the size in bytes above which adds refuse a file with
+// ErrFileTooLarge. Without it, add.max_size from config.toml applies, or else
+// DefaultMaxFileSize.
+func WithMaxFileSize(size int64) Option {
+	return func(l *Lnk) {
+		l.maxSize = size
+	}
+}
+
+// WithSSHCommand makes clone, push, pull and fetch run git with command as
+// GIT_SSH_COMMAND, for a dotfiles remote that needs its own key or ssh
+// options. Without it, git.ssh_command from config.toml applies. A
+// GIT_SSH_COMMAND or GIT_SSH in the environment takes precedence over both.
+func WithSSHCommand(command string) Option {
+	return func(l *Lnk) {
+		l.ssh = command
+	}
+}
+
+// WithRetries sets how many more times push, pull and fetch are attempted
+// after a transient network failure (an unresolvable host, a refused or reset
+// connection) and the wait before the first retry, doubled for each one
+// after. Without it, git.retries and git.retry_backoff from config.toml
+// apply, or DefaultRetries and DefaultRetryBackoff; a zero backoff keeps
+// those as well.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(l *Lnk) {
+		l.retries = retries
+		l.backoff = backoff
+		l.retriesSet = true
+	}
+}
+
+// WithRetryHandler registers h to hear about each network retry, e.g. to
+// tell the user why a push is taking longer.
+func WithRetryHandler(h RetryHandler) Option {
+	return func(l *Lnk) {
+		l.onRetry = h
+	}
+}
+
+// WithEventHandler registers h to hear about what this instance does as
+// structured events, for programs embedding lnk that want to observe adds,
+// removes, restores, commits and pushes without reading the CLI's output.
+// File events come once the change is committed; h runs synchronously.
+func WithEventHandler(h EventHandler) Option {
+	return func(l *Lnk) {
+		l.onEvent = h
+	}
+}
+
+// WithForceAdd makes adds accept files over the size limit and files that
+// look binary, which are refused otherwise.
+func WithForceAdd(force bool) Option {
+	return func(l *Lnk) {
+		l.force = force
+	}
+}
+
+// WithAllowHome lets recursive adds walk the home directory itself, or a
+// directory holding it, adding every file below it. Without it they fail
+// with ErrHomeDirectory; a non-recursive add of it always does.
+func WithAllowHome(enabled bool) Option {
+	return func(l *Lnk) {
+		l.allHome = enabled
+	}
+}
+
+// WithRestore makes Remove move a managed item back to $HOME when its
+// symlink there was already deleted, instead of dropping the stored copy.
+func WithRestore(enabled bool) Option {
+	return func(l *Lnk) {
+		l.restore = enabled
+	}
+}
+
+// WithNoCommit makes adds and removes by this instance stage their changes
+// without committing them; Commit or a push records them later.
+func WithNoCommit(enabled bool) Option {
+	return func(l *Lnk) {
+		l.noCommit = enabled
+	}
+}
+
+// WithRemote makes push, pull and fetch use the remote called name instead
+// of the default one (origin, or the first remote without an origin).
+func WithRemote(name string) Option {
+	return func(l *Lnk) {
+		l.remote = name
+	}
+}
+
+// WithRebase makes pull and sync rebase local commits onto the remote's
+// instead of merging, for a linear history. A rebase that stops on conflicts
+// fails with ErrRebaseConflict and leaves the repository mid-rebase.
+func WithRebase(enabled bool) Option {
+	return func(l *Lnk) {
+		l.rebase = enabled
+	}
+}
+
+// WithContext makes every git command this instance runs use ctx, so
+// cancelling it aborts a clone, push or pull in progress rather than waiting
+// for git's own timeout.
+func WithContext(ctx context.Context) Option {
+	return func(l *Lnk) {
+		l.ctx = ctx
+	}
+}
+
+// WithLockTimeout overrides DefaultLockTimeout.
+func WithLockTimeout(d time.Duration) Option {
+	return func(l *Lnk) {
+		l.lockWait = d
+		l.lockWaitSet = true
+	}
+}
+
+// WithHome makes this instance use dir as the home directory instead of
+// $HOME: managed paths are relative to it, symlinks are restored under it,
+// and the repository defaults to dir/.config/lnk (or LNK_REPO_NAME in place
+// of lnk) unless LNK_HOME or XDG_CONFIG_HOME is set. A relative dir is resolved against the working
+// directory. Package-level helpers such as GetRepoPath keep using $HOME.
+func WithHome(dir string) Option {
+	return func(l *Lnk) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		l.home = dir
+	}
+}
+
+// WithConflictResolver sets how symlink restoration handles real files that
+// are in the way. Without it, such files are renamed to <path>.lnk-backup.
+func WithConflictResolver(r ConflictResolver) Option {
+	return func(l *Lnk) {
+		l.resolve = r
+	}
+}
+
+// WithRestoreOnly limits symlink restoration (pull and friends) to managed
+// items at or below one of prefixes, given as paths in $HOME. The rest are
+// left unlinked and listed in RestoreInfo.Excluded.
+func WithRestoreOnly(prefixes []string) Option {
+	return func(l *Lnk) {
+		l.only = prefixes
+	}
+}
+
+// WithSkipHandler sets a handler that recursive adds and previews call for
+// each special file they leave out. Without it they are skipped silently.
+func WithSkipHandler(h SkipHandler) Option {
+	return func(l *Lnk) {
+		l.skip = h
+	}
+}
+
+// WithSkipDirs sets the directory names recursive adds and previews leave
+// out, replacing the built-in .git, node_modules and __pycache__. Without it,
+// add.skip_dirs from config.toml applies; an empty, non-nil names leaves out
+// nothing.
+func WithSkipDirs(names []string) Option {
+	return func(l *Lnk) {
+		l.skipDirs = names
+	}
+}
+
 // NewLnk creates a new Lnk instance with optional configuration.
 func NewLnk(opts ...Option) *Lnk {
-	repoPath := GetRepoPath()
 	l := &Lnk{
-		repoPath: repoPath,
-		host:     "",
+		host: "",
 	}
 
 	for _, opt := range opts {
 		opt(l)
 	}
 
+	// The repository location depends on the home directory (WithHome).
+	repoPath := repoPathFor(l.home)
+	l.repoPath = repoPath
+
+	// config.toml overrides the built-in defaults; options override both.
+	// A broken file is reported by LoadConfig, which the CLI calls first.
+	cfg, err := config.Load(configPathsFor(l.home)...)
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	if !l.lockWaitSet {
+		l.lockWait = DefaultLockTimeout
+		if cfg.LockTimeout > 0 {
+			l.lockWait = cfg.LockTimeout
+		}
+	}
+	if l.maxSize == 0 {
+		l.maxSize = cfg.AddMaxSize
+	}
+	if l.ssh == "" {
+		l.ssh = cfg.SSHCommand
+	}
+	if !l.retriesSet {
+		l.retries = DefaultRetries
+		if cfg.Retries != 0 {
+			l.retries = max(cfg.Retries, 0)
+		}
+	}
+	if l.backoff == 0 {
+		l.backoff = cfg.RetryWait
+	}
+	if l.skipDirs == nil {
+		l.skipDirs = cfg.SkipDirs
+	}
+
 	// Wire collaborators after options are applied (host may change).
 	g := git.New(repoPath)
+	g.SetSign(l.sign)
+	g.SetAuthorDate(l.date)
+	g.SetSSHCommand(l.ssh)
+	g.SetRetries(l.retries, l.backoff)
+	g.SetRetryHandler(l.onRetry)
+	g.SetEventHandler(l.onEvent)
+	g.SetRemote(l.remote)
+	g.SetRebase(l.rebase)
+	g.SetContext(l.ctx)
 	f := fs.New()
+	f.SetHome(l.home)
 	t := tracker.New(repoPath, l.host)
+	t.SetIndexFile(cfg.IndexFile)
 
 	l.tracker = t
 	l.files = filemanager.New(repoPath, l.host, g, f, t)
+	l.files.SetCopy(l.copy)
+	l.files.SetSecret(l.secret)
+	l.files.SetInto(l.into)
+	l.files.SetChmod(l.chmod)
+	l.files.SetHostSuffix(l.suffix)
+	l.files.SetAbsoluteSymlink(l.absolute)
+	l.files.SetXattrs(l.xattrs)
+	l.files.SetComment(l.comment)
+	l.files.SetSkipHandler(l.skip)
+	l.files.SetSkipDirs(l.skipDirs)
+	l.files.SetGlobalIgnore(filepath.Join(configHomeFor(l.home), "lnk", "ignore"))
+	l.files.SetMaxFileSize(l.maxSize)
+	l.files.SetForce(l.force)
+	l.files.SetAllowHome(l.allHome)
+	l.files.SetRestore(l.restore)
+	l.files.SetNoCommit(l.noCommit)
+	l.files.SetEventHandler(l.onEvent)
 	l.syncer = syncer.New(repoPath, l.host, g, f, t)
+	l.syncer.SetConflictResolver(l.resolve)
+	l.syncer.SetRestoreOnly(l.only)
+	l.syncer.SetEventHandler(l.onEvent)
+	l.syncer.SetHostName(l.messageHost)
 	l.init = initializer.New(repoPath, g, t)
 	l.boot = bootstrapper.New(repoPath, g)
-	l.health = doctor.New(repoPath, l.host, g, t, l.syncer)
+	l.health = doctor.New(repoPath, l.host, g, f, t, l.syncer)
+	l.export = exporter.New(repoPath, l.host, f, t)
 
 	return l
 }
@@ -100,40 +710,243 @@ func (l *Lnk) InitWithRemote(remoteURL string) error { return l.init.InitWithRem
 func (l *Lnk) InitWithRemoteForce(remoteURL string, force bool) error {
 	return l.init.InitWithRemoteForce(remoteURL, force)
 }
+func (l *Lnk) InitFromTemplate(url string) ([]string, error) {
+	return l.init.InitFromTemplate(url)
+}
 func (l *Lnk) Clone(url string) error           { return l.init.Clone(url) }
 func (l *Lnk) AddRemote(name, url string) error { return l.init.AddRemote(name, url) }
 func (l *Lnk) HasUserContent() bool             { return l.init.HasUserContent() }
+func (l *Lnk) IsInitialized() bool              { return l.init.IsInitialized() }
+
+// Verify checks an existing repository against its tracking files and
+// returns the problems found, one per line; see initializer.Service.Verify.
+func (l *Lnk) Verify() ([]string, error) { return l.init.Verify() }
+func (l *Lnk) SetIdentity(name, email string) error {
+	return l.init.SetIdentity(name, email)
+}
+func (l *Lnk) Remotes() ([]Remote, error) {
+	return l.init.Remotes()
+}
+
+// VerifyRemote checks that the remote push and pull would use (WithRemote,
+// or the default one) can be reached and read, without fetching anything. It
+// takes no lock, since nothing in the repository changes.
+func (l *Lnk) VerifyRemote() (*RemoteCheck, error) {
+	return l.init.VerifyRemote()
+}
+func (l *Lnk) Identity() (name, email string, err error) { return l.init.Identity() }
+
+// SetConfig writes a setting to the repository's shared config.toml and
+// commits it, or with user set to the machine-local one in
+// $XDG_CONFIG_HOME/lnk. It returns the path of the file written.
+func (l *Lnk) SetConfig(name, value string, user bool) (string, error) {
+	path := filepath.Join(l.repoPath, config.FileName)
+	if user {
+		path = filepath.Join(configHomeFor(l.home), "lnk", config.FileName)
+	}
+	if path != filepath.Join(l.repoPath, config.FileName) {
+		return path, l.init.SetConfig(path, name, value)
+	}
+	return path, l.withLock(func() error { return l.init.SetConfig(path, name, value) })
+}
+func (l *Lnk) ImportCandidates() ([]string, error) { return l.init.ImportCandidates() }
+
+// ImportExisting adopts a dotfiles repository that was not created by lnk:
+// it writes items (from ImportCandidates) as the manifest, commits it, and
+// restores the symlinks, resolving conflicts like Pull does.
+func (l *Lnk) ImportExisting(items []string) (*RestoreInfo, error) {
+	return withLockResult(l, func() (*RestoreInfo, error) {
+		if err := l.init.Import(items); err != nil {
+			return nil, err
+		}
+		return l.syncer.RestoreSymlinks()
+	})
+}
+
+// --- Repository lock ---
+
+// withLock runs fn holding the repository lock, an exclusive lock on
+// .git/lnk.lock that serializes mutating operations across lnk processes.
+// Waiting for it ends early when the WithContext context is done. Before the
+// repository exists there is nothing to protect, so fn runs unlocked and
+// reports the missing repository itself.
+func (l *Lnk) withLock(fn func() error) error {
+	_, err := withLockResult(l, func() (struct{}, error) { return struct{}{}, fn() })
+	return err
+}
+
+// withLockResult is withLock for operations that return a value.
+func withLockResult[T any](l *Lnk, fn func() (T, error)) (T, error) {
+	gitDir := filepath.Join(l.repoPath, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return fn()
+	}
+
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	repoLock, err := lock.Acquire(ctx, filepath.Join(gitDir, "lnk.lock"), l.lockWait)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer func() { _ = repoLock.Release() }()
+
+	return fn()
+}
 
 // --- File management delegates ---
 
-func (l *Lnk) Add(filePath string) error        { return l.files.Add(filePath) }
-func (l *Lnk) AddMultiple(paths []string) error { return l.files.AddMultiple(paths, nil) }
+func (l *Lnk) Add(filePath string) (*ManagedFile, error) {
+	return withLockResult(l, func() (*ManagedFile, error) { return l.files.Add(filePath) })
+}
+func (l *Lnk) AddMultiple(paths []string) error {
+	return l.AddMultipleWithProgress(paths, nil)
+}
+func (l *Lnk) AddMultipleWithProgress(paths []string, progress ProgressCallback) error {
+	return l.withLock(func() error { return l.files.AddMultiple(paths, progress) })
+}
 func (l *Lnk) AddRecursive(paths []string) error {
-	return l.files.AddRecursiveWithProgress(paths, nil)
+	return l.AddRecursiveWithProgress(paths, nil)
 }
 func (l *Lnk) AddRecursiveWithProgress(paths []string, progress ProgressCallback) error {
-	return l.files.AddRecursiveWithProgress(paths, progress)
+	return l.withLock(func() error { return l.files.AddRecursiveWithProgress(paths, progress) })
+}
+func (l *Lnk) AddMultipleSkipErrors(paths []string, progress ProgressCallback) (*AddReport, error) {
+	return withLockResult(l, func() (*AddReport, error) { return l.files.AddMultipleSkipErrors(paths, progress) })
+}
+func (l *Lnk) AddRecursiveSkipErrors(paths []string, progress ProgressCallback) (*AddReport, error) {
+	return withLockResult(l, func() (*AddReport, error) { return l.files.AddRecursiveSkipErrors(paths, progress) })
 }
 func (l *Lnk) PreviewAdd(paths []string, recursive bool) ([]string, error) {
 	return l.files.PreviewAdd(paths, recursive)
 }
-func (l *Lnk) Remove(filePath string) error      { return l.files.Remove(filePath) }
-func (l *Lnk) RemoveForce(filePath string) error { return l.files.RemoveForce(filePath) }
+func (l *Lnk) ExpandPaths(paths []string) ([]string, error) {
+	return l.files.ExpandPaths(paths)
+}
+func (l *Lnk) PreviewAddEntries(paths []string, recursive bool) ([]PreviewEntry, error) {
+	return l.files.PreviewAddEntries(paths, recursive)
+}
+func (l *Lnk) Remove(filePath string) (*ManagedFile, error) {
+	return withLockResult(l, func() (*ManagedFile, error) { return l.files.Remove(filePath) })
+}
+func (l *Lnk) RemoveForce(filePath string) (*ManagedFile, error) {
+	return withLockResult(l, func() (*ManagedFile, error) { return l.files.RemoveForce(filePath) })
+}
+func (l *Lnk) RemoveKeep(filePath string) (*ManagedFile, error) {
+	return withLockResult(l, func() (*ManagedFile, error) { return l.files.RemoveKeep(filePath) })
+}
+func (l *Lnk) Undo(force bool) (*UndoResult, error) {
+	return withLockResult(l, func() (*UndoResult, error) { return l.files.Undo(force) })
+}
+func (l *Lnk) ManagedPath(filePath string) (string, error) {
+	return l.files.ManagedPath(filePath)
+}
+func (l *Lnk) LookupManaged(filePath string) (ManagedEntry, string, error) {
+	return l.files.LookupManaged(filePath)
+}
+func (l *Lnk) RenameHost(oldHost, newHost string) (*RenameHostResult, error) {
+	for _, host := range []string{oldHost, newHost} {
+		if err := ValidateHost(host); err != nil {
+			return nil, err
+		}
+	}
+	return withLockResult(l, func() (*RenameHostResult, error) { return l.files.RenameHost(oldHost, newHost) })
+}
+func (l *Lnk) Unmanaged(patterns []string) ([]UnmanagedFile, error) {
+	return l.files.Unmanaged(patterns)
+}
+func (l *Lnk) PlanStowImport(stowDir string, dotfiles bool) ([]StowEntry, error) {
+	return l.files.PlanStowImport(stowDir, dotfiles)
+}
+func (l *Lnk) ImportStow(stowDir string, dotfiles bool) ([]StowEntry, error) {
+	return withLockResult(l, func() ([]StowEntry, error) { return l.files.ImportStow(stowDir, dotfiles) })
+}
 
 // --- Sync delegates ---
 
-func (l *Lnk) Status() (*StatusInfo, error)           { return l.syncer.Status() }
-func (l *Lnk) Diff(color bool) (string, error)        { return l.syncer.Diff(color) }
-func (l *Lnk) HasDiff() (bool, error)                 { return l.syncer.HasDiff() }
-func (l *Lnk) Push(message string) error              { return l.syncer.Push(message) }
-func (l *Lnk) Pull() (*RestoreInfo, error)            { return l.syncer.Pull() }
-func (l *Lnk) List() ([]string, error)                { return l.syncer.List() }
-func (l *Lnk) GetCommits() ([]string, error)          { return l.syncer.GetCommits() }
-func (l *Lnk) RestoreSymlinks() (*RestoreInfo, error) { return l.syncer.RestoreSymlinks() }
+func (l *Lnk) Status() (*StatusInfo, error)    { return l.syncer.Status() }
+func (l *Lnk) Diff(color bool) (string, error) { return l.syncer.Diff(color) }
+func (l *Lnk) HasDiff() (bool, error)          { return l.syncer.HasDiff() }
+func (l *Lnk) HasChanges() (bool, error)       { return l.syncer.HasChanges() }
+func (l *Lnk) List() ([]string, error)         { return l.syncer.List() }
+func (l *Lnk) GetCommits() ([]string, error)   { return l.syncer.GetCommits() }
+func (l *Lnk) Push(message string) error {
+	return l.withLock(func() error { return l.syncer.Push(message) })
+}
+func (l *Lnk) Commit(message string) error {
+	return l.withLock(func() error { return l.syncer.Commit(message) })
+}
+func (l *Lnk) Pull() (*RestoreInfo, error) {
+	return withLockResult(l, l.syncer.Pull)
+}
+func (l *Lnk) RestoreSymlinks() (*RestoreInfo, error) {
+	return withLockResult(l, l.syncer.RestoreSymlinks)
+}
+func (l *Lnk) PullHosts(hosts []string) ([]HostRestoreInfo, error) {
+	return withLockResult(l, func() ([]HostRestoreInfo, error) { return l.syncer.PullHosts(hosts) })
+}
+func (l *Lnk) PushWithOptions(message string, opts PushOptions) error {
+	return l.withLock(func() error { return l.syncer.PushWithOptions(message, opts) })
+}
+func (l *Lnk) Sync(message string, hosts []string) ([]HostRestoreInfo, error) {
+	return withLockResult(l, func() ([]HostRestoreInfo, error) { return l.syncer.Sync(message, hosts) })
+}
+
+// RenderMessage fills the {date}, {host} and {count} placeholders of a sync
+// commit message; with only, {count} covers just those managed paths. {host}
+// is the active host configuration, or this machine's sanitized hostname for
+// the common one.
+func (l *Lnk) RenderMessage(template string, only ...string) (string, error) {
+	return l.syncer.RenderMessage(template, only...)
+}
+
+// Watch commits (and optionally pushes) changes to managed files until ctx is
+// done, taking the repository lock for each commit rather than for the whole
+// run so other lnk commands keep working meanwhile. Create the instance with
+// WithContext(ctx) as well to have cancelling ctx kill a push in progress.
+func (l *Lnk) Watch(ctx context.Context, opts WatchOptions) error {
+	return l.syncer.Watch(ctx, opts, l.withLock)
+}
+
+func (l *Lnk) StatusWithOptions(opts StatusOptions) (*StatusInfo, error) {
+	return l.syncer.StatusWithOptions(opts)
+}
+func (l *Lnk) ChangedItems() ([]ChangedItem, error) {
+	return l.syncer.ChangedItems()
+}
+func (l *Lnk) Log(since string, max int) ([]LogEntry, error) {
+	return l.syncer.Log(since, max)
+}
+func (l *Lnk) Missing(host string) ([]MissingItem, error) {
+	return l.syncer.Missing(host)
+}
+func (l *Lnk) Usage(all bool) (*Usage, error) {
+	return l.syncer.Usage(all)
+}
+func (l *Lnk) ListEntries() ([]ManagedEntry, error)        { return l.syncer.ListEntries() }
+func (l *Lnk) IsManagedDirectory(relativePath string) bool { return l.syncer.IsDirectory(relativePath) }
+func (l *Lnk) DiffFiles(existingPath, repoItem string, color bool) (string, error) {
+	return l.syncer.DiffFiles(existingPath, repoItem, color)
+}
+
+// Hosts returns the hosts with a tracking file in the repository, in
+// directory order.
+func (l *Lnk) Hosts() ([]string, error) {
+	return l.tracker.Hosts()
+}
+
+// ListAllHosts returns the managed items of every configuration, keyed by
+// host: "" for the common one, then each host with a tracking file.
+func (l *Lnk) ListAllHosts() (map[string][]ManagedFile, error) {
+	return l.files.ListAllHosts()
+}
 
 // --- Bootstrap delegates ---
 
-func (l *Lnk) FindBootstrapScript() (string, error) { return l.boot.FindScript() }
+func (l *Lnk) FindBootstrapScript() (string, error)         { return l.boot.FindScript() }
+func (l *Lnk) CheckBootstrapScript(scriptName string) error { return l.boot.CheckScript(scriptName) }
 func (l *Lnk) RunBootstrapScript(scriptName string, stdout, stderr io.Writer, stdin io.Reader) error {
 	return l.boot.RunScript(scriptName, stdout, stderr, stdin)
 }
@@ -141,7 +954,13 @@ func (l *Lnk) RunBootstrapScript(scriptName string, stdout, stderr io.Writer, st
 // --- Doctor delegates ---
 
 func (l *Lnk) PreviewDoctor() (*DoctorResult, error) { return l.health.Preview() }
-func (l *Lnk) Doctor() (*DoctorResult, error)        { return l.health.Fix() }
+func (l *Lnk) Doctor() (*DoctorResult, error)        { return withLockResult(l, l.health.Fix) }
+func (l *Lnk) Normalize() (*NormalizeResult, error)  { return withLockResult(l, l.health.Normalize) }
+
+// --- Export delegates ---
+
+func (l *Lnk) PlanChezmoiExport() ([]ExportFile, error)       { return l.export.PlanChezmoi() }
+func (l *Lnk) ExportChezmoi(dir string) ([]ExportFile, error) { return l.export.ExportChezmoi(dir) }
 
 // --- Package-level helpers ---
 
@@ -194,21 +1013,164 @@ func GetCurrentHostname() (string, error) {
 	return hostname, nil
 }
 
+// messageHost is the {host} of commit messages: the active host, or the
+// sanitized hostname --host auto would select.
+func (l *Lnk) messageHost() (string, error) {
+	if l.host != "" {
+		return l.host, nil
+	}
+	return ResolveHost(AutoHost)
+}
+
+// AutoHost is the --host value that selects the current machine's hostname.
+const AutoHost = "auto"
+
+// SanitizeHostname turns a system hostname into a host name that is safe to
+// embed in tracking file names and storage directories. The domain part is
+// dropped (like `hostname -s`) and any character other than ASCII letters,
+// digits, '-' and '_' is replaced with '-'.
+func SanitizeHostname(hostname string) string {
+	if i := strings.IndexByte(hostname, '.'); i > 0 {
+		hostname = hostname[:i]
+	}
+	return strings.Map(func(r rune) rune {
+		if hostRune(r) {
+			return r
+		}
+		return '-'
+	}, hostname)
+}
+
+// hostRune reports whether r is kept as it is in a host name: an ASCII
+// letter or digit, '-' or '_'.
+func hostRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+// ResolveHost expands the special AutoHost value to the sanitized current
+// hostname. Any other value is returned unchanged.
+func ResolveHost(host string) (string, error) {
+	if host != AutoHost {
+		return host, nil
+	}
+	hostname, err := GetCurrentHostname()
+	if err != nil {
+		return "", err
+	}
+	return SanitizeHostname(hostname), nil
+}
+
+// ValidateHost rejects host names that would escape the repository when
+// interpolated into `.lnk.<host>` or `<host>.lnk/`: path separators, `.` and
+// `..` components, and values that are blank after trimming. It also rejects
+// every character SanitizeHostname would rewrite, such as ':' or spaces, which
+// are unsafe in file names on some systems, so an explicit host name is one
+// `--host auto` could have produced (apart from dots). The empty string is
+// valid and selects the common configuration.
+func ValidateHost(host string) error {
+	if host == "" {
+		return nil
+	}
+	if strings.TrimSpace(host) == "" {
+		return lnkerror.WithSuggestion(lnkerror.ErrInvalidHost, "host name must not be blank")
+	}
+	if strings.ContainsAny(host, `/\`) {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidHost, host, "host name must not contain path separators")
+	}
+	if host == "." || strings.Contains(host, "..") {
+		return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidHost, host, "host name must not contain '..'")
+	}
+	for _, r := range host {
+		if r != '.' && !hostRune(r) {
+			return lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidHost, host, fmt.Sprintf("host name must only use letters, digits, '-', '_' and '.', not %q", r))
+		}
+	}
+	return nil
+}
+
 // GetRepoPath returns the path to the lnk repository directory.
-// Priority: LNK_HOME > XDG_CONFIG_HOME/lnk > ~/.config/lnk.
+// Priority: LNK_HOME > XDG_CONFIG_HOME/<name> > ~/.config/<name>, where name
+// is RepoName's.
 func GetRepoPath() string {
+	return repoPathFor("")
+}
+
+// DefaultRepoName is the repository's directory name under the config
+// directory unless LNK_REPO_NAME names another.
+const DefaultRepoName = "lnk"
+
+// RepoName returns the repository's directory name under $XDG_CONFIG_HOME
+// or ~/.config: LNK_REPO_NAME when it is set, e.g. dotfiles, otherwise
+// DefaultRepoName. A value that is not a single directory name fails with
+// ErrInvalidRepoName, and the repository path falls back to DefaultRepoName.
+// LNK_HOME, naming the whole path, makes the name irrelevant. lnk's own
+// machine-local files (UserConfigPath, the global ignore file) stay under
+// $XDG_CONFIG_HOME/lnk whatever the name.
+func RepoName() (string, error) {
+	name := os.Getenv("LNK_REPO_NAME")
+	if name == "" {
+		return DefaultRepoName, nil
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return DefaultRepoName, lnkerror.WithPathAndSuggestion(lnkerror.ErrInvalidRepoName, name, "set LNK_REPO_NAME to a single directory name such as dotfiles, or LNK_HOME to a full path")
+	}
+	return name, nil
+}
+
+// UserConfigPath returns the machine-local config file,
+// $XDG_CONFIG_HOME/lnk/config.toml. With the default repository location it
+// is the repository's own config.toml.
+func UserConfigPath() string {
+	return filepath.Join(configHomeFor(""), "lnk", config.FileName)
+}
+
+// ConfigPaths returns the config files LoadConfig reads, lowest precedence
+// first: the repository's shared config.toml, then the machine-local one.
+func ConfigPaths() []string {
+	return configPathsFor("")
+}
+
+// LoadConfig reads and merges the files from ConfigPaths. Missing files are
+// fine; a malformed one fails with ErrConfigSyntax, ErrUnknownConfigKey or
+// ErrInvalidConfigValue, naming the file and line.
+func LoadConfig() (*Config, error) {
+	return config.Load(ConfigPaths()...)
+}
+
+// ConfigKeys returns every setting config.toml understands.
+func ConfigKeys() []ConfigKey {
+	return config.Keys()
+}
+
+// repoPathFor is GetRepoPath with home in place of $HOME when it is set.
+func repoPathFor(home string) string {
 	if lnkHome := os.Getenv("LNK_HOME"); lnkHome != "" {
 		return lnkHome
 	}
+	name, _ := RepoName()
+	return filepath.Join(configHomeFor(home), name)
+}
 
-	xdgConfig := os.Getenv("XDG_CONFIG_HOME")
-	if xdgConfig == "" {
+// configPathsFor is ConfigPaths with home in place of $HOME when it is set.
+func configPathsFor(home string) []string {
+	return []string{
+		filepath.Join(repoPathFor(home), config.FileName),
+		filepath.Join(configHomeFor(home), "lnk", config.FileName),
+	}
+}
+
+// configHomeFor returns $XDG_CONFIG_HOME, defaulting to .config under home,
+// or under $HOME when home is empty.
+func configHomeFor(home string) string {
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return xdgConfig
+	}
+	if home == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			xdgConfig = "."
-		} else {
-			xdgConfig = filepath.Join(homeDir, ".config")
+			return "."
 		}
+		home = homeDir
 	}
-	return filepath.Join(xdgConfig, "lnk")
+	return filepath.Join(home, ".config")
 }
@@ -1,14 +1,19 @@
 package lnk
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
 	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lock"
+	"github.com/yarlson/lnk/internal/tracker"
 )
 
 type CoreTestSuite struct {
@@ -35,8 +40,9 @@ func (suite *CoreTestSuite) SetupTest() {
 	// Set HOME to temp directory for consistent relative path calculation
 	suite.T().Setenv("HOME", tempDir)
 
-	// Clear LNK_HOME so it doesn't override test paths
+	// Clear LNK_HOME and LNK_REPO_NAME so they don't override test paths
 	suite.T().Setenv("LNK_HOME", "")
+	suite.T().Setenv("LNK_REPO_NAME", "")
 
 	// Set XDG_CONFIG_HOME to temp directory
 	suite.T().Setenv("XDG_CONFIG_HOME", tempDir)
@@ -98,7 +104,7 @@ func (suite *CoreTestSuite) TestErrorConditions() {
 	err := suite.lnk.Init()
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add("/nonexistent/file")
+	_, err = suite.lnk.Add("/nonexistent/file")
 	suite.Error(err)
 	suite.Contains(err.Error(), "File or directory not found")
 
@@ -107,7 +113,7 @@ func (suite *CoreTestSuite) TestErrorConditions() {
 	err = os.WriteFile(testFile, []byte("content"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Remove(testFile)
+	_, err = suite.lnk.Remove(testFile)
 	suite.Error(err)
 	suite.Contains(err.Error(), "File is not managed by lnk")
 
@@ -125,6 +131,78 @@ func (suite *CoreTestSuite) TestHostnameDetection() {
 	suite.NotEmpty(hostname)
 }
 
+// TestSanitizeHostname tests that hostnames are made safe for tracking files
+func (suite *CoreTestSuite) TestSanitizeHostname() {
+	tests := []struct {
+		name     string
+		hostname string
+		want     string
+	}{
+		{name: "plain", hostname: "laptop", want: "laptop"},
+		{name: "domain dropped", hostname: "MacBook-Pro.local", want: "MacBook-Pro"},
+		{name: "colons replaced", hostname: "host:8080", want: "host-8080"},
+		{name: "underscore kept", hostname: "work_box", want: "work_box"},
+		{name: "slashes replaced", hostname: "a/b", want: "a-b"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Equal(tt.want, SanitizeHostname(tt.hostname))
+		})
+	}
+}
+
+// TestResolveHost tests expansion of the "auto" host value
+func (suite *CoreTestSuite) TestResolveHost() {
+	host, err := ResolveHost("work")
+	suite.NoError(err)
+	suite.Equal("work", host)
+
+	host, err = ResolveHost("")
+	suite.NoError(err)
+	suite.Equal("", host)
+
+	hostname, err := GetCurrentHostname()
+	suite.Require().NoError(err)
+	host, err = ResolveHost(AutoHost)
+	suite.NoError(err)
+	suite.Equal(SanitizeHostname(hostname), host)
+}
+
+// TestValidateHost tests that traversal-prone host names are rejected
+func (suite *CoreTestSuite) TestValidateHost() {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{
+		{name: "common", host: "", wantErr: false},
+		{name: "plain", host: "work", wantErr: false},
+		{name: "dotted", host: "my.host", wantErr: false},
+		{name: "parent traversal", host: "../../evil", wantErr: true},
+		{name: "nested separator", host: "a/b", wantErr: true},
+		{name: "backslash", host: `a\b`, wantErr: true},
+		{name: "dot-dot only", host: "..", wantErr: true},
+		{name: "dot only", host: ".", wantErr: true},
+		{name: "blank", host: "   ", wantErr: true},
+		{name: "underscore and dash", host: "my_work-laptop", wantErr: false},
+		{name: "colon", host: "my:host", wantErr: true},
+		{name: "inner space", host: "my host", wantErr: true},
+		{name: "non-ascii", host: "büro", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := ValidateHost(tt.host)
+			if tt.wantErr {
+				suite.ErrorIs(err, ErrInvalidHost)
+			} else {
+				suite.NoError(err)
+			}
+		})
+	}
+}
+
 // TestGetRelativePath tests path conversion with various scenarios
 func (suite *CoreTestSuite) TestGetRelativePath() {
 	tests := []struct {
@@ -242,6 +320,23 @@ func (suite *CoreTestSuite) TestGetRepoPath() {
 			},
 			wantSuffix: "/.config/lnk",
 		},
+		{
+			name: "LNK_REPO_NAME renames the directory",
+			setupEnv: func() {
+				suite.T().Setenv("LNK_HOME", "")
+				suite.T().Setenv("XDG_CONFIG_HOME", "/custom/config")
+				suite.T().Setenv("LNK_REPO_NAME", "dotfiles")
+			},
+			wantSuffix: "/custom/config/dotfiles",
+		},
+		{
+			name: "LNK_HOME wins over LNK_REPO_NAME",
+			setupEnv: func() {
+				suite.T().Setenv("LNK_HOME", "/custom/dotfiles")
+				suite.T().Setenv("LNK_REPO_NAME", "other")
+			},
+			wantSuffix: "/custom/dotfiles",
+		},
 	}
 
 	for _, tt := range tests {
@@ -253,6 +348,21 @@ func (suite *CoreTestSuite) TestGetRepoPath() {
 	}
 }
 
+// TestRepoName verifies that LNK_REPO_NAME must be a single directory name,
+// and that the repository path keeps the default name when it is not.
+func (suite *CoreTestSuite) TestRepoName() {
+	name, err := RepoName()
+	suite.Require().NoError(err)
+	suite.Equal(DefaultRepoName, name)
+
+	for _, bad := range []string{"a/b", "..", "."} {
+		suite.T().Setenv("LNK_REPO_NAME", bad)
+		_, err := RepoName()
+		suite.ErrorIs(err, ErrInvalidRepoName, bad)
+		suite.Equal(filepath.Join(suite.tempDir, DefaultRepoName), GetRepoPath())
+	}
+}
+
 // Task 1.1: Tests for HasUserContent() method
 func (suite *CoreTestSuite) TestHasUserContent_WithCommonTracker_ReturnsTrue() {
 	// Initialize lnk repository
@@ -349,7 +459,7 @@ func (suite *CoreTestSuite) TestLnkFileTracking() {
 	testFile := filepath.Join(suite.tempDir, ".bashrc")
 	err = os.WriteFile(testFile, []byte("export PATH=/usr/local/bin:$PATH"), 0644)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	testDir := filepath.Join(suite.tempDir, ".ssh")
@@ -358,7 +468,7 @@ func (suite *CoreTestSuite) TestLnkFileTracking() {
 	configFile := filepath.Join(testDir, "config")
 	err = os.WriteFile(configFile, []byte("Host example.com"), 0600)
 	suite.Require().NoError(err)
-	err = suite.lnk.Add(testDir)
+	_, err = suite.lnk.Add(testDir)
 	suite.Require().NoError(err)
 
 	// Check .lnk file contains both entries
@@ -368,8 +478,9 @@ func (suite *CoreTestSuite) TestLnkFileTracking() {
 	lnkContent, err := os.ReadFile(lnkFile)
 	suite.Require().NoError(err)
 
-	lines := strings.Split(strings.TrimSpace(string(lnkContent)), "\n")
-	suite.Len(lines, 2)
+	entries, err := tracker.ParseEntries(lnkContent)
+	suite.Require().NoError(err)
+	suite.Len(entries, 2)
 
 	// The .lnk file now contains relative paths, not basenames
 	// Check that the content contains references to .bashrc and .ssh
@@ -378,16 +489,107 @@ func (suite *CoreTestSuite) TestLnkFileTracking() {
 	suite.Contains(content, ".ssh", ".lnk file should contain reference to .ssh")
 
 	// Remove one item and verify tracking is updated
-	err = suite.lnk.Remove(testFile)
+	_, err = suite.lnk.Remove(testFile)
 	suite.Require().NoError(err)
 
 	lnkContent, err = os.ReadFile(lnkFile)
 	suite.Require().NoError(err)
 
-	lines = strings.Split(strings.TrimSpace(string(lnkContent)), "\n")
-	suite.Len(lines, 1)
+	entries, err = tracker.ParseEntries(lnkContent)
+	suite.Require().NoError(err)
+	suite.Len(entries, 1)
 
 	content = string(lnkContent)
 	suite.Contains(content, ".ssh", ".lnk file should still contain reference to .ssh")
 	suite.NotContains(content, ".bashrc", ".lnk file should not contain reference to .bashrc after removal")
 }
+
+// TestRepositoryLock tests that mutating operations wait for the repository lock
+func (suite *CoreTestSuite) TestRepositoryLock() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	testFile := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(testFile, []byte("export PATH"), 0644))
+
+	held, err := lock.Acquire(context.Background(), filepath.Join(suite.tempDir, "lnk", ".git", "lnk.lock"), time.Second)
+	suite.Require().NoError(err)
+
+	impatient := NewLnk(WithLockTimeout(100 * time.Millisecond))
+	_, err = impatient.Add(testFile)
+	suite.Require().Error(err)
+	suite.ErrorIs(err, ErrLocked)
+
+	info, err := os.Lstat(testFile)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0), info.Mode()&os.ModeSymlink, "a locked-out add must not touch the file")
+
+	// Read-only operations don't take the lock
+	_, err = impatient.List()
+	suite.NoError(err)
+
+	suite.Require().NoError(held.Release())
+	_, err = impatient.Add(testFile)
+	suite.NoError(err)
+}
+
+// TestConcurrentAdds tests that overlapping adds are serialized rather than racing
+func (suite *CoreTestSuite) TestConcurrentAdds() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	const count = 5
+	var files []string
+	for i := range count {
+		file := filepath.Join(suite.tempDir, fmt.Sprintf(".rc%d", i))
+		suite.Require().NoError(os.WriteFile(file, []byte("content"), 0644))
+		files = append(files, file)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, count)
+	for i, file := range files {
+		wg.Go(func() {
+			_, errs[i] = NewLnk().Add(file)
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		suite.NoError(err)
+	}
+
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Len(items, count, "every concurrent add should be recorded")
+}
+
+// TestWithHome verifies that WithHome replaces $HOME for the repository
+// location, managed paths and restored symlinks, even when HOME is unset.
+func (suite *CoreTestSuite) TestWithHome() {
+	home := filepath.Join(suite.tempDir, "service")
+	suite.Require().NoError(os.MkdirAll(home, 0755))
+	suite.T().Setenv("HOME", "")
+	suite.T().Setenv("XDG_CONFIG_HOME", "")
+
+	l := NewLnk(WithHome(home))
+	suite.Require().NoError(l.Init())
+	repoPath := filepath.Join(home, ".config", "lnk")
+	suite.DirExists(filepath.Join(repoPath, ".git"))
+
+	file := filepath.Join(home, ".bashrc")
+	suite.Require().NoError(os.WriteFile(file, []byte("export PATH"), 0644))
+	added, err := l.Add(file)
+	suite.Require().NoError(err)
+	suite.Equal(".bashrc", added.RelativePath)
+	suite.Equal(filepath.Join(repoPath, ".bashrc"), added.RepoPath)
+
+	suite.Require().NoError(os.Remove(file))
+	info, err := l.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Restored)
+
+	target, err := filepath.EvalSymlinks(file)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(repoPath, ".bashrc"), target)
+}
@@ -2,6 +2,7 @@ package lnk
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 )
 
@@ -26,7 +27,7 @@ func (suite *CoreTestSuite) TestRemove() {
 				if err != nil {
 					return "", err
 				}
-				err = suite.lnk.Add(testFile)
+				_, err = suite.lnk.Add(testFile)
 				return testFile, err
 			},
 			wantErr: false,
@@ -73,7 +74,7 @@ func (suite *CoreTestSuite) TestRemove() {
 			suite.Require().NoError(err, "Setup failed for test: %s", tt.name)
 
 			// Execute Remove
-			err = suite.lnk.Remove(filePath)
+			_, err = suite.lnk.Remove(filePath)
 
 			// Verify
 			if tt.wantErr {
@@ -113,7 +114,7 @@ func (suite *CoreTestSuite) TestRemoveForce() {
 					return "", err
 				}
 
-				if err := suite.lnk.Add(filePath); err != nil {
+				if _, err := suite.lnk.Add(filePath); err != nil {
 					return "", err
 				}
 
@@ -145,7 +146,7 @@ func (suite *CoreTestSuite) TestRemoveForce() {
 					return "", err
 				}
 
-				if err := suite.lnk.Add(filePath); err != nil {
+				if _, err := suite.lnk.Add(filePath); err != nil {
 					return "", err
 				}
 
@@ -181,7 +182,7 @@ func (suite *CoreTestSuite) TestRemoveForce() {
 			filePath, err := tt.setupFunc()
 			suite.Require().NoError(err, "Setup failed for: %s", tt.name)
 
-			err = suite.lnk.RemoveForce(filePath)
+			_, err = suite.lnk.RemoveForce(filePath)
 
 			if tt.wantErr {
 				suite.Error(err, "Expected error for test: %s", tt.name)
@@ -197,3 +198,171 @@ func (suite *CoreTestSuite) TestRemoveForce() {
 		})
 	}
 }
+
+// TestRemoveReadOnly verifies that read-only files and directories come back
+// from the repository with the permissions they were added with.
+func (suite *CoreTestSuite) TestRemoveReadOnly() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	file := filepath.Join(suite.tempDir, ".vendored.conf")
+	suite.Require().NoError(os.WriteFile(file, []byte("vendored"), 0444))
+	_, err := suite.lnk.Add(file)
+	suite.Require().NoError(err)
+	_, err = suite.lnk.Remove(file)
+	suite.Require().NoError(err)
+
+	info, err := os.Lstat(file)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.Equal(os.FileMode(0444), info.Mode().Perm())
+	content, err := os.ReadFile(file)
+	suite.Require().NoError(err)
+	suite.Equal("vendored", string(content))
+
+	dir := filepath.Join(suite.tempDir, ".vendor")
+	suite.Require().NoError(os.MkdirAll(dir, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "init.lua"), []byte("-- vendored"), 0444))
+	suite.Require().NoError(os.Chmod(dir, 0555))
+	defer func() { _ = os.Chmod(dir, 0755) }() // so TearDownTest can delete it
+
+	_, err = suite.lnk.Add(dir)
+	suite.Require().NoError(err)
+	_, err = suite.lnk.Remove(dir)
+	suite.Require().NoError(err)
+
+	info, err = os.Lstat(dir)
+	suite.Require().NoError(err)
+	suite.True(info.IsDir())
+	suite.Equal(os.FileMode(0555), info.Mode().Perm())
+	suite.FileExists(filepath.Join(dir, "init.lua"))
+}
+
+// TestRemoveHostOverride verifies that removing a common entry whose $HOME
+// link a host version has taken over is refused and leaves both scopes as
+// they were, and that removing it with the host returns the host's file.
+func (suite *CoreTestSuite) TestRemoveHostOverride() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	_, err := suite.lnk.Add(gitconfig)
+	suite.Require().NoError(err)
+	work := NewLnk(WithHost("work"))
+	_, err = work.Add(gitconfig)
+	suite.Require().NoError(err)
+	hostItem := filepath.Join(repoPath, "work.lnk", ".gitconfig")
+	suite.True(suite.lnk.syncer.IsValidSymlink(gitconfig, hostItem))
+
+	_, err = suite.lnk.Remove(gitconfig)
+	suite.ErrorIs(err, ErrHostOverride)
+	suite.True(suite.lnk.syncer.IsValidSymlink(gitconfig, hostItem))
+	suite.FileExists(filepath.Join(repoPath, ".gitconfig"))
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".gitconfig"}, items)
+	status, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(status))
+
+	_, err = work.Remove(gitconfig)
+	suite.Require().NoError(err)
+	info, err := os.Lstat(gitconfig)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.NoFileExists(hostItem)
+	suite.FileExists(filepath.Join(repoPath, ".gitconfig"))
+}
+
+// TestRemoveKeep verifies that rm --keep drops the entry and the stored file
+// from git while the symlink and the repository file stay usable.
+func (suite *CoreTestSuite) TestRemoveKeep() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	file := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(file, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(file)
+	suite.Require().NoError(err)
+
+	removed, err := suite.lnk.RemoveKeep(file)
+	suite.Require().NoError(err)
+	suite.Equal(".bashrc", removed.RelativePath)
+
+	content, err := os.ReadFile(file)
+	suite.Require().NoError(err, "symlink should still resolve")
+	suite.Equal("export PATH", string(content))
+	info, err := os.Lstat(file)
+	suite.Require().NoError(err)
+	suite.NotZero(info.Mode() & os.ModeSymlink)
+	suite.FileExists(filepath.Join(suite.tempDir, "lnk", ".bashrc"))
+
+	items, err := suite.lnk.tracker.GetManagedItems()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: untracked .bashrc", commits[0])
+
+	exclude, err := os.ReadFile(filepath.Join(suite.tempDir, "lnk", ".git", "info", "exclude"))
+	suite.Require().NoError(err)
+	suite.Contains(string(exclude), "/.bashrc\n")
+
+	// The file is neither tracked nor reported as a change to push.
+	out, err := exec.Command("git", "-C", filepath.Join(suite.tempDir, "lnk"), "ls-files", ".bashrc").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(out))
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+
+	_, err = suite.lnk.RemoveKeep(file)
+	suite.ErrorIs(err, ErrNotManaged)
+}
+
+// TestRemoveMissingSymlink verifies that Remove untracks an item whose
+// symlink was deleted by hand, and that WithRestore moves the stored file
+// back instead of deleting it.
+func (suite *CoreTestSuite) TestRemoveMissingSymlink() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0600))
+	suite.Require().NoError(suite.lnk.AddMultiple([]string{bashrc, vimrc}))
+	suite.Require().NoError(os.Remove(bashrc))
+	suite.Require().NoError(os.Remove(vimrc))
+
+	removed, err := suite.lnk.Remove(bashrc)
+	suite.Require().NoError(err)
+	suite.True(removed.Missing)
+	suite.False(removed.Restored)
+	suite.NoFileExists(bashrc)
+	suite.NoFileExists(filepath.Join(suite.tempDir, "lnk", ".bashrc"))
+
+	removed, err = NewLnk(WithRestore(true)).Remove(vimrc)
+	suite.Require().NoError(err)
+	suite.True(removed.Restored)
+	content, err := os.ReadFile(vimrc)
+	suite.Require().NoError(err)
+	suite.Equal("set number", string(content))
+	info, err := os.Lstat(vimrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	items, err := suite.lnk.tracker.GetManagedItems()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"lnk: removed .vimrc", "lnk: removed .bashrc"}, commits[:2])
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+
+	// An item that was never managed still fails.
+	_, err = suite.lnk.Remove(filepath.Join(suite.tempDir, ".zshrc"))
+	suite.Error(err)
+}
@@ -1,9 +1,14 @@
 package lnk
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // TestSymlinkRestoration tests symlink restoration after pull
@@ -58,7 +63,7 @@ func (suite *CoreTestSuite) TestGitOperations() {
 	err = os.WriteFile(testFile, []byte(content), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Check that Git commit was made
@@ -88,7 +93,7 @@ func (suite *CoreTestSuite) TestStatusDetectsDirtyRepo() {
 	err = os.WriteFile(testFile, []byte("abc"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Add a remote so status works
@@ -136,7 +141,7 @@ func (suite *CoreTestSuite) TestListManagedItems() {
 	err = os.WriteFile(testFile, []byte(content), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testFile)
+	_, err = suite.lnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Test list with one managed file
@@ -153,7 +158,7 @@ func (suite *CoreTestSuite) TestListManagedItems() {
 	err = os.WriteFile(configFile, []byte("setting=value"), 0644)
 	suite.Require().NoError(err)
 
-	err = suite.lnk.Add(testDir)
+	_, err = suite.lnk.Add(testDir)
 	suite.Require().NoError(err)
 
 	// Test list with multiple managed items
@@ -175,7 +180,7 @@ func (suite *CoreTestSuite) TestListManagedItems() {
 	suite.True(found[".config"], "Should contain .config")
 
 	// Remove one item and verify list is updated
-	err = suite.lnk.Remove(testFile)
+	_, err = suite.lnk.Remove(testFile)
 	suite.Require().NoError(err)
 
 	items, err = suite.lnk.List()
@@ -202,12 +207,12 @@ func (suite *CoreTestSuite) TestMultihostFileOperations() {
 
 	// Add file to common configuration
 	commonLnk := NewLnk()
-	err = commonLnk.Add(testFile1)
+	_, err = commonLnk.Add(testFile1)
 	suite.Require().NoError(err)
 
 	// Add file to host-specific configuration
 	hostLnk := NewLnk(WithHost("workstation"))
-	err = hostLnk.Add(testFile2)
+	_, err = hostLnk.Add(testFile2)
 	suite.Require().NoError(err)
 
 	// Verify both files are symlinks
@@ -246,10 +251,10 @@ func (suite *CoreTestSuite) TestMultihostFileOperations() {
 	suite.FileExists(hostTrackingFile)
 
 	// Test removal
-	err = commonLnk.Remove(testFile1)
+	_, err = commonLnk.Remove(testFile1)
 	suite.Require().NoError(err)
 
-	err = hostLnk.Remove(testFile2)
+	_, err = hostLnk.Remove(testFile2)
 	suite.Require().NoError(err)
 
 	// Verify files are restored
@@ -324,11 +329,11 @@ func (suite *CoreTestSuite) TestMultihostIsolation() {
 
 	// Add to common
 	commonLnk := NewLnk()
-	err = commonLnk.Add(testFile)
+	_, err = commonLnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Remove and recreate with different content
-	err = commonLnk.Remove(testFile)
+	_, err = commonLnk.Remove(testFile)
 	suite.Require().NoError(err)
 
 	hostContent := "[user]\n\tname = Work User"
@@ -337,7 +342,7 @@ func (suite *CoreTestSuite) TestMultihostIsolation() {
 
 	// Add to host-specific
 	hostLnk := NewLnk(WithHost("work"))
-	err = hostLnk.Add(testFile)
+	_, err = hostLnk.Add(testFile)
 	suite.Require().NoError(err)
 
 	// Verify tracking files are separate
@@ -456,6 +461,25 @@ func (suite *CoreTestSuite) TestIsValidSymlink() {
 	}
 }
 
+// TestIsValidSymlinkCaseDifference verifies that a symlink whose target
+// differs from the expected path only in case is valid exactly when the
+// filesystem is case-insensitive, as it is by default on macOS.
+func (suite *CoreTestSuite) TestIsValidSymlinkCaseDifference() {
+	repoDir := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoDir, "Dotfiles"), 0755))
+	expectedTarget := filepath.Join(repoDir, "Dotfiles", "vimrc")
+	suite.Require().NoError(os.WriteFile(expectedTarget, []byte("content"), 0644))
+
+	_, err := os.Stat(filepath.Join(repoDir, "DOTFILES", "VIMRC"))
+	caseInsensitive := err == nil
+
+	symlink := filepath.Join(suite.tempDir, "case-link")
+	suite.Require().NoError(os.Symlink(filepath.Join(repoDir, "dotfiles", "VIMRC"), symlink))
+
+	suite.Equal(caseInsensitive, suite.lnk.syncer.IsValidSymlink(symlink, expectedTarget),
+		"case-insensitive filesystem: %v", caseInsensitive)
+}
+
 // TestRestoreSymlinks tests symlink restoration with table-driven tests
 func (suite *CoreTestSuite) TestRestoreSymlinks() {
 	tests := []struct {
@@ -531,6 +555,171 @@ func (suite *CoreTestSuite) TestRestoreSymlinks() {
 	}
 }
 
+// TestRestoreCustomXDGConfigHome verifies that a config added from a custom
+// XDG_CONFIG_HOME is recorded under .config/ and restored into whatever
+// config directory the restoring machine uses.
+func (suite *CoreTestSuite) TestRestoreCustomXDGConfigHome() {
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.T().Setenv("LNK_HOME", repoPath)
+	suite.T().Setenv("XDG_CONFIG_HOME", filepath.Join(suite.tempDir, "xdg"))
+
+	l := NewLnk()
+	suite.Require().NoError(l.Init())
+
+	configFile := filepath.Join(suite.tempDir, "xdg", "app", "config.toml")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(configFile), 0755))
+	suite.Require().NoError(os.WriteFile(configFile, []byte("theme = \"dark\""), 0644))
+	_, err := l.Add(configFile)
+	suite.Require().NoError(err)
+
+	repoFile := filepath.Join(repoPath, ".config", "app", "config.toml")
+	suite.FileExists(repoFile)
+	index, err := os.ReadFile(filepath.Join(repoPath, ".lnk"))
+	suite.Require().NoError(err)
+	suite.Contains(string(index), `"path":".config/app/config.toml"`)
+	suite.Contains(string(index), `"xdg":true`)
+
+	expectedRepoFile, err := filepath.EvalSymlinks(repoFile)
+	suite.Require().NoError(err)
+	for _, configHome := range []string{filepath.Join(suite.tempDir, "other"), ""} {
+		suite.T().Setenv("XDG_CONFIG_HOME", configHome)
+		if configHome == "" {
+			configHome = filepath.Join(suite.tempDir, ".config")
+		}
+
+		_, err := NewLnk().RestoreSymlinks()
+		suite.Require().NoError(err)
+
+		target, err := filepath.EvalSymlinks(filepath.Join(configHome, "app", "config.toml"))
+		suite.Require().NoError(err, configHome)
+		suite.Equal(expectedRepoFile, target)
+	}
+
+	// The item is still found by its new location.
+	_, err = NewLnk().Remove(filepath.Join(suite.tempDir, ".config", "app", "config.toml"))
+	suite.Require().NoError(err)
+	index, err = os.ReadFile(filepath.Join(repoPath, ".lnk"))
+	suite.Require().NoError(err)
+	suite.NotContains(string(index), "config.toml")
+}
+
+// TestRestoreAbsoluteSymlink verifies that an item added with
+// WithAbsoluteSymlink is linked by its absolute path, counts as in place on
+// restore, and gets its absolute link back when it was replaced by a relative
+// one or deleted.
+func (suite *CoreTestSuite) TestRestoreAbsoluteSymlink() {
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export EDITOR=vim"), 0644))
+
+	managed, err := NewLnk(WithAbsoluteSymlink(true)).Add(bashrc)
+	suite.Require().NoError(err)
+	dest, err := os.Readlink(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(managed.RepoPath, dest)
+	index, err := os.ReadFile(filepath.Join(suite.tempDir, "lnk", ".lnk"))
+	suite.Require().NoError(err)
+	suite.Contains(string(index), `"absolute":true`)
+
+	info, err := suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.InPlace)
+	suite.Empty(info.Restored)
+	dest, err = os.Readlink(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(managed.RepoPath, dest)
+
+	// A relative link resolves but is not what the entry records.
+	suite.Require().NoError(os.Remove(bashrc))
+	suite.Require().NoError(os.Symlink(filepath.Join("lnk", ".bashrc"), bashrc))
+	info, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Restored)
+	dest, err = os.Readlink(bashrc)
+	suite.Require().NoError(err)
+	suite.True(filepath.IsAbs(dest), dest)
+
+	suite.Require().NoError(os.Remove(bashrc))
+	info, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Restored)
+	dest, err = os.Readlink(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(managed.RepoPath, dest)
+}
+
+// TestRestoreAfterRepoMoved verifies that when the repository lives at
+// another path than the one its symlinks were made for, as after migrating
+// to a machine with another user name, doctor reports the links and a
+// restore rewrites every one of them, relative and absolute, to the current
+// storage path, also when the old location still exists.
+func (suite *CoreTestSuite) TestRestoreAfterRepoMoved() {
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export EDITOR=vim"), 0644))
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+	_, err = NewLnk(WithAbsoluteSymlink(true)).Add(vimrc)
+	suite.Require().NoError(err)
+
+	oldRepo := filepath.Join(suite.tempDir, "lnk")
+	newRepo := filepath.Join(suite.tempDir, "moved", "dotfiles")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(newRepo), 0755))
+	suite.Require().NoError(os.Rename(oldRepo, newRepo))
+	suite.T().Setenv("LNK_HOME", newRepo)
+
+	preview, err := NewLnk().PreviewDoctor()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".bashrc", ".vimrc"}, preview.BrokenSymlinks)
+
+	info, err := NewLnk().RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]string{".bashrc", ".vimrc"}, info.Restored)
+	suite.ElementsMatch([]string{".bashrc", ".vimrc"}, info.Relinked)
+	dest, err := os.Readlink(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join("moved", "dotfiles", ".bashrc"), dest)
+	dest, err = os.Readlink(vimrc)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(newRepo, ".vimrc"), dest)
+
+	// A copy left at the old path still resolves, but is not the storage.
+	suite.Require().NoError(os.MkdirAll(oldRepo, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(oldRepo, ".bashrc"), []byte("old"), 0644))
+	suite.Require().NoError(os.Remove(bashrc))
+	suite.Require().NoError(os.Symlink(filepath.Join("lnk", ".bashrc"), bashrc))
+	info, err = NewLnk().RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Relinked)
+	suite.Equal([]string{".vimrc"}, info.InPlace)
+	content, err := os.ReadFile(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal("export EDITOR=vim", string(content))
+}
+
+// TestRestoreOnly verifies that WithRestoreOnly links only the items at or
+// below the given paths and reports the others as excluded.
+func (suite *CoreTestSuite) TestRestoreOnly() {
+	suite.Require().NoError(suite.lnk.Init())
+	for _, name := range []string{".bashrc", ".config/nvim/init.lua", ".config/nvim-old/init.vim"} {
+		path := filepath.Join(suite.tempDir, name)
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(name), 0644))
+		_, err := suite.lnk.Add(path)
+		suite.Require().NoError(err)
+		suite.Require().NoError(os.Remove(path))
+	}
+
+	// Relative paths resolve against the working directory, here $HOME.
+	info, err := NewLnk(WithRestoreOnly([]string{".config/nvim/"})).RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{filepath.Join(".config", "nvim", "init.lua")}, info.Restored)
+	suite.ElementsMatch([]string{".bashrc", filepath.Join(".config", "nvim-old", "init.vim")}, info.Excluded)
+	suite.NoFileExists(filepath.Join(suite.tempDir, ".bashrc"))
+}
+
 // TestRestoreSymlinksBackupsExistingFile tests that regular files are backed up, not deleted
 func (suite *CoreTestSuite) TestRestoreSymlinksBackupsExistingFile() {
 	err := suite.lnk.Init()
@@ -576,6 +765,148 @@ func (suite *CoreTestSuite) TestRestoreSymlinksBackupsExistingFile() {
 	suite.Equal("original content", string(content))
 }
 
+// TestRestoreSymlinksConflictResolver tests that the resolver decides what
+// happens to an existing file and sees the paths it needs for a diff.
+func (suite *CoreTestSuite) TestRestoreSymlinksConflictResolver() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	repoFile := filepath.Join(suite.tempDir, "lnk", ".bashrc")
+	suite.Require().NoError(os.WriteFile(repoFile, []byte("repo content\n"), 0644))
+	lnkFile := filepath.Join(suite.tempDir, "lnk", ".lnk")
+	suite.Require().NoError(os.WriteFile(lnkFile, []byte(".bashrc\n"), 0644))
+
+	homeDir, err := os.UserHomeDir()
+	suite.Require().NoError(err)
+	targetFile := filepath.Join(homeDir, ".bashrc")
+	defer func() { _ = os.Remove(targetFile) }()
+
+	tests := []struct {
+		name   string
+		action ConflictAction
+		check  func(info *RestoreInfo)
+	}{
+		{
+			name:   "skip keeps the existing file",
+			action: ConflictSkip,
+			check: func(info *RestoreInfo) {
+				suite.Empty(info.Restored)
+				suite.Equal([]string{".bashrc"}, info.Skipped)
+				content, err := os.ReadFile(targetFile)
+				suite.Require().NoError(err)
+				suite.Equal("original content\n", string(content))
+			},
+		},
+		{
+			name:   "overwrite replaces without backup",
+			action: ConflictOverwrite,
+			check: func(info *RestoreInfo) {
+				suite.Equal([]string{".bashrc"}, info.Restored)
+				suite.Equal([]string{".bashrc"}, info.Overwritten)
+				suite.Empty(info.BackedUp)
+				suite.NoFileExists(targetFile + ".lnk-backup")
+				fi, err := os.Lstat(targetFile)
+				suite.Require().NoError(err)
+				suite.Equal(os.ModeSymlink, fi.Mode()&os.ModeSymlink)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			_ = os.Remove(targetFile)
+			suite.Require().NoError(os.WriteFile(targetFile, []byte("original content\n"), 0644))
+
+			var diff string
+			l := NewLnk(WithConflictResolver(func(relativePath, existingPath, repoItem string) (ConflictAction, error) {
+				suite.Equal(".bashrc", relativePath)
+				diff, err = suite.lnk.DiffFiles(existingPath, repoItem, false)
+				suite.Require().NoError(err)
+				return tt.action, nil
+			}))
+
+			info, err := l.RestoreSymlinks()
+			suite.Require().NoError(err)
+			suite.Contains(diff, "-original content")
+			suite.Contains(diff, "+repo content")
+			tt.check(info)
+		})
+	}
+}
+
+// TestRestoreSymlinksIdenticalConflicts verifies that a real file or
+// directory matching the stored item is replaced without asking the resolver
+// or keeping a backup, while a differing one still goes to the resolver.
+func (suite *CoreTestSuite) TestRestoreSymlinksIdenticalConflicts() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	for name, content := range map[string]string{".bashrc": "same\n", ".vimrc": "repo\n", ".config/app/init.lua": "-- same\n"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0755))
+		suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, ".lnk"), []byte(".bashrc\n.config/app\n.vimrc\n"), 0644))
+
+	for name, content := range map[string]string{".bashrc": "same\n", ".vimrc": "local\n", ".config/app/init.lua": "-- same\n"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(suite.tempDir, name)), 0755))
+		suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, name), []byte(content), 0644))
+	}
+
+	var asked []string
+	l := NewLnk(WithConflictResolver(func(relativePath, existingPath, repoItem string) (ConflictAction, error) {
+		asked = append(asked, relativePath)
+		return ConflictBackup, nil
+	}))
+	info, err := l.RestoreSymlinks()
+	suite.Require().NoError(err)
+
+	suite.Equal([]string{".vimrc"}, asked)
+	suite.ElementsMatch([]string{".bashrc", ".config/app", ".vimrc"}, info.Restored)
+	suite.Equal([]string{".vimrc"}, info.BackedUp)
+	suite.Empty(info.Overwritten)
+	for _, name := range []string{".bashrc", ".config/app", ".vimrc"} {
+		fi, err := os.Lstat(filepath.Join(suite.tempDir, name))
+		suite.Require().NoError(err)
+		suite.Equal(os.ModeSymlink, fi.Mode()&os.ModeSymlink, name)
+	}
+	suite.NoFileExists(filepath.Join(suite.tempDir, ".bashrc.lnk-backup"))
+	suite.NoDirExists(filepath.Join(suite.tempDir, ".config", "app.lnk-backup"))
+	suite.FileExists(filepath.Join(suite.tempDir, ".vimrc.lnk-backup"))
+}
+
+// TestRestoreSymlinksReportsOutcomes verifies that a restore accounts for
+// every managed item: restored, already in place, missing from the
+// repository, or failed with a reason, without one failure stopping the rest.
+func (suite *CoreTestSuite) TestRestoreSymlinksReportsOutcomes() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	for _, name := range []string{".bashrc", ".vimrc", ".local/app.conf"} {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0755))
+		suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, name), []byte(name), 0644))
+	}
+	index := ".bashrc\n.gitconfig\n.local/app.conf\n.vimrc\n"
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, ".lnk"), []byte(index), 0644))
+
+	// .vimrc is already linked, and ~/.local is a file, so nothing can be
+	// created below it.
+	suite.Require().NoError(os.Symlink(filepath.Join(repoPath, ".vimrc"), filepath.Join(suite.tempDir, ".vimrc")))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, ".local"), []byte("not a directory"), 0644))
+
+	info, err := suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Restored)
+	suite.Equal([]string{".vimrc"}, info.InPlace)
+	suite.Equal([]string{".gitconfig"}, info.Missing)
+	suite.Require().Len(info.Failed, 1)
+	suite.Equal(".local/app.conf", info.Failed[0].Path)
+	suite.ErrorContains(info.Failed[0].Err, "failed to create directory")
+	suite.FileExists(filepath.Join(suite.tempDir, ".bashrc"))
+
+	// Doctor still treats a failed restore as an error.
+	suite.Require().NoError(os.Remove(filepath.Join(suite.tempDir, ".bashrc")))
+	_, err = suite.lnk.Doctor()
+	suite.ErrorContains(err, "failed to restore symlinks")
+}
+
 // TestPush tests push operation error paths
 func (suite *CoreTestSuite) TestPush() {
 	tests := []struct {
@@ -621,6 +952,56 @@ func (suite *CoreTestSuite) TestPush() {
 	}
 }
 
+// TestRenderMessage verifies the placeholders of sync commit messages and
+// that {count} sees repository changes and edited copy-managed originals.
+func (suite *CoreTestSuite) TestRenderMessage() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	netrc := filepath.Join(suite.tempDir, ".netrc")
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine a\n"), 0600))
+	_, err := NewLnk(WithCopy(true)).Add(netrc)
+	suite.Require().NoError(err)
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number\n"), 0644))
+	_, err = suite.lnk.Add(vimrc)
+	suite.Require().NoError(err)
+
+	message, err := suite.lnk.RenderMessage("{count} files")
+	suite.Require().NoError(err)
+	suite.Equal("0 files", message)
+
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set nonumber\n"), 0644))
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine b\n"), 0600))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repoPath, "notes"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "notes", "a.txt"), []byte("a"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "notes", "b.txt"), []byte("b"), 0644))
+
+	hostname, err := os.Hostname()
+	suite.Require().NoError(err)
+	message, err = suite.lnk.RenderMessage("sync from {host} {date}: {count} files, {unknown}")
+	suite.Require().NoError(err)
+	suite.Equal(fmt.Sprintf("sync from %s %s: 4 files, {unknown}", SanitizeHostname(hostname), time.Now().Format("2006-01-02")), message)
+
+	// {host} names the active host configuration when there is one.
+	message, err = NewLnk(WithHost("work")).RenderMessage("sync from {host}")
+	suite.Require().NoError(err)
+	suite.Equal("sync from work", message)
+
+	// A host name that cannot be looked up fails instead of rendering blank.
+	l := NewLnk()
+	l.syncer.SetHostName(func() (string, error) { return "", errors.New("no hostname") })
+	_, err = l.RenderMessage("sync from {host}")
+	suite.True(errors.Is(err, ErrEmptyMessage), "%v", err)
+
+	message, err = suite.lnk.RenderMessage("{count}", vimrc)
+	suite.Require().NoError(err)
+	suite.Equal("1", message)
+
+	_, err = suite.lnk.RenderMessage("  ")
+	suite.True(errors.Is(err, ErrEmptyMessage), "%v", err)
+}
+
 // TestPull tests pull operation error paths
 func (suite *CoreTestSuite) TestPull() {
 	tests := []struct {
@@ -662,3 +1043,450 @@ func (suite *CoreTestSuite) TestPull() {
 		})
 	}
 }
+
+// TestMultipleRemotes verifies that push and pull can target a named remote,
+// that only the default remote becomes the upstream, and that AllRemotes
+// pushes to every remote.
+func (suite *CoreTestSuite) TestMultipleRemotes() {
+	primary := filepath.Join(suite.tempDir, "primary.git")
+	backup := filepath.Join(suite.tempDir, "backup.git")
+	for _, dir := range []string{primary, backup} {
+		suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", dir).Run())
+	}
+	suite.Require().NoError(suite.lnk.InitWithRemote(primary))
+	suite.Require().NoError(suite.lnk.AddRemote("backup", backup))
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	head := func(dir string) string {
+		out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+		suite.Require().NoError(err, dir)
+		return strings.TrimSpace(string(out))
+	}
+
+	remotes, err := suite.lnk.Remotes()
+	suite.Require().NoError(err)
+	suite.Equal([]Remote{{Name: "backup", URL: backup}, {Name: "origin", URL: primary, Default: true}}, remotes)
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err = suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(NewLnk(WithRemote("backup")).Push("to backup"))
+	suite.Equal(head(repoPath), head(backup))
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "@{upstream}").CombinedOutput()
+	suite.Error(err, "pushing to backup must not set it as upstream: %s", out)
+
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH=/opt/bin:$PATH"), 0644))
+	suite.Require().NoError(suite.lnk.PushWithOptions("to all", PushOptions{AllRemotes: true}))
+	suite.Equal(head(repoPath), head(primary))
+	suite.Equal(head(repoPath), head(backup))
+	out, err = exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "@{upstream}").Output()
+	suite.Require().NoError(err)
+	suite.Equal("origin/main", strings.TrimSpace(string(out)))
+
+	_, err = NewLnk(WithRemote("backup")).Pull()
+	suite.NoError(err)
+
+	// An unknown remote fails before the pending edit is committed.
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export EDITOR=vim"), 0644))
+	err = NewLnk(WithRemote("mirror")).Push("nowhere")
+	suite.ErrorIs(err, ErrRemoteNotFound)
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.True(dirty)
+	_, err = NewLnk(WithRemote("mirror")).Pull()
+	suite.ErrorIs(err, ErrRemoteNotFound)
+}
+
+// TestSSHRemoteURLs verifies that ssh:// URLs with a port and scp-like
+// addresses reach git as given: listed and shown by status unchanged, and
+// pushed and pulled through ssh with the port intact. A stand-in ssh records its
+// arguments and runs the remote command locally.
+func (suite *CoreTestSuite) TestSSHRemoteURLs() {
+	remote := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remote).Run())
+	sshLog := filepath.Join(suite.tempDir, "ssh.log")
+	fakeSSH := filepath.Join(suite.tempDir, "fake-ssh")
+	script := "#!/bin/sh\necho \"$@\" >> " + sshLog + "\nfor arg; do last=$arg; done\nexec sh -c \"$last\"\n"
+	suite.Require().NoError(os.WriteFile(fakeSSH, []byte(script), 0755))
+	suite.T().Setenv("GIT_SSH_COMMAND", fakeSSH)
+	suite.T().Setenv("GIT_SSH_VARIANT", "ssh")
+
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	tests := []struct {
+		name, url, sshArgs string
+	}{
+		{"ssh URL with port", "ssh://git@example.com:2222" + remote, "-p 2222 git@example.com"},
+		{"scp-like address", "git@example.com:" + remote, "git@example.com"},
+	}
+	for i, tt := range tests {
+		suite.Run(tt.name, func() {
+			name := fmt.Sprintf("remote%d", i)
+			suite.Require().NoError(suite.lnk.AddRemote(name, tt.url))
+			suite.Require().NoError(suite.lnk.AddRemote(name, tt.url), "adding the same URL again is a no-op")
+
+			remotes, err := suite.lnk.Remotes()
+			suite.Require().NoError(err)
+			suite.Contains(remotes, Remote{Name: name, URL: tt.url, Default: i == 0})
+			suite.Equal(tt.url, RedactURL(tt.url))
+			if i == 0 {
+				status, err := suite.lnk.Status()
+				suite.Require().NoError(err)
+				suite.Equal(tt.url, status.RemoteURL)
+			}
+
+			_ = os.Remove(sshLog)
+			suite.Require().NoError(NewLnk(WithRemote(name)).Push("lnk: push over ssh"))
+			logged, err := os.ReadFile(sshLog)
+			suite.Require().NoError(err, "push must go through ssh")
+			suite.Contains(string(logged), tt.sshArgs+" git-receive-pack '"+remote+"'")
+
+			local, err := exec.Command("git", "-C", filepath.Join(suite.tempDir, "lnk"), "rev-parse", "HEAD").Output()
+			suite.Require().NoError(err)
+			pushed, err := exec.Command("git", "-C", remote, "rev-parse", "main").Output()
+			suite.Require().NoError(err)
+			suite.Equal(string(local), string(pushed))
+
+			_ = os.Remove(sshLog)
+			_, err = NewLnk(WithRemote(name)).Pull()
+			suite.Require().NoError(err)
+			logged, err = os.ReadFile(sshLog)
+			suite.Require().NoError(err, "pull must go through ssh")
+			suite.Contains(string(logged), tt.sshArgs+" git-upload-pack '"+remote+"'")
+		})
+	}
+}
+
+// TestPushWithCanceledContext verifies that a push whose WithContext
+// context is cancelled never reaches the remote.
+func (suite *CoreTestSuite) TestPushWithCanceledContext() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	suite.Error(NewLnk(WithContext(ctx)).Push("cancelled"))
+
+	out, err := exec.Command("git", "-C", remoteDir, "rev-parse", "--verify", "--quiet", "main").CombinedOutput()
+	suite.Error(err, "remote must not have received a commit: %s", out)
+
+	suite.Require().NoError(suite.lnk.Push("after"))
+	suite.NoError(exec.Command("git", "-C", remoteDir, "rev-parse", "--verify", "main").Run())
+}
+
+// TestPushRefreshesCopies verifies that a push with a host refreshes the
+// copy-managed files of both the common configuration and that host.
+func (suite *CoreTestSuite) TestPushRefreshesCopies() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+
+	netrc := filepath.Join(suite.tempDir, ".netrc")
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine a\n"), 0600))
+	_, err := NewLnk(WithCopy(true), WithHost("work")).Add(netrc)
+	suite.Require().NoError(err)
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]\n"), 0644))
+	_, err = NewLnk(WithCopy(true)).Add(gitconfig)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine b\n"), 0600))
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[core]\n"), 0644))
+	suite.Require().NoError(NewLnk(WithHost("work")).Push("sync"))
+
+	for path, want := range map[string]string{"work.lnk/.netrc": "machine b\n", ".gitconfig": "[core]\n"} {
+		out, err := exec.Command("git", "-C", remoteDir, "show", "main:"+path).Output()
+		suite.Require().NoError(err, path)
+		suite.Equal(want, string(out), path)
+	}
+}
+
+// TestEventHandler verifies that WithEventHandler hears about adds,
+// commits, pushes, restores and removes, in the order they happen.
+func (suite *CoreTestSuite) TestEventHandler() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+
+	var events []Event
+	l := NewLnk(WithHost("work"), WithEventHandler(func(e Event) { events = append(events, e) }))
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := l.Add(bashrc)
+	suite.Require().NoError(err)
+	suite.Require().NoError(l.Push("sync"))
+	suite.Require().NoError(os.Remove(bashrc))
+	_, err = l.RestoreSymlinks()
+	suite.Require().NoError(err)
+	_, err = l.Remove(bashrc)
+	suite.Require().NoError(err)
+
+	kinds := make([]EventKind, len(events))
+	for i, e := range events {
+		kinds[i] = e.Kind
+	}
+	suite.Equal([]EventKind{GitCommitted, FileAdded, GitPushed, SymlinkRestored, GitCommitted, FileRemoved}, kinds)
+	suite.Equal("lnk: added .bashrc", events[0].Message)
+	head, err := exec.Command("git", "-C", filepath.Join(suite.tempDir, "lnk"), "rev-parse", "HEAD~1").Output()
+	suite.Require().NoError(err)
+	suite.Equal(strings.TrimSpace(string(head)), events[0].Hash)
+	suite.Equal(Event{Kind: FileAdded, Path: ".bashrc", Host: "work"}, events[1])
+	suite.Equal(Event{Kind: GitPushed, Remote: "origin"}, events[2])
+	suite.Equal(Event{Kind: SymlinkRestored, Path: ".bashrc", Host: "work"}, events[3])
+	suite.Equal(Event{Kind: FileRemoved, Path: ".bashrc", Host: "work"}, events[5])
+}
+
+// TestMissing verifies that Missing reports the managed items a restore would
+// put in place, and what is in their way.
+func (suite *CoreTestSuite) TestMissing() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	zshrc := filepath.Join(suite.tempDir, ".zshrc")
+	suite.Require().NoError(os.WriteFile(zshrc, []byte("setopt"), 0644))
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	suite.Require().NoError(suite.lnk.AddMultiple([]string{bashrc, vimrc, zshrc}))
+	_, err := NewLnk(WithHost("work")).Add(gitconfig)
+	suite.Require().NoError(err)
+
+	missing, err := suite.lnk.Missing("work")
+	suite.Require().NoError(err)
+	suite.Empty(missing)
+
+	suite.Require().NoError(os.Remove(bashrc))
+	suite.Require().NoError(os.Remove(vimrc))
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("local"), 0644))
+	suite.Require().NoError(os.Remove(gitconfig))
+
+	missing, err = suite.lnk.Missing("")
+	suite.Require().NoError(err)
+	suite.Equal([]MissingItem{{Path: ".bashrc", Location: bashrc}, {Path: ".vimrc", Location: vimrc, Blocker: "regular file"}}, missing)
+
+	missing, err = NewLnk(WithRestoreOnly([]string{gitconfig})).Missing("work")
+	suite.Require().NoError(err)
+	suite.Equal([]MissingItem{{Path: ".gitconfig", Location: gitconfig, Host: "work"}}, missing)
+}
+
+// TestChangedItems verifies that uncommitted changes are mapped back to the
+// managed items they belong to, with files created inside a managed
+// directory reported under the directory entry.
+func (suite *CoreTestSuite) TestChangedItems() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	sshDir := filepath.Join(suite.tempDir, ".ssh")
+	suite.Require().NoError(os.MkdirAll(sshDir, 0700))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sshDir, "config"), []byte("Host *"), 0600))
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	suite.Require().NoError(suite.lnk.AddMultiple([]string{sshDir, vimrc}))
+	_, err := NewLnk(WithHost("work")).Add(gitconfig)
+	suite.Require().NoError(err)
+
+	items, err := suite.lnk.ChangedItems()
+	suite.Require().NoError(err)
+	suite.Empty(items)
+
+	// Edits through the symlinks, including a new file in the directory.
+	suite.Require().NoError(os.WriteFile(filepath.Join(sshDir, "known_hosts"), []byte("github.com ssh-ed25519"), 0600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sshDir, "config"), []byte("Host github.com"), 0600))
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]\n\tname = me"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, "lnk", "README.md"), []byte("notes"), 0644))
+
+	items, err = suite.lnk.ChangedItems()
+	suite.Require().NoError(err)
+	suite.Equal([]ChangedItem{
+		{Path: ".ssh", Directory: true, Files: []string{"config", "known_hosts"}},
+		{Path: ".gitconfig", Host: "work"},
+	}, items)
+}
+
+// TestWatch verifies that Watch commits and pushes an edit to a managed file
+// once edits stop, and returns when its context is cancelled.
+func (suite *CoreTestSuite) TestWatch() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	cmd := exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir)
+	suite.Require().NoError(cmd.Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- suite.lnk.Watch(ctx, WatchOptions{
+			Message:  "lnk: watched",
+			Debounce: 50 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+			Push:     true,
+			OnEvent:  func(event WatchEvent) { events <- event },
+		})
+	}()
+
+	// Write through the symlink, as an editor would.
+	time.Sleep(30 * time.Millisecond)
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH=/opt/bin:$PATH"), 0644))
+
+	select {
+	case event := <-events:
+		suite.Require().NoError(event.Err)
+		suite.True(event.Committed)
+		suite.True(event.Pushed)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not commit the edit")
+	}
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: watched", commits[0])
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+
+	cancel()
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not stop")
+	}
+	suite.Empty(events, "an unchanged tree is not committed again")
+}
+
+// TestWatchRetriesPush verifies that a commit whose push failed is pushed on
+// a later pass without any further edit.
+func (suite *CoreTestSuite) TestWatchRetriesPush() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	// The remote is gone for the first push.
+	offline := remoteDir + ".offline"
+	suite.Require().NoError(os.Rename(remoteDir, offline))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewLnk(WithRetries(0, 0)).Watch(ctx, WatchOptions{
+			Message:  "lnk: watched",
+			Debounce: 50 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+			Push:     true,
+			OnEvent:  func(event WatchEvent) { events <- event },
+		})
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH=/opt/bin:$PATH"), 0644))
+	select {
+	case event := <-events:
+		suite.Error(event.Err)
+		suite.True(event.Committed)
+		suite.False(event.Pushed)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not commit the edit")
+	}
+
+	suite.Require().NoError(os.Rename(offline, remoteDir))
+	select {
+	case event := <-events:
+		suite.Require().NoError(event.Err)
+		suite.False(event.Committed)
+		suite.True(event.Pushed)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not retry the push")
+	}
+	out, err := exec.Command("git", "-C", remoteDir, "log", "-1", "--format=%s", "main").Output()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: watched", strings.TrimSpace(string(out)))
+
+	cancel()
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not stop")
+	}
+}
+
+// TestWatchHostCopies verifies that a watch with a host picks up edits to
+// the common configuration's copy-managed originals as well as the host's.
+func (suite *CoreTestSuite) TestWatchHostCopies() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	profile := filepath.Join(suite.tempDir, ".profile")
+	suite.Require().NoError(os.WriteFile(profile, []byte("umask 022"), 0644))
+	_, err := NewLnk(WithCopy(true)).Add(profile)
+	suite.Require().NoError(err)
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	_, err = NewLnk(WithHost("work"), WithCopy(true)).Add(gitconfig)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewLnk(WithHost("work")).Watch(ctx, WatchOptions{
+			Message:  "lnk: watched",
+			Debounce: 50 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+			OnEvent:  func(event WatchEvent) { events <- event },
+		})
+	}()
+
+	for _, edit := range []struct{ path, content, stored string }{
+		{profile, "umask 077", filepath.Join(repoPath, ".profile")},
+		{gitconfig, "[user]\n\tname = Work", filepath.Join(repoPath, "work.lnk", ".gitconfig")},
+	} {
+		time.Sleep(30 * time.Millisecond)
+		suite.Require().NoError(os.WriteFile(edit.path, []byte(edit.content), 0644))
+		select {
+		case event := <-events:
+			suite.Require().NoError(event.Err)
+			suite.True(event.Committed)
+		case <-time.After(5 * time.Second):
+			suite.FailNow("watch did not commit the edit", edit.path)
+		}
+		stored, err := os.ReadFile(edit.stored)
+		suite.Require().NoError(err)
+		suite.Equal(edit.content, string(stored))
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("watch did not stop")
+	}
+}
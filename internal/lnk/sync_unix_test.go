@@ -0,0 +1,129 @@
+//go:build unix
+
+package lnk
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/git"
+)
+
+// TestPullWithoutTerminal verifies that, with no terminal to prompt on, ssh
+// runs in batch mode and a credential failure is reported as ErrAuthRequired
+// instead of hanging.
+func (suite *CoreTestSuite) TestPullWithoutTerminal() {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		if null, err := os.Stat(os.DevNull); err != nil || !os.SameFile(info, null) {
+			suite.T().Skip("stdin is a terminal")
+		}
+	}
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(exec.Command("git", "-C", repoPath, "remote", "add", "origin", "ssh://git@example.invalid/dotfiles.git").Run())
+
+	argsFile := filepath.Join(suite.tempDir, "ssh-args")
+	fakeSSH := filepath.Join(suite.tempDir, "fake-ssh")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\necho 'Host key verification failed.' >&2\nexit 255\n"
+	suite.Require().NoError(os.WriteFile(fakeSSH, []byte(script), 0755))
+	suite.T().Setenv("GIT_SSH_COMMAND", fakeSSH)
+
+	_, err := suite.lnk.Pull()
+	suite.ErrorIs(err, ErrAuthRequired)
+
+	args, err := os.ReadFile(argsFile)
+	suite.Require().NoError(err)
+	suite.Contains(string(args), "-o BatchMode=yes")
+}
+
+// TestPullUsesConfiguredSSHCommand verifies that git.ssh_command reaches git
+// as GIT_SSH_COMMAND, and that GIT_SSH_COMMAND in the environment wins.
+func (suite *CoreTestSuite) TestPullUsesConfiguredSSHCommand() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(exec.Command("git", "-C", repoPath, "remote", "add", "origin", "ssh://git@example.invalid/dotfiles.git").Run())
+
+	fakeSSH := func(name string) (script, argsFile string) {
+		argsFile = filepath.Join(suite.tempDir, name+"-args")
+		script = filepath.Join(suite.tempDir, name)
+		content := "#!/bin/sh\necho \"$@\" > " + argsFile + "\nexit 255\n"
+		suite.Require().NoError(os.WriteFile(script, []byte(content), 0755))
+		return script, argsFile
+	}
+	configured, configuredArgs := fakeSSH("configured-ssh")
+	fromEnv, envArgs := fakeSSH("env-ssh")
+
+	suite.T().Setenv("GIT_SSH_COMMAND", "")
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "config.toml"), []byte("[git]\nssh_command = \""+configured+" -i dotfiles_key\"\n"), 0644))
+
+	_, err := NewLnk().Pull()
+	suite.Error(err)
+	args, err := os.ReadFile(configuredArgs)
+	suite.Require().NoError(err)
+	suite.Contains(string(args), "-i dotfiles_key")
+
+	suite.T().Setenv("GIT_SSH_COMMAND", fromEnv)
+	_, err = NewLnk().Pull()
+	suite.Error(err)
+	suite.FileExists(envArgs)
+}
+
+// TestPushRetriesNetworkFailures verifies that a push whose connection fails
+// is retried up to the configured count, reporting each retry, and that an
+// authentication failure is not retried at all.
+func (suite *CoreTestSuite) TestPushRetriesNetworkFailures() {
+	suite.T().Setenv("GIT_SSH_COMMAND", "")
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(exec.Command("git", "-C", repoPath, "remote", "set-url", "origin", "ssh://fakehost"+remoteDir).Run())
+
+	// The fake ssh refuses the first connections, then runs the git command
+	// it was asked to run against the local bare repository. git's "ssh -G"
+	// probe for the ssh variant is answered without counting.
+	counter := filepath.Join(suite.tempDir, "ssh-count")
+	fakeSSH := filepath.Join(suite.tempDir, "flaky-ssh")
+	script := "#!/bin/sh\n" +
+		"case \" $* \" in *\" -G \"*) exit 0;; esac\n" +
+		"n=$(cat " + counter + " 2>/dev/null || echo 0)\n" +
+		"echo $((n + 1)) > " + counter + "\n" +
+		"if [ \"$n\" -lt 2 ]; then echo 'ssh: connect to host fakehost port 22: Connection refused' >&2; exit 255; fi\n" +
+		"for last; do :; done\n" +
+		"exec sh -c \"$last\"\n"
+	suite.Require().NoError(os.WriteFile(fakeSSH, []byte(script), 0755))
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+
+	var retries []int
+	record := WithRetryHandler(func(op string, attempt, max int, wait time.Duration) {
+		suite.Equal("push", op)
+		retries = append(retries, attempt)
+	})
+
+	// One retry is not enough for two refused connections.
+	err = NewLnk(WithSSHCommand(fakeSSH), WithRetries(1, time.Millisecond), record).Push("lnk: sync")
+	suite.ErrorIs(err, git.ErrPush)
+	suite.Equal([]int{1}, retries)
+
+	retries = nil
+	suite.Require().NoError(os.Remove(counter))
+	suite.Require().NoError(NewLnk(WithSSHCommand(fakeSSH), WithRetries(2, time.Millisecond), record).Push("lnk: sync"))
+	suite.Equal([]int{1, 2}, retries)
+	out, err := exec.Command("git", "--git-dir", remoteDir, "log", "--format=%s", "main").Output()
+	suite.Require().NoError(err)
+	suite.Contains(strings.TrimSpace(string(out)), "lnk: added .bashrc")
+
+	retries = nil
+	deniedSSH := filepath.Join(suite.tempDir, "denied-ssh")
+	script = "#!/bin/sh\necho 'git@fakehost: Permission denied (publickey).' >&2\nexit 255\n"
+	suite.Require().NoError(os.WriteFile(deniedSSH, []byte(script), 0755))
+	suite.Error(NewLnk(WithSSHCommand(deniedSSH), WithRetries(3, time.Millisecond), record).Push("lnk: sync"))
+	suite.Empty(retries, "an authentication failure must not be retried")
+}
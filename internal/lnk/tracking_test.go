@@ -2,7 +2,11 @@ package lnk
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"time"
+
+	"github.com/yarlson/lnk/internal/tracker"
 )
 
 // TestAddManagedItem tests managed items tracking
@@ -186,3 +190,316 @@ func (suite *CoreTestSuite) TestWriteManagedItems() {
 		})
 	}
 }
+
+// TestManagedItemAddedAt tests that new items record when they were added and
+// legacy path-only entries are still read
+func (suite *CoreTestSuite) TestManagedItemAddedAt() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	lnkFile := filepath.Join(suite.tempDir, "lnk", ".lnk")
+	suite.Require().NoError(os.WriteFile(lnkFile, []byte(".vimrc\n"), 0644))
+
+	before := time.Now().UTC().Truncate(time.Second)
+	suite.Require().NoError(suite.lnk.tracker.AddManagedItem(".bashrc"))
+
+	entries, err := suite.lnk.tracker.GetEntries()
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 2)
+
+	suite.Equal(".bashrc", entries[0].Path)
+	suite.False(entries[0].AddedAt.Before(before))
+	suite.Equal(".vimrc", entries[1].Path)
+	suite.True(entries[1].AddedAt.IsZero(), "legacy entry has no timestamp")
+
+	// Rewriting the item list keeps existing timestamps
+	suite.Require().NoError(suite.lnk.tracker.WriteManagedItems([]string{".bashrc"}))
+	rewritten, err := suite.lnk.tracker.GetEntries()
+	suite.Require().NoError(err)
+	suite.Equal(entries[:1], rewritten)
+}
+
+// TestTrackingFileFormat tests that the v2 format is written and that both
+// v2 and legacy files are read
+func (suite *CoreTestSuite) TestTrackingFileFormat() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	lnkFile := filepath.Join(suite.tempDir, "lnk", ".lnk")
+
+	suite.Run("writes v2 header and JSON lines", func() {
+		addedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		err := suite.lnk.tracker.WriteEntries([]tracker.Entry{
+			{Path: ".bashrc", AddedAt: addedAt},
+			{Path: "path with\ttab"},
+		})
+		suite.Require().NoError(err)
+
+		content, err := os.ReadFile(lnkFile)
+		suite.Require().NoError(err)
+		suite.Equal("# lnk v2\n"+
+			`{"path":".bashrc","added_at":"2026-01-02T03:04:05Z"}`+"\n"+
+			`{"path":"path with\ttab"}`+"\n", string(content))
+
+		entries, err := suite.lnk.tracker.GetEntries()
+		suite.Require().NoError(err)
+		suite.Equal([]tracker.Entry{{Path: ".bashrc", AddedAt: addedAt}, {Path: "path with\ttab"}}, entries)
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "legacy plain paths",
+			content: ".bashrc\n.vimrc\n",
+			want:    []string{".bashrc", ".vimrc"},
+		},
+		{
+			name:    "v2 with comments and blank lines",
+			content: "# lnk v2\n\n# managed by hand\n{\"path\":\".bashrc\"}\n",
+			want:    []string{".bashrc"},
+		},
+		{
+			name:    "newer version is rejected",
+			content: "# lnk v3\n{\"path\":\".bashrc\"}\n",
+			wantErr: "unsupported tracking file version",
+		},
+		{
+			name:    "malformed v2 entry",
+			content: "# lnk v2\n.bashrc\n",
+			wantErr: "invalid entry on line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Require().NoError(os.WriteFile(lnkFile, []byte(tt.content), 0644))
+
+			items, err := suite.lnk.tracker.GetManagedItems()
+			if tt.wantErr != "" {
+				suite.Require().Error(err)
+				suite.Contains(err.Error(), tt.wantErr)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tt.want, items)
+		})
+	}
+}
+
+// TestTrackingFileNormalization tests reading and rewriting a hand-corrupted tracking file
+func (suite *CoreTestSuite) TestTrackingFileNormalization() {
+	err := suite.lnk.Init()
+	suite.Require().NoError(err)
+
+	lnkFile := filepath.Join(suite.tempDir, "lnk", ".lnk")
+	corrupted := ".vimrc\n  .bashrc  \n./.vimrc\n.Vimrc\n.config//nvim/init.lua\n.bashrc\n\n"
+	suite.Require().NoError(os.WriteFile(lnkFile, []byte(corrupted), 0644))
+
+	want := []string{".Vimrc", ".bashrc", ".config/nvim/init.lua", ".vimrc"}
+	items, err := suite.lnk.tracker.GetManagedItems()
+	suite.Require().NoError(err)
+	suite.Equal(want, items, "reads should be deduplicated, cleaned and sorted, keeping case variants")
+
+	result, err := suite.lnk.Normalize()
+	suite.Require().NoError(err)
+	suite.True(result.Rewritten)
+	suite.Equal(2, result.Removed)
+
+	content, err := os.ReadFile(lnkFile)
+	suite.Require().NoError(err)
+	entries, err := tracker.ParseEntries(content)
+	suite.Require().NoError(err)
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	suite.Equal(want, paths, "the file on disk should be rewritten in normalized form")
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Contains(commits[0], "lnk: normalized tracking file")
+
+	result, err = suite.lnk.Normalize()
+	suite.Require().NoError(err)
+	suite.False(result.Rewritten, "a normalized file should be left alone")
+	suite.Zero(result.Removed)
+}
+
+// TestTrackingFileCaseFolding verifies that a hand-corrupted tracking file
+// listing one stored file in two cases is read and normalized as one entry
+// where both spellings name that file, as on a case-insensitive volume.
+func (suite *CoreTestSuite) TestTrackingFileCaseFolding() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	stored := filepath.Join(repoPath, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(stored, []byte("[user]"), 0644))
+	_, err := os.Stat(filepath.Join(repoPath, ".GITCONFIG"))
+	caseInsensitive := err == nil
+	if !caseInsensitive {
+		// A hard link stands in for the second spelling on a case-sensitive volume.
+		suite.Require().NoError(os.Link(stored, filepath.Join(repoPath, ".GITCONFIG")))
+	}
+	lnkFile := filepath.Join(repoPath, ".lnk")
+	suite.Require().NoError(os.WriteFile(lnkFile, []byte(".gitconfig\n.GITCONFIG\n"), 0644))
+
+	items, err := suite.lnk.tracker.GetManagedItems()
+	suite.Require().NoError(err)
+	suite.Len(items, 1, "case-insensitive filesystem: %v", caseInsensitive)
+	if caseInsensitive {
+		suite.Equal([]string{".gitconfig"}, items, "the spelling the stored file has wins")
+	}
+
+	result, err := suite.lnk.Normalize()
+	suite.Require().NoError(err)
+	suite.True(result.Rewritten)
+	suite.Equal(1, result.Removed)
+	content, err := os.ReadFile(lnkFile)
+	suite.Require().NoError(err)
+	entries, err := tracker.ParseEntries(content)
+	suite.Require().NoError(err)
+	suite.Len(entries, 1)
+}
+
+// TestUnmanaged verifies that list --unmanaged reports candidate dotfiles no
+// common or host configuration tracks, with the target of foreign symlinks.
+func (suite *CoreTestSuite) TestUnmanaged() {
+	suite.Require().NoError(suite.lnk.Init())
+
+	for _, name := range []string{".bashrc", ".zshrc", ".vimrc"} {
+		suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, name), []byte(name), 0644))
+	}
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.tempDir, ".config", "nvim"), 0755))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.tempDir, "stow", "git"), 0755))
+	stowed := filepath.Join(suite.tempDir, "stow", "git", ".gitconfig")
+	suite.Require().NoError(os.WriteFile(stowed, []byte("[user]\n"), 0644))
+	suite.Require().NoError(os.Symlink(stowed, filepath.Join(suite.tempDir, ".gitconfig")))
+
+	_, err := suite.lnk.Add(filepath.Join(suite.tempDir, ".bashrc"))
+	suite.Require().NoError(err)
+	_, err = NewLnk(WithHost("work")).Add(filepath.Join(suite.tempDir, ".vimrc"))
+	suite.Require().NoError(err)
+
+	items, err := suite.lnk.Unmanaged(nil)
+	suite.Require().NoError(err)
+	suite.Equal([]UnmanagedFile{
+		{RelativePath: filepath.Join(".config", "nvim")},
+		{RelativePath: ".gitconfig", LinkTarget: stowed},
+		{RelativePath: ".zshrc"},
+	}, items)
+
+	// Configured patterns replace the defaults.
+	items, err = suite.lnk.Unmanaged([]string{".z*", ".bashrc"})
+	suite.Require().NoError(err)
+	suite.Equal([]UnmanagedFile{{RelativePath: ".zshrc"}}, items)
+}
+
+// TestRenameHost verifies that RenameHost moves a host's index and storage,
+// relinks its symlinks, follows it in config.toml and .git/info/exclude, and
+// commits once; and that it refuses unknown, taken and invalid names.
+func (suite *CoreTestSuite) TestRenameHost() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	sshDir := filepath.Join(suite.tempDir, ".ssh")
+	suite.Require().NoError(os.MkdirAll(sshDir, 0700))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sshDir, "config"), []byte("Host *"), 0600))
+	netrc := filepath.Join(suite.tempDir, ".netrc")
+	suite.Require().NoError(os.WriteFile(netrc, []byte("machine example.com"), 0600))
+	old := NewLnk(WithHost("old-laptop"))
+	suite.Require().NoError(old.AddMultiple([]string{gitconfig, sshDir, netrc}))
+	_, err = old.RemoveKeep(netrc)
+	suite.Require().NoError(err)
+	attributes := "/old-laptop.lnk/.ssh/** filter=git-crypt diff=git-crypt\n/.bashrc filter=git-crypt diff=git-crypt\n"
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, ".gitattributes"), []byte(attributes), 0644))
+	suite.Require().NoError(exec.Command("git", "-C", repoPath, "add", ".gitattributes").Run())
+	suite.Require().NoError(exec.Command("git", "-C", repoPath, "commit", "-m", "lnk: mark secrets").Run())
+	_, err = suite.lnk.SetConfig("host", "old-laptop", false)
+	suite.Require().NoError(err)
+
+	result, err := suite.lnk.RenameHost("old-laptop", "new-laptop")
+	suite.Require().NoError(err)
+	suite.Equal([]string{".gitconfig", ".ssh"}, result.Items)
+	suite.Equal([]string{".gitconfig", ".ssh"}, result.Relinked)
+	suite.True(result.Config)
+
+	suite.NoFileExists(filepath.Join(repoPath, ".lnk.old-laptop"))
+	suite.NoDirExists(filepath.Join(repoPath, "old-laptop.lnk"))
+	suite.FileExists(filepath.Join(repoPath, "new-laptop.lnk", ".gitconfig"))
+	items, err := NewLnk(WithHost("new-laptop")).List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".gitconfig", ".ssh"}, items)
+	suite.True(suite.lnk.syncer.IsValidSymlink(gitconfig, filepath.Join(repoPath, "new-laptop.lnk", ".gitconfig")))
+	suite.True(suite.lnk.syncer.IsValidSymlink(sshDir, filepath.Join(repoPath, "new-laptop.lnk", ".ssh")))
+	content, err := os.ReadFile(filepath.Join(sshDir, "config"))
+	suite.Require().NoError(err)
+	suite.Equal("Host *", string(content))
+	cfg, err := os.ReadFile(filepath.Join(repoPath, "config.toml"))
+	suite.Require().NoError(err)
+	suite.Contains(string(cfg), `host = "new-laptop"`)
+	attrs, err := os.ReadFile(filepath.Join(repoPath, ".gitattributes"))
+	suite.Require().NoError(err)
+	suite.Equal("/new-laptop.lnk/.ssh/** filter=git-crypt diff=git-crypt\n/.bashrc filter=git-crypt diff=git-crypt\n", string(attrs))
+
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: renamed host old-laptop to new-laptop", commits[0])
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty, "the rm --keep leftover must stay excluded")
+
+	suite.ErrorIs(suite.renameHostErr("old-laptop", "other"), ErrHostNotFound)
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.tempDir, ".zshrc"), []byte("setopt"), 0644))
+	_, err = NewLnk(WithHost("desktop")).Add(filepath.Join(suite.tempDir, ".zshrc"))
+	suite.Require().NoError(err)
+	suite.ErrorIs(suite.renameHostErr("new-laptop", "desktop"), ErrHostExists)
+	suite.ErrorIs(suite.renameHostErr("new-laptop", "../escape"), ErrInvalidHost)
+	suite.ErrorIs(suite.renameHostErr("new-laptop", ""), ErrInvalidHost)
+}
+
+// TestRenameHostRollback verifies that a rename whose commit fails puts the
+// index, storage, symlinks, config.toml and Git index of the old host back.
+func (suite *CoreTestSuite) TestRenameHostRollback() {
+	suite.Require().NoError(suite.lnk.Init())
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+
+	gitconfig := filepath.Join(suite.tempDir, ".gitconfig")
+	suite.Require().NoError(os.WriteFile(gitconfig, []byte("[user]"), 0644))
+	_, err := NewLnk(WithHost("old-laptop")).Add(gitconfig)
+	suite.Require().NoError(err)
+	_, err = suite.lnk.SetConfig("host", "old-laptop", false)
+	suite.Require().NoError(err)
+	before, err := os.ReadFile(filepath.Join(repoPath, "config.toml"))
+	suite.Require().NoError(err)
+	hook := filepath.Join(repoPath, ".git", "hooks", "pre-commit")
+	suite.Require().NoError(os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+	suite.Error(suite.renameHostErr("old-laptop", "new-laptop"))
+
+	suite.FileExists(filepath.Join(repoPath, ".lnk.old-laptop"))
+	suite.NoFileExists(filepath.Join(repoPath, ".lnk.new-laptop"))
+	suite.NoDirExists(filepath.Join(repoPath, "new-laptop.lnk"))
+	suite.True(suite.lnk.syncer.IsValidSymlink(gitconfig, filepath.Join(repoPath, "old-laptop.lnk", ".gitconfig")))
+	after, err := os.ReadFile(filepath.Join(repoPath, "config.toml"))
+	suite.Require().NoError(err)
+	suite.Equal(string(before), string(after))
+	status, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output()
+	suite.Require().NoError(err)
+	suite.Empty(string(status))
+}
+
+// renameHostErr returns the error of renaming oldHost to newHost.
+func (suite *CoreTestSuite) renameHostErr(oldHost, newHost string) error {
+	_, err := suite.lnk.RenameHost(oldHost, newHost)
+	return err
+}
@@ -0,0 +1,147 @@
+package lnk
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// TestUndoAdd verifies that undoing an add moves the file back out of the
+// repository and drops the commit.
+func (suite *CoreTestSuite) TestUndoAdd() {
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	_, err := suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.lnk.AddMultiple([]string{vimrc}))
+
+	result, err := suite.lnk.Undo(false)
+	suite.Require().NoError(err)
+	suite.Equal("lnk: added 1 files", result.Subject)
+	suite.Equal([]string{".vimrc"}, result.Restored)
+	suite.False(result.Reverted)
+
+	info, err := os.Lstat(vimrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	content, err := os.ReadFile(vimrc)
+	suite.Require().NoError(err)
+	suite.Equal("set number", string(content))
+
+	items, err := suite.lnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, items)
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal("lnk: added .bashrc", commits[0])
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+}
+
+// TestUndoRemove verifies that undoing rm links the file again, keeping
+// edits made to it since as uncommitted changes.
+func (suite *CoreTestSuite) TestUndoRemove() {
+	suite.Require().NoError(suite.lnk.Init())
+	hostLnk := NewLnk(WithHost("work"))
+	sshConfig := filepath.Join(suite.tempDir, ".ssh", "config")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(sshConfig), 0755))
+	suite.Require().NoError(os.WriteFile(sshConfig, []byte("Host *"), 0600))
+	_, err := hostLnk.Add(sshConfig)
+	suite.Require().NoError(err)
+	_, err = hostLnk.Remove(sshConfig)
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.WriteFile(sshConfig, []byte("Host work"), 0600))
+
+	result, err := suite.lnk.Undo(false)
+	suite.Require().NoError(err)
+	suite.Equal("lnk: removed config", result.Subject)
+	suite.Equal([]string{".ssh/config"}, result.Relinked)
+
+	stored := filepath.Join(suite.tempDir, "lnk", "work.lnk", ".ssh", "config")
+	target, err := os.Readlink(sshConfig)
+	suite.Require().NoError(err)
+	suite.Equal(stored, filepath.Join(filepath.Dir(sshConfig), target))
+	content, err := os.ReadFile(stored)
+	suite.Require().NoError(err)
+	suite.Equal("Host work", string(content))
+
+	items, err := hostLnk.List()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".ssh/config"}, items)
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.True(dirty, "the edit made after rm is left to commit")
+}
+
+// TestUndoRefusals verifies the cases undo refuses, and that --force turns a
+// pushed commit into a revert commit.
+func (suite *CoreTestSuite) TestUndoRefusals() {
+	remoteDir := filepath.Join(suite.tempDir, "remote.git")
+	suite.Require().NoError(exec.Command("git", "init", "--bare", "--initial-branch=main", remoteDir).Run())
+	suite.Require().NoError(suite.lnk.InitWithRemote(remoteDir))
+	_, err := suite.lnk.Undo(false)
+	suite.True(errors.Is(err, ErrCannotUndo), "no commits: %v", err)
+
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export PATH"), 0644))
+	_, err = suite.lnk.Add(bashrc)
+	suite.Require().NoError(err)
+	_, err = suite.lnk.Undo(false)
+	suite.True(errors.Is(err, ErrCannotUndo), "first commit: %v", err)
+
+	vimrc := filepath.Join(suite.tempDir, ".vimrc")
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("set number"), 0644))
+	_, err = suite.lnk.Add(vimrc)
+	suite.Require().NoError(err)
+
+	// A file in place of the symlink is never overwritten.
+	suite.Require().NoError(os.Remove(vimrc))
+	suite.Require().NoError(os.WriteFile(vimrc, []byte("local"), 0644))
+	_, err = suite.lnk.Undo(false)
+	suite.True(errors.Is(err, ErrUndoConflict), "conflict: %v", err)
+	suite.Require().NoError(os.Remove(vimrc))
+	_, err = suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+
+	repoPath := filepath.Join(suite.tempDir, "lnk")
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("x"), 0644))
+	_, err = suite.lnk.Undo(false)
+	suite.True(errors.Is(err, ErrUncommitted), "dirty: %v", err)
+	suite.Require().NoError(os.Remove(filepath.Join(repoPath, "notes.txt")))
+
+	suite.Require().NoError(suite.lnk.Push("lnk: sync configuration files"))
+
+	_, err = suite.lnk.Undo(false)
+	suite.True(errors.Is(err, ErrAlreadyPushed), "pushed: %v", err)
+
+	result, err := suite.lnk.Undo(true)
+	suite.Require().NoError(err)
+	suite.True(result.Reverted)
+	suite.Equal([]string{".vimrc"}, result.Restored)
+	commits, err := suite.lnk.GetCommits()
+	suite.Require().NoError(err)
+	suite.Equal(`lnk: reverted "added .vimrc"`, commits[0])
+	suite.Equal("lnk: added .vimrc", commits[1])
+	info, err := os.Lstat(vimrc)
+	suite.Require().NoError(err)
+	suite.True(info.Mode().IsRegular())
+	dirty, err := suite.lnk.HasChanges()
+	suite.Require().NoError(err)
+	suite.False(dirty)
+
+	// Commits made outside lnk are left to git.
+	suite.Require().NoError(os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("x"), 0644))
+	cmd := exec.Command("git", "add", "notes.txt")
+	cmd.Dir = repoPath
+	suite.Require().NoError(cmd.Run())
+	cmd = exec.Command("git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "notes")
+	cmd.Dir = repoPath
+	suite.Require().NoError(cmd.Run())
+	_, err = suite.lnk.Undo(true)
+	suite.True(errors.Is(err, ErrCannotUndo), "foreign commit: %v", err)
+}
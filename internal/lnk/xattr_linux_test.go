@@ -0,0 +1,50 @@
+//go:build linux
+
+package lnk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/yarlson/lnk/internal/fs"
+)
+
+// TestXattrsRestored verifies that a file added with WithXattrs records its
+// user.* attributes in the index, that a restore sets them again on a stored
+// file that lost them, and that removing the file puts them back in $HOME.
+func (suite *CoreTestSuite) TestXattrsRestored() {
+	suite.Require().NoError(suite.lnk.Init())
+	bashrc := filepath.Join(suite.tempDir, ".bashrc")
+	suite.Require().NoError(os.WriteFile(bashrc, []byte("export EDITOR=vim"), 0644))
+	if err := syscall.Setxattr(bashrc, "user.lnk.test", []byte("kept"), 0); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) {
+			suite.T().Skip("filesystem does not support user extended attributes")
+		}
+		suite.Require().NoError(err)
+	}
+
+	managed, err := NewLnk(WithXattrs(true)).Add(bashrc)
+	suite.Require().NoError(err)
+	index, err := os.ReadFile(filepath.Join(suite.tempDir, "lnk", ".lnk"))
+	suite.Require().NoError(err)
+	suite.Contains(string(index), `"xattrs":{"user.lnk.test":`)
+
+	// As on a fresh clone: the stored file lacks the attribute and $HOME
+	// lacks the link.
+	suite.Require().NoError(syscall.Removexattr(managed.RepoPath, "user.lnk.test"))
+	suite.Require().NoError(os.Remove(bashrc))
+	info, err := suite.lnk.RestoreSymlinks()
+	suite.Require().NoError(err)
+	suite.Equal([]string{".bashrc"}, info.Restored)
+	attrs, err := fs.ReadXattrs(managed.RepoPath)
+	suite.Require().NoError(err)
+	suite.Equal(map[string][]byte{"user.lnk.test": []byte("kept")}, attrs)
+
+	_, err = suite.lnk.Remove(bashrc)
+	suite.Require().NoError(err)
+	attrs, err = fs.ReadXattrs(bashrc)
+	suite.Require().NoError(err)
+	suite.Equal(map[string][]byte{"user.lnk.test": []byte("kept")}, attrs)
+}
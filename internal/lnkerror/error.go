@@ -13,6 +13,31 @@ var (
 	ErrBootstrapNotFound = errors.New("Bootstrap script not found")
 	ErrBootstrapFailed   = errors.New("Bootstrap script failed with error")
 	ErrBootstrapPerms    = errors.New("Failed to make bootstrap script executable")
+	ErrInvalidHost       = errors.New("Invalid host name")
+	ErrUncommitted       = errors.New("Repository has uncommitted changes")
+	ErrInvalidIdentity   = errors.New("Invalid commit identity")
+	ErrNoMatch           = errors.New("No files match the pattern")
+	ErrInvalidPattern    = errors.New("Invalid glob pattern")
+	ErrStowConflict      = errors.New("Existing file is in the way of a stow package file")
+	ErrStorageOccupied   = errors.New("A different file is already stored at this path in the repository")
+	ErrCannotUndo        = errors.New("The last commit cannot be undone by lnk")
+	ErrAlreadyPushed     = errors.New("The last commit has already been pushed")
+	ErrUndoConflict      = errors.New("A file is in the way of undoing the last commit")
+	ErrInsideRepo        = errors.New("Cannot manage the lnk repository or files inside it")
+	ErrFileTooLarge      = errors.New("File is larger than the add size limit")
+	ErrBinaryFile        = errors.New("File looks binary")
+	ErrNoGitCrypt        = errors.New("The lnk repository is not set up for git-crypt")
+	ErrInvalidInto       = errors.New("Invalid repository directory to store files in")
+	ErrInvalidMode       = errors.New("Invalid file mode")
+	ErrRepoInconsistent  = errors.New("Repository does not match its tracking files")
+	ErrHostNotFound      = errors.New("No configuration exists for this host")
+	ErrHostExists        = errors.New("A configuration already exists for this host")
+	ErrSymlinkCollision  = errors.New("Another managed item is linked at the same location")
+	ErrInterrupted       = errors.New("Interrupted before the change was committed")
+	ErrNothingStaged     = errors.New("Nothing is staged to commit")
+	ErrHomeDirectory     = errors.New("Refusing to manage your whole home directory")
+	ErrInvalidRepoName   = errors.New("Invalid repository directory name")
+	ErrHostOverride      = errors.New("File is linked to a host's version, not the common one")
 )
 
 // Error wraps a sentinel error with optional context for display.
@@ -0,0 +1,62 @@
+// Package lock provides the repository lock that keeps concurrent lnk
+// invocations from interleaving changes to the index and Git state.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// ErrLocked is returned when the lock is still held by another process after
+// the timeout.
+var ErrLocked = errors.New("Another lnk operation is in progress")
+
+// errBusy is returned by tryLock when another process holds the lock.
+var errBusy = errors.New("lock is held")
+
+// pollInterval is how often Acquire retries a held lock.
+const pollInterval = 50 * time.Millisecond
+
+// Lock is an acquired exclusive lock on a lock file.
+type Lock struct {
+	release func() error
+}
+
+// Acquire takes an exclusive lock on the file at path, creating it if needed,
+// and waits up to timeout for another holder to release it. It stops waiting
+// with ctx.Err() once ctx is done. The lock is tied to the process, so it is
+// freed even if lnk exits without calling Release.
+func Acquire(ctx context.Context, path string, timeout time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		release, err := tryLock(path)
+		if err == nil {
+			return &Lock{release: release}, nil
+		}
+		if !errors.Is(err, errBusy) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, lnkerror.WithPathAndSuggestion(ErrLocked, path, "wait for the other lnk command to finish and try again")
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Release frees the lock. The lock file itself is left in place.
+func (l *Lock) Release() error {
+	return l.release()
+}
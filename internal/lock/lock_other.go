@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package lock
+
+// tryLock is a no-op on platforms without file locking.
+func tryLock(string) (func() error, error) {
+	return func() error { return nil }, nil
+}
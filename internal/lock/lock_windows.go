@@ -0,0 +1,35 @@
+//go:build windows
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, returned when another
+// process already has the file open.
+const errorSharingViolation syscall.Errno = 32
+
+// tryLock opens path without sharing, which Windows refuses while another
+// process holds it open.
+func tryLock(path string) (func() error, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	h, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, errBusy
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() error {
+		return syscall.CloseHandle(h)
+	}, nil
+}
@@ -0,0 +1,79 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// ChangedItem is a managed item whose stored copy differs from the last
+// commit. A managed directory is a single index entry, so files created,
+// edited or deleted inside it through its symlink are reported under it:
+// Files lists them, sorted, relative to the directory. Files is empty for a
+// file.
+type ChangedItem struct {
+	Path      string   // Index entry, relative to $HOME
+	Host      string   // Host configuration; empty for the common one
+	Directory bool     // Whether the entry is a managed directory
+	Files     []string // Changed paths inside a directory, slash-separated
+}
+
+// ChangedItems maps the repository's uncommitted changes back to the managed
+// items of the common and every host configuration, in index order. Changes
+// to files no index lists, such as the index files themselves, are left out.
+func (s *Syncer) ChangedItems() ([]ChangedItem, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	changed, err := s.git.ChangedPaths()
+	if err != nil || len(changed) == 0 {
+		return nil, err
+	}
+	hosts, err := s.tracker.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []ChangedItem
+	for _, host := range append([]string{""}, hosts...) {
+		t := s.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			storagePath := t.StoragePath(entry)
+			gitPath, err := filepath.Rel(s.repoPath, storagePath)
+			if err != nil {
+				continue
+			}
+			gitPath = filepath.ToSlash(gitPath)
+			info, err := os.Stat(storagePath)
+			item := ChangedItem{Path: entry.Path, Host: host, Directory: err == nil && info.IsDir()}
+
+			found := false
+			for _, path := range changed {
+				if path == gitPath {
+					found = true
+				} else if inside, ok := strings.CutPrefix(path, gitPath+"/"); ok {
+					found = true
+					item.Files = append(item.Files, inside)
+				}
+			}
+			if len(item.Files) > 0 {
+				item.Directory = true
+				sort.Strings(item.Files)
+			}
+			if found {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,104 @@
+package syncer
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// LogEntry is one commit of the repository with the files it changed.
+type LogEntry struct {
+	Hash    string
+	Date    time.Time // Author date
+	Subject string
+	Files   []LogFile
+}
+
+// LogFile is a repository path a commit changed. Home is where the file
+// lives relative to $HOME according to the current indexes, slash-separated;
+// it is empty for files no index lists, such as the indexes themselves or an
+// item that has since been removed.
+type LogFile struct {
+	Path string // Repo-relative, slash-separated
+	Home string
+	Host string // Host configuration Home belongs to; empty for the common one
+}
+
+// logItem is a managed item's location in the repository, for mapping
+// changed paths back to $HOME.
+type logItem struct {
+	gitPath string
+	home    string
+	host    string
+}
+
+// Log returns the commits since a revision or date (see git.Log), newest
+// first and at most max of them when positive, with each changed path mapped
+// to the managed item it belongs to in the common or any host configuration.
+func (s *Syncer) Log(since string, max int) ([]LogEntry, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	commits, err := s.git.Log(since, max)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.logItems()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LogEntry, 0, len(commits))
+	for _, commit := range commits {
+		entry := LogEntry{Hash: commit.Hash, Date: commit.Date, Subject: commit.Subject}
+		for _, changed := range commit.Paths {
+			entry.Files = append(entry.Files, mapLogPath(changed, items))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// logItems returns where every managed item of the common and each host
+// configuration is stored.
+func (s *Syncer) logItems() ([]logItem, error) {
+	hosts, err := s.tracker.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []logItem
+	for _, host := range append([]string{""}, hosts...) {
+		t := s.tracker.ForHost(host)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			items = append(items, logItem{
+				gitPath: filepath.ToSlash(t.GitPath(entry)),
+				home:    filepath.ToSlash(entry.Path),
+				host:    host,
+			})
+		}
+	}
+	return items, nil
+}
+
+// mapLogPath maps a changed repository path to its place under $HOME: the
+// item stored there, or the file inside a managed directory.
+func mapLogPath(changed string, items []logItem) LogFile {
+	for _, item := range items {
+		if changed == item.gitPath {
+			return LogFile{Path: changed, Home: item.home, Host: item.host}
+		}
+		if inside, ok := strings.CutPrefix(changed, item.gitPath+"/"); ok {
+			return LogFile{Path: changed, Home: path.Join(item.home, inside), Host: item.host}
+		}
+	}
+	return LogFile{Path: changed}
+}
@@ -0,0 +1,102 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// ErrEmptyMessage is returned when a commit message renders to nothing.
+var ErrEmptyMessage = errors.New("Commit message is empty")
+
+// RenderMessage fills the placeholders of a sync commit message: {date} is
+// today's date (2006-01-02), {host} the name from SetHostName and {count} the
+// number of files the commit would include — changed or untracked files in
+// the repository plus copy-managed originals edited in $HOME. With only, the
+// count covers just those managed paths, as for `lnk push --only`. Other text
+// is kept as is. A message that is blank once rendered, or whose {host} has
+// no name to fill in, fails with ErrEmptyMessage.
+func (s *Syncer) RenderMessage(template string, only ...string) (string, error) {
+	message := template
+	if strings.Contains(message, "{date}") {
+		message = strings.ReplaceAll(message, "{date}", time.Now().Format("2006-01-02"))
+	}
+	if strings.Contains(message, "{host}") {
+		hostname, err := s.hostName()
+		if err != nil || hostname == "" {
+			return "", lnkerror.WithPathAndSuggestion(ErrEmptyMessage, template, "{host} has no host name to fill in; pass --host, or leave {host} out")
+		}
+		message = strings.ReplaceAll(message, "{host}", hostname)
+	}
+	if strings.Contains(message, "{count}") {
+		count, err := s.pendingCount(only)
+		if err != nil {
+			return "", err
+		}
+		message = strings.ReplaceAll(message, "{count}", strconv.Itoa(count))
+	}
+
+	if strings.TrimSpace(message) == "" {
+		return "", lnkerror.WithPathAndSuggestion(ErrEmptyMessage, template, "pass a message or fix push.default_message in config.toml")
+	}
+	return message, nil
+}
+
+// pendingCount counts the files a sync commit would include without
+// refreshing anything: what git reports as changed, plus copy-managed items
+// whose $HOME original no longer matches the repository copy.
+func (s *Syncer) pendingCount(only []string) (int, error) {
+	if !s.git.IsGitRepository() {
+		return 0, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	var relativePaths, gitPaths []string
+	if len(only) > 0 {
+		var err error
+		if relativePaths, err = s.managedPaths(only); err != nil {
+			return 0, err
+		}
+		for _, relativePath := range relativePaths {
+			gitPaths = append(gitPaths, s.gitPath(relativePath))
+		}
+	}
+
+	changed, err := s.git.ChangedPaths(gitPaths...)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := s.tracker.GetEntries()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get managed items: %w", err)
+	}
+	homeDir, err := s.fs.HomeDir()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.Copy || (relativePaths != nil && !slices.Contains(relativePaths, entry.Path)) {
+			continue
+		}
+		gitPath := filepath.ToSlash(s.gitPath(entry.Path))
+		if slices.Contains(changed, gitPath) {
+			continue
+		}
+		original := s.fs.HomePath(homeDir, entry.Path, entry.XDG)
+		if info, err := os.Stat(original); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if !s.fs.SameContent(original, s.tracker.StoragePath(entry)) {
+			changed = append(changed, gitPath)
+		}
+	}
+
+	return len(changed), nil
+}
@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yarlson/lnk/internal/fs"
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// MissingItem is a managed item that a restore would put in place on this
+// machine, because its $HOME path does not already hold it.
+type MissingItem struct {
+	Path     string // Index entry, relative to $HOME
+	Location string // Absolute path the item belongs at on this machine
+	Host     string // Host configuration; empty for the common one
+	Copy     bool   // Whether the item is copy-managed
+	Blocker  string // What occupies the path instead ("regular file", "symlink", ...); empty when nothing does
+}
+
+// Missing returns the items of the common configuration, and of host when it
+// is not empty, that RestoreSymlinksForHost would restore: symlinks that are
+// absent or point elsewhere, and copy-managed items whose $HOME copy is
+// absent or differs. Only the working tree is read, so this is what a pull
+// would restore if the remote has nothing new. Items whose stored copy is
+// missing, items outside WithRestoreOnly prefixes and common items a host
+// version overrides are left out.
+func (s *Syncer) Missing(host string) ([]MissingItem, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	homeDir, err := s.fs.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	prefixes, err := s.restorePrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{""}
+	if host != "" {
+		scopes = append(scopes, host)
+	}
+	shadowed, err := s.hostPaths(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingItem
+	for _, scope := range scopes {
+		t := s.tracker.ForHost(scope)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+
+		for _, entry := range entries {
+			if !underPrefix(entry.Path, prefixes) {
+				continue
+			}
+			repoItem := t.StoragePath(entry)
+			if _, err := os.Stat(repoItem); err != nil {
+				continue
+			}
+
+			homePath := s.fs.HomePath(homeDir, entry.Path, entry.XDG)
+			if scope == "" && (shadowed[entry.Path] || s.HostOverride(entry, homePath)) {
+				continue
+			}
+			if entry.Copy && s.fs.SameContent(homePath, repoItem) {
+				continue
+			}
+			if !entry.Copy && s.IsValidSymlink(homePath, repoItem) {
+				continue
+			}
+
+			item := MissingItem{Path: entry.Path, Location: homePath, Host: scope, Copy: entry.Copy}
+			if info, err := os.Lstat(homePath); err == nil {
+				item.Blocker = fs.TypeName(info.Mode())
+			}
+			missing = append(missing, item)
+		}
+	}
+
+	return missing, nil
+}
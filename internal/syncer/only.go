@@ -0,0 +1,51 @@
+package syncer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SetRestoreOnly limits symlink restoration to managed items at or below one
+// of prefixes, paths as the user sees them in $HOME (relative ones resolve
+// against the working directory, as with push --only). Items outside every
+// prefix are left unlinked and reported in RestoreInfo.Excluded. No prefixes
+// restores everything.
+func (s *Syncer) SetRestoreOnly(prefixes []string) {
+	s.only = prefixes
+}
+
+// restorePrefixes maps the SetRestoreOnly prefixes to index-relative paths.
+func (s *Syncer) restorePrefixes() ([]string, error) {
+	prefixes := make([]string, 0, len(s.only))
+	for _, p := range s.only {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", p, err)
+		}
+
+		relativePath, err := s.fs.RelativePath(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path for %s: %w", p, err)
+		}
+		prefixes = append(prefixes, relativePath)
+	}
+
+	return prefixes, nil
+}
+
+// underPrefix reports whether relativePath is one of prefixes or lies below
+// one, comparing whole path components: .config/nvim does not select
+// .config/nvim-old. An empty prefixes selects everything.
+func underPrefix(relativePath string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range prefixes {
+		if prefix == "." || relativePath == prefix || strings.HasPrefix(relativePath, prefix+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
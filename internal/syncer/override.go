@@ -0,0 +1,58 @@
+package syncer
+
+import (
+	"github.com/yarlson/lnk/internal/tracker"
+)
+
+// hostPaths returns the paths the host scopes among hosts ("" is the common
+// configuration) manage, when the common configuration is restored with
+// them; their common items are then left to the host versions. It is nil
+// when hosts has no common scope or no host scope.
+func (s *Syncer) hostPaths(hosts []string) (map[string]bool, error) {
+	common := false
+	for _, host := range hosts {
+		common = common || host == ""
+	}
+	if !common {
+		return nil, nil
+	}
+
+	var paths map[string]bool
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+		entries, err := s.tracker.ForHost(host).GetEntries()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if paths == nil {
+				paths = make(map[string]bool)
+			}
+			paths[entry.Path] = true
+		}
+	}
+	return paths, nil
+}
+
+// HostOverride reports whether symlinkPath, where the common entry belongs,
+// already links to some host's version of the same path. That link is the
+// host overriding the common item, which restores and doctor leave in place.
+func (s *Syncer) HostOverride(entry tracker.Entry, symlinkPath string) bool {
+	hosts, err := s.tracker.Hosts()
+	if err != nil {
+		return false
+	}
+	for _, host := range hosts {
+		t := s.tracker.ForHost(host)
+		hostEntry, ok, err := t.GetEntry(entry.Path)
+		if err != nil || !ok || hostEntry.Copy {
+			continue
+		}
+		if s.IsValidSymlink(symlinkPath, t.StoragePath(hostEntry)) {
+			return true
+		}
+	}
+	return false
+}
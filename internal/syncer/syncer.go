@@ -2,30 +2,113 @@
 package syncer
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/yarlson/lnk/internal/event"
 	"github.com/yarlson/lnk/internal/fs"
 	"github.com/yarlson/lnk/internal/git"
 	"github.com/yarlson/lnk/internal/lnkerror"
 	"github.com/yarlson/lnk/internal/tracker"
 )
 
+// ErrInvalidConflictAction is returned for an unknown conflict policy name.
+var ErrInvalidConflictAction = errors.New("Invalid conflict policy")
+
+// ErrRestoreIncomplete is returned by the CLI when a restore left managed
+// items it could not put in place; RestoreInfo.Failed says which and why.
+var ErrRestoreIncomplete = errors.New("Some managed items could not be restored")
+
 // StatusInfo contains repository sync status information.
-// Remote is empty when no remote is configured; in that case Behind is always 0.
-type StatusInfo struct {
-	Ahead  int
-	Behind int
-	Remote string
-	Dirty  bool
+type StatusInfo = git.StatusInfo
+
+// StatusOptions controls how Status gathers remote state.
+// Fetch runs `git fetch` before comparing against the remote-tracking branch.
+// Host limits Dirty to that host's tracking file and storage root; ahead and
+// behind stay repository-wide, since commits are not per host.
+type StatusOptions struct {
+	Fetch bool
+	Host  string
 }
 
 // RestoreInfo reports which managed items had symlinks restored and which
 // pre-existing real files were renamed to <path>.lnk-backup along the way.
+// Overwritten lists real files that were deleted in favour of the repo
+// version, and Skipped lists conflicts that were left untouched. Excluded
+// lists managed items that a SetRestoreOnly filter left unlinked. InPlace
+// lists items that were already correctly linked (or copied), Missing lists
+// index entries whose stored item is absent from the repository, and Failed
+// lists items that could not be restored, with the reason. Relinked lists the
+// Restored items whose old symlink pointed somewhere else, typically the
+// repository's location on another machine or before it moved; they are
+// linked to the current storage path whatever the old target was.
+// Overridden lists common items left alone because a host manages the same
+// path: one restored alongside, or one whose version is already linked.
 type RestoreInfo struct {
-	Restored []string
-	BackedUp []string
+	Restored    []string
+	Relinked    []string
+	Overridden  []string
+	BackedUp    []string
+	Overwritten []string
+	Skipped     []string
+	Excluded    []string
+	InPlace     []string
+	Missing     []string
+	Failed      []RestoreFailure
+}
+
+// RestoreFailure is one managed item a restore could not put in place.
+type RestoreFailure struct {
+	Path string
+	Err  error
+}
+
+// ConflictAction says what to do with a real file or directory found where a
+// managed symlink should be restored.
+type ConflictAction string
+
+// Conflict actions. ConflictBackup is the default.
+const (
+	ConflictBackup    ConflictAction = "backup"
+	ConflictOverwrite ConflictAction = "overwrite"
+	ConflictSkip      ConflictAction = "skip"
+)
+
+// conflictIdentical is what restore does with a real file or directory that
+// matches the stored item: it is replaced without asking or keeping a
+// backup. It is not a policy users can choose.
+const conflictIdentical ConflictAction = "identical"
+
+// ParseConflictAction validates a user-supplied conflict policy name.
+func ParseConflictAction(name string) (ConflictAction, error) {
+	switch action := ConflictAction(name); action {
+	case ConflictBackup, ConflictOverwrite, ConflictSkip:
+		return action, nil
+	default:
+		return "", lnkerror.WithPathAndSuggestion(ErrInvalidConflictAction, name, "use one of: overwrite, skip, backup")
+	}
+}
+
+// ConflictResolver decides what to do when restoring relativePath finds a real
+// file or directory at existingPath. repoItem is the stored version.
+type ConflictResolver func(relativePath, existingPath, repoItem string) (ConflictAction, error)
+
+// ConflictPolicy returns a ConflictResolver that always picks action.
+func ConflictPolicy(action ConflictAction) ConflictResolver {
+	return func(string, string, string) (ConflictAction, error) {
+		return action, nil
+	}
+}
+
+// HostRestoreInfo pairs a RestoreInfo with the host scope it was computed
+// for. Host is empty for the common configuration.
+type HostRestoreInfo struct {
+	Host string
+	*RestoreInfo
 }
 
 // Syncer handles synchronization operations.
@@ -35,6 +118,10 @@ type Syncer struct {
 	git      *git.Git
 	fs       *fs.FileSystem
 	tracker  *tracker.Tracker
+	resolve  ConflictResolver
+	only     []string
+	onEvent  event.Handler
+	hostName func() (string, error)
 }
 
 // New creates a new Syncer.
@@ -45,26 +132,92 @@ func New(repoPath, host string, g *git.Git, f *fs.FileSystem, t *tracker.Tracker
 		git:      g,
 		fs:       f,
 		tracker:  t,
+		resolve:  ConflictPolicy(ConflictBackup),
+		hostName: os.Hostname,
+	}
+}
+
+// SetConflictResolver replaces the default backup policy used when a restore
+// finds a real file where a symlink should go. A nil resolver restores the
+// default.
+func (s *Syncer) SetConflictResolver(r ConflictResolver) {
+	if r == nil {
+		r = ConflictPolicy(ConflictBackup)
 	}
+	s.resolve = r
+}
+
+// SetEventHandler registers h to hear about each item a restore put back in
+// $HOME. A nil handler reports nothing.
+func (s *Syncer) SetEventHandler(h event.Handler) {
+	s.onEvent = h
+}
+
+// SetHostName replaces os.Hostname as the source of the {host} commit
+// message placeholder.
+func (s *Syncer) SetHostName(hostName func() (string, error)) {
+	s.hostName = hostName
+}
+
+// DiffFiles returns a patch from the item at existingPath to repoItem, for
+// showing the user what a restore would replace.
+func (s *Syncer) DiffFiles(existingPath, repoItem string, color bool) (string, error) {
+	return s.git.DiffFiles(existingPath, repoItem, color)
 }
 
 // Status returns the repository sync status.
 func (s *Syncer) Status() (*StatusInfo, error) {
+	return s.StatusWithOptions(StatusOptions{})
+}
+
+// StatusWithOptions is Status with control over fetching first. Fetching is
+// skipped when no remote is configured.
+func (s *Syncer) StatusWithOptions(opts StatusOptions) (*StatusInfo, error) {
 	if !s.git.IsGitRepository() {
 		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
 	}
 
-	gitStatus, err := s.git.GetStatus()
+	fetched := false
+	if opts.Fetch {
+		if _, err := s.git.GetRemoteInfo(); err == nil {
+			if err := s.git.Fetch(); err != nil {
+				return nil, err
+			}
+			fetched = true
+		}
+	}
+
+	status, err := s.git.GetStatus()
 	if err != nil {
 		return nil, err
 	}
+	status.Fetched = fetched
 
-	return &StatusInfo{
-		Ahead:  gitStatus.Ahead,
-		Behind: gitStatus.Behind,
-		Remote: gitStatus.Remote,
-		Dirty:  gitStatus.Dirty,
-	}, nil
+	if opts.Host != "" {
+		t := s.tracker.ForHost(opts.Host)
+		if _, err := os.Stat(filepath.Join(s.repoPath, t.LnkFileName())); err != nil {
+			return nil, lnkerror.WithPathAndSuggestion(lnkerror.ErrHostNotFound, opts.Host, "run 'lnk list --all' to see the hosts in the repository")
+		}
+		root, err := filepath.Rel(s.repoPath, t.HostStoragePath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate storage for host %s: %w", opts.Host, err)
+		}
+		paths := []string{t.LnkFileName(), root}
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Suffix {
+				paths = append(paths, t.GitPath(entry))
+			}
+		}
+		if status.Dirty, err = s.git.HasChanges(paths...); err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
 }
 
 // Diff returns the diff output for uncommitted changes in the repository.
@@ -86,35 +239,264 @@ func (s *Syncer) HasDiff() (bool, error) {
 	return s.git.HasDiff()
 }
 
+// HasChanges reports whether the repository has uncommitted changes,
+// including untracked files.
+func (s *Syncer) HasChanges() (bool, error) {
+	if !s.git.IsGitRepository() {
+		return false, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	return s.git.HasChanges()
+}
+
+// PushOptions narrows what Push commits before pushing.
+// NoCommit pushes existing commits only and fails if the working tree is
+// dirty. Only, when non-empty, commits just the listed managed files (paths
+// as the user sees them in $HOME) instead of staging everything. AllRemotes
+// pushes to every configured remote rather than the one git.SetRemote chose.
+type PushOptions struct {
+	NoCommit   bool
+	Only       []string
+	AllRemotes bool
+}
+
 // Push stages all changes and creates a sync commit, then pushes to remote.
 func (s *Syncer) Push(message string) error {
+	return s.PushWithOptions(message, PushOptions{})
+}
+
+// Commit records what is already staged, such as adds and removes made with
+// --no-commit, as one commit with message. Unstaged edits are left out.
+func (s *Syncer) Commit(message string) error {
 	if !s.git.IsGitRepository() {
 		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
 	}
 
-	hasChanges, err := s.git.HasChanges()
+	staged, err := s.git.HasStagedChanges()
 	if err != nil {
 		return err
 	}
+	if !staged {
+		return lnkerror.WithSuggestion(lnkerror.ErrNothingStaged, "stage changes with 'lnk add --no-commit' or 'lnk rm --no-commit', or run 'lnk push' to commit everything")
+	}
 
-	if hasChanges {
-		if err := s.git.AddAll(); err != nil {
+	return s.git.Commit(message)
+}
+
+// PushWithOptions is Push with control over what gets committed first.
+// Copy-managed files of the common configuration and the Syncer's host are
+// refreshed from $HOME before committing, so their edits are included;
+// --no-commit pushes leave them as they are.
+func (s *Syncer) PushWithOptions(message string, opts PushOptions) error {
+	if !s.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+	if !opts.AllRemotes {
+		if err := s.git.CheckRemote(); err != nil {
 			return err
 		}
+	}
 
-		if err := s.git.Commit(message); err != nil {
+	switch {
+	case opts.NoCommit:
+		hasChanges, err := s.git.HasChanges()
+		if err != nil {
+			return err
+		}
+		if hasChanges {
+			return lnkerror.WithPathAndSuggestion(lnkerror.ErrUncommitted, s.repoPath, "commit them first or run 'lnk push' without --no-commit")
+		}
+
+	case len(opts.Only) > 0:
+		relativePaths, err := s.managedPaths(opts.Only)
+		if err != nil {
+			return err
+		}
+
+		if err := s.refreshCopies(s.tracker, relativePaths); err != nil {
+			return err
+		}
+
+		gitPaths := make([]string, 0, len(relativePaths))
+		for _, relativePath := range relativePaths {
+			gitPaths = append(gitPaths, s.gitPath(relativePath))
+		}
+
+		hasChanges, err := s.git.HasChanges(gitPaths...)
+		if err != nil {
+			return err
+		}
+		if hasChanges {
+			if err := s.git.CommitPaths(message, gitPaths); err != nil {
+				return err
+			}
+		}
+
+	default:
+		if _, err := s.commitAll(message); err != nil {
 			return err
 		}
 	}
 
+	if opts.AllRemotes {
+		return s.pushAllRemotes()
+	}
 	return s.git.Push()
 }
 
+// pushAllRemotes pushes to every remote in turn. A failing remote does not
+// stop the others; when some fail, the error names them, and when all fail,
+// the first failure is returned as it is.
+func (s *Syncer) pushAllRemotes() error {
+	remotes, err := s.git.Remotes()
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		return lnkerror.WithSuggestion(git.ErrPush, "add a remote repository first")
+	}
+
+	var failed []string
+	var first error
+	for _, remote := range remotes {
+		if err := s.git.PushTo(remote); err != nil {
+			failed = append(failed, remote)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(failed) == len(remotes):
+		return first
+	default:
+		return lnkerror.WithPathAndSuggestion(git.ErrPush, strings.Join(failed, ", "), "the other remotes were pushed; retry with 'lnk push --remote <name>'")
+	}
+}
+
+// copyScopes returns the configurations whose copy-managed files a push
+// refreshes: the common one, and the Syncer's host when it has one.
+func (s *Syncer) copyScopes() []string {
+	if s.host == "" {
+		return []string{""}
+	}
+	return []string{"", s.host}
+}
+
+// commitAll refreshes the copy-managed files of the common configuration
+// and the Syncer's host, then stages and commits every change in the
+// repository. It reports whether a commit was made.
+func (s *Syncer) commitAll(message string) (bool, error) {
+	for _, host := range s.copyScopes() {
+		if err := s.refreshCopies(s.tracker.ForHost(host), nil); err != nil {
+			return false, err
+		}
+	}
+
+	hasChanges, err := s.git.HasChanges()
+	if err != nil || !hasChanges {
+		return false, err
+	}
+
+	if err := s.git.AddAll(); err != nil {
+		return false, err
+	}
+	if err := s.git.Commit(message); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// managedPaths maps user-facing paths to their index-relative paths, failing
+// if any of them is not managed in the Syncer's host scope.
+func (s *Syncer) managedPaths(paths []string) ([]string, error) {
+	managedItems, err := s.tracker.GetManagedItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	relativePaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", p, err)
+		}
+
+		relativePath, err := s.fs.RelativePath(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path for %s: %w", p, err)
+		}
+
+		if !slices.Contains(managedItems, relativePath) {
+			return nil, lnkerror.WithPath(lnkerror.ErrNotManaged, relativePath)
+		}
+
+		relativePaths = append(relativePaths, relativePath)
+	}
+
+	return relativePaths, nil
+}
+
+// gitPath returns the repo-relative storage path of a managed item in the
+// Syncer's host scope, following the entry's Repo path and layout when it
+// has them.
+func (s *Syncer) gitPath(relativePath string) string {
+	entry, managed, err := s.tracker.GetEntry(relativePath)
+	if err != nil || !managed {
+		entry = tracker.Entry{Path: relativePath}
+	}
+	return s.tracker.GitPath(entry)
+}
+
+// refreshCopies copies the $HOME originals of copy-managed items in t over
+// their repository copies, so local edits become repository changes. When
+// only is non-nil, just those items are refreshed. Originals that are missing
+// or already match are left alone.
+func (s *Syncer) refreshCopies(t *tracker.Tracker, only []string) error {
+	entries, err := t.GetEntries()
+	if err != nil {
+		return fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	homeDir, err := s.fs.HomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Copy || (only != nil && !slices.Contains(only, entry.Path)) {
+			continue
+		}
+
+		original := s.fs.HomePath(homeDir, entry.Path, entry.XDG)
+		repoItem := t.StoragePath(entry)
+		if info, err := os.Stat(original); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if s.fs.SameContent(original, repoItem) {
+			continue
+		}
+
+		if err := s.fs.CopyFile(original, repoItem); err != nil {
+			return fmt.Errorf("failed to refresh copy of %s: %w", entry.Path, err)
+		}
+	}
+
+	return nil
+}
+
 // Pull fetches changes from remote and restores symlinks as needed.
 func (s *Syncer) Pull() (*RestoreInfo, error) {
 	if !s.git.IsGitRepository() {
 		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
 	}
+	if _, err := s.restorePrefixes(); err != nil {
+		return nil, err
+	}
 
 	if err := s.git.Pull(); err != nil {
 		return nil, err
@@ -128,6 +510,68 @@ func (s *Syncer) Pull() (*RestoreInfo, error) {
 	return info, nil
 }
 
+// PullHosts fetches changes from remote once, then restores symlinks for each
+// of the given host scopes in order ("" selects the common configuration).
+// Results are returned per host so callers can report them grouped.
+func (s *Syncer) PullHosts(hosts []string) ([]HostRestoreInfo, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+	if _, err := s.restorePrefixes(); err != nil {
+		return nil, err
+	}
+
+	if err := s.git.Pull(); err != nil {
+		return nil, err
+	}
+
+	shadowed, err := s.hostPaths(hosts)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]HostRestoreInfo, 0, len(hosts))
+	for _, host := range hosts {
+		info, err := s.restoreScope(host, shadowed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore symlinks: %w", err)
+		}
+		results = append(results, HostRestoreInfo{Host: host, RestoreInfo: info})
+	}
+
+	return results, nil
+}
+
+// Sync pulls and restores symlinks for the given host scopes, then pushes.
+// Nothing is pushed unless the pull completed cleanly; when the pull stops on
+// merge conflicts, ErrMergeConflict is returned naming the unmerged files.
+// Copy-managed files are refreshed from $HOME first, so restoring after the
+// pull does not treat local edits as conflicts.
+func (s *Syncer) Sync(message string, hosts []string) ([]HostRestoreInfo, error) {
+	for _, host := range hosts {
+		if err := s.refreshCopies(s.tracker.ForHost(host), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := s.PullHosts(hosts)
+	if err != nil {
+		if errors.Is(err, git.ErrRebaseConflict) {
+			return nil, err
+		}
+		if conflicts, cerr := s.git.ConflictedFiles(); cerr == nil && len(conflicts) > 0 {
+			return nil, lnkerror.WithPathAndSuggestion(git.ErrMergeConflict, strings.Join(conflicts, ", "),
+				"resolve the conflicts in "+s.repoPath+" and commit, then run 'lnk sync' again (nothing was pushed)")
+		}
+		return nil, err
+	}
+
+	if err := s.Push(message); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 // List returns the list of files and directories currently managed by lnk.
 func (s *Syncer) List() ([]string, error) {
 	if !s.git.IsGitRepository() {
@@ -142,6 +586,31 @@ func (s *Syncer) List() ([]string, error) {
 	return managedItems, nil
 }
 
+// ListEntries is List with the metadata recorded for each managed item.
+func (s *Syncer) ListEntries() ([]tracker.Entry, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	entries, err := s.tracker.GetEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed items: %w", err)
+	}
+
+	return entries, nil
+}
+
+// IsDirectory reports whether the managed item at relativePath is stored in
+// the repository as a directory.
+func (s *Syncer) IsDirectory(relativePath string) bool {
+	entry, managed, err := s.tracker.GetEntry(relativePath)
+	if err != nil || !managed {
+		entry = tracker.Entry{Path: relativePath}
+	}
+	info, err := os.Stat(s.tracker.StoragePath(entry))
+	return err == nil && info.IsDir()
+}
+
 // GetCommits returns the list of commits.
 func (s *Syncer) GetCommits() ([]string, error) {
 	return s.git.GetCommits()
@@ -151,92 +620,172 @@ func (s *Syncer) GetCommits() ([]string, error) {
 // Reports both which items had a symlink (re)created and which pre-existing
 // real files were renamed to <path>.lnk-backup along the way.
 func (s *Syncer) RestoreSymlinks() (*RestoreInfo, error) {
+	return s.RestoreSymlinksForHost(s.host)
+}
+
+// RestoreSymlinksForHost is RestoreSymlinks for an explicit host scope,
+// independent of the host the Syncer was created with.
+// Copy-managed items are restored by copying the repository version into
+// place rather than linking to it.
+func (s *Syncer) RestoreSymlinksForHost(host string) (*RestoreInfo, error) {
+	return s.restoreScope(host, nil)
+}
+
+// restoreScope restores host's items. For the common configuration, items
+// at a path in shadowed, which hosts restored alongside also manage, and
+// items whose $HOME path already links to a host's version are Overridden:
+// a host's version takes precedence over the common one.
+func (s *Syncer) restoreScope(host string, shadowed map[string]bool) (*RestoreInfo, error) {
 	info := &RestoreInfo{}
+	t := s.tracker
+	if host != s.host {
+		t = s.tracker.ForHost(host)
+	}
 
-	managedItems, err := s.tracker.GetManagedItems()
+	entries, err := t.GetEntries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := s.fs.HomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	for _, relativePath := range managedItems {
-		storagePath := s.tracker.HostStoragePath()
-		repoItem := filepath.Join(storagePath, relativePath)
+	prefixes, err := s.restorePrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		relativePath := entry.Path
+		repoItem := t.StoragePath(entry)
 
-		if _, err := os.Stat(repoItem); os.IsNotExist(err) {
+		if !underPrefix(relativePath, prefixes) {
+			info.Excluded = append(info.Excluded, relativePath)
 			continue
 		}
 
-		symlinkPath := filepath.Join(homeDir, relativePath)
+		symlinkPath := s.fs.HomePath(homeDir, relativePath, entry.XDG)
+		if host == "" && (shadowed[relativePath] || s.HostOverride(entry, symlinkPath)) {
+			info.Overridden = append(info.Overridden, relativePath)
+			continue
+		}
 
-		if s.IsValidSymlink(symlinkPath, repoItem) {
+		if _, err := os.Stat(repoItem); os.IsNotExist(err) {
+			info.Missing = append(info.Missing, relativePath)
 			continue
 		}
 
-		symlinkDir := filepath.Dir(symlinkPath)
-		if err := os.MkdirAll(symlinkDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create directory %s: %w", symlinkDir, err)
-		}
-
-		if existing, err := os.Lstat(symlinkPath); err == nil {
-			if existing.Mode()&os.ModeSymlink == 0 {
-				// Existing item is a regular file or directory — back it up
-				backupPath := symlinkPath + ".lnk-backup"
-				if err := os.Rename(symlinkPath, backupPath); err != nil {
-					return nil, fmt.Errorf("failed to back up existing item %s to %s: %w", symlinkPath, backupPath, err)
-				}
-				info.BackedUp = append(info.BackedUp, relativePath)
-			} else {
-				// Existing item is a stale symlink — safe to remove
-				if err := os.Remove(symlinkPath); err != nil {
-					return nil, fmt.Errorf("failed to remove existing symlink %s: %w", symlinkPath, err)
-				}
+		// Git does not keep a mode set by add --chmod, and rewrites the
+		// file with default permissions whenever a pull changes it.
+		if mode, ok := entry.Perm(); ok {
+			if err := os.Chmod(repoItem, mode); err != nil {
+				info.Failed = append(info.Failed, RestoreFailure{Path: relativePath, Err: fmt.Errorf("failed to set mode %s: %w", entry.Mode, err)})
+				continue
 			}
 		}
 
-		if err := s.fs.CreateSymlink(repoItem, symlinkPath); err != nil {
-			return nil, err
+		if entry.Copy && s.fs.SameContent(symlinkPath, repoItem) || !entry.Copy && s.linkInPlace(entry, symlinkPath, repoItem) {
+			info.InPlace = append(info.InPlace, relativePath)
+			continue
 		}
 
+		// A real file or directory in the way is up to the resolver, unless
+		// it is identical to the stored item and can simply be replaced; an
+		// error from the resolver (e.g. an interactive prompt losing its
+		// input) stops the whole restore rather than counting against one
+		// item.
+		var action ConflictAction
+		if existing, err := os.Lstat(symlinkPath); err == nil && existing.Mode()&os.ModeSymlink == 0 {
+			if s.fs.SameTree(symlinkPath, repoItem) {
+				action = conflictIdentical
+			} else if action, err = s.resolve(relativePath, symlinkPath, repoItem); err != nil {
+				return nil, err
+			}
+			if action == ConflictSkip {
+				info.Skipped = append(info.Skipped, relativePath)
+				continue
+			}
+		}
+
+		if err := s.restoreEntry(info, entry, symlinkPath, repoItem, action); err != nil {
+			info.Failed = append(info.Failed, RestoreFailure{Path: relativePath, Err: err})
+			continue
+		}
 		info.Restored = append(info.Restored, relativePath)
+		if s.onEvent != nil {
+			s.onEvent(event.Event{Kind: event.SymlinkRestored, Path: relativePath, Host: host, Copy: entry.Copy})
+		}
 	}
 
 	return info, nil
 }
 
-// IsValidSymlink checks if the given path is a symlink pointing to the expected target.
-func (s *Syncer) IsValidSymlink(symlinkPath, expectedTarget string) bool {
-	info, err := os.Lstat(symlinkPath)
-	if err != nil {
-		return false
+// restoreEntry puts one managed item in place at symlinkPath, linking it to
+// repoItem or, for a copy-managed entry, copying it there. A stale symlink in
+// the way is removed; a real file or directory is overwritten or backed up
+// according to action, the resolver's answer for it, or simply removed when
+// identical to repoItem. Missing parent directories are created, which is
+// what brings back a directory kept only by its .lnkkeep marker.
+func (s *Syncer) restoreEntry(info *RestoreInfo, entry tracker.Entry, symlinkPath, repoItem string, action ConflictAction) error {
+	symlinkDir := filepath.Dir(symlinkPath)
+	if err := os.MkdirAll(symlinkDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", symlinkDir, err)
 	}
 
-	if info.Mode()&os.ModeSymlink == 0 {
-		return false
-	}
-
-	target, err := os.Readlink(symlinkPath)
-	if err != nil {
-		return false
+	if existing, err := os.Lstat(symlinkPath); err == nil {
+		switch {
+		case existing.Mode()&os.ModeSymlink != 0:
+			// Existing item is a stale symlink — safe to remove
+			if err := os.Remove(symlinkPath); err != nil {
+				return fmt.Errorf("failed to remove existing symlink %s: %w", symlinkPath, err)
+			}
+			info.Relinked = append(info.Relinked, entry.Path)
+		case action == conflictIdentical:
+			if err := os.RemoveAll(symlinkPath); err != nil {
+				return fmt.Errorf("failed to remove existing item %s: %w", symlinkPath, err)
+			}
+		case action == ConflictOverwrite:
+			if err := os.RemoveAll(symlinkPath); err != nil {
+				return fmt.Errorf("failed to remove existing item %s: %w", symlinkPath, err)
+			}
+			info.Overwritten = append(info.Overwritten, entry.Path)
+		default:
+			backupPath := symlinkPath + ".lnk-backup"
+			if err := os.Rename(symlinkPath, backupPath); err != nil {
+				return fmt.Errorf("failed to back up existing item %s to %s: %w", symlinkPath, backupPath, err)
+			}
+			info.BackedUp = append(info.BackedUp, entry.Path)
+		}
 	}
 
-	if !filepath.IsAbs(target) {
-		target = filepath.Join(filepath.Dir(symlinkPath), target)
+	if entry.Copy {
+		if err := s.fs.CopyFile(repoItem, symlinkPath); err != nil {
+			return err
+		}
+		_ = fs.WriteXattrs(symlinkPath, entry.Xattrs)
+		return nil
 	}
+	// A fresh clone holds the stored file without its attributes; the link
+	// reads them through to it.
+	_ = fs.WriteXattrs(repoItem, entry.Xattrs)
+	return s.fs.Link(repoItem, symlinkPath, entry.Absolute)
+}
 
-	targetAbs, err := filepath.Abs(target)
-	if err != nil {
-		return false
-	}
+// IsValidSymlink checks if the given path is a symlink pointing to the expected target.
+func (s *Syncer) IsValidSymlink(symlinkPath, expectedTarget string) bool {
+	return s.fs.IsSymlinkTo(symlinkPath, expectedTarget)
+}
 
-	expectedAbs, err := filepath.Abs(expectedTarget)
-	if err != nil {
+// linkInPlace reports whether symlinkPath is the link entry asks for: one
+// pointing to repoItem, holding an absolute target when the entry records
+// an absolute link. A relative link that was not made per the entry still
+// resolves here but may not where the entry needed the absolute form, so
+// restore replaces it. An absolute link to a relative entry is left alone.
+func (s *Syncer) linkInPlace(entry tracker.Entry, symlinkPath, repoItem string) bool {
+	if !s.IsValidSymlink(symlinkPath, repoItem) {
 		return false
 	}
-
-	return targetAbs == expectedAbs
+	return !entry.Absolute || fs.IsAbsoluteSymlink(symlinkPath)
 }
@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// ItemUsage is how much one managed item takes up in the repository.
+type ItemUsage struct {
+	Path  string // Index entry, relative to $HOME
+	Host  string // Host configuration; empty for the common one
+	Size  int64  // Bytes in the regular files stored for the item
+	Files int    // Regular files stored for the item
+}
+
+// Usage is the size of the managed set: its items, largest first, and their
+// sum.
+type Usage struct {
+	Items []ItemUsage
+	Size  int64
+	Files int
+}
+
+// Usage walks the stored copy of every item of the common configuration and
+// of the Syncer's host, or of every host with all, and adds up the regular
+// files in it. Symlinks are not followed, items whose stored copy is missing
+// count as empty, and nothing outside the indexes (such as .git) is counted.
+func (s *Syncer) Usage(all bool) (*Usage, error) {
+	if !s.git.IsGitRepository() {
+		return nil, lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	scopes := []string{""}
+	if all {
+		hosts, err := s.tracker.Hosts()
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, hosts...)
+	} else if s.host != "" {
+		scopes = append(scopes, s.host)
+	}
+
+	usage := &Usage{Items: []ItemUsage{}}
+	for _, scope := range scopes {
+		t := s.tracker.ForHost(scope)
+		entries, err := t.GetEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+
+		for _, entry := range entries {
+			item := ItemUsage{Path: entry.Path, Host: scope}
+			root := t.StoragePath(entry)
+			err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if os.IsNotExist(err) && path == root {
+					return filepath.SkipAll
+				}
+				if err != nil {
+					return err
+				}
+				if info.Mode().IsRegular() {
+					item.Size += info.Size()
+					item.Files++
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", root, err)
+			}
+
+			usage.Items = append(usage.Items, item)
+			usage.Size += item.Size
+			usage.Files += item.Files
+		}
+	}
+
+	sort.SliceStable(usage.Items, func(i, j int) bool { return usage.Items[i].Size > usage.Items[j].Size })
+	return usage, nil
+}
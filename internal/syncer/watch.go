@@ -0,0 +1,196 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yarlson/lnk/internal/lnkerror"
+)
+
+// DefaultWatchDebounce is how long Watch waits after the last change before
+// committing, when WatchOptions.Debounce is not set.
+const DefaultWatchDebounce = 2 * time.Second
+
+// scanShare bounds the cost of polling: the wait between two scans is at
+// least scanShare times as long as the last scan took, so a large tree is
+// checked less often instead of keeping a core busy.
+const scanShare = 10
+
+// WatchOptions controls Watch. Debounce is the quiet period after the last
+// change before a commit (DefaultWatchDebounce when zero); Interval is how
+// often files are checked (the smaller of Debounce and one second when zero).
+// Push pushes after each commit. OnEvent, when set, is called after every
+// commit attempt.
+type WatchOptions struct {
+	Message  string
+	Debounce time.Duration
+	Interval time.Duration
+	Push     bool
+	OnEvent  func(WatchEvent)
+}
+
+// WatchEvent reports one commit or push attempt by Watch. Err is set when
+// committing or pushing failed; Watch keeps running and retries after the
+// next quiet period, pushing a commit whose push failed even if nothing else
+// changes.
+type WatchEvent struct {
+	Committed bool
+	Pushed    bool
+	Err       error
+}
+
+// Watch commits changes to managed files until ctx is done. Because managed
+// files are symlinks into the repository, edits land in its working tree,
+// which is polled for changes together with the $HOME originals of
+// copy-managed items in the common and the Syncer's host configuration.
+// Polling is deliberate: it needs no notification library or per-directory
+// watches, and it sees edits made through symlinks and on network file
+// systems alike. A scan only stats files, and scanShare keeps it to a small
+// share of the time. Once nothing has changed for the debounce period, the
+// changes are committed (and pushed with Push). Each commit runs through
+// locked, so it never interleaves with another lnk command. Watch does not
+// bind git to ctx itself; set the same context on the Syncer's Git
+// (git.SetContext) to have cancelling it abort a commit or push in progress.
+func (s *Syncer) Watch(ctx context.Context, opts WatchOptions, locked func(func() error) error) error {
+	if !s.git.IsGitRepository() {
+		return lnkerror.WithSuggestion(lnkerror.ErrNotInitialized, "run 'lnk init' first")
+	}
+
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = min(debounce, time.Second)
+	}
+
+	// Changes made before the watch started are committed on the first pass.
+	committed := uint64(0)
+	if dirty, err := s.git.HasChanges(); err == nil && !dirty {
+		committed, _ = s.fingerprint()
+	}
+	last, _ := s.fingerprint()
+	changedAt := time.Now()
+	// A commit whose push failed is pushed on a later pass.
+	pendingPush := false
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		current, err := s.fingerprint()
+		timer.Reset(max(interval, scanShare*time.Since(start)))
+		if err != nil {
+			continue
+		}
+		if current != last {
+			last, changedAt = current, time.Now()
+			continue
+		}
+		if (current == committed && !pendingPush) || time.Since(changedAt) < debounce {
+			continue
+		}
+
+		event := WatchEvent{}
+		event.Err = locked(func() error {
+			if current != committed {
+				message, err := s.RenderMessage(opts.Message)
+				if err != nil {
+					return err
+				}
+				if event.Committed, err = s.commitAll(message); err != nil {
+					return err
+				}
+				pendingPush = pendingPush || (event.Committed && opts.Push)
+			}
+			if !pendingPush {
+				return nil
+			}
+			if err := s.git.Push(); err != nil {
+				return err
+			}
+			pendingPush = false
+			event.Pushed = true
+			return nil
+		})
+		if ctx.Err() != nil {
+			// A git command killed by the cancellation is not worth reporting.
+			return nil
+		}
+		if event.Err == nil || event.Committed {
+			committed, _ = s.fingerprint()
+			last = committed
+		}
+		if event.Err != nil {
+			// Wait another debounce period before retrying.
+			changedAt = time.Now()
+		}
+		if opts.OnEvent != nil && (event.Committed || event.Pushed || event.Err != nil) {
+			opts.OnEvent(event)
+		}
+	}
+}
+
+// fingerprint hashes the name, size, mode and modification time of every
+// file in the repository working tree outside .git, and of the $HOME
+// originals of the copy-managed items commitAll refreshes, so that any edit
+// changes the result.
+func (s *Syncer) fingerprint() (uint64, error) {
+	h := fnv.New64a()
+	stamp := func(path string, info fs.FileInfo) {
+		fmt.Fprintf(h, "%s\x00%d\x00%v\x00%d\n", path, info.Size(), info.Mode(), info.ModTime().UnixNano())
+	}
+
+	err := filepath.WalkDir(s.repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stamp(path, info)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s: %w", s.repoPath, err)
+	}
+
+	homeDir, err := s.fs.HomeDir()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	for _, host := range s.copyScopes() {
+		entries, err := s.tracker.ForHost(host).GetEntries()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get managed items: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.Copy {
+				continue
+			}
+			original := s.fs.HomePath(homeDir, entry.Path, entry.XDG)
+			if info, err := os.Stat(original); err == nil {
+				stamp(original, info)
+			}
+		}
+	}
+
+	return h.Sum64(), nil
+}
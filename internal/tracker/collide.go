@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/lnk/internal/fs"
+)
+
+// ScopedEntry is an index entry together with the host whose index lists
+// it; Host is empty for the common configuration.
+type ScopedEntry struct {
+	Host string
+	Entry
+}
+
+// Collision is a pair of index entries that a restore would put at the same
+// location in $HOME, so restoring one replaces the other.
+type Collision struct {
+	First, Second ScopedEntry
+}
+
+// ScopedEntries returns the entries of the given host scopes in order ("" is
+// the common configuration), each marked with its scope.
+func (t *Tracker) ScopedEntries(hosts ...string) ([]ScopedEntry, error) {
+	var scoped []ScopedEntry
+	for _, host := range hosts {
+		entries, err := t.ForHost(host).GetEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed items: %w", err)
+		}
+		for _, entry := range entries {
+			scoped = append(scoped, ScopedEntry{Host: host, Entry: entry})
+		}
+	}
+	return scoped, nil
+}
+
+// Locations indexes entries by the $HOME location a restore puts them at,
+// to find entries whose paths differ but name the same file: spelled through
+// a symlinked parent directory, or differing only in case on a
+// case-insensitive volume. The same path in two scopes is not a collision,
+// since a host's entry is meant to take the common one's place on
+// pull --host.
+type Locations struct {
+	homeDir string
+	keys    []string
+	groups  map[string][]ScopedEntry
+}
+
+// NewLocations indexes entries restored under homeDir.
+func NewLocations(homeDir string, entries []ScopedEntry) *Locations {
+	l := &Locations{homeDir: homeDir, groups: make(map[string][]ScopedEntry)}
+	for _, entry := range entries {
+		l.Add(entry)
+	}
+	return l
+}
+
+// key groups paths that can name the same file: only those with the same
+// resolved form, ignoring case, are compared.
+func (l *Locations) key(path string) string {
+	return strings.ToLower(fs.ResolvePath(filepath.Join(l.homeDir, path)))
+}
+
+// Add indexes one more entry, e.g. one about to be added.
+func (l *Locations) Add(entry ScopedEntry) {
+	key := l.key(entry.Path)
+	if _, ok := l.groups[key]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.groups[key] = append(l.groups[key], entry)
+}
+
+// Collides returns an indexed entry with another path that names the same
+// location as path.
+func (l *Locations) Collides(path string) (ScopedEntry, bool) {
+	target := filepath.Join(l.homeDir, path)
+	for _, other := range l.groups[l.key(path)] {
+		if other.Path != path && fs.SamePath(target, filepath.Join(l.homeDir, other.Path)) {
+			return other, true
+		}
+	}
+	return ScopedEntry{}, false
+}
+
+// Collisions returns every pair of indexed entries that name the same
+// location, in index order.
+func (l *Locations) Collisions() []Collision {
+	var collisions []Collision
+	for _, key := range l.keys {
+		group := l.groups[key]
+		for i, a := range group {
+			for _, b := range group[i+1:] {
+				if a.Path != b.Path && fs.SamePath(filepath.Join(l.homeDir, a.Path), filepath.Join(l.homeDir, b.Path)) {
+					collisions = append(collisions, Collision{First: a, Second: b})
+				}
+			}
+		}
+	}
+	return collisions
+}
@@ -2,23 +2,276 @@
 package tracker
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/yarlson/lnk/internal/fs"
 )
 
+// FormatHeader is the first line of a v2 tracking file. Files without it are
+// read as the legacy format: one relative path per line, optionally followed
+// by a tab and the RFC 3339 time the item was added.
+const FormatHeader = "# lnk v2"
+
+// formatHeaderPrefix identifies any versioned header, so files written by a
+// newer lnk are rejected instead of misread as legacy paths.
+const formatHeaderPrefix = "# lnk v"
+
+// Entry is one managed item recorded in the tracking file. In the v2 format
+// each entry is a JSON object on its own line.
+// AddedAt is zero for entries written before timestamps were recorded.
+// Copy marks a copy-managed item: the original stays a regular file in $HOME
+// and the repository holds a copy that is refreshed on push, instead of the
+// original being replaced by a symlink.
+// Secret marks an item that .gitattributes hands to git-crypt.
+// Repo is where the item is stored relative to the storage root when that
+// differs from Path (add --into); empty means it mirrors Path.
+// Mode is the permission set by add --chmod, in octal such as "0600"; Git
+// keeps only the executable bit, so restores put it back on the stored item.
+// Suffix marks a host entry stored at the repository root as
+// <stored path>.<host> instead of inside <host>.lnk/ (add --hostname-suffix).
+// XDG marks an item added from a custom $XDG_CONFIG_HOME: Path starts with
+// .config/ and restores under the target machine's config directory.
+// Absolute marks an item whose symlink holds the absolute path of its stored
+// copy instead of a relative one (add --absolute-symlink).
+// Xattrs holds the item's user.* extended attributes when it was added with
+// add --xattrs, for putting back where git does not carry them.
+// Comment is a free-text note on why the item is tracked (add --comment);
+// JSON escaping keeps any character in it, newlines included, on the
+// entry's line.
+type Entry struct {
+	Path     string            `json:"path"`
+	AddedAt  time.Time         `json:"added_at,omitzero"`
+	Copy     bool              `json:"copy,omitempty"`
+	Secret   bool              `json:"secret,omitempty"`
+	Repo     string            `json:"repo,omitempty"`
+	Mode     string            `json:"mode,omitempty"`
+	Suffix   bool              `json:"suffix,omitempty"`
+	XDG      bool              `json:"xdg,omitempty"`
+	Absolute bool              `json:"absolute,omitempty"`
+	Xattrs   map[string][]byte `json:"xattrs,omitempty"`
+	Comment  string            `json:"comment,omitempty"`
+}
+
+// StoredPath returns where the entry lives relative to the storage root:
+// Repo if set, otherwise Path.
+func (e Entry) StoredPath() string {
+	if e.Repo != "" {
+		return e.Repo
+	}
+	return e.Path
+}
+
+// Perm returns the permission bits recorded in Mode, and false when the
+// entry has none or they do not parse.
+func (e Entry) Perm() (os.FileMode, bool) {
+	if e.Mode == "" {
+		return 0, false
+	}
+	mode, err := ParseMode(e.Mode)
+	return mode, err == nil
+}
+
+// ParseMode parses permission bits written in octal, such as "600" or
+// "0600". Anything beyond the nine rwx bits is rejected.
+func ParseMode(s string) (os.FileMode, error) {
+	bits, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || bits > 0777 {
+		return 0, fmt.Errorf("invalid mode %q: want octal permissions such as 0600", s)
+	}
+	return os.FileMode(bits), nil
+}
+
+// ParseEntries decodes tracking file content in either the v2 format or the
+// legacy plain format, detected by the presence of FormatHeader.
+func ParseEntries(content []byte) ([]Entry, error) {
+	lines := strings.Split(string(content), "\n")
+
+	first := ""
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			first = line
+			break
+		}
+	}
+
+	if !strings.HasPrefix(first, formatHeaderPrefix) {
+		return parseLegacyEntries(lines), nil
+	}
+	if first != FormatHeader {
+		return nil, fmt.Errorf("unsupported tracking file version %q (upgrade lnk to read it)", strings.TrimPrefix(first, "# "))
+	}
+
+	var entries []Entry
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("invalid entry on line %d: %w", i+1, err)
+		}
+		if entry.Path == "" {
+			return nil, fmt.Errorf("invalid entry on line %d: missing path", i+1)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// NormalizeEntries trims and cleans each entry's path, drops entries left
+// empty and any later duplicates of a path, and sorts the result by path.
+// Paths are compared exactly: entries that differ only in case are distinct
+// files on case-sensitive filesystems and are kept; Tracker reads also fold
+// them where the repository's volume is case-insensitive (foldCase).
+func NormalizeEntries(entries []Entry) []Entry {
+	seen := make(map[string]bool, len(entries))
+	normalized := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		path := strings.TrimSpace(entry.Path)
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		if path == "." || seen[path] {
+			continue
+		}
+		seen[path] = true
+		entry.Path = path
+		normalized = append(normalized, entry)
+	}
+
+	sort.SliceStable(normalized, func(i, j int) bool { return normalized[i].Path < normalized[j].Path })
+	return normalized
+}
+
+// parseLegacyEntries reads the pre-v2 format, where each non-blank line is a
+// path optionally followed by a tab and an RFC 3339 timestamp.
+func parseLegacyEntries(lines []string) []Entry {
+	var entries []Entry
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		entry := Entry{Path: line}
+		if path, stamp, ok := strings.Cut(line, "\t"); ok {
+			entry.Path = strings.TrimSpace(path)
+			if addedAt, err := time.Parse(time.RFC3339, strings.TrimSpace(stamp)); err == nil {
+				entry.AddedAt = addedAt
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// FormatEntries encodes entries in the v2 format: FormatHeader followed by
+// one JSON object per line. No entries encode to empty content.
+func FormatEntries(entries []Entry) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	var b bytes.Buffer
+	b.WriteString(FormatHeader + "\n")
+	for _, entry := range entries {
+		if !entry.AddedAt.IsZero() {
+			entry.AddedAt = entry.AddedAt.UTC()
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode entry %s: %w", entry.Path, err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// NormalizeResult reports what Normalize did to a tracking file.
+// Removed counts duplicate or empty entries dropped; Rewritten is false when
+// the file was already in normalized form.
+type NormalizeResult struct {
+	Removed   int
+	Rewritten bool
+}
+
+// DefaultIndexFile is where the common tracking file lives, relative to the
+// repository root, unless SetIndexFile moves it.
+const DefaultIndexFile = ".lnk"
+
 // Tracker manages the .lnk tracking file that records which files are managed.
 type Tracker struct {
 	repoPath string
 	host     string
+	index    string
 }
 
 // New creates a new Tracker.
 func New(repoPath, host string) *Tracker {
-	return &Tracker{repoPath: repoPath, host: host}
+	return &Tracker{repoPath: repoPath, host: host, index: DefaultIndexFile}
+}
+
+// SetIndexFile moves the common tracking file to name, a path relative to
+// the repository root (index.file); host tracking files become
+// <name>.<host> next to it. Storage directories are not affected. An empty
+// name restores DefaultIndexFile.
+func (t *Tracker) SetIndexFile(name string) {
+	if name == "" {
+		name = DefaultIndexFile
+	}
+	t.index = filepath.Clean(filepath.FromSlash(name))
+}
+
+// ForHost returns a Tracker for host in the same repository, with the same
+// index location.
+func (t *Tracker) ForHost(host string) *Tracker {
+	return &Tracker{repoPath: t.repoPath, host: host, index: t.index}
+}
+
+// Hosts returns the hosts with a tracking file next to the common one, in
+// directory order. A missing repository has none.
+func (t *Tracker) Hosts() ([]string, error) {
+	dir := filepath.Join(t.repoPath, filepath.Dir(t.index))
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	prefix := filepath.Base(t.index) + "."
+	var hosts []string
+	for _, entry := range entries {
+		if host, ok := strings.CutPrefix(entry.Name(), prefix); ok && host != "" && !entry.IsDir() {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// IndexHost reports whether the repo-relative path is a tracking file, and
+// for which host: the common one ("") or <index>.<host>.
+func (t *Tracker) IndexHost(path string) (string, bool) {
+	path = filepath.Clean(filepath.FromSlash(path))
+	if path == t.index {
+		return "", true
+	}
+	if filepath.Dir(path) != filepath.Dir(t.index) {
+		return "", false
+	}
+	host, ok := strings.CutPrefix(path, t.index+".")
+	return host, ok && host != ""
 }
 
 // RepoPath returns the repository path.
@@ -26,12 +279,13 @@ func (t *Tracker) RepoPath() string {
 	return t.repoPath
 }
 
-// LnkFileName returns the appropriate .lnk tracking file name.
+// LnkFileName returns the path of the tracking file relative to the
+// repository root: .lnk or .lnk.<host>, unless SetIndexFile moved them.
 func (t *Tracker) LnkFileName() string {
 	if t.host == "" {
-		return ".lnk"
+		return t.index
 	}
-	return ".lnk." + t.host
+	return t.index + "." + t.host
 }
 
 // HostStoragePath returns the storage path for host-specific or common files.
@@ -42,82 +296,297 @@ func (t *Tracker) HostStoragePath() string {
 	return filepath.Join(t.repoPath, t.host+".lnk")
 }
 
+// StoragePath returns the absolute path entry is stored at in the repository.
+func (t *Tracker) StoragePath(entry Entry) string {
+	return filepath.Join(t.repoPath, t.GitPath(entry))
+}
+
+// GitPath returns where entry is stored relative to the repository root, the
+// form git takes: <host>.lnk/<stored path> for a host entry, or
+// <stored path>.<host> when the entry uses the suffix layout.
+func (t *Tracker) GitPath(entry Entry) string {
+	switch {
+	case t.host == "":
+		return entry.StoredPath()
+	case entry.Suffix:
+		return entry.StoredPath() + "." + t.host
+	}
+	return filepath.Join(t.host+".lnk", entry.StoredPath())
+}
+
 // GetManagedItems returns the list of managed files and directories from .lnk file.
 func (t *Tracker) GetManagedItems() ([]string, error) {
+	entries, err := t.GetEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, entry.Path)
+	}
+
+	return items, nil
+}
+
+// GetEntries returns the managed items from the .lnk file with their metadata,
+// normalized with NormalizeEntries and foldCase so hand edits cannot produce
+// duplicates.
+func (t *Tracker) GetEntries() ([]Entry, error) {
+	_, entries, err := t.readEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	return t.foldCase(NormalizeEntries(entries)), nil
+}
+
+// foldCase drops entries whose paths differ from an earlier one only in case
+// when both name the same stored file, as they do on a case-insensitive
+// volume (the macOS default). fs.SamePath decides, so on a case-sensitive
+// volume, or while nothing is stored yet, every spelling is kept. Of two
+// spellings the one the stored file actually has wins.
+func (t *Tracker) foldCase(entries []Entry) []Entry {
+	kept := make([]Entry, 0, len(entries))
+	groups := make(map[string][]int, len(entries))
+	for _, entry := range entries {
+		key := strings.ToLower(entry.Path)
+		duplicate := false
+		for _, i := range groups[key] {
+			if !fs.SamePath(t.StoragePath(kept[i]), t.StoragePath(entry)) {
+				continue
+			}
+			duplicate = true
+			if !t.storedAs(kept[i]) && t.storedAs(entry) {
+				kept[i] = entry
+			}
+			break
+		}
+		if !duplicate {
+			groups[key] = append(groups[key], len(kept))
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// storedAs reports whether every element of entry's storage path below the
+// repository is spelled on disk exactly as in the entry.
+func (t *Tracker) storedAs(entry Entry) bool {
+	rel, err := filepath.Rel(t.repoPath, t.StoragePath(entry))
+	if err != nil {
+		return false
+	}
+	dir := t.repoPath
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		dirEntries, err := os.ReadDir(dir)
+		if err != nil || !slices.ContainsFunc(dirEntries, func(e os.DirEntry) bool { return e.Name() == name }) {
+			return false
+		}
+		dir = filepath.Join(dir, name)
+	}
+	return true
+}
+
+// readEntries returns the raw .lnk file content and its entries as written.
+func (t *Tracker) readEntries() ([]byte, []Entry, error) {
 	lnkFile := filepath.Join(t.repoPath, t.LnkFileName())
 
 	if _, err := os.Stat(lnkFile); os.IsNotExist(err) {
-		return []string{}, nil
+		return nil, []Entry{}, nil
 	}
 
 	content, err := os.ReadFile(lnkFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read .lnk file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read .lnk file: %w", err)
+	}
+
+	entries, err := ParseEntries(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse .lnk file: %w", err)
+	}
+	if entries == nil {
+		return content, []Entry{}, nil
 	}
 
-	if len(content) == 0 {
-		return []string{}, nil
+	return content, entries, nil
+}
+
+// Normalize rewrites the .lnk file in the form GetEntries reads it as:
+// deduplicated (case-folded where the volume ignores case), cleaned and
+// sorted, in the current format. The file is left
+// untouched when it is already in that form.
+func (t *Tracker) Normalize() (*NormalizeResult, error) {
+	content, entries, err := t.readEntries()
+	if err != nil {
+		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
-	var items []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			items = append(items, line)
-		}
+	normalized := t.foldCase(NormalizeEntries(entries))
+	want, err := FormatEntries(normalized)
+	if err != nil {
+		return nil, err
 	}
 
-	return items, nil
+	result := &NormalizeResult{Removed: len(entries) - len(normalized)}
+	if bytes.Equal(content, want) {
+		return result, nil
+	}
+
+	if err := t.WriteEntries(normalized); err != nil {
+		return nil, err
+	}
+	result.Rewritten = true
+
+	return result, nil
 }
 
-// AddManagedItem adds an item to the .lnk tracking file.
+// AddManagedItem adds an item to the .lnk tracking file, recording the
+// current time as when it was first managed.
 func (t *Tracker) AddManagedItem(relativePath string) error {
-	items, err := t.GetManagedItems()
+	return t.AddEntry(Entry{Path: relativePath})
+}
+
+// AddEntry is AddManagedItem for an entry with metadata such as Copy. A zero
+// AddedAt is stamped with the current time.
+func (t *Tracker) AddEntry(entry Entry) error {
+	entries, err := t.GetEntries()
 	if err != nil {
 		return fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	if slices.Contains(items, relativePath) {
+	if slices.ContainsFunc(entries, func(e Entry) bool { return e.Path == entry.Path }) {
 		return nil // Already managed
 	}
 
-	items = append(items, relativePath)
-	sort.Strings(items)
+	if entry.AddedAt.IsZero() {
+		entry.AddedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	entries = append(entries, entry)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
 
-	return t.WriteManagedItems(items)
+	return t.WriteEntries(entries)
 }
 
 // RemoveManagedItem removes an item from the .lnk tracking file.
 func (t *Tracker) RemoveManagedItem(relativePath string) error {
-	items, err := t.GetManagedItems()
+	entries, err := t.GetEntries()
 	if err != nil {
 		return fmt.Errorf("failed to get managed items: %w", err)
 	}
 
-	var newItems []string
-	for _, item := range items {
-		if item != relativePath {
-			newItems = append(newItems, item)
+	var newEntries []Entry
+	for _, entry := range entries {
+		if entry.Path != relativePath {
+			newEntries = append(newEntries, entry)
 		}
 	}
 
-	return t.WriteManagedItems(newItems)
+	return t.WriteEntries(newEntries)
 }
 
-// WriteManagedItems writes the list of managed items to .lnk file.
+// GetEntry returns the tracked entry for relativePath and whether it exists.
+func (t *Tracker) GetEntry(relativePath string) (Entry, bool, error) {
+	entries, err := t.GetEntries()
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	for _, entry := range entries {
+		if entry.Path == relativePath {
+			return entry, true, nil
+		}
+	}
+
+	return Entry{}, false, nil
+}
+
+// WriteManagedItems writes the list of managed items to .lnk file, keeping
+// the recorded metadata of items that were already tracked.
 func (t *Tracker) WriteManagedItems(items []string) error {
-	lnkFile := filepath.Join(t.repoPath, t.LnkFileName())
+	existing, err := t.GetEntries()
+	if err != nil {
+		return fmt.Errorf("failed to get managed items: %w", err)
+	}
 
-	content := strings.Join(items, "\n")
-	if len(items) > 0 {
-		content += "\n"
+	known := make(map[string]Entry, len(existing))
+	for _, entry := range existing {
+		known[entry.Path] = entry
 	}
 
-	err := os.WriteFile(lnkFile, []byte(content), 0644)
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entry, ok := known[item]
+		if !ok {
+			entry = Entry{Path: item}
+		}
+		entries = append(entries, entry)
+	}
+
+	return t.WriteEntries(entries)
+}
+
+// WriteEntries writes entries to the .lnk file in the given order.
+// The file is replaced atomically, so a failed or interrupted write leaves
+// the previous contents intact.
+func (t *Tracker) WriteEntries(entries []Entry) error {
+	lnkFile := filepath.Join(t.repoPath, t.LnkFileName())
+
+	content, err := FormatEntries(entries)
 	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lnkFile), 0755); err != nil {
 		return fmt.Errorf("failed to write .lnk file: %w", err)
 	}
+	if err := writeFileAtomic(lnkFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write .lnk file: %w", err)
+	}
+
+	return nil
+}
+
+// syncFile flushes a written temp file to disk; tests replace it to simulate
+// a failed write.
+var syncFile = (*os.File).Sync
+
+// writeFileAtomic writes content to a temp file next to path, fsyncs it and
+// renames it over path. The temp name deliberately does not start with
+// ".lnk." so a leftover is never mistaken for a host tracking file.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "lnk-index-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := syncFile(tmp); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,73 @@
+package tracker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteEntriesFailureKeepsPreviousContent(t *testing.T) {
+	repoPath := t.TempDir()
+	tr := New(repoPath, "")
+
+	if err := tr.WriteEntries([]Entry{{Path: ".bashrc"}, {Path: ".vimrc"}}); err != nil {
+		t.Fatalf("initial write failed: %v", err)
+	}
+	lnkFile := filepath.Join(repoPath, ".lnk")
+	before, err := os.ReadFile(lnkFile)
+	if err != nil {
+		t.Fatalf("failed to read tracking file: %v", err)
+	}
+
+	original := syncFile
+	t.Cleanup(func() { syncFile = original })
+	syncFile = func(*os.File) error { return errors.New("disk full") }
+
+	if err := tr.WriteEntries([]Entry{{Path: ".zshrc"}}); err == nil {
+		t.Fatal("expected write to fail")
+	}
+
+	after, err := os.ReadFile(lnkFile)
+	if err != nil {
+		t.Fatalf("failed to read tracking file: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("tracking file changed after failed write:\n got %q\nwant %q", after, before)
+	}
+
+	files, err := os.ReadDir(repoPath)
+	if err != nil {
+		t.Fatalf("failed to list repo: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only .lnk in repo, found %d entries", len(files))
+	}
+}
+
+func TestWriteEntriesReplacesFile(t *testing.T) {
+	repoPath := t.TempDir()
+	tr := New(repoPath, "work")
+
+	for _, paths := range [][]string{{".bashrc", ".vimrc"}, {".zshrc"}} {
+		if err := tr.WriteManagedItems(paths); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	items, err := tr.GetManagedItems()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(items) != 1 || items[0] != ".zshrc" {
+		t.Errorf("got %v, want [.zshrc]", items)
+	}
+
+	info, err := os.Stat(filepath.Join(repoPath, ".lnk.work"))
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("got mode %v, want 0644", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,198 @@
+// Package lnk is the library interface to lnk, for Go programs that manage
+// dotfiles in an lnk repository without running the lnk binary.
+//
+// A Client applies its options to every call, and every call takes a
+// context: git runs under it, so cancelling the context kills a push or pull
+// in progress. A call whose context is done returns ctx.Err(), which
+// errors.Is matches against context.Canceled or context.DeadlineExceeded.
+// Other failures are the sentinel errors below, matched the same way.
+//
+// Calls that change the repository take the same lock as the lnk command,
+// so a Client and a running lnk never interleave their updates.
+package lnk
+
+import (
+	"context"
+	"slices"
+
+	core "github.com/yarlson/lnk/internal/lnk"
+)
+
+// ManagedFile describes a file or directory lnk manages: its path in $HOME,
+// its path in the index and where it is stored in the repository.
+type ManagedFile = core.ManagedFile
+
+// StatusInfo reports how the repository compares to its remote.
+type StatusInfo = core.StatusInfo
+
+// RestoreInfo reports the symlinks a pull restored, the files it moved out
+// of their way, and the items it found in place, missing or could not restore.
+type RestoreInfo = core.RestoreInfo
+
+// RestoreFailure is one item a pull could not restore, with the reason.
+type RestoreFailure = core.RestoreFailure
+
+// Event is one thing a Client did: a file added, removed or restored, a
+// commit recorded or a push made. Only the fields of its Kind are set.
+type Event = core.Event
+
+// EventKind names what an Event reports.
+type EventKind = core.EventKind
+
+// The kinds of Event.
+const (
+	FileAdded       = core.FileAdded
+	FileRemoved     = core.FileRemoved
+	SymlinkRestored = core.SymlinkRestored
+	GitCommitted    = core.GitCommitted
+	GitPushed       = core.GitPushed
+)
+
+// Sentinel errors returned by Client methods.
+var (
+	ErrNotInitialized = core.ErrNotInitialized
+	ErrAlreadyManaged = core.ErrAlreadyManaged
+	ErrNotManaged     = core.ErrNotManaged
+	ErrInvalidHost    = core.ErrInvalidHost
+	ErrLocked         = core.ErrLocked
+	ErrAuthRequired   = core.ErrAuthRequired
+	ErrRemoteNotFound = core.ErrRemoteNotFound
+	ErrRebaseConflict = core.ErrRebaseConflict
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHost makes the Client manage the configuration of host instead of the
+// common one. Every call checks the name first, failing with ErrInvalidHost
+// for one that could escape the repository.
+func WithHost(host string) Option {
+	return func(c *Client) {
+		c.host = host
+	}
+}
+
+// WithHome makes the Client use dir as the home directory instead of $HOME;
+// the repository defaults to dir/.config/lnk (or LNK_REPO_NAME in place of
+// lnk) unless LNK_HOME or XDG_CONFIG_HOME is set.
+func WithHome(dir string) Option {
+	return func(c *Client) {
+		c.opts = append(c.opts, core.WithHome(dir))
+	}
+}
+
+// WithRemote makes pushes and pulls use the remote called name instead of
+// the default one (origin, or the first remote without an origin).
+func WithRemote(name string) Option {
+	return func(c *Client) {
+		c.opts = append(c.opts, core.WithRemote(name))
+	}
+}
+
+// WithRebase makes pulls rebase local commits onto the remote's instead of
+// merging them, as pull.rebase in config.toml does for the CLI.
+func WithRebase(enabled bool) Option {
+	return func(c *Client) {
+		c.opts = append(c.opts, core.WithRebase(enabled))
+	}
+}
+
+// WithSSHCommand makes pushes and pulls run git with command as
+// GIT_SSH_COMMAND, as git.ssh_command in config.toml does.
+func WithSSHCommand(command string) Option {
+	return func(c *Client) {
+		c.opts = append(c.opts, core.WithSSHCommand(command))
+	}
+}
+
+// WithEventHandler makes the Client call h with an Event for each file it
+// adds, removes or restores and each commit and push it makes, as they
+// happen and on the calling goroutine.
+func WithEventHandler(h func(Event)) Option {
+	return func(c *Client) {
+		c.opts = append(c.opts, core.WithEventHandler(h))
+	}
+}
+
+// Client runs lnk operations on one repository. Its methods are safe for
+// concurrent use; each builds its own lnk instance.
+type Client struct {
+	host string
+	opts []core.Option
+}
+
+// New creates a Client with the given options.
+func New(opts ...Option) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// AddFile moves the file or directory at path into the repository, links it
+// back and commits it as "lnk: added <name>".
+func (c *Client) AddFile(ctx context.Context, path string) (*ManagedFile, error) {
+	return run(ctx, c, func(l *core.Lnk) (*ManagedFile, error) { return l.Add(path) })
+}
+
+// RemoveFile replaces the symlink at path with the managed file, stops
+// tracking it and commits the removal.
+func (c *Client) RemoveFile(ctx context.Context, path string) (*ManagedFile, error) {
+	return run(ctx, c, func(l *core.Lnk) (*ManagedFile, error) { return l.Remove(path) })
+}
+
+// PushChanges commits any uncommitted changes with message and pushes to the
+// remote.
+func (c *Client) PushChanges(ctx context.Context, message string) error {
+	_, err := run(ctx, c, func(l *core.Lnk) (struct{}, error) { return struct{}{}, l.Push(message) })
+	return err
+}
+
+// PullChanges pulls from the remote and restores the symlinks of the managed
+// files.
+func (c *Client) PullChanges(ctx context.Context) (*RestoreInfo, error) {
+	return run(ctx, c, (*core.Lnk).Pull)
+}
+
+// GetStatus reports how far the repository is ahead of or behind its remote
+// and whether it has uncommitted changes. It does not fetch first.
+func (c *Client) GetStatus(ctx context.Context) (*StatusInfo, error) {
+	return run(ctx, c, (*core.Lnk).Status)
+}
+
+// ListManagedFiles returns the paths, relative to $HOME, of the managed
+// files in the Client's configuration.
+func (c *Client) ListManagedFiles(ctx context.Context) ([]string, error) {
+	return run(ctx, c, (*core.Lnk).List)
+}
+
+// ListAllHosts returns the managed files of the common configuration, keyed
+// by "", and of every host with a tracking file in the repository, keyed by
+// the host name. It ignores WithHost.
+func (c *Client) ListAllHosts(ctx context.Context) (map[string][]ManagedFile, error) {
+	return run(ctx, c, (*core.Lnk).ListAllHosts)
+}
+
+// run calls fn on an lnk instance bound to ctx. It fails with ctx.Err()
+// without calling fn when ctx is already done, and reports ctx.Err() instead
+// of the git failure when ctx ended while fn ran.
+func run[T any](ctx context.Context, c *Client, fn func(*core.Lnk) (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if err := core.ValidateHost(c.host); err != nil {
+		return zero, err
+	}
+
+	opts := append(slices.Clone(c.opts), core.WithHost(c.host), core.WithContext(ctx))
+	result, err := fn(core.NewLnk(opts...))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return zero, ctxErr
+		}
+		return zero, err
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package lnk
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	core "github.com/yarlson/lnk/internal/lnk"
+	"github.com/yarlson/lnk/internal/lock"
+)
+
+// newTestClient returns a Client for an initialized repository in a fresh
+// home directory, along with that directory.
+func newTestClient(t *testing.T, opts ...Option) (*Client, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("LNK_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	if err := core.NewLnk(core.WithHome(home)).Init(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	return New(append([]Option{WithHome(home)}, opts...)...), home
+}
+
+func TestClientAddListRemove(t *testing.T) {
+	client, home := newTestClient(t)
+	ctx := context.Background()
+	vimrc := filepath.Join(home, ".vimrc")
+	if err := os.WriteFile(vimrc, []byte("set number\n"), 0644); err != nil {
+		t.Fatalf("failed to write .vimrc: %v", err)
+	}
+
+	managed, err := client.AddFile(ctx, vimrc)
+	if err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	if managed.RelativePath != ".vimrc" {
+		t.Errorf("RelativePath = %q, want .vimrc", managed.RelativePath)
+	}
+	if info, err := os.Lstat(vimrc); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected %s to be a symlink after AddFile", vimrc)
+	}
+
+	files, err := client.ListManagedFiles(ctx)
+	if err != nil {
+		t.Fatalf("ListManagedFiles failed: %v", err)
+	}
+	if !slices.Equal(files, []string{".vimrc"}) {
+		t.Errorf("ListManagedFiles = %v, want [.vimrc]", files)
+	}
+
+	if _, err := client.AddFile(ctx, vimrc); !errors.Is(err, ErrAlreadyManaged) {
+		t.Errorf("second AddFile error = %v, want ErrAlreadyManaged", err)
+	}
+
+	if _, err := client.RemoveFile(ctx, vimrc); err != nil {
+		t.Fatalf("RemoveFile failed: %v", err)
+	}
+	if info, err := os.Lstat(vimrc); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected %s to be a regular file after RemoveFile", vimrc)
+	}
+}
+
+func TestClientCanceledContext(t *testing.T) {
+	client, home := newTestClient(t)
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.AddFile(ctx, bashrc); !errors.Is(err, context.Canceled) {
+		t.Errorf("AddFile error = %v, want context.Canceled", err)
+	}
+	if err := client.PushChanges(ctx, "update"); !errors.Is(err, context.Canceled) {
+		t.Errorf("PushChanges error = %v, want context.Canceled", err)
+	}
+	if info, err := os.Lstat(bashrc); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected %s to stay a regular file", bashrc)
+	}
+}
+
+func TestClientCancelWhileLocked(t *testing.T) {
+	client, home := newTestClient(t)
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatalf("failed to write .bashrc: %v", err)
+	}
+
+	held, err := lock.Acquire(context.Background(), filepath.Join(home, ".config", "lnk", ".git", "lnk.lock"), time.Second)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	defer func() { _ = held.Release() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.AddFile(ctx, bashrc); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AddFile error = %v, want context.DeadlineExceeded", err)
+	}
+	if waited := time.Since(start); waited > core.DefaultLockTimeout/2 {
+		t.Errorf("AddFile waited %s for the lock after its context ended", waited)
+	}
+	if info, err := os.Lstat(bashrc); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected %s to stay a regular file", bashrc)
+	}
+}
+
+func TestClientInvalidHost(t *testing.T) {
+	client, _ := newTestClient(t, WithHost("../escape"))
+
+	if _, err := client.ListManagedFiles(context.Background()); !errors.Is(err, ErrInvalidHost) {
+		t.Errorf("ListManagedFiles error = %v, want ErrInvalidHost", err)
+	}
+}
+
+func TestClientListAllHosts(t *testing.T) {
+	ctx := context.Background()
+	client, home := newTestClient(t)
+	add := func(c *Client, name string) {
+		t.Helper()
+		path := filepath.Join(home, name)
+		if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := c.AddFile(ctx, path); err != nil {
+			t.Fatalf("AddFile %s failed: %v", name, err)
+		}
+	}
+	add(client, ".vimrc")
+	add(New(WithHome(home), WithHost("work")), ".bashrc")
+	add(New(WithHome(home), WithHost("work")), ".npmrc")
+	add(New(WithHome(home), WithHost("laptop")), ".tmux.conf")
+
+	all, err := New(WithHome(home), WithHost("work")).ListAllHosts(ctx)
+	if err != nil {
+		t.Fatalf("ListAllHosts failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"":       {".vimrc"},
+		"work":   {".bashrc", ".npmrc"},
+		"laptop": {".tmux.conf"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("ListAllHosts hosts = %v, want %v", slices.Collect(maps.Keys(all)), slices.Collect(maps.Keys(want)))
+	}
+	for host, paths := range want {
+		var got []string
+		for _, file := range all[host] {
+			got = append(got, file.RelativePath)
+			if file.Host != host {
+				t.Errorf("%s: Host = %q, want %q", file.RelativePath, file.Host, host)
+			}
+			if file.Path != filepath.Join(home, file.RelativePath) {
+				t.Errorf("%s: Path = %q, want it under %s", file.RelativePath, file.Path, home)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, paths) {
+			t.Errorf("ListAllHosts[%q] = %v, want %v", host, got, paths)
+		}
+	}
+}